@@ -507,7 +507,10 @@ func autoConvert_v1beta3_CloudStackIsolatedNetworkSpec_To_v1beta1_CloudStackIsol
 	out.ControlPlaneEndpoint = in.ControlPlaneEndpoint
 	// WARNING: in.FailureDomainName requires manual conversion: does not exist in peer-type
 	// WARNING: in.CIDR requires manual conversion: does not exist in peer-type
+	// WARNING: in.IPv6CIDR requires manual conversion: does not exist in peer-type
 	// WARNING: in.Domain requires manual conversion: does not exist in peer-type
+	// WARNING: in.Offering requires manual conversion: does not exist in peer-type
+	// WARNING: in.EgressRules requires manual conversion: does not exist in peer-type
 	return nil
 }
 
@@ -523,6 +526,7 @@ func autoConvert_v1beta3_CloudStackIsolatedNetworkStatus_To_v1beta1_CloudStackIs
 	out.PublicIPID = in.PublicIPID
 	out.LBRuleID = in.LBRuleID
 	// WARNING: in.LoadBalancerRuleIDs requires manual conversion: does not exist in peer-type
+	// WARNING: in.OfferingID requires manual conversion: does not exist in peer-type
 	// WARNING: in.APIServerLoadBalancer requires manual conversion: does not exist in peer-type
 	out.Ready = in.Ready
 	return nil
@@ -643,6 +647,7 @@ func autoConvert_v1beta3_CloudStackMachineSpec_To_v1beta1_CloudStackMachineSpec(
 	out.AffinityGroupRef = (*corev1.ObjectReference)(unsafe.Pointer(in.AffinityGroupRef))
 	out.ProviderID = (*string)(unsafe.Pointer(in.ProviderID))
 	// WARNING: in.FailureDomainName requires manual conversion: does not exist in peer-type
+	// WARNING: in.PlacementStrategy requires manual conversion: does not exist in peer-type
 	// WARNING: in.UncompressedUserData requires manual conversion: does not exist in peer-type
 	return nil
 }
@@ -762,6 +767,8 @@ func autoConvert_v1beta3_CloudStackMachineStatus_To_v1beta1_CloudStackMachineSta
 	out.Ready = in.Ready
 	// WARNING: in.Status requires manual conversion: does not exist in peer-type
 	// WARNING: in.Reason requires manual conversion: does not exist in peer-type
+	// WARNING: in.Conditions requires manual conversion: does not exist in peer-type
+	// WARNING: in.Placement requires manual conversion: does not exist in peer-type
 	return nil
 }
 
@@ -974,6 +981,10 @@ func autoConvert_v1beta3_Network_To_v1beta1_Network(in *v1beta3.Network, out *Ne
 	out.Name = in.Name
 	out.Type = in.Type
 	// WARNING: in.CIDR requires manual conversion: does not exist in peer-type
+	// WARNING: in.IPv6CIDR requires manual conversion: does not exist in peer-type
 	// WARNING: in.Domain requires manual conversion: does not exist in peer-type
+	// WARNING: in.Offering requires manual conversion: does not exist in peer-type
+	// WARNING: in.EgressRules requires manual conversion: does not exist in peer-type
+	// WARNING: in.VPC requires manual conversion: does not exist in peer-type
 	return nil
 }
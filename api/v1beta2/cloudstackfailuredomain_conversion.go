@@ -17,6 +17,7 @@ limitations under the License.
 package v1beta2
 
 import (
+	machineryconversion "k8s.io/apimachinery/pkg/conversion"
 	"sigs.k8s.io/controller-runtime/pkg/conversion"
 
 	"sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
@@ -33,3 +34,11 @@ func (r *CloudStackFailureDomain) ConvertFrom(srcRaw conversion.Hub) error {
 
 	return Convert_v1beta3_CloudStackFailureDomain_To_v1beta2_CloudStackFailureDomain(src, r, nil)
 }
+
+func Convert_v1beta3_CloudStackFailureDomainSpec_To_v1beta2_CloudStackFailureDomainSpec(in *v1beta3.CloudStackFailureDomainSpec, out *CloudStackFailureDomainSpec, s machineryconversion.Scope) error {
+	return autoConvert_v1beta3_CloudStackFailureDomainSpec_To_v1beta2_CloudStackFailureDomainSpec(in, out, s)
+}
+
+func Convert_v1beta3_CloudStackFailureDomainStatus_To_v1beta2_CloudStackFailureDomainStatus(in *v1beta3.CloudStackFailureDomainStatus, out *CloudStackFailureDomainStatus, s machineryconversion.Scope) error {
+	return autoConvert_v1beta3_CloudStackFailureDomainStatus_To_v1beta2_CloudStackFailureDomainStatus(in, out, s)
+}
@@ -39,6 +39,15 @@ type CloudStackClusterSpec struct {
 	// If not specified, no load balancer will be created for the API server.
 	//+optional
 	APIServerLoadBalancer *APIServerLoadBalancer `json:"apiServerLoadBalancer,omitempty"`
+
+	// APIServerVMLoadBalancer deploys load balancer VMs for the API server in a shared network, and points the control
+	// plane endpoint at them.
+	//+optional
+	APIServerVMLoadBalancer *APIServerVMLoadBalancer `json:"apiServerVMLoadBalancer,omitempty"`
+
+	// Bastion deploys a jump host with a public IP address in the network of a failure domain.
+	//+optional
+	Bastion *Bastion `json:"bastion,omitempty"`
 }
 
 // The status of the CloudStackCluster object.
@@ -48,6 +57,19 @@ type CloudStackClusterStatus struct {
 	//+optional
 	FailureDomains clusterv1.FailureDomains `json:"failureDomains,omitempty"`
 
+	// APIServerVMLoadBalancer reports the load balancer VMs of the API server, if any are deployed.
+	//+optional
+	APIServerVMLoadBalancer *APIServerVMLoadBalancerStatus `json:"apiServerVMLoadBalancer,omitempty"`
+
+	// Bastion reports the bastion host, if one is deployed.
+	//+optional
+	Bastion *BastionStatus `json:"bastion,omitempty"`
+
+	// ManagedTenants records the projects and accounts CAPC manages for the failure domains of the cluster, so they're
+	// deleted with the cluster even once their failure domain is removed or renamed.
+	//+optional
+	ManagedTenants []ManagedTenantRecord `json:"managedTenants,omitempty"`
+
 	// Reflects the readiness of the CS cluster.
 	//+optional
 	Ready bool `json:"ready"`
@@ -17,15 +17,19 @@ limitations under the License.
 package v1beta3
 
 import (
+	"context"
 	"fmt"
 	"net"
+	"reflect"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"sigs.k8s.io/cluster-api/util/annotations"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
@@ -36,7 +40,13 @@ import (
 // log is for logging in this package.
 var cloudstackclusterlog = logf.Log.WithName("cloudstackcluster-resource")
 
+// cloudstackclusterReader reads the CloudStackClusterIdentities and namespaces that failure domain credentials are
+// checked against. The identity checks are skipped while it's unset.
+var cloudstackclusterReader client.Reader
+
 func (r *CloudStackCluster) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	cloudstackclusterReader = mgr.GetAPIReader()
+
 	return ctrl.NewWebhookManagedBy(mgr).
 		For(r).
 		Complete()
@@ -76,29 +86,307 @@ func (r *CloudStackCluster) ValidateCreate() (admission.Warnings, error) {
 					field.NewPath("spec", "failureDomains", "Zone", "Network"),
 					"each Zone requires a Network specification"))
 			}
-			if fdSpec.ACSEndpoint.Name == "" || fdSpec.ACSEndpoint.Namespace == "" {
+			if fdSpec.IdentityRef != nil {
+				if fdSpec.ACSEndpoint.Name != "" || fdSpec.ACSEndpoint.Namespace != "" {
+					errorList = append(errorList, field.Forbidden(
+						field.NewPath("spec", "failureDomains", "identityRef"),
+						"identityRef cannot be combined with ACSEndpoint"))
+				}
+				if fdSpec.IdentityRef.Name == "" {
+					errorList = append(errorList, field.Required(
+						field.NewPath("spec", "failureDomains", "identityRef", "name"),
+						"Name is required"))
+				}
+			} else if fdSpec.ACSEndpoint.Name == "" || fdSpec.ACSEndpoint.Namespace == "" {
 				errorList = append(errorList, field.Required(
 					field.NewPath("spec", "failureDomains", "ACSEndpoint"),
 					"Name and Namespace are required"))
 			}
+			errorList = validateCredentials(r.Namespace, fdSpec, errorList)
 			if fdSpec.Zone.Network.CIDR != "" {
-				if _, errMsg := ValidateCIDR(fdSpec.Zone.Network.CIDR); errMsg != nil {
+				if cidr, errMsg := ValidateCIDR(fdSpec.Zone.Network.CIDR); errMsg != nil {
 					errorList = append(errorList, field.Invalid(
 						field.NewPath("spec", "failureDomains", "Zone", "Network"), fdSpec.Zone.Network.CIDR, "must be valid CIDR: "+errMsg.Error()))
+				} else if cidr.IP.To4() == nil {
+					errorList = append(errorList, field.Invalid(
+						field.NewPath("spec", "failureDomains", "Zone", "Network"), fdSpec.Zone.Network.CIDR,
+						"must be an IPv4 CIDR, IPv6 ranges go in ipv6CIDR"))
 				}
 			}
+			errorList = validateIPv6CIDR(fdSpec.Zone.Network, errorList)
 			if fdSpec.Zone.Network.Domain != "" {
 				for _, errMsg := range validation.IsDNS1123Subdomain(fdSpec.Zone.Network.Domain) {
 					errorList = append(errorList, field.Invalid(
 						field.NewPath("spec", "failureDomains", "Zone", "Network"), fdSpec.Zone.Network.Domain, errMsg))
 				}
 			}
+			errorList = validateVPC(fdSpec.Zone.Network, errorList)
+			errorList = validateNetworkOffering(fdSpec.Zone.Network, errorList)
+			errorList = validateEgressRules(fdSpec.Zone.Network, errorList)
+			if fdSpec.ManagedTenant != nil {
+				if fdSpec.Account != "" || fdSpec.Project != "" {
+					errorList = append(errorList, field.Forbidden(
+						field.NewPath("spec", "failureDomains", "managedTenant"),
+						"managedTenant cannot be combined with account or project"))
+				}
+				if fdSpec.ManagedTenant.Type != ManagedTenantTypeProject && fdSpec.ManagedTenant.Type != ManagedTenantTypeAccount {
+					errorList = append(errorList, field.NotSupported(
+						field.NewPath("spec", "failureDomains", "managedTenant", "type"), fdSpec.ManagedTenant.Type,
+						[]string{ManagedTenantTypeProject, ManagedTenantTypeAccount}))
+				}
+			}
 		}
+		errorList = ensureControlPlaneFailureDomain(r.Spec.FailureDomains, errorList)
+		errorList = validateBastion(r.Spec, errorList)
+		errorList = validateAPIServerVMLoadBalancer(r.Spec, errorList)
 	}
 
 	return nil, webhookutil.AggregateObjErrors(r.GroupVersionKind().GroupKind(), r.Name, errorList)
 }
 
+// validateCredentials checks that the failure domain uses credentials the cluster's namespace may use: an ACSEndpoint
+// secret in that namespace, or a CloudStackClusterIdentity that allows the namespace. Identities that don't exist yet
+// are left to the controller, which waits for them.
+func validateCredentials(namespace string, fdSpec CloudStackFailureDomainSpec, errorList field.ErrorList) field.ErrorList {
+	if fdSpec.IdentityRef == nil {
+		if fdSpec.ACSEndpoint.Namespace != "" && fdSpec.ACSEndpoint.Namespace != namespace {
+			errorList = append(errorList, field.Forbidden(
+				field.NewPath("spec", "failureDomains", "ACSEndpoint", "namespace"),
+				"ACSEndpoint must be in the namespace of the cluster, use identityRef to share credentials across namespaces"))
+		}
+
+		return errorList
+	}
+	if cloudstackclusterReader == nil || fdSpec.IdentityRef.Name == "" {
+		return errorList
+	}
+
+	identityRefPath := field.NewPath("spec", "failureDomains", "identityRef")
+	ctx := context.Background()
+	identity := &CloudStackClusterIdentity{}
+	if err := cloudstackclusterReader.Get(ctx, client.ObjectKey{Name: fdSpec.IdentityRef.Name}, identity); err != nil {
+		if errors.IsNotFound(err) {
+			return errorList
+		}
+
+		return append(errorList, field.InternalError(identityRefPath, err))
+	}
+	ns := &corev1.Namespace{}
+	if err := cloudstackclusterReader.Get(ctx, client.ObjectKey{Name: namespace}, ns); err != nil {
+		return append(errorList, field.InternalError(identityRefPath, err))
+	}
+	allowed, err := identity.AllowsNamespace(ns)
+	if err != nil {
+		return append(errorList, field.InternalError(identityRefPath, err))
+	} else if !allowed {
+		errorList = append(errorList, field.Forbidden(identityRefPath, fmt.Sprintf(
+			"CloudStackClusterIdentity %s does not allow use from namespace %s", fdSpec.IdentityRef.Name, namespace)))
+	}
+
+	return errorList
+}
+
+// validateVPC checks the VPC settings of a network, which are only allowed for the VPCTier network type.
+func validateVPC(network Network, errorList field.ErrorList) field.ErrorList {
+	vpcPath := field.NewPath("spec", "failureDomains", "Zone", "Network", "vpc")
+	if network.Type != NetworkTypeVPCTier {
+		if network.VPC != nil {
+			errorList = append(errorList, field.Forbidden(vpcPath, "vpc requires the VPCTier network type"))
+		}
+
+		return errorList
+	}
+	if network.Name == "" {
+		errorList = append(errorList, field.Required(
+			field.NewPath("spec", "failureDomains", "Zone", "Network", "name"), "the VPCTier network type requires the name of the VPC"))
+	}
+	if network.VPC == nil {
+		return errorList
+	}
+	for path, cidr := range map[*field.Path]string{
+		vpcPath.Child("cidr"):                     network.VPC.CIDR,
+		vpcPath.Child("controlPlaneTier", "cidr"): network.VPC.ControlPlaneTier.CIDR,
+		vpcPath.Child("workerTier", "cidr"):       network.VPC.WorkerTier.CIDR,
+	} {
+		if cidr == "" {
+			continue
+		}
+		if _, err := ValidateCIDR(cidr); err != nil {
+			errorList = append(errorList, field.Invalid(path, cidr, "must be valid CIDR: "+err.Error()))
+		}
+	}
+
+	return errorList
+}
+
+// validateNetworkOffering checks the network offering of a network, which only applies to isolated networks created by
+// CAPC.
+func validateNetworkOffering(network Network, errorList field.ErrorList) field.ErrorList {
+	if network.Offering == nil {
+		return errorList
+	}
+	offeringPath := field.NewPath("spec", "failureDomains", "Zone", "Network", "offering")
+	if network.Type == NetworkTypeShared || network.Type == NetworkTypeVPCTier {
+		errorList = append(errorList, field.Forbidden(offeringPath, "offering requires the Isolated network type"))
+	}
+	if network.Offering.ID == "" && network.Offering.Name == "" {
+		errorList = append(errorList, field.Required(offeringPath, "offering requires an ID or a name"))
+	}
+
+	return errorList
+}
+
+// validateIPv6CIDR checks the IPv6 range of a network, which only applies to isolated networks created by CAPC.
+func validateIPv6CIDR(network Network, errorList field.ErrorList) field.ErrorList {
+	if network.IPv6CIDR == "" {
+		return errorList
+	}
+	cidrPath := field.NewPath("spec", "failureDomains", "Zone", "Network", "ipv6CIDR")
+	if network.Type == NetworkTypeShared || network.Type == NetworkTypeVPCTier {
+		errorList = append(errorList, field.Forbidden(cidrPath, "ipv6CIDR requires the Isolated network type"))
+	}
+	cidr, err := ValidateCIDR(network.IPv6CIDR)
+	if err != nil {
+		return append(errorList, field.Invalid(cidrPath, network.IPv6CIDR, "must be valid CIDR: "+err.Error()))
+	}
+	if ones, bits := cidr.Mask.Size(); bits != net.IPv6len*8 || cidr.IP.To4() != nil {
+		errorList = append(errorList, field.Invalid(cidrPath, network.IPv6CIDR, "must be an IPv6 CIDR"))
+	} else if ones > 64 {
+		errorList = append(errorList, field.Invalid(cidrPath, network.IPv6CIDR, "must be a /64 or larger"))
+	}
+
+	return errorList
+}
+
+// validateEgressRules checks the egress rules of a network, which only apply to isolated networks.
+func validateEgressRules(network Network, errorList field.ErrorList) field.ErrorList {
+	if len(network.EgressRules) == 0 {
+		return errorList
+	}
+	rulesPath := field.NewPath("spec", "failureDomains", "Zone", "Network", "egressRules")
+	if network.Type == NetworkTypeShared || network.Type == NetworkTypeVPCTier {
+		errorList = append(errorList, field.Forbidden(rulesPath, "egressRules requires the Isolated network type"))
+	}
+	for idx, rule := range network.EgressRules {
+		if rule.EndPort != 0 && rule.EndPort < rule.StartPort {
+			errorList = append(errorList, field.Invalid(rulesPath.Index(idx).Child("endPort"), rule.EndPort,
+				"must not be lower than startPort"))
+		}
+		for _, cidr := range rule.DestinationCIDRs {
+			if _, err := ValidateCIDR(cidr); err != nil {
+				errorList = append(errorList, field.Invalid(rulesPath.Index(idx).Child("destinationCIDRs"), cidr,
+					"must be valid CIDR: "+err.Error()))
+			}
+		}
+	}
+
+	return errorList
+}
+
+// validateBastion checks that the bastion is placed in a failure domain of the cluster that may use an isolated
+// network, and can be reached from valid CIDRs.
+func validateBastion(spec CloudStackClusterSpec, errorList field.ErrorList) field.ErrorList {
+	bastion := spec.Bastion
+	if bastion == nil {
+		return errorList
+	}
+	bastionPath := field.NewPath("spec", "bastion")
+	var network *Network
+	for idx := range spec.FailureDomains {
+		if spec.FailureDomains[idx].Name == bastion.FailureDomainName {
+			network = &spec.FailureDomains[idx].Zone.Network
+		}
+	}
+	if network == nil {
+		errorList = append(errorList, field.Invalid(bastionPath.Child("failureDomainName"), bastion.FailureDomainName,
+			"must name a failure domain of the cluster"))
+	} else if network.Type == NetworkTypeShared || network.Type == NetworkTypeVPCTier {
+		errorList = append(errorList, field.Forbidden(bastionPath.Child("failureDomainName"),
+			"the bastion requires a failure domain with the Isolated network type"))
+	}
+	if bastion.Offering.ID == "" && bastion.Offering.Name == "" {
+		errorList = append(errorList, field.Required(bastionPath.Child("offering"), "offering requires an ID or a name"))
+	}
+	if bastion.Template.ID == "" && bastion.Template.Name == "" {
+		errorList = append(errorList, field.Required(bastionPath.Child("template"), "template requires an ID or a name"))
+	}
+	for idx, cidr := range bastion.AllowedCIDRs {
+		if _, err := ValidateCIDR(cidr); err != nil {
+			errorList = append(errorList, field.Invalid(bastionPath.Child("allowedCIDRs").Index(idx), cidr,
+				"must be valid CIDR: "+err.Error()))
+		}
+	}
+
+	return errorList
+}
+
+// validateAPIServerVMLoadBalancer checks that the load balancer VMs are placed in a failure domain of the cluster that
+// may use a shared network, and that the virtual IP they hold is the control plane endpoint.
+func validateAPIServerVMLoadBalancer(spec CloudStackClusterSpec, errorList field.ErrorList) field.ErrorList {
+	vmLB := spec.APIServerVMLoadBalancer
+	if vmLB == nil {
+		return errorList
+	}
+	vmLBPath := field.NewPath("spec", "apiServerVMLoadBalancer")
+	var network *Network
+	for idx := range spec.FailureDomains {
+		if spec.FailureDomains[idx].Name == vmLB.FailureDomainName {
+			network = &spec.FailureDomains[idx].Zone.Network
+		}
+	}
+	if network == nil {
+		errorList = append(errorList, field.Invalid(vmLBPath.Child("failureDomainName"), vmLB.FailureDomainName,
+			"must name a failure domain of the cluster"))
+	} else if network.Type == NetworkTypeIsolated || network.Type == NetworkTypeVPCTier {
+		errorList = append(errorList, field.Forbidden(vmLBPath.Child("failureDomainName"),
+			"the load balancer VMs require a failure domain with the Shared network type"))
+	}
+	if spec.APIServerLoadBalancer.IsEnabled() {
+		errorList = append(errorList, field.Forbidden(vmLBPath, "apiServerVMLoadBalancer cannot be combined with apiServerLoadBalancer"))
+	}
+	if vmLB.Offering.ID == "" && vmLB.Offering.Name == "" {
+		errorList = append(errorList, field.Required(vmLBPath.Child("offering"), "offering requires an ID or a name"))
+	}
+	if vmLB.Template.ID == "" && vmLB.Template.Name == "" {
+		errorList = append(errorList, field.Required(vmLBPath.Child("template"), "template requires an ID or a name"))
+	}
+	if vmLB.VirtualIP == "" {
+		if vmLB.ReplicasOrDefault() > 1 {
+			errorList = append(errorList, field.Required(vmLBPath.Child("virtualIP"), "virtualIP is required with two replicas"))
+		}
+	} else if net.ParseIP(vmLB.VirtualIP) == nil {
+		errorList = append(errorList, field.Invalid(vmLBPath.Child("virtualIP"), vmLB.VirtualIP, "must be an IP address"))
+	} else if host := spec.ControlPlaneEndpoint.Host; host != "" && host != vmLB.VirtualIP {
+		errorList = append(errorList, field.Invalid(field.NewPath("spec", "controlPlaneEndpoint", "host"), host,
+			"must match apiServerVMLoadBalancer.virtualIP"))
+	}
+
+	return errorList
+}
+
+// failureDomainNetworkName returns the name of the network of the named failure domain.
+func failureDomainNetworkName(spec CloudStackClusterSpec, fdName string) string {
+	for _, fdSpec := range spec.FailureDomains {
+		if fdSpec.Name == fdName {
+			return fdSpec.Zone.Network.Name
+		}
+	}
+
+	return ""
+}
+
+// ensureControlPlaneFailureDomain adds an error if no failure domain may hold control plane machines.
+func ensureControlPlaneFailureDomain(fdSpecs []CloudStackFailureDomainSpec, errorList field.ErrorList) field.ErrorList {
+	for _, fdSpec := range fdSpecs {
+		if fdSpec.IsControlPlaneEligible() {
+			return errorList
+		}
+	}
+
+	return append(errorList, field.Forbidden(field.NewPath("spec", "failureDomains", "controlPlane"),
+		"at least one failure domain must allow control plane machines"))
+}
+
 // ValidateUpdate implements webhook.Validator so a webhook will be registered for the type.
 func (r *CloudStackCluster) ValidateUpdate(old runtime.Object) (admission.Warnings, error) {
 	cloudstackclusterlog.V(1).Info("entered validate update webhook", "api resource name", r.Name)
@@ -116,6 +404,58 @@ func (r *CloudStackCluster) ValidateUpdate(old runtime.Object) (admission.Warnin
 	if err := ValidateFailureDomainUpdates(oldSpec.FailureDomains, spec.FailureDomains); err != nil {
 		errorList = append(errorList, err)
 	}
+	errorList = ensureControlPlaneFailureDomain(spec.FailureDomains, errorList)
+	errorList = validateBastion(spec, errorList)
+	errorList = validateAPIServerVMLoadBalancer(spec, errorList)
+	// Only credentials that are added or changed are checked, clusters keep the credentials they were admitted with.
+	oldFDsByName := map[string]CloudStackFailureDomainSpec{}
+	for _, oldFD := range oldSpec.FailureDomains {
+		oldFDsByName[oldFD.Name] = oldFD
+	}
+	for _, fdSpec := range spec.FailureDomains {
+		oldFD, found := oldFDsByName[fdSpec.Name]
+		if !found || oldFD.ACSEndpoint != fdSpec.ACSEndpoint || !reflect.DeepEqual(oldFD.IdentityRef, fdSpec.IdentityRef) {
+			errorList = validateCredentials(r.Namespace, fdSpec, errorList)
+		}
+	}
+	if spec.Bastion != nil && oldSpec.Bastion != nil { // The bastion VM isn't redeployed, only its allowed CIDRs may change.
+		errorList = webhookutil.EnsureEqualStrings(
+			spec.Bastion.FailureDomainName, oldSpec.Bastion.FailureDomainName, "bastion.failureDomainName", errorList)
+		errorList = webhookutil.EnsureEqualStrings(spec.Bastion.Offering.ID, oldSpec.Bastion.Offering.ID, "bastion.offering", errorList)
+		errorList = webhookutil.EnsureEqualStrings(spec.Bastion.Offering.Name, oldSpec.Bastion.Offering.Name, "bastion.offering", errorList)
+		errorList = webhookutil.EnsureEqualStrings(spec.Bastion.Template.ID, oldSpec.Bastion.Template.ID, "bastion.template", errorList)
+		errorList = webhookutil.EnsureEqualStrings(spec.Bastion.Template.Name, oldSpec.Bastion.Template.Name, "bastion.template", errorList)
+		errorList = webhookutil.EnsureEqualStrings(spec.Bastion.SSHKey, oldSpec.Bastion.SSHKey, "bastion.sshKey", errorList)
+		errorList = webhookutil.EnsureEqualStrings(
+			failureDomainNetworkName(spec, spec.Bastion.FailureDomainName),
+			failureDomainNetworkName(oldSpec, oldSpec.Bastion.FailureDomainName),
+			"bastion.failureDomainName.zone.network.name", errorList)
+	}
+	if newLB, oldLB := spec.APIServerVMLoadBalancer, oldSpec.APIServerVMLoadBalancer; newLB != nil && oldLB != nil {
+		// The load balancer VMs aren't redeployed, and the control plane endpoint can't move.
+		errorList = webhookutil.EnsureEqualStrings(
+			newLB.FailureDomainName, oldLB.FailureDomainName, "apiServerVMLoadBalancer.failureDomainName", errorList)
+		errorList = webhookutil.EnsureEqualStrings(newLB.Offering.ID, oldLB.Offering.ID, "apiServerVMLoadBalancer.offering", errorList)
+		errorList = webhookutil.EnsureEqualStrings(newLB.Offering.Name, oldLB.Offering.Name, "apiServerVMLoadBalancer.offering", errorList)
+		errorList = webhookutil.EnsureEqualStrings(newLB.Template.ID, oldLB.Template.ID, "apiServerVMLoadBalancer.template", errorList)
+		errorList = webhookutil.EnsureEqualStrings(newLB.Template.Name, oldLB.Template.Name, "apiServerVMLoadBalancer.template", errorList)
+		errorList = webhookutil.EnsureEqualStrings(newLB.SSHKey, oldLB.SSHKey, "apiServerVMLoadBalancer.sshKey", errorList)
+		errorList = webhookutil.EnsureEqualStrings(newLB.VirtualIP, oldLB.VirtualIP, "apiServerVMLoadBalancer.virtualIP", errorList)
+		if newLB.ReplicasOrDefault() != oldLB.ReplicasOrDefault() {
+			errorList = append(errorList, field.Forbidden(
+				field.NewPath("spec", "apiServerVMLoadBalancer", "replicas"), "apiServerVMLoadBalancer.replicas"))
+		}
+		errorList = webhookutil.EnsureEqualStrings(
+			failureDomainNetworkName(spec, newLB.FailureDomainName),
+			failureDomainNetworkName(oldSpec, oldLB.FailureDomainName),
+			"apiServerVMLoadBalancer.failureDomainName.zone.network.name", errorList)
+	}
+
+	if oldSpec.ControlPlaneEndpoint.Host != "" && (spec.APIServerVMLoadBalancer == nil) != (oldSpec.APIServerVMLoadBalancer == nil) {
+		// The endpoint either is the address of the load balancer VMs or it isn't, and it can't move afterwards.
+		errorList = append(errorList, field.Forbidden(field.NewPath("spec", "apiServerVMLoadBalancer"),
+			"apiServerVMLoadBalancer can't be added or removed once the control plane endpoint is set"))
+	}
 
 	if oldSpec.ControlPlaneEndpoint.Host != "" { // Need to allow one time endpoint setting via CAPC cluster controller.
 		errorList = webhookutil.EnsureEqualStrings(
@@ -142,29 +482,21 @@ func (r *CloudStackCluster) ValidateDelete() (admission.Warnings, error) {
 	return nil, nil
 }
 
-// ValidateFailureDomainUpdates verifies that at least one failure domain has not been deleted, and
-// failure domains that are held over have not been modified.
+// ValidateFailureDomainUpdates verifies that at least one failure domain has not been deleted. Failure domains that are
+// held over may be changed: the controller starts a new generation of them and moves their machines over.
 func ValidateFailureDomainUpdates(oldFDs, newFDs []CloudStackFailureDomainSpec) *field.Error {
-	newFDsByName := map[string]CloudStackFailureDomainSpec{}
+	newFDsByName := map[string]bool{}
 	for _, newFD := range newFDs {
-		newFDsByName[newFD.Name] = newFD
+		newFDsByName[newFD.Name] = true
 	}
 
-	atLeastOneRemains := false
 	for _, oldFD := range oldFDs {
-		if newFD, present := newFDsByName[oldFD.Name]; present {
-			atLeastOneRemains = true
-			if !FailureDomainsEqual(newFD, oldFD) {
-				return field.Forbidden(field.NewPath("spec", "FailureDomains"),
-					"Cannot change FailureDomain "+oldFD.Name)
-			}
+		if newFDsByName[oldFD.Name] {
+			return nil
 		}
 	}
-	if !atLeastOneRemains {
-		return field.Forbidden(field.NewPath("spec", "FailureDomains"), "At least one FailureDomain must be unchanged on update.")
-	}
 
-	return nil
+	return field.Forbidden(field.NewPath("spec", "FailureDomains"), "At least one FailureDomain must be kept on update.")
 }
 
 // FailureDomainsEqual is a manual deep equal on failure domains.
@@ -178,7 +510,9 @@ func FailureDomainsEqual(fd1, fd2 CloudStackFailureDomainSpec) bool {
 		fd1.Zone.Network.Name == fd2.Zone.Network.Name &&
 		fd1.Zone.Network.ID == fd2.Zone.Network.ID &&
 		fd1.Zone.Network.Type == fd2.Zone.Network.Type &&
-		fd1.Zone.Network.Domain == fd2.Zone.Network.Domain
+		fd1.Zone.Network.Domain == fd2.Zone.Network.Domain &&
+		reflect.DeepEqual(fd1.ManagedTenant, fd2.ManagedTenant) &&
+		reflect.DeepEqual(fd1.IdentityRef, fd2.IdentityRef)
 }
 
 // ValidateCIDR validates whether a CIDR matches the conventions expected by net.ParseCIDR.
@@ -21,6 +21,9 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/cluster-api/util/annotations"
 
@@ -79,6 +82,143 @@ var _ = Describe("CloudStackCluster webhooks", func() {
 			Ω(k8sClient.Create(ctx, dummies.CSCluster)).Should(MatchError(MatchRegexp(invalidRegex,
 				"must be valid CIDR: invalid CIDR address: 111.222.333.444/55")))
 		})
+		It("Should reject a CloudStackCluster with an IPv6 network CIDR", func() {
+			dummies.CSCluster.Spec.FailureDomains[0].Zone.Network.CIDR = "2001:db8::/64"
+			Ω(k8sClient.Create(ctx, dummies.CSCluster)).Should(MatchError(MatchRegexp(invalidRegex,
+				"must be an IPv4 CIDR, IPv6 ranges go in ipv6CIDR")))
+		})
+		It("Should reject a CloudStackCluster with an IPv4 network ipv6CIDR", func() {
+			dummies.CSCluster.Spec.FailureDomains[0].Zone.Network.IPv6CIDR = "10.0.0.0/16"
+			Ω(k8sClient.Create(ctx, dummies.CSCluster)).Should(MatchError(MatchRegexp(invalidRegex,
+				"must be an IPv6 CIDR")))
+		})
+		It("Should reject a CloudStackCluster with a network ipv6CIDR smaller than a /64", func() {
+			dummies.CSCluster.Spec.FailureDomains[0].Zone.Network.IPv6CIDR = "2001:db8::/96"
+			Ω(k8sClient.Create(ctx, dummies.CSCluster)).Should(MatchError(MatchRegexp(invalidRegex,
+				"must be a /64 or larger")))
+		})
+		It("Should reject a CloudStackCluster with an ipv6CIDR on a shared network", func() {
+			dummies.CSCluster.Spec.FailureDomains[0].Zone.Network.Type = infrav1.NetworkTypeShared
+			dummies.CSCluster.Spec.FailureDomains[0].Zone.Network.IPv6CIDR = "2001:db8::/64"
+			Ω(k8sClient.Create(ctx, dummies.CSCluster)).Should(MatchError(MatchRegexp(forbiddenRegex,
+				"ipv6CIDR requires the Isolated network type")))
+		})
+		It("Should reject a CloudStackCluster with a managed tenant and an account", func() {
+			dummies.CSCluster.Spec.FailureDomains[0].Account = "account"
+			dummies.CSCluster.Spec.FailureDomains[0].ManagedTenant = &infrav1.CloudStackManagedTenant{
+				Type: infrav1.ManagedTenantTypeProject,
+			}
+			Ω(k8sClient.Create(ctx, dummies.CSCluster)).Should(MatchError(MatchRegexp(forbiddenRegex,
+				"managedTenant cannot be combined with account or project")))
+		})
+		It("Should reject a CloudStackCluster with both an identity reference and an ACSEndpoint", func() {
+			dummies.CSCluster.Spec.FailureDomains[0].IdentityRef = &infrav1.CloudStackClusterIdentityReference{Name: "identity"}
+			Ω(k8sClient.Create(ctx, dummies.CSCluster)).Should(MatchError(MatchRegexp(forbiddenRegex,
+				"identityRef cannot be combined with ACSEndpoint")))
+		})
+		It("Should reject a CloudStackCluster with an ACSEndpoint in another namespace", func() {
+			dummies.CSCluster.Spec.FailureDomains[0].ACSEndpoint.Namespace = "capc-system"
+			Ω(k8sClient.Create(ctx, dummies.CSCluster)).Should(MatchError(MatchRegexp(forbiddenRegex,
+				"ACSEndpoint must be in the namespace of the cluster")))
+		})
+		It("Should reject a CloudStackCluster with an identity that doesn't allow its namespace", func() {
+			identity := &infrav1.CloudStackClusterIdentity{
+				ObjectMeta: metav1.ObjectMeta{Name: "other-team"},
+				Spec: infrav1.CloudStackClusterIdentitySpec{
+					SecretRef:         corev1.SecretReference{Name: "secret", Namespace: "capc-system"},
+					AllowedNamespaces: &infrav1.AllowedNamespaces{NamespaceList: []string{"other-team"}},
+				},
+			}
+			_ = k8sClient.Delete(ctx, identity)
+			Ω(k8sClient.Create(ctx, identity)).Should(Succeed())
+			dummies.CSCluster.Spec.FailureDomains[0].ACSEndpoint = corev1.SecretReference{}
+			dummies.CSCluster.Spec.FailureDomains[0].IdentityRef = &infrav1.CloudStackClusterIdentityReference{Name: identity.Name}
+			Ω(k8sClient.Create(ctx, dummies.CSCluster)).Should(MatchError(MatchRegexp(forbiddenRegex,
+				"CloudStackClusterIdentity other-team does not allow use from namespace default")))
+
+			identity.Spec.AllowedNamespaces.NamespaceList = append(identity.Spec.AllowedNamespaces.NamespaceList, "default")
+			Ω(k8sClient.Update(ctx, identity)).Should(Succeed())
+			Ω(k8sClient.Create(ctx, dummies.CSCluster)).Should(Succeed())
+		})
+		It("Should reject a CloudStackCluster without a failure domain allowing control plane machines", func() {
+			for idx := range dummies.CSCluster.Spec.FailureDomains {
+				dummies.CSCluster.Spec.FailureDomains[idx].ControlPlane = ptr.To(false)
+			}
+			Ω(k8sClient.Create(ctx, dummies.CSCluster)).Should(MatchError(MatchRegexp(forbiddenRegex,
+				"at least one failure domain must allow control plane machines")))
+		})
+		It("Should reject a CloudStackCluster with VPC settings on a network that isn't a VPC tier", func() {
+			dummies.CSCluster.Spec.FailureDomains[0].Zone.Network.VPC = &infrav1.VPCSpec{}
+			Ω(k8sClient.Create(ctx, dummies.CSCluster)).Should(MatchError(MatchRegexp(forbiddenRegex,
+				"vpc requires the VPCTier network type")))
+		})
+		It("Should reject a CloudStackCluster with an invalid VPC tier CIDR", func() {
+			dummies.CSCluster.Spec.FailureDomains[0].Zone.Network.Type = infrav1.NetworkTypeVPCTier
+			dummies.CSCluster.Spec.FailureDomains[0].Zone.Network.VPC = &infrav1.VPCSpec{
+				WorkerTier: infrav1.VPCTierSpec{CIDR: "10.0.2.0/33"},
+			}
+			Ω(k8sClient.Create(ctx, dummies.CSCluster)).Should(MatchError(MatchRegexp(invalidRegex,
+				"must be valid CIDR: invalid CIDR address: 10.0.2.0/33")))
+		})
+		It("Should reject a CloudStackCluster with a network offering on a shared network", func() {
+			dummies.CSCluster.Spec.FailureDomains[0].Zone.Network.Type = infrav1.NetworkTypeShared
+			dummies.CSCluster.Spec.FailureDomains[0].Zone.Network.Offering = &infrav1.CloudStackResourceIdentifier{Name: "offering"}
+			Ω(k8sClient.Create(ctx, dummies.CSCluster)).Should(MatchError(MatchRegexp(forbiddenRegex,
+				"offering requires the Isolated network type")))
+		})
+		It("Should reject a CloudStackCluster with a network offering without ID or name", func() {
+			dummies.CSCluster.Spec.FailureDomains[0].Zone.Network.Type = infrav1.NetworkTypeIsolated
+			dummies.CSCluster.Spec.FailureDomains[0].Zone.Network.Offering = &infrav1.CloudStackResourceIdentifier{}
+			Ω(k8sClient.Create(ctx, dummies.CSCluster)).Should(MatchError(MatchRegexp(requiredRegex,
+				"offering requires an ID or a name")))
+		})
+		It("Should reject a CloudStackCluster with an invalid egress rule destination CIDR", func() {
+			dummies.CSCluster.Spec.FailureDomains[0].Zone.Network.Type = infrav1.NetworkTypeIsolated
+			dummies.CSCluster.Spec.FailureDomains[0].Zone.Network.EgressRules = []infrav1.EgressRule{
+				{Protocol: "tcp", StartPort: 443, DestinationCIDRs: []string{"192.0.2.0/33"}},
+			}
+			Ω(k8sClient.Create(ctx, dummies.CSCluster)).Should(MatchError(MatchRegexp(invalidRegex,
+				"must be valid CIDR: invalid CIDR address: 192.0.2.0/33")))
+		})
+		It("Should reject a CloudStackCluster with a bastion in an unknown failure domain", func() {
+			dummies.CSCluster.Spec.Bastion = dummies.Bastion
+			dummies.CSCluster.Spec.Bastion.FailureDomainName = "nonexistent"
+			Ω(k8sClient.Create(ctx, dummies.CSCluster)).Should(MatchError(MatchRegexp(invalidRegex,
+				"must name a failure domain of the cluster")))
+		})
+		It("Should reject a CloudStackCluster with a bastion on a shared network", func() {
+			dummies.CSCluster.Spec.Bastion = dummies.Bastion
+			Ω(k8sClient.Create(ctx, dummies.CSCluster)).Should(MatchError(MatchRegexp(forbiddenRegex,
+				"the bastion requires a failure domain with the Isolated network type")))
+		})
+		It("Should reject a CloudStackCluster with an invalid bastion allowed CIDR", func() {
+			dummies.CSCluster.Spec.FailureDomains[0].Zone.Network.Type = infrav1.NetworkTypeIsolated
+			dummies.CSCluster.Spec.Bastion = dummies.Bastion
+			dummies.CSCluster.Spec.Bastion.AllowedCIDRs = []string{"198.51.100.0/33"}
+			Ω(k8sClient.Create(ctx, dummies.CSCluster)).Should(MatchError(MatchRegexp(invalidRegex,
+				"must be valid CIDR: invalid CIDR address: 198.51.100.0/33")))
+		})
+		It("Should reject a CloudStackCluster with both API server load balancer kinds", func() {
+			dummies.CSCluster.Spec.ControlPlaneEndpoint.Host = ""
+			dummies.CSCluster.Spec.APIServerVMLoadBalancer = dummies.APIServerVMLoadBalancer
+			Ω(k8sClient.Create(ctx, dummies.CSCluster)).Should(MatchError(MatchRegexp(forbiddenRegex,
+				"apiServerVMLoadBalancer cannot be combined with apiServerLoadBalancer")))
+		})
+		It("Should reject a CloudStackCluster with two API server load balancer VMs and no virtual IP", func() {
+			dummies.CSCluster.Spec.ControlPlaneEndpoint.Host = ""
+			dummies.CSCluster.Spec.APIServerLoadBalancer = nil
+			dummies.CSCluster.Spec.APIServerVMLoadBalancer = dummies.APIServerVMLoadBalancer
+			dummies.CSCluster.Spec.APIServerVMLoadBalancer.VirtualIP = ""
+			Ω(k8sClient.Create(ctx, dummies.CSCluster)).Should(MatchError(MatchRegexp(requiredRegex,
+				"virtualIP is required with two replicas")))
+		})
+		It("Should reject a CloudStackCluster with a control plane endpoint other than the virtual IP", func() {
+			dummies.CSCluster.Spec.ControlPlaneEndpoint.Host = "10.0.0.11"
+			dummies.CSCluster.Spec.APIServerLoadBalancer = nil
+			dummies.CSCluster.Spec.APIServerVMLoadBalancer = dummies.APIServerVMLoadBalancer
+			Ω(k8sClient.Create(ctx, dummies.CSCluster)).Should(MatchError(MatchRegexp(invalidRegex,
+				"must match apiServerVMLoadBalancer.virtualIP")))
+		})
 	})
 
 	Context("When updating a CloudStackCluster", func() {
@@ -86,13 +226,24 @@ var _ = Describe("CloudStackCluster webhooks", func() {
 			Ω(k8sClient.Create(ctx, dummies.CSCluster)).Should(Succeed())
 		})
 
-		It("Should reject updates to CloudStackCluster FailureDomains", func() {
+		It("Should allow updates to CloudStackCluster FailureDomains", func() {
 			dummies.CSCluster.Spec.FailureDomains[0].Zone.Name = "SomeRandomUpdate"
-			Ω(k8sClient.Update(ctx, dummies.CSCluster)).Should(MatchError(MatchRegexp(forbiddenRegex, "Cannot change FailureDomain")))
+			Ω(k8sClient.Update(ctx, dummies.CSCluster)).Should(Succeed())
 		})
-		It("Should reject updates to Networks specified in CloudStackCluster Zones", func() {
+		It("Should allow updates to Networks specified in CloudStackCluster Zones", func() {
 			dummies.CSCluster.Spec.FailureDomains[0].Zone.Network.Name = "ArbitraryUpdateNetworkName"
-			Ω(k8sClient.Update(ctx, dummies.CSCluster)).Should(MatchError(MatchRegexp(forbiddenRegex, "Cannot change FailureDomain")))
+			Ω(k8sClient.Update(ctx, dummies.CSCluster)).Should(Succeed())
+		})
+		It("Should reject updates moving the ACSEndpoint of a failure domain to another namespace", func() {
+			dummies.CSCluster.Spec.FailureDomains[0].ACSEndpoint.Namespace = "capc-system"
+			Ω(k8sClient.Update(ctx, dummies.CSCluster)).Should(MatchError(MatchRegexp(forbiddenRegex,
+				"ACSEndpoint must be in the namespace of the cluster")))
+		})
+		It("Should reject updates replacing every CloudStackCluster FailureDomain", func() {
+			for idx := range dummies.CSCluster.Spec.FailureDomains {
+				dummies.CSCluster.Spec.FailureDomains[idx].Name += "-renamed"
+			}
+			Ω(k8sClient.Update(ctx, dummies.CSCluster)).Should(MatchError(MatchRegexp(forbiddenRegex, "At least one FailureDomain must be kept")))
 		})
 		It("Should reject updates to CloudStackCluster controlplaneendpoint.host", func() {
 			dummies.CSCluster.Spec.ControlPlaneEndpoint.Host = "1.1.1.1"
@@ -105,6 +256,42 @@ var _ = Describe("CloudStackCluster webhooks", func() {
 			Ω(k8sClient.Update(ctx, dummies.CSCluster)).
 				Should(MatchError(MatchRegexp(forbiddenRegex, "controlplaneendpoint\\.port")))
 		})
+		It("Should reject updates to the template of the bastion", func() {
+			dummies.CSCluster.Spec.FailureDomains[0].Zone.Network.Type = infrav1.NetworkTypeIsolated
+			dummies.CSCluster.Spec.Bastion = dummies.Bastion
+			Ω(k8sClient.Update(ctx, dummies.CSCluster)).Should(Succeed())
+			dummies.CSCluster.Spec.Bastion.Template.Name = "OtherTemplate"
+			Ω(k8sClient.Update(ctx, dummies.CSCluster)).
+				Should(MatchError(MatchRegexp(forbiddenRegex, "bastion\\.template")))
+		})
+		It("Should reject adding API server load balancer VMs once the control plane endpoint is set", func() {
+			dummies.CSCluster.Spec.APIServerLoadBalancer = nil
+			dummies.CSCluster.Spec.APIServerVMLoadBalancer = dummies.APIServerVMLoadBalancer
+			dummies.CSCluster.Spec.APIServerVMLoadBalancer.VirtualIP = ""
+			Ω(k8sClient.Update(ctx, dummies.CSCluster)).Should(MatchError(MatchRegexp(forbiddenRegex,
+				"apiServerVMLoadBalancer can't be added or removed once the control plane endpoint is set")))
+		})
+	})
+
+	Context("When updating a CloudStackCluster with API server load balancer VMs", func() {
+		BeforeEach(func() {
+			dummies.CSCluster.Spec.APIServerLoadBalancer = nil
+			dummies.CSCluster.Spec.APIServerVMLoadBalancer = dummies.APIServerVMLoadBalancer
+			dummies.CSCluster.Spec.APIServerVMLoadBalancer.Replicas = 1
+			dummies.CSCluster.Spec.APIServerVMLoadBalancer.VirtualIP = ""
+			Ω(k8sClient.Create(ctx, dummies.CSCluster)).Should(Succeed())
+		})
+
+		It("Should reject updates to the template of the API server load balancer VMs", func() {
+			dummies.CSCluster.Spec.APIServerVMLoadBalancer.Template.Name = "OtherTemplate"
+			Ω(k8sClient.Update(ctx, dummies.CSCluster)).
+				Should(MatchError(MatchRegexp(forbiddenRegex, "apiServerVMLoadBalancer\\.template")))
+		})
+		It("Should reject removing the API server load balancer VMs", func() {
+			dummies.CSCluster.Spec.APIServerVMLoadBalancer = nil
+			Ω(k8sClient.Update(ctx, dummies.CSCluster)).Should(MatchError(MatchRegexp(forbiddenRegex,
+				"apiServerVMLoadBalancer can't be added or removed once the control plane endpoint is set")))
+		})
 	})
 
 	Context("When updating a CloudStackCluster's annotations", func() {
@@ -0,0 +1,107 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta3
+
+import (
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// CloudStackClusterIdentitySpec defines the desired state of CloudStackClusterIdentity.
+type CloudStackClusterIdentitySpec struct {
+	// SecretRef references the ACS endpoint secret holding the credentials of this identity.
+	SecretRef corev1.SecretReference `json:"secretRef"`
+
+	// AllowedNamespaces selects the namespaces whose clusters may use this identity. Namespaces can be selected by
+	// name, by label selector, or both. An empty allowedNamespaces allows all namespaces, while omitting it allows none.
+	//+optional
+	AllowedNamespaces *AllowedNamespaces `json:"allowedNamespaces,omitempty"`
+}
+
+// AllowedNamespaces selects namespaces by name or by label.
+type AllowedNamespaces struct {
+	// NamespaceList is a list of namespace names.
+	//+optional
+	NamespaceList []string `json:"list,omitempty"`
+
+	// Selector is a label selector matched against namespace labels.
+	//+optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+}
+
+// CloudStackClusterIdentityReference references a CloudStackClusterIdentity.
+type CloudStackClusterIdentityReference struct {
+	// Name of the CloudStackClusterIdentity.
+	Name string `json:"name"`
+}
+
+// CloudStackClusterIdentityStatus defines the observed state of CloudStackClusterIdentity.
+type CloudStackClusterIdentityStatus struct{}
+
+// AllowsNamespace returns whether clusters in the passed namespace may use the identity.
+func (r *CloudStackClusterIdentity) AllowsNamespace(namespace *corev1.Namespace) (bool, error) {
+	allowed := r.Spec.AllowedNamespaces
+	if allowed == nil {
+		return false, nil
+	}
+	if allowed.NamespaceList == nil && allowed.Selector == nil {
+		return true, nil
+	}
+	if slices.Contains(allowed.NamespaceList, namespace.Name) {
+		return true, nil
+	}
+	if allowed.Selector == nil {
+		return false, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(allowed.Selector)
+	if err != nil {
+		return false, err
+	}
+
+	return selector.Matches(labels.Set(namespace.Labels)), nil
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:path=cloudstackclusteridentities,scope=Cluster,categories=cluster-api
+//+kubebuilder:subresource:status
+//+kubebuilder:storageversion
+//+kubebuilder:printcolumn:name="Secret",type="string",JSONPath=".spec.secretRef.name",description="ACS endpoint secret of the identity"
+
+// CloudStackClusterIdentity is the Schema for the cloudstackclusteridentities API.
+type CloudStackClusterIdentity struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   CloudStackClusterIdentitySpec   `json:"spec,omitempty"`
+	Status CloudStackClusterIdentityStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// CloudStackClusterIdentityList contains a list of CloudStackClusterIdentity.
+type CloudStackClusterIdentityList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []CloudStackClusterIdentity `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&CloudStackClusterIdentity{}, &CloudStackClusterIdentityList{})
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta3_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
+)
+
+var _ = Describe("CloudStackClusterIdentity_AllowsNamespace", func() {
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a", Labels: map[string]string{"team": "a"}}}
+
+	for _, tc := range []struct {
+		Name    string
+		Allowed *infrav1.AllowedNamespaces
+		Expect  bool
+	}{
+		{
+			Name:    "is false when allowed namespaces are omitted",
+			Allowed: nil,
+			Expect:  false,
+		},
+		{
+			Name:    "is true when allowed namespaces are empty",
+			Allowed: &infrav1.AllowedNamespaces{},
+			Expect:  true,
+		},
+		{
+			Name:    "is true when the namespace is listed",
+			Allowed: &infrav1.AllowedNamespaces{NamespaceList: []string{"team-a"}},
+			Expect:  true,
+		},
+		{
+			Name:    "is false when the namespace is not listed",
+			Allowed: &infrav1.AllowedNamespaces{NamespaceList: []string{"team-b"}},
+			Expect:  false,
+		},
+		{
+			Name: "is true when the selector matches the namespace labels",
+			Allowed: &infrav1.AllowedNamespaces{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "a"}},
+			},
+			Expect: true,
+		},
+		{
+			Name: "is false when the selector doesn't match the namespace labels",
+			Allowed: &infrav1.AllowedNamespaces{
+				NamespaceList: []string{"team-b"},
+				Selector:      &metav1.LabelSelector{MatchLabels: map[string]string{"team": "b"}},
+			},
+			Expect: false,
+		},
+	} {
+		tcc := tc
+		It(tcc.Name, func() {
+			identity := &infrav1.CloudStackClusterIdentity{
+				Spec: infrav1.CloudStackClusterIdentitySpec{AllowedNamespaces: tcc.Allowed},
+			}
+			result, err := identity.AllowsNamespace(namespace)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(Equal(tcc.Expect))
+		})
+	}
+})
@@ -0,0 +1,79 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta3
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"sigs.k8s.io/cluster-api-provider-cloudstack/pkg/webhookutil"
+)
+
+// log is for logging in this package.
+var cloudstackclusteridentitylog = logf.Log.WithName("cloudstackclusteridentity-resource")
+
+func (r *CloudStackClusterIdentity) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+// +kubebuilder:webhook:verbs=create;update,path=/validate-infrastructure-cluster-x-k8s-io-v1beta3-cloudstackclusteridentity,mutating=false,failurePolicy=fail,matchPolicy=Equivalent,groups=infrastructure.cluster.x-k8s.io,resources=cloudstackclusteridentities,versions=v1beta3,name=validation.cloudstackclusteridentity.infrastructure.cluster.x-k8s.io,sideEffects=None,admissionReviewVersions=v1;v1beta1
+
+var _ webhook.Validator = &CloudStackClusterIdentity{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type.
+func (r *CloudStackClusterIdentity) ValidateCreate() (admission.Warnings, error) {
+	cloudstackclusteridentitylog.V(1).Info("entered validate create webhook", "api resource name", r.Name)
+
+	return nil, r.validate()
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type.
+func (r *CloudStackClusterIdentity) ValidateUpdate(_ runtime.Object) (admission.Warnings, error) {
+	cloudstackclusteridentitylog.V(1).Info("entered validate update webhook", "api resource name", r.Name)
+
+	return nil, r.validate()
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type.
+func (r *CloudStackClusterIdentity) ValidateDelete() (admission.Warnings, error) {
+	cloudstackclusteridentitylog.V(1).Info("entered validate delete webhook", "api resource name", r.Name)
+	// No deletion validations.  Deletion webhook not enabled.
+	return nil, nil
+}
+
+func (r *CloudStackClusterIdentity) validate() error {
+	var errorList field.ErrorList
+
+	if r.Spec.SecretRef.Name == "" || r.Spec.SecretRef.Namespace == "" {
+		errorList = append(errorList, field.Required(field.NewPath("spec", "secretRef"), "Name and Namespace are required"))
+	}
+	if allowed := r.Spec.AllowedNamespaces; allowed != nil && allowed.Selector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(allowed.Selector); err != nil {
+			errorList = append(errorList, field.Invalid(
+				field.NewPath("spec", "allowedNamespaces", "selector"), allowed.Selector, err.Error()))
+		}
+	}
+
+	return webhookutil.AggregateObjErrors(r.GroupVersionKind().GroupKind(), r.Name, errorList)
+}
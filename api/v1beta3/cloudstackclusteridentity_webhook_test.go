@@ -0,0 +1,65 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta3_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
+)
+
+var _ = Describe("CloudStackClusterIdentity webhooks", func() {
+	var (
+		ctx      context.Context
+		identity *infrav1.CloudStackClusterIdentity
+	)
+
+	BeforeEach(func() { // Reset test vars to initial state.
+		ctx = context.Background()
+		identity = &infrav1.CloudStackClusterIdentity{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-identity"},
+			Spec: infrav1.CloudStackClusterIdentitySpec{
+				SecretRef:         corev1.SecretReference{Name: "secret", Namespace: "default"},
+				AllowedNamespaces: &infrav1.AllowedNamespaces{},
+			},
+		}
+		_ = k8sClient.Delete(ctx, identity) // Delete any remnants.
+	})
+
+	Context("When creating a CloudStackClusterIdentity", func() {
+		It("Should accept a CloudStackClusterIdentity with all attributes present", func() {
+			Ω(k8sClient.Create(ctx, identity)).Should(Succeed())
+		})
+
+		It("Should reject a CloudStackClusterIdentity without a secret namespace", func() {
+			identity.Spec.SecretRef.Namespace = ""
+			Ω(k8sClient.Create(ctx, identity)).Should(MatchError(MatchRegexp(requiredRegex, "Name and Namespace are required")))
+		})
+
+		It("Should reject a CloudStackClusterIdentity with an invalid namespace selector", func() {
+			identity.Spec.AllowedNamespaces.Selector = &metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "team", Operator: "Bogus"}},
+			}
+			Ω(k8sClient.Create(ctx, identity)).Should(MatchError(ContainSubstring("is not a valid label selector operator")))
+		})
+	})
+})
@@ -18,10 +18,12 @@ package v1beta3
 
 import (
 	"crypto/md5" // #nosec G501 -- weak cryptographic primitive doesn't matter here. Not security related.
+	"encoding/json"
 	"fmt"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 )
 
 // FailureDomainHashedMetaName returns an MD5 name generated from the FailureDomain and Cluster name.
@@ -32,14 +34,42 @@ func FailureDomainHashedMetaName(fdName, clusterName string) string {
 	return fmt.Sprintf("%x", md5.Sum([]byte(fdName+clusterName))) // #nosec G401 -- weak cryptographic primitive doesn't matter here. Not security related.
 }
 
+// FailureDomainSpecHash returns a short hash of the parts of a failure domain spec that define a generation of it.
+// Fields that can change in place, like ControlPlane, Weight and the network's egress rules and VPC tier ACLs, are
+// left out.
+func FailureDomainSpecHash(fdSpec CloudStackFailureDomainSpec) string {
+	fdSpec.ControlPlane, fdSpec.Weight = nil, nil
+	fdSpec.Zone.Network.EgressRules = nil
+	if fdSpec.Zone.Network.VPC != nil {
+		fdSpec.Zone.Network.VPC = fdSpec.Zone.Network.VPC.DeepCopy()
+		fdSpec.Zone.Network.VPC.ControlPlaneTier.ACL, fdSpec.Zone.Network.VPC.WorkerTier.ACL = nil, nil
+	}
+	specJSON, _ := json.Marshal(fdSpec)
+
+	return fmt.Sprintf("%x", md5.Sum(specJSON))[:8] // #nosec G401 -- weak cryptographic primitive doesn't matter here. Not security related.
+}
+
+// FailureDomainGenerationMetaName returns the name of the CloudStackFailureDomain for a changed failure domain spec.
+// Changing a failure domain starts a new generation of it next to the old one, so that machines can be moved over
+// before the old generation is retired. The first generation keeps the FailureDomainHashedMetaName.
+func FailureDomainGenerationMetaName(fdSpec CloudStackFailureDomainSpec, clusterName string) string {
+	return FailureDomainHashedMetaName(fdSpec.Name, clusterName) + "-" + FailureDomainSpecHash(fdSpec)
+}
+
 const (
 	FailureDomainFinalizer = "cloudstackfailuredomain.infrastructure.cluster.x-k8s.io"
 	FailureDomainLabelName = "cloudstackfailuredomain.infrastructure.cluster.x-k8s.io/name"
+	// FailureDomainSpecHashAnnotation records the FailureDomainSpecHash of the spec a CloudStackFailureDomain was
+	// created for, as the controller fills in resolved IDs in the spec itself.
+	FailureDomainSpecHashAnnotation = "cloudstackfailuredomain.infrastructure.cluster.x-k8s.io/spec-hash"
+	// FailureDomainEvacuateAnnotation drains a CloudStackFailureDomain, e.g. for zone maintenance. Its value is ignored.
+	FailureDomainEvacuateAnnotation = "cloudstackfailuredomain.infrastructure.cluster.x-k8s.io/evacuate"
 )
 
 const (
 	NetworkTypeIsolated = "Isolated"
 	NetworkTypeShared   = "Shared"
+	NetworkTypeVPCTier  = "VPCTier"
 )
 
 type Network struct {
@@ -54,13 +84,51 @@ type Network struct {
 	//+optional
 	Type string `json:"type,omitempty"`
 
-	// CIDR is the IP address range of the network.
+	// CIDR is the IPv4 address range of the network.
 	//+optional
 	CIDR string `json:"cidr,omitempty"`
 
+	// IPv6CIDR is the IPv6 address range of a dual-stack isolated network, a /64 or larger. It requires a network
+	// offering with dual-stack support, available from CloudStack 4.17.
+	//+optional
+	IPv6CIDR string `json:"ipv6CIDR,omitempty"`
+
 	// Domain is the DNS domain name used for all instances in the network.
 	//+optional
 	Domain string `json:"domain,omitempty"`
+
+	// Offering is the network offering, by ID or name, used when the isolated network is created. It must provide
+	// the SourceNat, Lb and Firewall services. Defaults to DefaultIsolatedNetworkOfferingWithSourceNatService.
+	//+optional
+	Offering *CloudStackResourceIdentifier `json:"offering,omitempty"`
+
+	// EgressRules are the egress firewall rules of the isolated network, replacing any others. Defaults to allowing all
+	// TCP, UDP and ICMP traffic.
+	//+optional
+	EgressRules []EgressRule `json:"egressRules,omitempty"`
+
+	// VPC configures the VPC and its tiers when the network type is VPCTier.
+	//+optional
+	VPC *VPCSpec `json:"vpc,omitempty"`
+}
+
+// EgressRule allows traffic from an isolated network to the outside world.
+type EgressRule struct {
+	// Protocol of the traffic.
+	//+kubebuilder:validation:Enum=tcp;udp;icmp;all
+	Protocol string `json:"protocol"`
+
+	// StartPort of the port range for tcp and udp rules. All ports are allowed if unset.
+	//+optional
+	StartPort int `json:"startPort,omitempty"`
+
+	// EndPort of the port range for tcp and udp rules. Defaults to StartPort.
+	//+optional
+	EndPort int `json:"endPort,omitempty"`
+
+	// DestinationCIDRs the traffic may go to. Defaults to 0.0.0.0/0.
+	//+optional
+	DestinationCIDRs []string `json:"destinationCIDRs,omitempty"`
 }
 
 // CloudStackZoneSpec specifies a Zone's details.
@@ -97,8 +165,131 @@ type CloudStackFailureDomainSpec struct {
 	//+optional
 	Project string `json:"project,omitempty"`
 
-	// Apache CloudStack Endpoint secret reference.
-	ACSEndpoint corev1.SecretReference `json:"acsEndpoint"`
+	// Apache CloudStack Endpoint secret reference. Either this or IdentityRef is required.
+	//+optional
+	ACSEndpoint corev1.SecretReference `json:"acsEndpoint,omitempty"`
+
+	// IdentityRef references a CloudStackClusterIdentity whose ACS endpoint secret is used instead of ACSEndpoint.
+	// The identity must allow the cluster's namespace.
+	//+optional
+	IdentityRef *CloudStackClusterIdentityReference `json:"identityRef,omitempty"`
+
+	// ManagedTenant has CAPC create a dedicated CloudStack project or account for the cluster and act as it.
+	// Mutually exclusive with Account and Project.
+	//+optional
+	ManagedTenant *CloudStackManagedTenant `json:"managedTenant,omitempty"`
+
+	// ControlPlane sets whether control plane machines may be placed in the failure domain, e.g. false for zones
+	// without storage fast enough for etcd. Defaults to true.
+	//+kubebuilder:default=true
+	//+optional
+	ControlPlane *bool `json:"controlPlane,omitempty"`
+
+	// Weight of the failure domain relative to the others when placing machines with the Weighted placement strategy.
+	// A weight of 0 keeps such machines out of the failure domain. Defaults to 1.
+	//+kubebuilder:validation:Minimum=0
+	//+optional
+	Weight *int32 `json:"weight,omitempty"`
+}
+
+// IsControlPlaneEligible returns whether control plane machines may be placed in the failure domain.
+func (s *CloudStackFailureDomainSpec) IsControlPlaneEligible() bool {
+	return s.ControlPlane == nil || *s.ControlPlane
+}
+
+// PlacementWeight returns the weight of the failure domain, defaulting to 1.
+func (s *CloudStackFailureDomainSpec) PlacementWeight() int32 {
+	if s.Weight == nil {
+		return 1
+	}
+
+	return *s.Weight
+}
+
+const (
+	ManagedTenantTypeProject = "Project"
+	ManagedTenantTypeAccount = "Account"
+)
+
+// CloudStackManagedTenant describes a project or account that CAPC creates on demand and deletes with the cluster.
+type CloudStackManagedTenant struct {
+	// Type of tenant to create. Either Project or Account.
+	//+kubebuilder:validation:Enum=Project;Account
+	Type string `json:"type"`
+
+	// Name of the project or account. Defaults to capc-<namespace>-<cluster name>.
+	//+optional
+	Name string `json:"name,omitempty"`
+
+	// RoleID of the role given to a created account. Defaults to the User account type.
+	//+optional
+	RoleID string `json:"roleID,omitempty"`
+
+	// Limits set on the tenant right after it is created.
+	//+optional
+	Limits *CloudStackResourceLimits `json:"limits,omitempty"`
+}
+
+// TenantName returns the name of the CloudStack project or account for a cluster.
+func (t *CloudStackManagedTenant) TenantName(namespace, clusterName string) string {
+	if t.Name != "" {
+		return t.Name
+	}
+
+	return fmt.Sprintf("capc-%s-%s", namespace, clusterName)
+}
+
+// ManagedTenantRecord identifies a managed tenant, and the credentials it's managed with.
+type ManagedTenantRecord struct {
+	// Type of the tenant. Either Project or Account.
+	Type string `json:"type"`
+
+	// Name of the project or account.
+	Name string `json:"name"`
+
+	// Domain of an account.
+	//+optional
+	Domain string `json:"domain,omitempty"`
+
+	// ACSEndpoint the tenant is managed with, unless IdentityRef is set.
+	//+optional
+	ACSEndpoint corev1.SecretReference `json:"acsEndpoint,omitempty"`
+
+	// IdentityRef the tenant is managed with.
+	//+optional
+	IdentityRef *CloudStackClusterIdentityReference `json:"identityRef,omitempty"`
+}
+
+// CloudStackResourceLimits are maximum resource counts for a CloudStack project or account. Unset fields keep the
+// CloudStack defaults, and -1 means unlimited.
+type CloudStackResourceLimits struct {
+	// Maximum number of VM instances.
+	//+optional
+	Instances *int64 `json:"instances,omitempty"`
+
+	// Maximum number of CPU cores.
+	//+optional
+	CPU *int64 `json:"cpu,omitempty"`
+
+	// Maximum memory in MiB.
+	//+optional
+	MemoryMiB *int64 `json:"memoryMiB,omitempty"`
+
+	// Maximum primary storage in GiB.
+	//+optional
+	PrimaryStorageGiB *int64 `json:"primaryStorageGiB,omitempty"`
+
+	// Maximum number of volumes.
+	//+optional
+	Volumes *int64 `json:"volumes,omitempty"`
+
+	// Maximum number of public IP addresses.
+	//+optional
+	PublicIPs *int64 `json:"publicIPs,omitempty"`
+
+	// Maximum number of guest networks.
+	//+optional
+	Networks *int64 `json:"networks,omitempty"`
 }
 
 // CloudStackFailureDomainStatus defines the observed state of CloudStackFailureDomain.
@@ -106,6 +297,86 @@ type CloudStackFailureDomainStatus struct {
 	// Reflects the readiness of the CloudStack Failure Domain.
 	//+optional
 	Ready bool `json:"ready"`
+
+	// Conditions report the outcome of the checks run against the CloudStack Failure Domain.
+	//+optional
+	Conditions clusterv1.Conditions `json:"conditions,omitempty"`
+
+	// Capacity is the resource headroom left in the CloudStack Failure Domain, refreshed periodically.
+	//+optional
+	Capacity *CloudStackFailureDomainCapacity `json:"capacity,omitempty"`
+}
+
+// CloudStackFailureDomainCapacity reports how much more can be allocated in a CloudStack Failure Domain.
+type CloudStackFailureDomainCapacity struct {
+	// Account is the headroom left by the resource limits of the account.
+	//+optional
+	Account *CloudStackResourceHeadroom `json:"account,omitempty"`
+
+	// Domain is the headroom left by the resource limits of the account's domain.
+	//+optional
+	Domain *CloudStackResourceHeadroom `json:"domain,omitempty"`
+
+	// Project is the headroom left by the resource limits of the project, if the failure domain acts in one.
+	//+optional
+	Project *CloudStackResourceHeadroom `json:"project,omitempty"`
+
+	// Zone is the capacity of the zone. Only reported if the CloudStack user may call listCapacity, which by default
+	// only root admins can.
+	//+optional
+	Zone []CloudStackZoneCapacity `json:"zone,omitempty"`
+
+	// LastUpdated is when the capacity was last refreshed.
+	//+optional
+	LastUpdated *metav1.Time `json:"lastUpdated,omitempty"`
+}
+
+// CloudStackResourceHeadroom is the amount of each resource that can still be allocated before a CloudStack resource
+// limit is reached. Unset fields are unlimited.
+type CloudStackResourceHeadroom struct {
+	// Number of VM instances.
+	//+optional
+	Instances *int64 `json:"instances,omitempty"`
+
+	// Number of CPU cores.
+	//+optional
+	CPU *int64 `json:"cpu,omitempty"`
+
+	// Memory in MiB.
+	//+optional
+	MemoryMiB *int64 `json:"memoryMiB,omitempty"`
+
+	// Primary storage in GiB.
+	//+optional
+	PrimaryStorageGiB *int64 `json:"primaryStorageGiB,omitempty"`
+
+	// Number of volumes.
+	//+optional
+	Volumes *int64 `json:"volumes,omitempty"`
+
+	// Number of public IP addresses.
+	//+optional
+	PublicIPs *int64 `json:"publicIPs,omitempty"`
+
+	// Number of guest networks.
+	//+optional
+	Networks *int64 `json:"networks,omitempty"`
+}
+
+// CloudStackZoneCapacity is the usage of one type of zone capacity, as reported by listCapacity.
+type CloudStackZoneCapacity struct {
+	// Type of capacity, e.g. CPU, Memory or PrimaryStorage.
+	Type string `json:"type"`
+
+	// Used is the amount in use, in MHz for CPU, bytes for memory and storage, and a count otherwise.
+	Used int64 `json:"used"`
+
+	// Total is the amount available in the zone, in the same unit as Used.
+	Total int64 `json:"total"`
+
+	// PercentUsed is the share of Total in use.
+	//+optional
+	PercentUsed string `json:"percentUsed,omitempty"`
 }
 
 //+kubebuilder:object:root=true
@@ -121,6 +392,24 @@ type CloudStackFailureDomain struct {
 	Status CloudStackFailureDomainStatus `json:"status,omitempty"`
 }
 
+// IsEvacuating returns whether the CloudStackFailureDomain is annotated for evacuation, in which case no new machines
+// are placed in it and its machines are moved to other failure domains.
+func (r *CloudStackFailureDomain) IsEvacuating() bool {
+	_, found := r.Annotations[FailureDomainEvacuateAnnotation]
+
+	return found
+}
+
+// GetConditions returns the conditions of the CloudStackFailureDomain.
+func (r *CloudStackFailureDomain) GetConditions() clusterv1.Conditions {
+	return r.Status.Conditions
+}
+
+// SetConditions sets the conditions of the CloudStackFailureDomain.
+func (r *CloudStackFailureDomain) SetConditions(conditions clusterv1.Conditions) {
+	r.Status.Conditions = conditions
+}
+
 //+kubebuilder:object:root=true
 
 // CloudStackFailureDomainList contains a list of CloudStackFailureDomain.
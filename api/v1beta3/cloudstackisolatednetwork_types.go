@@ -44,9 +44,22 @@ type CloudStackIsolatedNetworkSpec struct {
 	//+optional
 	CIDR string `json:"cidr,omitempty"`
 
+	// IPv6CIDR is the IPv6 range of a dual-stack isolated network.
+	//+optional
+	IPv6CIDR string `json:"ipv6CIDR,omitempty"`
+
 	// Domain is the DNS domain name used for all instances in the isolated network.
 	//+optional
 	Domain string `json:"domain,omitempty"`
+
+	// Offering is the network offering, by ID or name, the isolated network is created with.
+	//+optional
+	Offering *CloudStackResourceIdentifier `json:"offering,omitempty"`
+
+	// EgressRules are the egress firewall rules of the isolated network. Defaults to allowing all TCP, UDP and ICMP
+	// traffic.
+	//+optional
+	EgressRules []EgressRule `json:"egressRules,omitempty"`
 }
 
 // CloudStackIsolatedNetworkStatus defines the observed state of CloudStackIsolatedNetwork.
@@ -64,6 +77,10 @@ type CloudStackIsolatedNetworkStatus struct {
 	// The IDs of the lb rule used to assign VMs to the lb.
 	LoadBalancerRuleIDs []string `json:"loadBalancerRuleIDs,omitempty"`
 
+	// The ID of the network offering of the isolated network, whether CAPC created it or it already existed.
+	//+optional
+	OfferingID string `json:"offeringID,omitempty"`
+
 	// APIServerLoadBalancer describes the api server load balancer if one exists
 	//+optional
 	APIServerLoadBalancer *LoadBalancer `json:"apiServerLoadBalancer,omitempty"`
@@ -0,0 +1,142 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta3
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const LoadBalancerFinalizer = "cloudstackloadbalancer.infrastructure.cluster.x-k8s.io"
+
+// CloudStackLoadBalancerSpec defines the desired state of CloudStackLoadBalancer.
+type CloudStackLoadBalancerSpec struct {
+	// FailureDomainName -- the name of the FailureDomain whose isolated network the load balancer is placed in. Only
+	// machines in this FailureDomain are load balanced.
+	FailureDomainName string `json:"failureDomainName"`
+
+	// IPAddress is the public IP address to use. An unused one is picked if unset.
+	//+optional
+	IPAddress string `json:"ipAddress,omitempty"`
+
+	// Ports forwarded to the selected machines.
+	//+kubebuilder:validation:MinItems=1
+	Ports []LoadBalancerPort `json:"ports"`
+
+	// MachineDeploymentName selects the machines of a MachineDeployment.
+	//+optional
+	MachineDeploymentName string `json:"machineDeploymentName,omitempty"`
+
+	// Selector selects machines by the labels of their CAPI Machine. Without a selector or MachineDeploymentName, all
+	// worker machines are selected.
+	//+optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+
+	// AllowedCIDRs may reach the ports. Defaults to 0.0.0.0/0.
+	//+optional
+	AllowedCIDRs []string `json:"allowedCIDRs,omitempty"`
+}
+
+// LoadBalancerPort forwards a port of the public IP address to the machines.
+type LoadBalancerPort struct {
+	// Port on the public IP address.
+	//+kubebuilder:validation:Minimum=1
+	//+kubebuilder:validation:Maximum=65535
+	Port int `json:"port"`
+
+	// TargetPort on the machines, e.g. the NodePort of a Service. Defaults to Port.
+	//+kubebuilder:validation:Minimum=1
+	//+kubebuilder:validation:Maximum=65535
+	//+optional
+	TargetPort int `json:"targetPort,omitempty"`
+
+	// Protocol of the traffic. Defaults to tcp.
+	//+kubebuilder:validation:Enum=tcp;udp
+	//+optional
+	Protocol string `json:"protocol,omitempty"`
+}
+
+// CloudStackLoadBalancerStatus defines the observed state of CloudStackLoadBalancer.
+type CloudStackLoadBalancerStatus struct {
+	// IPAddress is the public IP address of the load balancer.
+	//+optional
+	IPAddress string `json:"ipAddress,omitempty"`
+
+	// IPAddressID is the CloudStack ID of the public IP address.
+	//+optional
+	IPAddressID string `json:"ipAddressID,omitempty"`
+
+	// The IDs of the load balancer rules of the ports.
+	//+optional
+	LoadBalancerRuleIDs []string `json:"loadBalancerRuleIDs,omitempty"`
+
+	// The IDs of the instances the load balancer forwards to.
+	//+optional
+	InstanceIDs []string `json:"instanceIDs,omitempty"`
+
+	// Ready indicates the readiness of this provider resource.
+	//+optional
+	Ready bool `json:"ready"`
+}
+
+// TargetPortOrDefault returns the port on the machines a load balancer port forwards to.
+func (p LoadBalancerPort) TargetPortOrDefault() int {
+	if p.TargetPort != 0 {
+		return p.TargetPort
+	}
+
+	return p.Port
+}
+
+// ProtocolOrDefault returns the protocol of a load balancer port.
+func (p LoadBalancerPort) ProtocolOrDefault() string {
+	if p.Protocol != "" {
+		return p.Protocol
+	}
+
+	return "tcp"
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:path=cloudstackloadbalancers,scope=Namespaced,categories=cluster-api
+//+kubebuilder:subresource:status
+//+kubebuilder:storageversion
+//+kubebuilder:printcolumn:name="Cluster",type="string",JSONPath=".metadata.labels.cluster\\.x-k8s\\.io/cluster-name",description="Cluster to which this CloudStackLoadBalancer belongs"
+//+kubebuilder:printcolumn:name="IP",type="string",JSONPath=".status.ipAddress",description="Public IP address of the load balancer"
+//+kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.ready",description="Load balancer ready status"
+
+// CloudStackLoadBalancer is the Schema for the cloudstackloadbalancers API. It exposes ports of worker machines, like
+// the NodePorts of an ingress controller, on a public IP address of an isolated network.
+type CloudStackLoadBalancer struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   CloudStackLoadBalancerSpec   `json:"spec,omitempty"`
+	Status CloudStackLoadBalancerStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// CloudStackLoadBalancerList contains a list of CloudStackLoadBalancer.
+type CloudStackLoadBalancerList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []CloudStackLoadBalancer `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&CloudStackLoadBalancer{}, &CloudStackLoadBalancerList{})
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta3
+
+import (
+	"context"
+	"net"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"sigs.k8s.io/cluster-api-provider-cloudstack/pkg/webhookutil"
+)
+
+// log is for logging in this package.
+var cloudstackloadbalancerlog = logf.Log.WithName("cloudstackloadbalancer-resource")
+
+// cloudstackloadbalancerReader reads the CloudStackCluster a load balancer is checked against. The checks against the
+// cluster are skipped while it's unset.
+var cloudstackloadbalancerReader client.Reader
+
+func (r *CloudStackLoadBalancer) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	cloudstackloadbalancerReader = mgr.GetAPIReader()
+
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+// +kubebuilder:webhook:verbs=create;update,path=/validate-infrastructure-cluster-x-k8s-io-v1beta3-cloudstackloadbalancer,mutating=false,failurePolicy=fail,matchPolicy=Equivalent,groups=infrastructure.cluster.x-k8s.io,resources=cloudstackloadbalancers,versions=v1beta3,name=validation.cloudstackloadbalancer.infrastructure.cluster.x-k8s.io,sideEffects=None,admissionReviewVersions=v1;v1beta1
+
+var _ webhook.Validator = &CloudStackLoadBalancer{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type.
+func (r *CloudStackLoadBalancer) ValidateCreate() (admission.Warnings, error) {
+	cloudstackloadbalancerlog.V(1).Info("entered validate create webhook", "api resource name", r.Name)
+
+	return nil, r.validate()
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type.
+func (r *CloudStackLoadBalancer) ValidateUpdate(_ runtime.Object) (admission.Warnings, error) {
+	cloudstackloadbalancerlog.V(1).Info("entered validate update webhook", "api resource name", r.Name)
+
+	return nil, r.validate()
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type.
+func (r *CloudStackLoadBalancer) ValidateDelete() (admission.Warnings, error) {
+	cloudstackloadbalancerlog.V(1).Info("entered validate delete webhook", "api resource name", r.Name)
+	// No deletion validations.  Deletion webhook not enabled.
+	return nil, nil
+}
+
+func (r *CloudStackLoadBalancer) validate() error {
+	var errorList field.ErrorList
+
+	specPath := field.NewPath("spec")
+	if r.Spec.IPAddress != "" && net.ParseIP(r.Spec.IPAddress).To4() == nil {
+		errorList = append(errorList, field.Invalid(specPath.Child("ipAddress"), r.Spec.IPAddress, "must be an IPv4 address"))
+	}
+	for idx, cidr := range r.Spec.AllowedCIDRs {
+		if ipNet, err := ValidateCIDR(cidr); err != nil {
+			errorList = append(errorList, field.Invalid(specPath.Child("allowedCIDRs").Index(idx), cidr,
+				"must be valid CIDR: "+err.Error()))
+		} else if ipNet.IP.To4() == nil {
+			errorList = append(errorList, field.Invalid(specPath.Child("allowedCIDRs").Index(idx), cidr,
+				"must be an IPv4 CIDR"))
+		}
+	}
+	errorList = r.validateAgainstCluster(errorList)
+
+	return webhookutil.AggregateObjErrors(r.GroupVersionKind().GroupKind(), r.Name, errorList)
+}
+
+// validateAgainstCluster checks that the load balancer is placed in a failure domain of its cluster with an isolated
+// network, and that it doesn't claim the control plane endpoint's IP address. It's skipped for load balancers whose
+// CloudStackCluster doesn't exist yet.
+func (r *CloudStackLoadBalancer) validateAgainstCluster(errorList field.ErrorList) field.ErrorList {
+	clusterName := r.GetLabels()[clusterv1.ClusterNameLabel]
+	if cloudstackloadbalancerReader == nil || clusterName == "" {
+		return errorList
+	}
+	csCluster := &CloudStackCluster{}
+	if err := cloudstackloadbalancerReader.Get(context.Background(),
+		client.ObjectKey{Namespace: r.Namespace, Name: clusterName}, csCluster); err != nil {
+		if errors.IsNotFound(err) {
+			return errorList
+		}
+
+		return append(errorList, field.InternalError(field.NewPath("metadata", "labels"), err))
+	}
+
+	specPath := field.NewPath("spec")
+	var network *Network
+	for idx := range csCluster.Spec.FailureDomains {
+		if csCluster.Spec.FailureDomains[idx].Name == r.Spec.FailureDomainName {
+			network = &csCluster.Spec.FailureDomains[idx].Zone.Network
+		}
+	}
+	if network == nil {
+		errorList = append(errorList, field.Invalid(specPath.Child("failureDomainName"), r.Spec.FailureDomainName,
+			"must name a failure domain of the cluster"))
+	} else if network.Type == NetworkTypeShared || network.Type == NetworkTypeVPCTier {
+		errorList = append(errorList, field.Forbidden(specPath.Child("failureDomainName"),
+			"the load balancer requires a failure domain with the Isolated network type"))
+	}
+	if r.Spec.IPAddress != "" && r.Spec.IPAddress == csCluster.Spec.ControlPlaneEndpoint.Host {
+		errorList = append(errorList, field.Forbidden(specPath.Child("ipAddress"),
+			"the IP address of the control plane endpoint is taken by the API server load balancer"))
+	}
+
+	return errorList
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta3_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
+	dummies "sigs.k8s.io/cluster-api-provider-cloudstack/test/dummies/v1beta3"
+)
+
+var _ = Describe("CloudStackLoadBalancer webhooks", func() {
+	var (
+		ctx context.Context
+		lb  *infrav1.CloudStackLoadBalancer
+	)
+
+	BeforeEach(func() { // Reset test vars to initial state.
+		ctx = context.Background()
+		dummies.SetDummyVars()
+		_ = k8sClient.Delete(ctx, dummies.CSCluster) // Delete any remnants.
+		dummies.SetDummyVars()
+		dummies.CSCluster.Spec.ControlPlaneEndpoint.Host = "203.0.113.10"
+		dummies.CSCluster.Spec.FailureDomains[0].Zone.Network = dummies.ISONet1
+		Ω(k8sClient.Create(ctx, dummies.CSCluster)).Should(Succeed())
+
+		lb = &infrav1.CloudStackLoadBalancer{
+			ObjectMeta: metav1.ObjectMeta{Name: "ingress", Namespace: dummies.CSCluster.Namespace, Labels: dummies.ClusterLabel},
+			Spec: infrav1.CloudStackLoadBalancerSpec{
+				FailureDomainName: dummies.CSCluster.Spec.FailureDomains[0].Name,
+				Ports:             []infrav1.LoadBalancerPort{{Port: 443, TargetPort: 30443}},
+				AllowedCIDRs:      []string{"198.51.100.0/24"},
+			},
+		}
+		_ = k8sClient.Delete(ctx, lb) // Delete any remnants.
+	})
+
+	Context("When creating a CloudStackLoadBalancer", func() {
+		It("Should accept a CloudStackLoadBalancer in an isolated network of the cluster", func() {
+			Ω(k8sClient.Create(ctx, lb)).Should(Succeed())
+		})
+
+		It("Should reject a CloudStackLoadBalancer with an invalid allowed CIDR", func() {
+			lb.Spec.AllowedCIDRs = []string{"198.51.100.0/33"}
+			Ω(k8sClient.Create(ctx, lb)).Should(MatchError(MatchRegexp(invalidRegex, "must be valid CIDR")))
+		})
+
+		It("Should reject a CloudStackLoadBalancer in a failure domain without an isolated network", func() {
+			lb.Spec.FailureDomainName = dummies.CSCluster.Spec.FailureDomains[1].Name
+			Ω(k8sClient.Create(ctx, lb)).Should(MatchError(MatchRegexp(forbiddenRegex,
+				"the load balancer requires a failure domain with the Isolated network type")))
+		})
+
+		It("Should reject a CloudStackLoadBalancer in a failure domain the cluster doesn't have", func() {
+			lb.Spec.FailureDomainName = "fd-bogus"
+			Ω(k8sClient.Create(ctx, lb)).Should(MatchError(MatchRegexp(invalidRegex, "must name a failure domain of the cluster")))
+		})
+
+		It("Should reject a CloudStackLoadBalancer claiming the control plane endpoint's IP address", func() {
+			lb.Spec.IPAddress = dummies.CSCluster.Spec.ControlPlaneEndpoint.Host
+			Ω(k8sClient.Create(ctx, lb)).Should(MatchError(MatchRegexp(forbiddenRegex,
+				"the IP address of the control plane endpoint is taken by the API server load balancer")))
+		})
+	})
+
+	Context("When updating a CloudStackLoadBalancer", func() {
+		It("Should reject adding an invalid allowed CIDR", func() {
+			Ω(k8sClient.Create(ctx, lb)).Should(Succeed())
+			lb.Spec.AllowedCIDRs = append(lb.Spec.AllowedCIDRs, "bogus")
+			Ω(k8sClient.Update(ctx, lb)).Should(MatchError(MatchRegexp(invalidRegex, "must be valid CIDR")))
+		})
+	})
+})
@@ -21,6 +21,7 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 )
 
 // The presence of a finalizer prevents CAPI from deleting the corresponding CAPI data.
@@ -34,6 +35,14 @@ const (
 	AffinityTypeNo       = "no"
 )
 
+// Strategies for placing worker machines whose Machine doesn't specify a failure domain.
+const (
+	PlacementStrategyRandom        = "Random"
+	PlacementStrategySpread        = "Spread"
+	PlacementStrategyWeighted      = "Weighted"
+	PlacementStrategyCapacityAware = "CapacityAware"
+)
+
 // CloudStackMachineSpec defines the desired state of CloudStackMachine.
 type CloudStackMachineSpec struct {
 	// Name.
@@ -86,6 +95,14 @@ type CloudStackMachineSpec struct {
 	//+optional
 	FailureDomainName string `json:"failureDomainName,omitempty"`
 
+	// PlacementStrategy picks the failure domain of a machine whose Machine doesn't specify one, i.e. a worker machine.
+	// Random picks any failure domain. Spread picks the failure domain with the fewest machines of the same
+	// MachineDeployment. Weighted picks at random in proportion to the failure domain weights. CapacityAware spreads
+	// over the failure domains with VM, CPU and memory quota left. Defaults to Random.
+	//+kubebuilder:validation:Enum=Random;Spread;Weighted;CapacityAware
+	//+optional
+	PlacementStrategy string `json:"placementStrategy,omitempty"`
+
 	// UncompressedUserData specifies whether the user data is gzip-compressed.
 	// cloud-init has built-in support for gzip-compressed user data, ignition does not.
 	//
@@ -136,6 +153,24 @@ type CloudStackMachineStatus struct {
 	// Reason indicates the reason of status failure.
 	//+optional
 	Reason *string `json:"reason,omitempty"`
+
+	// Conditions defines current service state of the CloudStackMachine.
+	//+optional
+	Conditions clusterv1.Conditions `json:"conditions,omitempty"`
+
+	// Placement records how the failure domain of the machine was picked, if CAPC picked it.
+	//+optional
+	Placement *CloudStackMachinePlacement `json:"placement,omitempty"`
+}
+
+// CloudStackMachinePlacement records the placement strategy that picked a machine's failure domain.
+type CloudStackMachinePlacement struct {
+	// Strategy is the placement strategy used.
+	Strategy string `json:"strategy"`
+
+	// Reason explains why the failure domain was picked.
+	//+optional
+	Reason string `json:"reason,omitempty"`
 }
 
 // TimeSinceLastStateChange returns the amount of time that's elapsed since the state was last updated.  If the state
@@ -167,6 +202,16 @@ type CloudStackMachine struct {
 	Status CloudStackMachineStatus `json:"status,omitempty"`
 }
 
+// GetConditions returns the conditions of the CloudStackMachine.
+func (r *CloudStackMachine) GetConditions() clusterv1.Conditions {
+	return r.Status.Conditions
+}
+
+// SetConditions sets the conditions of the CloudStackMachine.
+func (r *CloudStackMachine) SetConditions(conditions clusterv1.Conditions) {
+	r.Status.Conditions = conditions
+}
+
 //+kubebuilder:object:root=true
 
 // CloudStackMachineList contains a list of CloudStackMachine.
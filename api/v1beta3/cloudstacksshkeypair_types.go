@@ -0,0 +1,105 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta3
+
+import (
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const SSHKeyPairFinalizer = "cloudstacksshkeypair.infrastructure.cluster.x-k8s.io"
+
+// CloudStackSSHKeyPairSpec defines the desired state of CloudStackSSHKeyPair.
+type CloudStackSSHKeyPairSpec struct {
+	// Name of the keypair in CloudStack. This is the name to reference from CloudStackMachineSpec.SSHKey.
+	Name string `json:"name"`
+
+	// FailureDomainName -- the name of the FailureDomain whose account/project the keypair is created in.
+	FailureDomainName string `json:"failureDomainName"`
+
+	// PublicKeySecretRef references a Secret key holding an OpenSSH public key to register with CloudStack.
+	// When omitted, CloudStack generates the keypair and the private key is stored in a Secret instead.
+	//+optional
+	PublicKeySecretRef *corev1.SecretKeySelector `json:"publicKeySecretRef,omitempty"`
+
+	// PrivateKeySecretName is the name of the Secret the generated private key is written to.
+	// Defaults to <metadata.name>-ssh-private-key. Ignored when PublicKeySecretRef is set.
+	//+optional
+	PrivateKeySecretName string `json:"privateKeySecretName,omitempty"`
+
+	// ID.
+	//+optional
+	ID string `json:"id,omitempty"`
+}
+
+// CloudStackSSHKeyPairStatus defines the observed state of CloudStackSSHKeyPair.
+type CloudStackSSHKeyPairStatus struct {
+	// Fingerprint of the keypair as reported by CloudStack when CAPC created it. CAPC refuses to take over a keypair
+	// with another fingerprint, and only deletes or regenerates the keypair with this one.
+	//+optional
+	Fingerprint string `json:"fingerprint,omitempty"`
+
+	// Reflects the readiness of the CS SSH keypair.
+	//+optional
+	Ready bool `json:"ready"`
+}
+
+// GeneratesKeyPair returns whether CloudStack is asked to generate the keypair rather than register a public key.
+func (r *CloudStackSSHKeyPair) GeneratesKeyPair() bool {
+	return r.Spec.PublicKeySecretRef == nil
+}
+
+// PrivateKeySecretName returns the name of the Secret holding the generated private key.
+func (r *CloudStackSSHKeyPair) PrivateKeySecretName() string {
+	if r.Spec.PrivateKeySecretName != "" {
+		return r.Spec.PrivateKeySecretName
+	}
+
+	return strings.ToLower(r.Name + "-ssh-private-key")
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:path=cloudstacksshkeypairs,scope=Namespaced,categories=cluster-api
+//+kubebuilder:subresource:status
+//+kubebuilder:storageversion
+//+kubebuilder:printcolumn:name="Cluster",type="string",JSONPath=".metadata.labels.cluster\\.x-k8s\\.io/cluster-name",description="Cluster to which this CloudStackSSHKeyPair belongs"
+//+kubebuilder:printcolumn:name="KeyPair",type="string",JSONPath=".spec.name",description="Name of the keypair in CloudStack"
+//+kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.ready",description="SSH keypair ready status"
+
+// CloudStackSSHKeyPair is the Schema for the cloudstacksshkeypairs API.
+type CloudStackSSHKeyPair struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   CloudStackSSHKeyPairSpec   `json:"spec,omitempty"`
+	Status CloudStackSSHKeyPairStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// CloudStackSSHKeyPairList contains a list of CloudStackSSHKeyPair.
+type CloudStackSSHKeyPairList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []CloudStackSSHKeyPair `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&CloudStackSSHKeyPair{}, &CloudStackSSHKeyPairList{})
+}
@@ -0,0 +1,196 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta3
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+// The presence of a finalizer prevents CAPI from deleting the corresponding CAPI data.
+const VPCFinalizer = "cloudstackvpc.infrastructure.cluster.x-k8s.io"
+
+// Defaults of VPCs CAPC creates.
+const (
+	DefaultVPCCIDR              = "10.0.0.0/16"
+	DefaultVPCOffering          = "Default VPC offering"
+	DefaultControlPlaneTierCIDR = "10.0.1.0/24"
+	DefaultWorkerTierCIDR       = "10.0.2.0/24"
+)
+
+// Network ACL rule traffic types and actions.
+const (
+	NetworkACLTrafficTypeIngress = "Ingress"
+	NetworkACLTrafficTypeEgress  = "Egress"
+	NetworkACLActionAllow        = "Allow"
+	NetworkACLActionDeny         = "Deny"
+)
+
+// VPCSpec configures the VPC of a failure domain with the VPCTier network type, and the tiers its machines are placed
+// in. The network name is the name of the VPC.
+type VPCSpec struct {
+	// ID of an existing VPC to use. If unset, the VPC is looked up by name, and created if there's none.
+	//+optional
+	ID string `json:"id,omitempty"`
+
+	// CIDR of the VPC if it's created. Defaults to 10.0.0.0/16.
+	//+optional
+	CIDR string `json:"cidr,omitempty"`
+
+	// Offering is the name of the VPC offering used to create the VPC. Defaults to "Default VPC offering".
+	//+optional
+	Offering string `json:"offering,omitempty"`
+
+	// ControlPlaneTier is the tier control plane machines are placed in, and which the API server load balancer
+	// forwards to. Its CIDR defaults to 10.0.1.0/24.
+	//+optional
+	ControlPlaneTier VPCTierSpec `json:"controlPlaneTier,omitempty"`
+
+	// WorkerTier is the tier all other machines are placed in. Its CIDR defaults to 10.0.2.0/24.
+	//+optional
+	WorkerTier VPCTierSpec `json:"workerTier,omitempty"`
+}
+
+// VPCTierSpec configures a tier of a VPC.
+type VPCTierSpec struct {
+	// Name of the tier, created if there's no tier with the name in the VPC. Defaults to the VPC name suffixed with
+	// -control-plane or -workers.
+	//+optional
+	Name string `json:"name,omitempty"`
+
+	// CIDR of the tier. Must be within the CIDR of the VPC.
+	//+optional
+	CIDR string `json:"cidr,omitempty"`
+
+	// ACL lists the rules of the tier's network ACL list, in order. The worker tier defaults to allowing all traffic.
+	// The control plane tier defaults to allowing traffic from within the VPC, and to the API server ports from the
+	// allowedCIDRs of the apiServerLoadBalancer.
+	//+optional
+	ACL []NetworkACLRule `json:"acl,omitempty"`
+}
+
+// NetworkACLRule is a rule of a VPC network ACL list.
+type NetworkACLRule struct {
+	// Protocol the rule applies to.
+	//+kubebuilder:validation:Enum=tcp;udp;icmp;all
+	Protocol string `json:"protocol"`
+
+	// CIDRs the traffic comes from, or goes to for egress rules. Defaults to 0.0.0.0/0.
+	//+optional
+	CIDRs []string `json:"cidrs,omitempty"`
+
+	// StartPort of the port range for tcp and udp rules.
+	//+optional
+	StartPort int `json:"startPort,omitempty"`
+
+	// EndPort of the port range for tcp and udp rules. Defaults to StartPort.
+	//+optional
+	EndPort int `json:"endPort,omitempty"`
+
+	// TrafficType is Ingress or Egress. Defaults to Ingress.
+	//+kubebuilder:validation:Enum=Ingress;Egress
+	//+optional
+	TrafficType string `json:"trafficType,omitempty"`
+
+	// Action is Allow or Deny. Defaults to Allow.
+	//+kubebuilder:validation:Enum=Allow;Deny
+	//+optional
+	Action string `json:"action,omitempty"`
+}
+
+// CloudStackVPCSpec defines the desired state of CloudStackVPC.
+type CloudStackVPCSpec struct {
+	VPCSpec `json:",inline"`
+
+	// Name of the VPC.
+	Name string `json:"name"`
+
+	// The kubernetes control plane endpoint.
+	ControlPlaneEndpoint clusterv1.APIEndpoint `json:"controlPlaneEndpoint"`
+
+	// FailureDomainName -- the FailureDomain the VPC is placed in.
+	FailureDomainName string `json:"failureDomainName"`
+}
+
+// VPCTierStatus reports a tier of a VPC.
+type VPCTierStatus struct {
+	// ID of the tier network.
+	//+optional
+	ID string `json:"id,omitempty"`
+
+	// ACLID is the ID of the tier's network ACL list.
+	//+optional
+	ACLID string `json:"aclID,omitempty"`
+}
+
+// CloudStackVPCStatus defines the observed state of CloudStackVPC.
+type CloudStackVPCStatus struct {
+	// ControlPlaneTier is the tier control plane machines are placed in.
+	//+optional
+	ControlPlaneTier VPCTierStatus `json:"controlPlaneTier,omitempty"`
+
+	// WorkerTier is the tier all other machines are placed in.
+	//+optional
+	WorkerTier VPCTierStatus `json:"workerTier,omitempty"`
+
+	// The IDs of the lb rules used to assign control plane VMs to the lb.
+	//+optional
+	LoadBalancerRuleIDs []string `json:"loadBalancerRuleIDs,omitempty"`
+
+	// APIServerLoadBalancer describes the api server load balancer if one exists.
+	//+optional
+	APIServerLoadBalancer *LoadBalancer `json:"apiServerLoadBalancer,omitempty"`
+
+	// Ready indicates the readiness of this provider resource.
+	//+optional
+	Ready bool `json:"ready"`
+}
+
+// TierNetworkID returns the ID of the tier machines are placed in.
+func (v *CloudStackVPC) TierNetworkID(controlPlane bool) string {
+	if controlPlane {
+		return v.Status.ControlPlaneTier.ID
+	}
+
+	return v.Status.WorkerTier.ID
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:storageversion
+
+// CloudStackVPC is the Schema for the cloudstackvpcs API.
+type CloudStackVPC struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   CloudStackVPCSpec   `json:"spec,omitempty"`
+	Status CloudStackVPCStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// CloudStackVPCList contains a list of CloudStackVPC.
+type CloudStackVPCList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []CloudStackVPC `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&CloudStackVPC{}, &CloudStackVPCList{})
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta3
+
+import clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+
+const (
+	// PreflightChecksPassedCondition reports whether the failure domain's CloudStack user may call every API CAPC
+	// needs, and whether the CloudStack version is supported.
+	PreflightChecksPassedCondition clusterv1.ConditionType = "PreflightChecksPassed"
+
+	// MissingPermissionsReason is used when the user's role doesn't grant some of the required APIs.
+	MissingPermissionsReason = "MissingPermissions"
+	// UnsupportedCloudStackVersionReason is used when the CloudStack version is older than the minimum supported one.
+	UnsupportedCloudStackVersionReason = "UnsupportedCloudStackVersion"
+	// PreflightChecksFailedReason is used when the checks themselves couldn't be run.
+	PreflightChecksFailedReason = "PreflightChecksFailed"
+)
+
+const (
+	// ResourceLimitsAvailableCondition reports whether the account, domain and project limits leave room for the
+	// machine's VM.
+	ResourceLimitsAvailableCondition clusterv1.ConditionType = "ResourceLimitsAvailable"
+
+	// LimitExceededReason is used when deploying the VM would exceed a CloudStack resource limit.
+	LimitExceededReason = "LimitExceeded"
+)
+
+const (
+	// FailureDomainAvailableCondition reports whether the failure domain's zone is enabled and its network usable.
+	// Unavailable failure domains aren't offered to new machines.
+	FailureDomainAvailableCondition clusterv1.ConditionType = "Available"
+
+	// ZoneDisabledReason is used when the zone's allocation state isn't Enabled.
+	ZoneDisabledReason = "ZoneDisabled"
+	// NetworkDegradedReason is used when the network is shut down or being destroyed.
+	NetworkDegradedReason = "NetworkDegraded"
+	// AvailabilityCheckFailedReason is used when the zone or network state couldn't be looked up.
+	AvailabilityCheckFailedReason = "AvailabilityCheckFailed"
+)
+
+const (
+	// FailureDomainEvacuatedCondition reports the progress of moving machines off a failure domain annotated with
+	// FailureDomainEvacuateAnnotation. It's removed along with the annotation.
+	FailureDomainEvacuatedCondition clusterv1.ConditionType = "Evacuated"
+
+	// EvacuatingReason is used while machines are still being moved off the failure domain.
+	EvacuatingReason = "Evacuating"
+	// EvacuationBlockedReason is used when a machine can't be moved without risking its MachineSet or control plane.
+	EvacuationBlockedReason = "EvacuationBlocked"
+	// MachinesPinnedReason is used when the only machines left are pinned to the failure domain by their
+	// MachineDeployment, and would be recreated in it.
+	MachinesPinnedReason = "MachinesPinned"
+)
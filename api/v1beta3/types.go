@@ -33,6 +33,15 @@ type LoadBalancer struct {
 	IPAddressID string `json:"ipAddressID"`
 	//+optional
 	AllowedCIDRs []string `json:"allowedCIDRs,omitempty"`
+
+	// HealthCheckPolicy records that CAPC applied a health check policy to the load balancer rules, so it's removed
+	// once the health check is dropped from the spec.
+	//+optional
+	HealthCheckPolicy bool `json:"healthCheckPolicy,omitempty"`
+
+	// StickinessPolicy records that CAPC applied a stickiness policy to the load balancer rules.
+	//+optional
+	StickinessPolicy bool `json:"stickinessPolicy,omitempty"`
 }
 
 type APIServerLoadBalancer struct {
@@ -56,13 +65,186 @@ type APIServerLoadBalancer struct {
 	//+optional
 	//+listType=set
 	AllowedCIDRs []string `json:"allowedCIDRs,omitempty"`
+
+	// Algorithm the load balancer uses to pick a control plane machine. Defaults to roundrobin.
+	//+kubebuilder:validation:Enum=roundrobin;leastconn;source
+	//+optional
+	Algorithm string `json:"algorithm,omitempty"`
+
+	// HealthCheck takes control plane machines that fail it out of rotation.
+	//+optional
+	HealthCheck *LoadBalancerHealthCheck `json:"healthCheck,omitempty"`
+
+	// Stickiness keeps sending a client to the same control plane machine.
+	//+optional
+	Stickiness *LoadBalancerStickiness `json:"stickiness,omitempty"`
+}
+
+const (
+	LoadBalancerHealthCheckTCP = "TCP"
+)
+
+// LoadBalancerHealthCheck configures the health check policy of the API server load balancer rules.
+type LoadBalancerHealthCheck struct {
+	// Type of the check. TCP checks that the port accepts connections.
+	//+kubebuilder:validation:Enum=TCP
+	//+kubebuilder:default:=TCP
+	//+optional
+	Type string `json:"type,omitempty"`
+
+	// IntervalSeconds between two checks. Defaults to 5.
+	//+kubebuilder:validation:Minimum=1
+	//+optional
+	IntervalSeconds int `json:"intervalSeconds,omitempty"`
+
+	// TimeoutSeconds to wait for a response. Defaults to 2.
+	//+kubebuilder:validation:Minimum=1
+	//+optional
+	TimeoutSeconds int `json:"timeoutSeconds,omitempty"`
+
+	// HealthyThreshold is the number of consecutive successful checks before a machine is put back in rotation.
+	// Defaults to 2.
+	//+kubebuilder:validation:Minimum=1
+	//+optional
+	HealthyThreshold int `json:"healthyThreshold,omitempty"`
+
+	// UnhealthyThreshold is the number of consecutive failed checks before a machine is taken out of rotation.
+	// Defaults to 3.
+	//+kubebuilder:validation:Minimum=1
+	//+optional
+	UnhealthyThreshold int `json:"unhealthyThreshold,omitempty"`
+}
+
+// LoadBalancerStickiness configures the stickiness policy of the API server load balancer rules.
+type LoadBalancerStickiness struct {
+	// Method of the stickiness policy, as supported by the load balancer provider of the network.
+	//+kubebuilder:validation:Enum=SourceBased;LbCookie
+	Method string `json:"method"`
+}
+
+// AlgorithmOrDefault returns the load balancing algorithm of the API server load balancer.
+func (s *APIServerLoadBalancer) AlgorithmOrDefault() string {
+	if s == nil || s.Algorithm == "" {
+		return "roundrobin"
+	}
+
+	return s.Algorithm
 }
 
 func (s *APIServerLoadBalancer) IsZero() bool {
-	return s == nil || ((s.Enabled == nil || !*s.Enabled) && len(s.AdditionalPorts) == 0 && len(s.AllowedCIDRs) == 0)
+	return s == nil || ((s.Enabled == nil || !*s.Enabled) && len(s.AdditionalPorts) == 0 && len(s.AllowedCIDRs) == 0 &&
+		s.Algorithm == "" && s.HealthCheck == nil && s.Stickiness == nil)
 }
 
 func (s *APIServerLoadBalancer) IsEnabled() bool {
 	// The CRD default value for Enabled is true, so if the field is nil, it should be considered as true.
 	return s != nil && (s.Enabled == nil || *s.Enabled)
 }
+
+// Bastion configures a jump host in the network of a failure domain, for reaching the cluster's machines over SSH.
+type Bastion struct {
+	// FailureDomainName -- the name of the FailureDomain whose isolated network the bastion is deployed in.
+	FailureDomainName string `json:"failureDomainName"`
+
+	// Offering is the service offering of the bastion VM.
+	Offering CloudStackResourceIdentifier `json:"offering"`
+
+	// Template is the template the bastion VM is deployed from.
+	Template CloudStackResourceIdentifier `json:"template"`
+
+	// SSHKey is the name of the CloudStack SSH key pair to log into the bastion with.
+	SSHKey string `json:"sshKey"`
+
+	// AllowedCIDRs may reach the bastion on port 22.
+	//+kubebuilder:validation:MinItems=1
+	//+listType=set
+	AllowedCIDRs []string `json:"allowedCIDRs"`
+}
+
+// BastionStatus reports the bastion host of the cluster.
+type BastionStatus struct {
+	// FailureDomainName -- the name of the FailureDomain the bastion was deployed in.
+	//+optional
+	FailureDomainName string `json:"failureDomainName,omitempty"`
+
+	// InstanceID is the CloudStack ID of the bastion VM.
+	//+optional
+	InstanceID string `json:"instanceID,omitempty"`
+
+	// PrivateIPAddress is the address of the bastion VM in the network.
+	//+optional
+	PrivateIPAddress string `json:"privateIPAddress,omitempty"`
+
+	// IPAddress is the public IP address the bastion is reachable on.
+	//+optional
+	IPAddress string `json:"ipAddress,omitempty"`
+
+	// IPAddressID is the CloudStack ID of the public IP address.
+	//+optional
+	IPAddressID string `json:"ipAddressID,omitempty"`
+
+	// Ready is true once the bastion is reachable from the allowed CIDRs.
+	//+optional
+	Ready bool `json:"ready"`
+}
+
+// APIServerVMLoadBalancer deploys HAProxy VMs in front of the control plane, for shared networks that have no CloudStack
+// load balancer. With two replicas, keepalived moves a virtual IP between them.
+type APIServerVMLoadBalancer struct {
+	// FailureDomainName -- the name of the FailureDomain whose shared network the load balancer VMs are deployed in.
+	FailureDomainName string `json:"failureDomainName"`
+
+	// Offering is the service offering of the load balancer VMs.
+	Offering CloudStackResourceIdentifier `json:"offering"`
+
+	// Template the load balancer VMs are deployed from. It must ship cloud-init, curl, HAProxy and, with a virtual IP,
+	// keepalived.
+	Template CloudStackResourceIdentifier `json:"template"`
+
+	// SSHKey is the name of the CloudStack SSH key pair to log into the load balancer VMs with.
+	//+optional
+	SSHKey string `json:"sshKey,omitempty"`
+
+	// Replicas is the number of load balancer VMs, one or two. Defaults to two.
+	//+kubebuilder:validation:Minimum=1
+	//+kubebuilder:validation:Maximum=2
+	//+optional
+	Replicas int `json:"replicas,omitempty"`
+
+	// VirtualIP is an unused address in the shared network that keepalived assigns to the active load balancer VM.
+	// Required with two replicas. With one, the address of the VM is used.
+	//+optional
+	VirtualIP string `json:"virtualIP,omitempty"`
+}
+
+// ReplicasOrDefault returns the number of load balancer VMs.
+func (s *APIServerVMLoadBalancer) ReplicasOrDefault() int {
+	if s.Replicas == 0 {
+		return 2
+	}
+
+	return s.Replicas
+}
+
+// APIServerVMLoadBalancerStatus reports the load balancer VMs in front of the control plane.
+type APIServerVMLoadBalancerStatus struct {
+	// FailureDomainName -- the name of the FailureDomain the load balancer VMs were deployed in.
+	//+optional
+	FailureDomainName string `json:"failureDomainName,omitempty"`
+
+	// InstanceIDs are the CloudStack IDs of the load balancer VMs.
+	//+optional
+	InstanceIDs []string `json:"instanceIDs,omitempty"`
+
+	// IPAddresses are the addresses of the load balancer VMs in the network.
+	//+optional
+	IPAddresses []string `json:"ipAddresses,omitempty"`
+
+	// Backends are the addresses of the control plane machines the load balancer forwards to.
+	//+optional
+	Backends []string `json:"backends,omitempty"`
+
+	// Ready is true once all load balancer VMs are running.
+	//+optional
+	Ready bool `json:"ready"`
+}
@@ -30,6 +30,7 @@ import (
 	//+kubebuilder:scaffold:imports
 	admissionv1 "k8s.io/api/admission/v1"
 	apimachineryruntime "k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -76,6 +77,9 @@ var _ = BeforeSuite(func() {
 	Expect(cfg).NotTo(BeNil())
 
 	scheme := apimachineryruntime.NewScheme()
+	err = clientgoscheme.AddToScheme(scheme)
+	Expect(err).NotTo(HaveOccurred())
+
 	err = infrav1.AddToScheme(scheme)
 	Expect(err).NotTo(HaveOccurred())
 
@@ -105,6 +109,8 @@ var _ = BeforeSuite(func() {
 	Ω((&infrav1.CloudStackCluster{}).SetupWebhookWithManager(mgr)).Should(Succeed())
 	Ω((&infrav1.CloudStackMachine{}).SetupWebhookWithManager(mgr)).Should(Succeed())
 	Ω((&infrav1.CloudStackMachineTemplate{}).SetupWebhookWithManager(mgr)).Should(Succeed())
+	Ω((&infrav1.CloudStackClusterIdentity{}).SetupWebhookWithManager(mgr)).Should(Succeed())
+	Ω((&infrav1.CloudStackLoadBalancer{}).SetupWebhookWithManager(mgr)).Should(Succeed())
 
 	//+kubebuilder:scaffold:webhook
 
@@ -21,7 +21,8 @@ limitations under the License.
 package v1beta3
 
 import (
-	"k8s.io/api/core/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/cluster-api/api/v1beta1"
 )
@@ -44,6 +45,16 @@ func (in *APIServerLoadBalancer) DeepCopyInto(out *APIServerLoadBalancer) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.HealthCheck != nil {
+		in, out := &in.HealthCheck, &out.HealthCheck
+		*out = new(LoadBalancerHealthCheck)
+		**out = **in
+	}
+	if in.Stickiness != nil {
+		in, out := &in.Stickiness, &out.Stickiness
+		*out = new(LoadBalancerStickiness)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new APIServerLoadBalancer.
@@ -56,6 +67,115 @@ func (in *APIServerLoadBalancer) DeepCopy() *APIServerLoadBalancer {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *APIServerVMLoadBalancer) DeepCopyInto(out *APIServerVMLoadBalancer) {
+	*out = *in
+	out.Offering = in.Offering
+	out.Template = in.Template
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new APIServerVMLoadBalancer.
+func (in *APIServerVMLoadBalancer) DeepCopy() *APIServerVMLoadBalancer {
+	if in == nil {
+		return nil
+	}
+	out := new(APIServerVMLoadBalancer)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *APIServerVMLoadBalancerStatus) DeepCopyInto(out *APIServerVMLoadBalancerStatus) {
+	*out = *in
+	if in.InstanceIDs != nil {
+		in, out := &in.InstanceIDs, &out.InstanceIDs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.IPAddresses != nil {
+		in, out := &in.IPAddresses, &out.IPAddresses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Backends != nil {
+		in, out := &in.Backends, &out.Backends
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new APIServerVMLoadBalancerStatus.
+func (in *APIServerVMLoadBalancerStatus) DeepCopy() *APIServerVMLoadBalancerStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(APIServerVMLoadBalancerStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AllowedNamespaces) DeepCopyInto(out *AllowedNamespaces) {
+	*out = *in
+	if in.NamespaceList != nil {
+		in, out := &in.NamespaceList, &out.NamespaceList
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AllowedNamespaces.
+func (in *AllowedNamespaces) DeepCopy() *AllowedNamespaces {
+	if in == nil {
+		return nil
+	}
+	out := new(AllowedNamespaces)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Bastion) DeepCopyInto(out *Bastion) {
+	*out = *in
+	out.Offering = in.Offering
+	out.Template = in.Template
+	if in.AllowedCIDRs != nil {
+		in, out := &in.AllowedCIDRs, &out.AllowedCIDRs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Bastion.
+func (in *Bastion) DeepCopy() *Bastion {
+	if in == nil {
+		return nil
+	}
+	out := new(Bastion)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BastionStatus) DeepCopyInto(out *BastionStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BastionStatus.
+func (in *BastionStatus) DeepCopy() *BastionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BastionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CloudStackAffinityGroup) DeepCopyInto(out *CloudStackAffinityGroup) {
 	*out = *in
@@ -172,6 +292,116 @@ func (in *CloudStackCluster) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloudStackClusterIdentity) DeepCopyInto(out *CloudStackClusterIdentity) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStackClusterIdentity.
+func (in *CloudStackClusterIdentity) DeepCopy() *CloudStackClusterIdentity {
+	if in == nil {
+		return nil
+	}
+	out := new(CloudStackClusterIdentity)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CloudStackClusterIdentity) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloudStackClusterIdentityList) DeepCopyInto(out *CloudStackClusterIdentityList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]CloudStackClusterIdentity, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStackClusterIdentityList.
+func (in *CloudStackClusterIdentityList) DeepCopy() *CloudStackClusterIdentityList {
+	if in == nil {
+		return nil
+	}
+	out := new(CloudStackClusterIdentityList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CloudStackClusterIdentityList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloudStackClusterIdentityReference) DeepCopyInto(out *CloudStackClusterIdentityReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStackClusterIdentityReference.
+func (in *CloudStackClusterIdentityReference) DeepCopy() *CloudStackClusterIdentityReference {
+	if in == nil {
+		return nil
+	}
+	out := new(CloudStackClusterIdentityReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloudStackClusterIdentitySpec) DeepCopyInto(out *CloudStackClusterIdentitySpec) {
+	*out = *in
+	out.SecretRef = in.SecretRef
+	if in.AllowedNamespaces != nil {
+		in, out := &in.AllowedNamespaces, &out.AllowedNamespaces
+		*out = new(AllowedNamespaces)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStackClusterIdentitySpec.
+func (in *CloudStackClusterIdentitySpec) DeepCopy() *CloudStackClusterIdentitySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CloudStackClusterIdentitySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloudStackClusterIdentityStatus) DeepCopyInto(out *CloudStackClusterIdentityStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStackClusterIdentityStatus.
+func (in *CloudStackClusterIdentityStatus) DeepCopy() *CloudStackClusterIdentityStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CloudStackClusterIdentityStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CloudStackClusterList) DeepCopyInto(out *CloudStackClusterList) {
 	*out = *in
@@ -210,7 +440,9 @@ func (in *CloudStackClusterSpec) DeepCopyInto(out *CloudStackClusterSpec) {
 	if in.FailureDomains != nil {
 		in, out := &in.FailureDomains, &out.FailureDomains
 		*out = make([]CloudStackFailureDomainSpec, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	out.ControlPlaneEndpoint = in.ControlPlaneEndpoint
 	if in.APIServerLoadBalancer != nil {
@@ -218,6 +450,16 @@ func (in *CloudStackClusterSpec) DeepCopyInto(out *CloudStackClusterSpec) {
 		*out = new(APIServerLoadBalancer)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.APIServerVMLoadBalancer != nil {
+		in, out := &in.APIServerVMLoadBalancer, &out.APIServerVMLoadBalancer
+		*out = new(APIServerVMLoadBalancer)
+		**out = **in
+	}
+	if in.Bastion != nil {
+		in, out := &in.Bastion, &out.Bastion
+		*out = new(Bastion)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStackClusterSpec.
@@ -240,6 +482,23 @@ func (in *CloudStackClusterStatus) DeepCopyInto(out *CloudStackClusterStatus) {
 			(*out)[key] = *val.DeepCopy()
 		}
 	}
+	if in.APIServerVMLoadBalancer != nil {
+		in, out := &in.APIServerVMLoadBalancer, &out.APIServerVMLoadBalancer
+		*out = new(APIServerVMLoadBalancerStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Bastion != nil {
+		in, out := &in.Bastion, &out.Bastion
+		*out = new(BastionStatus)
+		**out = **in
+	}
+	if in.ManagedTenants != nil {
+		in, out := &in.ManagedTenants, &out.ManagedTenants
+		*out = make([]ManagedTenantRecord, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStackClusterStatus.
@@ -257,8 +516,8 @@ func (in *CloudStackFailureDomain) DeepCopyInto(out *CloudStackFailureDomain) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
-	out.Status = in.Status
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStackFailureDomain.
@@ -279,6 +538,45 @@ func (in *CloudStackFailureDomain) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloudStackFailureDomainCapacity) DeepCopyInto(out *CloudStackFailureDomainCapacity) {
+	*out = *in
+	if in.Account != nil {
+		in, out := &in.Account, &out.Account
+		*out = new(CloudStackResourceHeadroom)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Domain != nil {
+		in, out := &in.Domain, &out.Domain
+		*out = new(CloudStackResourceHeadroom)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Project != nil {
+		in, out := &in.Project, &out.Project
+		*out = new(CloudStackResourceHeadroom)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Zone != nil {
+		in, out := &in.Zone, &out.Zone
+		*out = make([]CloudStackZoneCapacity, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastUpdated != nil {
+		in, out := &in.LastUpdated, &out.LastUpdated
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStackFailureDomainCapacity.
+func (in *CloudStackFailureDomainCapacity) DeepCopy() *CloudStackFailureDomainCapacity {
+	if in == nil {
+		return nil
+	}
+	out := new(CloudStackFailureDomainCapacity)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CloudStackFailureDomainList) DeepCopyInto(out *CloudStackFailureDomainList) {
 	*out = *in
@@ -314,8 +612,28 @@ func (in *CloudStackFailureDomainList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CloudStackFailureDomainSpec) DeepCopyInto(out *CloudStackFailureDomainSpec) {
 	*out = *in
-	out.Zone = in.Zone
+	in.Zone.DeepCopyInto(&out.Zone)
 	out.ACSEndpoint = in.ACSEndpoint
+	if in.IdentityRef != nil {
+		in, out := &in.IdentityRef, &out.IdentityRef
+		*out = new(CloudStackClusterIdentityReference)
+		**out = **in
+	}
+	if in.ManagedTenant != nil {
+		in, out := &in.ManagedTenant, &out.ManagedTenant
+		*out = new(CloudStackManagedTenant)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ControlPlane != nil {
+		in, out := &in.ControlPlane, &out.ControlPlane
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Weight != nil {
+		in, out := &in.Weight, &out.Weight
+		*out = new(int32)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStackFailureDomainSpec.
@@ -331,6 +649,18 @@ func (in *CloudStackFailureDomainSpec) DeepCopy() *CloudStackFailureDomainSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CloudStackFailureDomainStatus) DeepCopyInto(out *CloudStackFailureDomainStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make(v1beta1.Conditions, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Capacity != nil {
+		in, out := &in.Capacity, &out.Capacity
+		*out = new(CloudStackFailureDomainCapacity)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStackFailureDomainStatus.
@@ -348,7 +678,7 @@ func (in *CloudStackIsolatedNetwork) DeepCopyInto(out *CloudStackIsolatedNetwork
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	in.Status.DeepCopyInto(&out.Status)
 }
 
@@ -406,6 +736,18 @@ func (in *CloudStackIsolatedNetworkList) DeepCopyObject() runtime.Object {
 func (in *CloudStackIsolatedNetworkSpec) DeepCopyInto(out *CloudStackIsolatedNetworkSpec) {
 	*out = *in
 	out.ControlPlaneEndpoint = in.ControlPlaneEndpoint
+	if in.Offering != nil {
+		in, out := &in.Offering, &out.Offering
+		*out = new(CloudStackResourceIdentifier)
+		**out = **in
+	}
+	if in.EgressRules != nil {
+		in, out := &in.EgressRules, &out.EgressRules
+		*out = make([]EgressRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStackIsolatedNetworkSpec.
@@ -444,7 +786,7 @@ func (in *CloudStackIsolatedNetworkStatus) DeepCopy() *CloudStackIsolatedNetwork
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CloudStackMachine) DeepCopyInto(out *CloudStackMachine) {
+func (in *CloudStackLoadBalancer) DeepCopyInto(out *CloudStackLoadBalancer) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -452,18 +794,18 @@ func (in *CloudStackMachine) DeepCopyInto(out *CloudStackMachine) {
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStackMachine.
-func (in *CloudStackMachine) DeepCopy() *CloudStackMachine {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStackLoadBalancer.
+func (in *CloudStackLoadBalancer) DeepCopy() *CloudStackLoadBalancer {
 	if in == nil {
 		return nil
 	}
-	out := new(CloudStackMachine)
+	out := new(CloudStackLoadBalancer)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *CloudStackMachine) DeepCopyObject() runtime.Object {
+func (in *CloudStackLoadBalancer) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -471,31 +813,31 @@ func (in *CloudStackMachine) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CloudStackMachineList) DeepCopyInto(out *CloudStackMachineList) {
+func (in *CloudStackLoadBalancerList) DeepCopyInto(out *CloudStackLoadBalancerList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]CloudStackMachine, len(*in))
+		*out = make([]CloudStackLoadBalancer, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStackMachineList.
-func (in *CloudStackMachineList) DeepCopy() *CloudStackMachineList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStackLoadBalancerList.
+func (in *CloudStackLoadBalancerList) DeepCopy() *CloudStackLoadBalancerList {
 	if in == nil {
 		return nil
 	}
-	out := new(CloudStackMachineList)
+	out := new(CloudStackLoadBalancerList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *CloudStackMachineList) DeepCopyObject() runtime.Object {
+func (in *CloudStackLoadBalancerList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -503,24 +845,153 @@ func (in *CloudStackMachineList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CloudStackMachineSpec) DeepCopyInto(out *CloudStackMachineSpec) {
+func (in *CloudStackLoadBalancerSpec) DeepCopyInto(out *CloudStackLoadBalancerSpec) {
 	*out = *in
-	if in.InstanceID != nil {
-		in, out := &in.InstanceID, &out.InstanceID
-		*out = new(string)
-		**out = **in
+	if in.Ports != nil {
+		in, out := &in.Ports, &out.Ports
+		*out = make([]LoadBalancerPort, len(*in))
+		copy(*out, *in)
 	}
-	out.Offering = in.Offering
-	out.Template = in.Template
-	if in.DiskOffering != nil {
-		in, out := &in.DiskOffering, &out.DiskOffering
-		*out = new(CloudStackResourceDiskOffering)
-		**out = **in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
 	}
-	if in.Details != nil {
-		in, out := &in.Details, &out.Details
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
+	if in.AllowedCIDRs != nil {
+		in, out := &in.AllowedCIDRs, &out.AllowedCIDRs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStackLoadBalancerSpec.
+func (in *CloudStackLoadBalancerSpec) DeepCopy() *CloudStackLoadBalancerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CloudStackLoadBalancerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloudStackLoadBalancerStatus) DeepCopyInto(out *CloudStackLoadBalancerStatus) {
+	*out = *in
+	if in.LoadBalancerRuleIDs != nil {
+		in, out := &in.LoadBalancerRuleIDs, &out.LoadBalancerRuleIDs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.InstanceIDs != nil {
+		in, out := &in.InstanceIDs, &out.InstanceIDs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStackLoadBalancerStatus.
+func (in *CloudStackLoadBalancerStatus) DeepCopy() *CloudStackLoadBalancerStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CloudStackLoadBalancerStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloudStackMachine) DeepCopyInto(out *CloudStackMachine) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStackMachine.
+func (in *CloudStackMachine) DeepCopy() *CloudStackMachine {
+	if in == nil {
+		return nil
+	}
+	out := new(CloudStackMachine)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CloudStackMachine) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloudStackMachineList) DeepCopyInto(out *CloudStackMachineList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]CloudStackMachine, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStackMachineList.
+func (in *CloudStackMachineList) DeepCopy() *CloudStackMachineList {
+	if in == nil {
+		return nil
+	}
+	out := new(CloudStackMachineList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CloudStackMachineList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloudStackMachinePlacement) DeepCopyInto(out *CloudStackMachinePlacement) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStackMachinePlacement.
+func (in *CloudStackMachinePlacement) DeepCopy() *CloudStackMachinePlacement {
+	if in == nil {
+		return nil
+	}
+	out := new(CloudStackMachinePlacement)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloudStackMachineSpec) DeepCopyInto(out *CloudStackMachineSpec) {
+	*out = *in
+	if in.InstanceID != nil {
+		in, out := &in.InstanceID, &out.InstanceID
+		*out = new(string)
+		**out = **in
+	}
+	out.Offering = in.Offering
+	out.Template = in.Template
+	if in.DiskOffering != nil {
+		in, out := &in.DiskOffering, &out.DiskOffering
+		*out = new(CloudStackResourceDiskOffering)
+		**out = **in
+	}
+	if in.Details != nil {
+		in, out := &in.Details, &out.Details
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
 			(*out)[key] = val
 		}
 	}
@@ -531,7 +1002,7 @@ func (in *CloudStackMachineSpec) DeepCopyInto(out *CloudStackMachineSpec) {
 	}
 	if in.AffinityGroupRef != nil {
 		in, out := &in.AffinityGroupRef, &out.AffinityGroupRef
-		*out = new(v1.ObjectReference)
+		*out = new(corev1.ObjectReference)
 		**out = **in
 	}
 	if in.ProviderID != nil {
@@ -650,7 +1121,7 @@ func (in *CloudStackMachineStatus) DeepCopyInto(out *CloudStackMachineStatus) {
 	*out = *in
 	if in.Addresses != nil {
 		in, out := &in.Addresses, &out.Addresses
-		*out = make([]v1.NodeAddress, len(*in))
+		*out = make([]corev1.NodeAddress, len(*in))
 		copy(*out, *in)
 	}
 	in.InstanceStateLastUpdated.DeepCopyInto(&out.InstanceStateLastUpdated)
@@ -664,6 +1135,18 @@ func (in *CloudStackMachineStatus) DeepCopyInto(out *CloudStackMachineStatus) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make(v1beta1.Conditions, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Placement != nil {
+		in, out := &in.Placement, &out.Placement
+		*out = new(CloudStackMachinePlacement)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStackMachineStatus.
@@ -767,6 +1250,26 @@ func (in *CloudStackMachineTemplateSpec) DeepCopy() *CloudStackMachineTemplateSp
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloudStackManagedTenant) DeepCopyInto(out *CloudStackManagedTenant) {
+	*out = *in
+	if in.Limits != nil {
+		in, out := &in.Limits, &out.Limits
+		*out = new(CloudStackResourceLimits)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStackManagedTenant.
+func (in *CloudStackManagedTenant) DeepCopy() *CloudStackManagedTenant {
+	if in == nil {
+		return nil
+	}
+	out := new(CloudStackManagedTenant)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CloudStackResourceDiskOffering) DeepCopyInto(out *CloudStackResourceDiskOffering) {
 	*out = *in
@@ -783,6 +1286,56 @@ func (in *CloudStackResourceDiskOffering) DeepCopy() *CloudStackResourceDiskOffe
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloudStackResourceHeadroom) DeepCopyInto(out *CloudStackResourceHeadroom) {
+	*out = *in
+	if in.Instances != nil {
+		in, out := &in.Instances, &out.Instances
+		*out = new(int64)
+		**out = **in
+	}
+	if in.CPU != nil {
+		in, out := &in.CPU, &out.CPU
+		*out = new(int64)
+		**out = **in
+	}
+	if in.MemoryMiB != nil {
+		in, out := &in.MemoryMiB, &out.MemoryMiB
+		*out = new(int64)
+		**out = **in
+	}
+	if in.PrimaryStorageGiB != nil {
+		in, out := &in.PrimaryStorageGiB, &out.PrimaryStorageGiB
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Volumes != nil {
+		in, out := &in.Volumes, &out.Volumes
+		*out = new(int64)
+		**out = **in
+	}
+	if in.PublicIPs != nil {
+		in, out := &in.PublicIPs, &out.PublicIPs
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Networks != nil {
+		in, out := &in.Networks, &out.Networks
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStackResourceHeadroom.
+func (in *CloudStackResourceHeadroom) DeepCopy() *CloudStackResourceHeadroom {
+	if in == nil {
+		return nil
+	}
+	out := new(CloudStackResourceHeadroom)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CloudStackResourceIdentifier) DeepCopyInto(out *CloudStackResourceIdentifier) {
 	*out = *in
@@ -798,10 +1351,272 @@ func (in *CloudStackResourceIdentifier) DeepCopy() *CloudStackResourceIdentifier
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloudStackResourceLimits) DeepCopyInto(out *CloudStackResourceLimits) {
+	*out = *in
+	if in.Instances != nil {
+		in, out := &in.Instances, &out.Instances
+		*out = new(int64)
+		**out = **in
+	}
+	if in.CPU != nil {
+		in, out := &in.CPU, &out.CPU
+		*out = new(int64)
+		**out = **in
+	}
+	if in.MemoryMiB != nil {
+		in, out := &in.MemoryMiB, &out.MemoryMiB
+		*out = new(int64)
+		**out = **in
+	}
+	if in.PrimaryStorageGiB != nil {
+		in, out := &in.PrimaryStorageGiB, &out.PrimaryStorageGiB
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Volumes != nil {
+		in, out := &in.Volumes, &out.Volumes
+		*out = new(int64)
+		**out = **in
+	}
+	if in.PublicIPs != nil {
+		in, out := &in.PublicIPs, &out.PublicIPs
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Networks != nil {
+		in, out := &in.Networks, &out.Networks
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStackResourceLimits.
+func (in *CloudStackResourceLimits) DeepCopy() *CloudStackResourceLimits {
+	if in == nil {
+		return nil
+	}
+	out := new(CloudStackResourceLimits)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloudStackSSHKeyPair) DeepCopyInto(out *CloudStackSSHKeyPair) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStackSSHKeyPair.
+func (in *CloudStackSSHKeyPair) DeepCopy() *CloudStackSSHKeyPair {
+	if in == nil {
+		return nil
+	}
+	out := new(CloudStackSSHKeyPair)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CloudStackSSHKeyPair) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloudStackSSHKeyPairList) DeepCopyInto(out *CloudStackSSHKeyPairList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]CloudStackSSHKeyPair, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStackSSHKeyPairList.
+func (in *CloudStackSSHKeyPairList) DeepCopy() *CloudStackSSHKeyPairList {
+	if in == nil {
+		return nil
+	}
+	out := new(CloudStackSSHKeyPairList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CloudStackSSHKeyPairList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloudStackSSHKeyPairSpec) DeepCopyInto(out *CloudStackSSHKeyPairSpec) {
+	*out = *in
+	if in.PublicKeySecretRef != nil {
+		in, out := &in.PublicKeySecretRef, &out.PublicKeySecretRef
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStackSSHKeyPairSpec.
+func (in *CloudStackSSHKeyPairSpec) DeepCopy() *CloudStackSSHKeyPairSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CloudStackSSHKeyPairSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloudStackSSHKeyPairStatus) DeepCopyInto(out *CloudStackSSHKeyPairStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStackSSHKeyPairStatus.
+func (in *CloudStackSSHKeyPairStatus) DeepCopy() *CloudStackSSHKeyPairStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CloudStackSSHKeyPairStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloudStackVPC) DeepCopyInto(out *CloudStackVPC) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStackVPC.
+func (in *CloudStackVPC) DeepCopy() *CloudStackVPC {
+	if in == nil {
+		return nil
+	}
+	out := new(CloudStackVPC)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CloudStackVPC) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloudStackVPCList) DeepCopyInto(out *CloudStackVPCList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]CloudStackVPC, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStackVPCList.
+func (in *CloudStackVPCList) DeepCopy() *CloudStackVPCList {
+	if in == nil {
+		return nil
+	}
+	out := new(CloudStackVPCList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CloudStackVPCList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloudStackVPCSpec) DeepCopyInto(out *CloudStackVPCSpec) {
+	*out = *in
+	in.VPCSpec.DeepCopyInto(&out.VPCSpec)
+	out.ControlPlaneEndpoint = in.ControlPlaneEndpoint
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStackVPCSpec.
+func (in *CloudStackVPCSpec) DeepCopy() *CloudStackVPCSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CloudStackVPCSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloudStackVPCStatus) DeepCopyInto(out *CloudStackVPCStatus) {
+	*out = *in
+	out.ControlPlaneTier = in.ControlPlaneTier
+	out.WorkerTier = in.WorkerTier
+	if in.LoadBalancerRuleIDs != nil {
+		in, out := &in.LoadBalancerRuleIDs, &out.LoadBalancerRuleIDs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.APIServerLoadBalancer != nil {
+		in, out := &in.APIServerLoadBalancer, &out.APIServerLoadBalancer
+		*out = new(LoadBalancer)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStackVPCStatus.
+func (in *CloudStackVPCStatus) DeepCopy() *CloudStackVPCStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CloudStackVPCStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloudStackZoneCapacity) DeepCopyInto(out *CloudStackZoneCapacity) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStackZoneCapacity.
+func (in *CloudStackZoneCapacity) DeepCopy() *CloudStackZoneCapacity {
+	if in == nil {
+		return nil
+	}
+	out := new(CloudStackZoneCapacity)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CloudStackZoneSpec) DeepCopyInto(out *CloudStackZoneSpec) {
 	*out = *in
-	out.Network = in.Network
+	in.Network.DeepCopyInto(&out.Network)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStackZoneSpec.
@@ -814,6 +1629,26 @@ func (in *CloudStackZoneSpec) DeepCopy() *CloudStackZoneSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EgressRule) DeepCopyInto(out *EgressRule) {
+	*out = *in
+	if in.DestinationCIDRs != nil {
+		in, out := &in.DestinationCIDRs, &out.DestinationCIDRs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EgressRule.
+func (in *EgressRule) DeepCopy() *EgressRule {
+	if in == nil {
+		return nil
+	}
+	out := new(EgressRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LoadBalancer) DeepCopyInto(out *LoadBalancer) {
 	*out = *in
@@ -834,9 +1669,92 @@ func (in *LoadBalancer) DeepCopy() *LoadBalancer {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoadBalancerHealthCheck) DeepCopyInto(out *LoadBalancerHealthCheck) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadBalancerHealthCheck.
+func (in *LoadBalancerHealthCheck) DeepCopy() *LoadBalancerHealthCheck {
+	if in == nil {
+		return nil
+	}
+	out := new(LoadBalancerHealthCheck)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoadBalancerPort) DeepCopyInto(out *LoadBalancerPort) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadBalancerPort.
+func (in *LoadBalancerPort) DeepCopy() *LoadBalancerPort {
+	if in == nil {
+		return nil
+	}
+	out := new(LoadBalancerPort)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoadBalancerStickiness) DeepCopyInto(out *LoadBalancerStickiness) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadBalancerStickiness.
+func (in *LoadBalancerStickiness) DeepCopy() *LoadBalancerStickiness {
+	if in == nil {
+		return nil
+	}
+	out := new(LoadBalancerStickiness)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManagedTenantRecord) DeepCopyInto(out *ManagedTenantRecord) {
+	*out = *in
+	out.ACSEndpoint = in.ACSEndpoint
+	if in.IdentityRef != nil {
+		in, out := &in.IdentityRef, &out.IdentityRef
+		*out = new(CloudStackClusterIdentityReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagedTenantRecord.
+func (in *ManagedTenantRecord) DeepCopy() *ManagedTenantRecord {
+	if in == nil {
+		return nil
+	}
+	out := new(ManagedTenantRecord)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Network) DeepCopyInto(out *Network) {
 	*out = *in
+	if in.Offering != nil {
+		in, out := &in.Offering, &out.Offering
+		*out = new(CloudStackResourceIdentifier)
+		**out = **in
+	}
+	if in.EgressRules != nil {
+		in, out := &in.EgressRules, &out.EgressRules
+		*out = make([]EgressRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.VPC != nil {
+		in, out := &in.VPC, &out.VPC
+		*out = new(VPCSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Network.
@@ -848,3 +1766,77 @@ func (in *Network) DeepCopy() *Network {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkACLRule) DeepCopyInto(out *NetworkACLRule) {
+	*out = *in
+	if in.CIDRs != nil {
+		in, out := &in.CIDRs, &out.CIDRs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkACLRule.
+func (in *NetworkACLRule) DeepCopy() *NetworkACLRule {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkACLRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VPCSpec) DeepCopyInto(out *VPCSpec) {
+	*out = *in
+	in.ControlPlaneTier.DeepCopyInto(&out.ControlPlaneTier)
+	in.WorkerTier.DeepCopyInto(&out.WorkerTier)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VPCSpec.
+func (in *VPCSpec) DeepCopy() *VPCSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VPCSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VPCTierSpec) DeepCopyInto(out *VPCTierSpec) {
+	*out = *in
+	if in.ACL != nil {
+		in, out := &in.ACL, &out.ACL
+		*out = make([]NetworkACLRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VPCTierSpec.
+func (in *VPCTierSpec) DeepCopy() *VPCTierSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VPCTierSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VPCTierStatus) DeepCopyInto(out *VPCTierStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VPCTierStatus.
+func (in *VPCTierStatus) DeepCopy() *VPCTierStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VPCTierStatus)
+	in.DeepCopyInto(out)
+	return out
+}
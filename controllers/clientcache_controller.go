@@ -0,0 +1,93 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	csCtrlrUtils "sigs.k8s.io/cluster-api-provider-cloudstack/controllers/utils"
+	"sigs.k8s.io/cluster-api-provider-cloudstack/pkg/cloud"
+)
+
+// ClientCacheReconciler keeps the CloudStack client cache in line with the endpoint secrets and the client config map.
+// It only watches metadata so that secret contents aren't held in the manager's cache.
+type ClientCacheReconciler struct {
+	csCtrlrUtils.ReconcilerBase
+}
+
+// ReconcileSecret evicts the cached clients built from a changed or deleted endpoint secret.
+func (reconciler *ClientCacheReconciler) ReconcileSecret(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	if evicted := cloud.InvalidateClientsForSecret(req.Namespace, req.Name); evicted > 0 {
+		ctrl.LoggerFrom(ctx).Info("Endpoint secret changed, evicted cached CloudStack clients.", "evicted", evicted)
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// ReconcileClientConfig reloads the client config map.
+func (reconciler *ClientCacheReconciler) ReconcileClientConfig(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	clientConfig := &corev1.ConfigMap{}
+	if err := reconciler.K8sClient.Get(ctx, req.NamespacedName, clientConfig); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return ctrl.Result{}, errors.Wrap(err, "getting client config map")
+		}
+		clientConfig = nil // Deleted, fall back to defaults.
+	}
+	ctrl.LoggerFrom(ctx).Info("Reloading CloudStack client config.")
+	cloud.ReloadClientConfig(clientConfig)
+
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the endpoint secret and client config map controllers with the Manager.
+func (reconciler *ClientCacheReconciler) SetupWithManager(_ context.Context, mgr ctrl.Manager) error {
+	// Creation can't make a cached client stale, and resyncs don't change the resource version.
+	changed := predicate.Funcs{
+		CreateFunc: func(event.CreateEvent) bool { return false },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return e.ObjectOld.GetResourceVersion() != e.ObjectNew.GetResourceVersion()
+		},
+		DeleteFunc:  func(event.DeleteEvent) bool { return true },
+		GenericFunc: func(event.GenericEvent) bool { return false },
+	}
+
+	if err := ctrl.NewControllerManagedBy(mgr).
+		Named("endpointsecret").
+		For(&corev1.Secret{}, builder.OnlyMetadata, builder.WithPredicates(changed)).
+		Complete(reconcile.Func(reconciler.ReconcileSecret)); err != nil {
+		return err
+	}
+
+	isClientConfig := predicate.NewPredicateFuncs(func(o client.Object) bool {
+		return o.GetName() == cloud.ClientConfigMapName && o.GetNamespace() == cloud.ClientConfigMapNamespace
+	})
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("clientconfig").
+		For(&corev1.ConfigMap{}, builder.OnlyMetadata, builder.WithPredicates(isClientConfig)).
+		Complete(reconcile.Func(reconciler.ReconcileClientConfig))
+}
@@ -20,10 +20,16 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"slices"
+	"strings"
 
 	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/cluster-api/util/predicates"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
@@ -35,6 +41,7 @@ import (
 
 	infrav1 "sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
 	csCtrlrUtils "sigs.k8s.io/cluster-api-provider-cloudstack/controllers/utils"
+	"sigs.k8s.io/cluster-api-provider-cloudstack/pkg/cloud"
 )
 
 // RBAC permissions used in all reconcilers. Events and Secrets.
@@ -42,6 +49,9 @@ import (
 // +kubebuilder:rbac:groups="",resources=secrets;,verbs=get;list;watch
 // +kubebuilder:rbac:groups="",resources=configmaps;,verbs=get;list;watch
 // +kubebuilder:rbac:groups="",resources=events,verbs=get;list;watch;create;update;patch
+// Namespaces and identities are read to check whether a failure domain may use a CloudStackClusterIdentity.
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=cloudstackclusteridentities,verbs=get;list;watch
 
 // RBAC permissions for CloudStackCluster.
 // +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=cloudstackclusters,verbs=get;list;watch;create;update;patch;delete
@@ -89,12 +99,15 @@ func (reconciler *CloudStackClusterReconciler) Reconcile(ctx context.Context, re
 // Reconcile actually reconciles the CloudStackCluster.
 func (r *CloudStackClusterReconciliationRunner) Reconcile() (ctrl.Result, error) {
 	return r.RunReconciliationStages(
-		r.SetFailureDomainsStatusMap,
+		r.RecordFailureDomainManagedTenants,
 		r.CreateFailureDomains(r.ReconciliationSubject.Spec.FailureDomains),
 		r.GetFailureDomains(r.FailureDomains),
+		r.SetFailureDomainsStatusMap,
 		r.RemoveExtraneousFailureDomains(r.FailureDomains),
 		r.VerifyFailureDomainCRDs,
-		r.SetReady)
+		r.ReconcileAPIServerVMLoadBalancer,
+		r.SetReady,
+		r.ReconcileBastion)
 }
 
 // SetReady adds a finalizer and sets the cluster status to ready.
@@ -105,23 +118,29 @@ func (r *CloudStackClusterReconciliationRunner) SetReady() (ctrl.Result, error)
 	return ctrl.Result{}, nil
 }
 
+// RecordFailureDomainManagedTenants records the managed tenants of the failure domains in the status before the failure
+// domains are created, and with them the tenants. The record is patched back right away, so a tenant is never created
+// without it.
+func (r *CloudStackClusterReconciliationRunner) RecordFailureDomainManagedTenants() (ctrl.Result, error) {
+	csCluster := r.ReconciliationSubject
+	recorded := len(csCluster.Status.ManagedTenants)
+	csCluster.Status.ManagedTenants = r.RecordManagedTenants(csCluster.Status.ManagedTenants, csCluster.Spec.FailureDomains)
+	if len(csCluster.Status.ManagedTenants) == recorded {
+		return ctrl.Result{}, nil
+	}
+
+	return r.PatchReconciliationSubject()
+}
+
 // VerifyFailureDomainCRDs verifies the FailureDomains found match against those requested.
 func (r *CloudStackClusterReconciliationRunner) VerifyFailureDomainCRDs() (ctrl.Result, error) {
-	// Check that all required failure domains are present and ready.
+	// Check that the current generations of all required failure domains are present and ready.
 	for _, requiredFdSpec := range r.ReconciliationSubject.Spec.FailureDomains {
-		found := false
-		for _, fd := range r.FailureDomains.Items {
-			if requiredFdSpec.Name == fd.Spec.Name {
-				found = true
-				if !fd.Status.Ready {
-					return r.RequeueWithMessage(fmt.Sprintf("Required FailureDomain %s not ready, requeueing.", fd.Spec.Name))
-				}
-
-				break
-			}
-		}
-		if !found {
+		fd := csCtrlrUtils.CurrentFailureDomainGeneration(r.FailureDomains, requiredFdSpec)
+		if fd == nil {
 			return r.RequeueWithMessage(fmt.Sprintf("Required FailureDomain %s not found, requeueing.", requiredFdSpec.Name))
+		} else if !fd.Status.Ready {
+			return r.RequeueWithMessage(fmt.Sprintf("Required FailureDomain %s not ready, requeueing.", fd.Spec.Name))
 		}
 	}
 
@@ -129,24 +148,209 @@ func (r *CloudStackClusterReconciliationRunner) VerifyFailureDomainCRDs() (ctrl.
 }
 
 // SetFailureDomainsStatusMap sets failure domains in CloudStackCluster status to be used for CAPI machine placement.
+// Failure domains that don't allow control plane machines, whose zone or network is unavailable, or that are being
+// evacuated, aren't eligible for control plane machines. The MetaHashName attribute names the current generation of
+// each failure domain, which new machines are placed in.
 func (r *CloudStackClusterReconciliationRunner) SetFailureDomainsStatusMap() (ctrl.Result, error) {
 	r.ReconciliationSubject.Status.FailureDomains = clusterv1.FailureDomains{}
 	for _, fdSpec := range r.ReconciliationSubject.Spec.FailureDomains {
 		metaHashName := infrav1.FailureDomainHashedMetaName(fdSpec.Name, r.CAPICluster.Name)
+		unavailable := false
+		if fd := csCtrlrUtils.CurrentFailureDomainGeneration(r.FailureDomains, fdSpec); fd != nil {
+			metaHashName = fd.Name
+			unavailable = conditions.IsFalse(fd, infrav1.FailureDomainAvailableCondition) || fd.IsEvacuating()
+		}
 		r.ReconciliationSubject.Status.FailureDomains[fdSpec.Name] = clusterv1.FailureDomainSpec{
-			ControlPlane: true, Attributes: map[string]string{"MetaHashName": metaHashName},
+			ControlPlane: fdSpec.IsControlPlaneEligible() && !unavailable, Attributes: map[string]string{"MetaHashName": metaHashName},
+		}
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// ReconcileAPIServerVMLoadBalancer deploys the API server load balancer VMs in the current generation of their failure
+// domain, points them at the ready control plane machines, and makes their address the control plane endpoint. They're
+// deleted once they're removed from the spec.
+func (r *CloudStackClusterReconciliationRunner) ReconcileAPIServerVMLoadBalancer() (ctrl.Result, error) {
+	csCluster := r.ReconciliationSubject
+	vmLB := csCluster.Spec.APIServerVMLoadBalancer
+	if vmLB == nil {
+		return r.DeleteAPIServerVMLoadBalancer()
+	}
+	var fd *infrav1.CloudStackFailureDomain
+	for _, fdSpec := range csCluster.Spec.FailureDomains {
+		if fdSpec.Name == vmLB.FailureDomainName {
+			fd = csCtrlrUtils.CurrentFailureDomainGeneration(r.FailureDomains, fdSpec)
+		}
+	}
+	if fd == nil {
+		return r.RequeueWithMessage("API server load balancer FailureDomain not found, requeueing.")
+	}
+	if res, err := r.AsFailureDomainUser(&fd.Spec)(); r.ShouldReturn(res, err) {
+		return res, err
+	}
+
+	req, _ := labels.NewRequirement(clusterv1.MachineControlPlaneLabel, selection.Exists, nil)
+	csMachines, err := r.ReadyCloudStackMachines(labels.NewSelector().Add(*req), func(string) bool { return true })
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	backends := []string{}
+	for _, csMachine := range csMachines {
+		for _, address := range csMachine.Status.Addresses {
+			if address.Type == corev1.NodeInternalIP {
+				backends = append(backends, address.Address)
+
+				break
+			}
+		}
+	}
+
+	var oldBackends []string
+	if csCluster.Status.APIServerVMLoadBalancer != nil {
+		oldBackends = csCluster.Status.APIServerVMLoadBalancer.Backends
+	}
+	if err := r.CSUser.ReconcileAPIServerVMLoadBalancer(fd, csCluster, backends); err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "reconciling API server load balancer VMs")
+	}
+	status := csCluster.Status.APIServerVMLoadBalancer
+	if !slices.Equal(oldBackends, status.Backends) {
+		r.Recorder.Eventf(csCluster, "Normal", "BackendsUpdated", "load balancing to %s", strings.Join(status.Backends, ", "))
+	}
+	if csCluster.Spec.ControlPlaneEndpoint.Host == "" {
+		// Without a virtual IP, the address of the only load balancer VM is the endpoint.
+		csCluster.Spec.ControlPlaneEndpoint.Host = vmLB.VirtualIP
+		if csCluster.Spec.ControlPlaneEndpoint.Host == "" {
+			csCluster.Spec.ControlPlaneEndpoint.Host = status.IPAddresses[0]
+		}
+	}
+	if csCluster.Spec.ControlPlaneEndpoint.Port == 0 {
+		csCluster.Spec.ControlPlaneEndpoint.Port = cloud.K8sDefaultAPIPort
+	}
+	if !status.Ready {
+		return r.RequeueWithMessage("API server load balancer VMs not running yet, requeueing.")
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// DeleteAPIServerVMLoadBalancer tears the API server load balancer VMs down with the credentials of the failure domain
+// they were deployed in.
+func (r *CloudStackClusterReconciliationRunner) DeleteAPIServerVMLoadBalancer() (ctrl.Result, error) {
+	csCluster := r.ReconciliationSubject
+	if csCluster.Status.APIServerVMLoadBalancer == nil {
+		return ctrl.Result{}, nil
+	}
+	fdSpec := r.failureDomainSpecByName(csCluster.Status.APIServerVMLoadBalancer.FailureDomainName)
+	if fdSpec == nil {
+		r.Log.Info("API server load balancer FailureDomain gone, leaving the load balancer VMs in place.",
+			"failureDomain", csCluster.Status.APIServerVMLoadBalancer.FailureDomainName)
+		csCluster.Status.APIServerVMLoadBalancer = nil
+
+		return ctrl.Result{}, nil
+	}
+	if res, err := r.AsFailureDomainUser(fdSpec)(); r.ShouldReturn(res, err) {
+		return res, err
+	}
+
+	if err := r.CSUser.DeleteAPIServerVMLoadBalancer(csCluster); err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "deleting API server load balancer VMs")
+	}
+	r.Recorder.Eventf(csCluster, "Normal", "LoadBalancerDeleted", "deleted API server load balancer VMs")
+
+	return ctrl.Result{}, nil
+}
+
+// ReconcileBastion deploys the bastion in the current generation of its failure domain, and deletes it once it's
+// removed from the spec.
+func (r *CloudStackClusterReconciliationRunner) ReconcileBastion() (ctrl.Result, error) {
+	csCluster := r.ReconciliationSubject
+	if csCluster.Spec.Bastion == nil {
+		return r.DeleteBastion()
+	}
+	var fd *infrav1.CloudStackFailureDomain
+	for _, fdSpec := range csCluster.Spec.FailureDomains {
+		if fdSpec.Name == csCluster.Spec.Bastion.FailureDomainName {
+			fd = csCtrlrUtils.CurrentFailureDomainGeneration(r.FailureDomains, fdSpec)
 		}
 	}
+	if fd == nil {
+		return r.RequeueWithMessage("Bastion FailureDomain not found, requeueing.")
+	}
+	if res, err := r.AsFailureDomainUser(&fd.Spec)(); r.ShouldReturn(res, err) {
+		return res, err
+	}
+
+	wasReady := csCluster.Status.Bastion != nil && csCluster.Status.Bastion.Ready
+	if err := r.CSUser.GetOrCreateBastion(fd, csCluster); err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "reconciling bastion")
+	}
+	if !csCluster.Status.Bastion.Ready {
+		return r.RequeueWithMessage("Bastion not running yet, requeueing.")
+	} else if !wasReady {
+		r.Recorder.Eventf(csCluster, "Normal", "BastionReady", "bastion reachable on %s", csCluster.Status.Bastion.IPAddress)
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// DeleteBastion tears the bastion down with the credentials of the failure domain it was deployed in.
+func (r *CloudStackClusterReconciliationRunner) DeleteBastion() (ctrl.Result, error) {
+	csCluster := r.ReconciliationSubject
+	if csCluster.Status.Bastion == nil {
+		return ctrl.Result{}, nil
+	}
+	fdName := csCluster.Status.Bastion.FailureDomainName
+	fdSpec := r.failureDomainSpecByName(fdName)
+	if fdSpec == nil {
+		r.Log.Info("Bastion FailureDomain gone, leaving the bastion in place.", "failureDomain", fdName)
+		csCluster.Status.Bastion = nil
+
+		return ctrl.Result{}, nil
+	}
+	if res, err := r.AsFailureDomainUser(fdSpec)(); r.ShouldReturn(res, err) {
+		return res, err
+	}
+
+	if err := r.CSUser.DeleteBastion(csCluster); err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "deleting bastion")
+	}
+	r.Recorder.Eventf(csCluster, "Normal", "BastionDeleted", "deleted bastion")
 
 	return ctrl.Result{}, nil
 }
 
+// failureDomainSpecByName returns the spec of the named failure domain, falling back to the failure domains that were
+// already removed from the cluster spec but still exist. It returns nil if neither has it.
+func (r *CloudStackClusterReconciliationRunner) failureDomainSpecByName(fdName string) *infrav1.CloudStackFailureDomainSpec {
+	csCluster := r.ReconciliationSubject
+	for idx := range csCluster.Spec.FailureDomains {
+		if csCluster.Spec.FailureDomains[idx].Name == fdName {
+			return &csCluster.Spec.FailureDomains[idx]
+		}
+	}
+	for idx := range r.FailureDomains.Items {
+		if r.FailureDomains.Items[idx].Spec.Name == fdName {
+			return &r.FailureDomains.Items[idx].Spec
+		}
+	}
+
+	return nil
+}
+
 // ReconcileDelete cleans up resources used by the cluster and finally removes the CloudStackCluster's finalizers.
 func (r *CloudStackClusterReconciliationRunner) ReconcileDelete() (ctrl.Result, error) {
 	r.Log.Info("Deleting CloudStackCluster.")
 	if res, err := r.GetFailureDomains(r.FailureDomains)(); r.ShouldReturn(res, err) {
 		return res, err
 	}
+	// The bastion and load balancer VMs sit in the network of a failure domain, so they go first.
+	if res, err := r.DeleteBastion(); r.ShouldReturn(res, err) {
+		return res, err
+	}
+	if res, err := r.DeleteAPIServerVMLoadBalancer(); r.ShouldReturn(res, err) {
+		return res, err
+	}
 	if len(r.FailureDomains.Items) > 0 {
 		for idx := range r.FailureDomains.Items {
 			if err := r.K8sClient.Delete(r.RequestCtx, &r.FailureDomains.Items[idx]); err != nil {
@@ -156,6 +360,12 @@ func (r *CloudStackClusterReconciliationRunner) ReconcileDelete() (ctrl.Result,
 
 		return r.RequeueWithMessage("Child FailureDomains still present, requeueing.")
 	}
+	// Managed tenants may be shared by several failure domains, so they're only removed once all of them are gone. The
+	// tenants of failure domains that were removed from the spec are only found in the record.
+	tenants := r.RecordManagedTenants(r.ReconciliationSubject.Status.ManagedTenants, r.ReconciliationSubject.Spec.FailureDomains)
+	if res, err := r.DeleteManagedTenants(tenants)(); r.ShouldReturn(res, err) {
+		return res, err
+	}
 	controllerutil.RemoveFinalizer(r.ReconciliationSubject, infrav1.ClusterFinalizer)
 
 	return ctrl.Result{}, nil
@@ -166,6 +376,8 @@ func (reconciler *CloudStackClusterReconciler) SetupWithManager(ctx context.Cont
 	err := ctrl.NewControllerManagedBy(mgr).
 		WithOptions(opts).
 		For(&infrav1.CloudStackCluster{}).
+		// Failure domain availability changes are reflected in the cluster's failure domains status.
+		Owns(&infrav1.CloudStackFailureDomain{}).
 		WithEventFilter(predicates.ResourceNotPausedAndHasFilterLabel(ctrl.LoggerFrom(ctx), reconciler.WatchFilterValue)).
 		WithEventFilter(
 			predicate.Funcs{
@@ -196,6 +408,11 @@ func (reconciler *CloudStackClusterReconciler) SetupWithManager(ctx context.Cont
 				predicates.ClusterUnpaused(ctrl.LoggerFrom(ctx)),
 			),
 		).
+		// Control plane machines coming and going change the backends of the API server load balancer VMs.
+		Watches(
+			&infrav1.CloudStackMachine{},
+			handler.EnqueueRequestsFromMapFunc(csCtrlrUtils.ControlPlaneCloudStackMachineToCloudStackCluster(reconciler.K8sClient, ctrl.LoggerFrom(ctx))),
+		).
 		WithEventFilter(predicates.ResourceIsNotExternallyManaged(ctrl.LoggerFrom(ctx))).
 		Complete(reconciler)
 	if err != nil {
@@ -20,11 +20,13 @@ import (
 	"github.com/golang/mock/gomock"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 
 	infrav1 "sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
 	"sigs.k8s.io/cluster-api-provider-cloudstack/controllers"
+	csCtrlrUtils "sigs.k8s.io/cluster-api-provider-cloudstack/controllers/utils"
 	dummies "sigs.k8s.io/cluster-api-provider-cloudstack/test/dummies/v1beta3"
 )
 
@@ -39,6 +41,8 @@ var _ = Describe("CloudStackClusterReconciler", func() {
 		It("Should create a CloudStackFailureDomain.", func() {
 			tempfd := &infrav1.CloudStackFailureDomain{}
 			mockCloudClient.EXPECT().ResolveZone(gomock.Any()).AnyTimes()
+			mockCloudClient.EXPECT().GetCloudStackVersion().Return("4.19.0.0", nil).AnyTimes()
+			mockCloudClient.EXPECT().ListMissingAPIs(gomock.Any()).AnyTimes()
 			Eventually(func() bool {
 				key := client.ObjectKeyFromObject(dummies.CSFailureDomain1)
 				key.Name = key.Name + "-" + dummies.CSCluster.Name
@@ -56,5 +60,20 @@ var _ = Describe("CloudStackClusterReconciler", func() {
 			reconRunenr := controllers.NewCSClusterReconciliationRunner()
 			Ω(reconRunenr.ReconciliationSubject).ShouldNot(BeNil())
 		})
+
+		It("Should start a new failure domain generation only when the spec changes more than in place fields.", func() {
+			fdSpec := dummies.CSFailureDomain1.Spec
+			fd := dummies.CSFailureDomain1.DeepCopy()
+			fd.Annotations = map[string]string{infrav1.FailureDomainSpecHashAnnotation: infrav1.FailureDomainSpecHash(fdSpec)}
+			fds := &infrav1.CloudStackFailureDomainList{Items: []infrav1.CloudStackFailureDomain{*fd}}
+
+			fdSpec.Weight = ptr.To[int32](3)
+			Ω(csCtrlrUtils.CurrentFailureDomainGeneration(fds, fdSpec)).Should(Equal(&fds.Items[0]))
+
+			fdSpec.Account = "another-account"
+			Ω(csCtrlrUtils.CurrentFailureDomainGeneration(fds, fdSpec)).Should(BeNil())
+			Ω(infrav1.FailureDomainGenerationMetaName(fdSpec, dummies.ClusterName)).
+				Should(HavePrefix(infrav1.FailureDomainHashedMetaName(fdSpec.Name, dummies.ClusterName) + "-"))
+		})
 	})
 })
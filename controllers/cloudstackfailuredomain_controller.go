@@ -19,11 +19,16 @@ package controllers
 import (
 	"context"
 	"sort"
+	"strings"
+	"time"
 
 	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/cluster-api/util/predicates"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -32,6 +37,7 @@ import (
 
 	infrav1 "sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
 	csCtrlrUtils "sigs.k8s.io/cluster-api-provider-cloudstack/controllers/utils"
+	"sigs.k8s.io/cluster-api-provider-cloudstack/pkg/cloud"
 )
 
 const (
@@ -61,6 +67,7 @@ type CloudStackFailureDomainReconciliationRunner struct {
 	*csCtrlrUtils.ReconciliationRunner
 	ReconciliationSubject *infrav1.CloudStackFailureDomain
 	IsoNet                *infrav1.CloudStackIsolatedNetwork
+	VPC                   *infrav1.CloudStackVPC
 	Machines              []infrav1.CloudStackMachine
 }
 
@@ -69,6 +76,7 @@ func NewCSFailureDomainReconciliationRunner() *CloudStackFailureDomainReconcilia
 	// Set concrete type and init pointers.
 	r := &CloudStackFailureDomainReconciliationRunner{ReconciliationSubject: &infrav1.CloudStackFailureDomain{}}
 	r.IsoNet = &infrav1.CloudStackIsolatedNetwork{}
+	r.VPC = &infrav1.CloudStackVPC{}
 	// Setup the base runner. Initializes pointers and links reconciliation methods.
 	r.ReconciliationRunner = csCtrlrUtils.NewRunner(r, r.ReconciliationSubject, "CloudStackFailureDomain")
 
@@ -97,15 +105,43 @@ func (r *CloudStackFailureDomainReconciliationRunner) Reconcile() (ctrl.Result,
 	if err := r.CSUser.ResolveZone(&r.ReconciliationSubject.Spec.Zone); err != nil {
 		return ctrl.Result{}, errors.Wrap(err, "resolving CloudStack zone information")
 	}
-	if err := r.CSUser.ResolveNetworkForZone(&r.ReconciliationSubject.Spec.Zone); err != nil &&
-		!csCtrlrUtils.ContainsNoMatchSubstring(err) {
-		return ctrl.Result{}, errors.Wrap(err, "resolving Cloudstack network information")
+	if r.ReconciliationSubject.Spec.Zone.Network.Type != infrav1.NetworkTypeVPCTier {
+		if err := r.CSUser.ResolveNetworkForZone(&r.ReconciliationSubject.Spec.Zone); err != nil &&
+			!csCtrlrUtils.ContainsNoMatchSubstring(err) {
+			return ctrl.Result{}, errors.Wrap(err, "resolving Cloudstack network information")
+		}
+	}
+
+	// Catch misconfigured roles before they surface as errors halfway through creating the cluster. The required APIs
+	// depend on the network type, which is only known once the network is resolved.
+	if res, err := r.RunPreflightChecks(); r.ShouldReturn(res, err) {
+		return res, err
+	}
+
+	if r.ReconciliationSubject.Spec.Zone.Network.Type == infrav1.NetworkTypeVPCTier {
+		// The network names a VPC rather than a network. Its tiers are set up by a CloudStackVPC.
+		if res, err := r.GenerateVPC(
+			r.ReconciliationSubject.Spec.Zone.Network, r.ReconciliationSubject.Spec.Name)(); r.ShouldReturn(res, err) {
+			return res, err
+		} else if res, err := r.GetObjectByName(r.VPCMetaName(r.ReconciliationSubject.Spec.Zone.Network.Name), r.VPC)(); r.ShouldReturn(res, err) {
+			return res, err
+		}
+		if r.VPC.Name == "" {
+			return r.RequeueWithMessage("Couldn't find VPC.")
+		}
+		if res, err := r.UpdateVPCTierACLs(r.ReconciliationSubject.Spec.Zone.Network, r.VPC)(); r.ShouldReturn(res, err) {
+			return res, err
+		}
+		if !r.VPC.Status.Ready {
+			return r.RequeueWithMessage("VPC dependency not ready.")
+		}
 	}
 
 	// Check if the passed network was an isolated network or the network was missing. In either case, create a
 	// CloudStackIsolatedNetwork to manage the many intricacies and wait until CloudStackIsolatedNetwork is ready.
-	if r.ReconciliationSubject.Spec.Zone.Network.ID == "" ||
-		r.ReconciliationSubject.Spec.Zone.Network.Type == infrav1.NetworkTypeIsolated {
+	if r.ReconciliationSubject.Spec.Zone.Network.Type != infrav1.NetworkTypeVPCTier &&
+		(r.ReconciliationSubject.Spec.Zone.Network.ID == "" ||
+			r.ReconciliationSubject.Spec.Zone.Network.Type == infrav1.NetworkTypeIsolated) {
 		if res, err := r.GenerateIsolatedNetwork(
 			r.ReconciliationSubject.Spec.Zone.Network, r.ReconciliationSubject.Spec.Name)(); r.ShouldReturn(res, err) {
 			return res, err
@@ -115,11 +151,217 @@ func (r *CloudStackFailureDomainReconciliationRunner) Reconcile() (ctrl.Result,
 		if r.IsoNet.Name == "" {
 			return r.RequeueWithMessage("Couldn't find isolated network.")
 		}
+		if res, err := r.UpdateIsolatedNetworkEgressRules(r.ReconciliationSubject.Spec.Zone.Network, r.IsoNet)(); r.ShouldReturn(res, err) {
+			return res, err
+		}
 		if !r.IsoNet.Status.Ready {
 			return r.RequeueWithMessage("Isolated network dependency not ready.")
 		}
 	}
 	r.ReconciliationSubject.Status.Ready = true
+	r.CheckAvailability()
+	if res, err := r.Evacuate(); r.ShouldReturn(res, err) {
+		return res, err
+	}
+
+	res, err = r.ReportCapacity()
+	if res.RequeueAfter > csCtrlrUtils.AvailabilityCheckInterval {
+		res.RequeueAfter = csCtrlrUtils.AvailabilityCheckInterval
+	}
+
+	return res, err
+}
+
+// Evacuate moves the machines off a failure domain annotated with FailureDomainEvacuateAnnotation, one at a time and
+// only when it's safe to, as when the failure domain is deleted. CAPI recreates them in other failure domains, as
+// evacuating failure domains aren't offered to new machines. Progress is reported in the Evacuated condition, which
+// is removed once the annotation is.
+func (r *CloudStackFailureDomainReconciliationRunner) Evacuate() (ctrl.Result, error) {
+	fd := r.ReconciliationSubject
+	if !fd.IsEvacuating() {
+		if conditions.Has(fd, infrav1.FailureDomainEvacuatedCondition) {
+			conditions.Delete(fd, infrav1.FailureDomainEvacuatedCondition)
+			r.Recorder.Event(fd, "Normal", "EvacuationEnded", "failure domain takes new machines again")
+		}
+
+		return ctrl.Result{}, nil
+	}
+	if !conditions.Has(fd, infrav1.FailureDomainEvacuatedCondition) {
+		r.Recorder.Event(fd, "Normal", "EvacuationStarted", "moving machines to other failure domains")
+	}
+
+	if res, err := r.GetAllMachinesInFailureDomain(); r.ShouldReturn(res, err) {
+		return res, err
+	}
+	pinned, err := r.dropPinnedMachines()
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if len(r.Machines) == 0 {
+		if pinned > 0 {
+			conditions.MarkFalse(fd, infrav1.FailureDomainEvacuatedCondition, infrav1.MachinesPinnedReason,
+				clusterv1.ConditionSeverityWarning, "%d machines are pinned to the failure domain by their MachineDeployment", pinned)
+		} else if !conditions.IsTrue(fd, infrav1.FailureDomainEvacuatedCondition) {
+			conditions.MarkTrue(fd, infrav1.FailureDomainEvacuatedCondition)
+			r.Recorder.Event(fd, "Normal", "Evacuated", "no machines are left in the failure domain")
+		}
+
+		return ctrl.Result{}, nil
+	}
+
+	conditions.MarkFalse(fd, infrav1.FailureDomainEvacuatedCondition, infrav1.EvacuatingReason, clusterv1.ConditionSeverityInfo,
+		"%d machines left to move", len(r.Machines))
+	res, err := r.RunReconciliationStages(r.RequeueIfClusterNotReady, r.RequeueIfMachineCannotBeRemoved)
+	if err != nil {
+		conditions.MarkFalse(fd, infrav1.FailureDomainEvacuatedCondition, infrav1.EvacuationBlockedReason,
+			clusterv1.ConditionSeverityWarning, "%s", err.Error())
+
+		return r.RequeueWithMessage("Can't move machines off the failure domain.", "reason", err.Error())
+	} else if r.ShouldReturn(res, err) {
+		return res, err
+	}
+
+	return r.ClearMachines()
+}
+
+// dropPinnedMachines leaves worker machines whose CAPI Machine names the failure domain out of r.Machines, returning
+// how many there were. Their MachineDeployment would recreate them in the same failure domain.
+func (r *CloudStackFailureDomainReconciliationRunner) dropPinnedMachines() (int, error) {
+	var movable []infrav1.CloudStackMachine
+	for _, csMachine := range r.Machines {
+		pinned := false
+		for _, ref := range csMachine.OwnerReferences {
+			if ref.Kind != "Machine" {
+				continue
+			}
+			machine := &clusterv1.Machine{}
+			if err := r.K8sClient.Get(r.RequestCtx, client.ObjectKey{Namespace: csMachine.Namespace, Name: ref.Name}, machine); err != nil {
+				return 0, err
+			}
+			pinned = !util.IsControlPlaneMachine(machine) && machine.Spec.FailureDomain != nil && *machine.Spec.FailureDomain != ""
+		}
+		if !pinned {
+			movable = append(movable, csMachine)
+		}
+	}
+	pinned := len(r.Machines) - len(movable)
+	r.Machines = movable
+
+	return pinned, nil
+}
+
+// CheckAvailability re-checks that the zone is enabled and that the network isn't shut down, reporting the outcome in
+// the Available condition and emitting an event whenever availability changes. The cluster controller stops offering
+// unavailable failure domains to control plane machines.
+func (r *CloudStackFailureDomainReconciliationRunner) CheckAvailability() {
+	fd := r.ReconciliationSubject
+	wasAvailable := !conditions.IsFalse(fd, infrav1.FailureDomainAvailableCondition)
+
+	zone := fd.Spec.Zone
+	zoneState, err := r.CSUser.GetZoneAllocationState(zone.ID)
+	if err != nil {
+		r.Log.Info("Couldn't check zone availability.", "reason", err.Error())
+		conditions.MarkUnknown(fd, infrav1.FailureDomainAvailableCondition, infrav1.AvailabilityCheckFailedReason, "%s", err.Error())
+
+		return
+	}
+	networkState := ""
+	if zone.Network.ID != "" {
+		if networkState, err = r.CSUser.GetNetworkState(zone.Network.ID); err != nil {
+			r.Log.Info("Couldn't check network availability.", "reason", err.Error())
+			conditions.MarkUnknown(fd, infrav1.FailureDomainAvailableCondition, infrav1.AvailabilityCheckFailedReason, "%s", err.Error())
+
+			return
+		}
+	}
+
+	switch {
+	case zoneState != cloud.ZoneAllocationStateEnabled:
+		conditions.MarkFalse(fd, infrav1.FailureDomainAvailableCondition, infrav1.ZoneDisabledReason,
+			clusterv1.ConditionSeverityWarning, "zone %s is %s", zone.Name, zoneState)
+	case cloud.IsNetworkDegraded(networkState):
+		conditions.MarkFalse(fd, infrav1.FailureDomainAvailableCondition, infrav1.NetworkDegradedReason,
+			clusterv1.ConditionSeverityWarning, "network %s is in state %s", zone.Network.Name, networkState)
+	default:
+		conditions.MarkTrue(fd, infrav1.FailureDomainAvailableCondition)
+	}
+
+	if isAvailable := conditions.IsTrue(fd, infrav1.FailureDomainAvailableCondition); wasAvailable && !isAvailable {
+		r.Recorder.Event(fd, "Warning", "FailureDomainUnavailable", conditions.GetMessage(fd, infrav1.FailureDomainAvailableCondition))
+	} else if !wasAvailable && isAvailable {
+		r.Recorder.Event(fd, "Normal", "FailureDomainAvailable", "zone and network are usable again")
+	}
+}
+
+// ReportCapacity refreshes the resource headroom and zone capacity in the failure domain status once the last report
+// is older than CapacityReportInterval, and requeues for the next refresh. The capacity is informational only, so
+// failing to look it up is logged rather than failing the reconciliation.
+func (r *CloudStackFailureDomainReconciliationRunner) ReportCapacity() (ctrl.Result, error) {
+	status := &r.ReconciliationSubject.Status
+	if status.Capacity != nil && status.Capacity.LastUpdated != nil {
+		if age := time.Since(status.Capacity.LastUpdated.Time); age < csCtrlrUtils.CapacityReportInterval {
+			return ctrl.Result{RequeueAfter: csCtrlrUtils.CapacityReportInterval - age}, nil
+		}
+	}
+
+	capacity, err := r.CSUser.GetResourceHeadroom()
+	if err != nil {
+		r.Log.Info("Couldn't look up resource headroom.", "reason", err.Error())
+
+		return ctrl.Result{RequeueAfter: csCtrlrUtils.CapacityReportInterval}, nil
+	}
+	// listCapacity is usually reserved for root admins.
+	if zoneCapacity, err := r.CSUser.ListZoneCapacity(r.ReconciliationSubject.Spec.Zone.ID); err != nil {
+		r.Log.V(1).Info("Not reporting zone capacity.", "reason", err.Error())
+	} else {
+		capacity.Zone = zoneCapacity
+	}
+	now := metav1.Now()
+	capacity.LastUpdated = &now
+	status.Capacity = capacity
+
+	return ctrl.Result{RequeueAfter: csCtrlrUtils.CapacityReportInterval}, nil
+}
+
+// RunPreflightChecks checks that the CloudStack version is supported and that the failure domain user may call every
+// required API, reporting the outcome in the PreflightChecksPassed condition. The checks are repeated until they pass.
+// Checks that can't be run, e.g. because listApis itself isn't granted, leave the condition unknown without blocking.
+func (r *CloudStackFailureDomainReconciliationRunner) RunPreflightChecks() (ctrl.Result, error) {
+	if conditions.IsTrue(r.ReconciliationSubject, infrav1.PreflightChecksPassedCondition) {
+		return ctrl.Result{}, nil
+	}
+
+	version, err := r.CSUser.GetCloudStackVersion()
+	if err != nil {
+		r.Log.Info("Skipping preflight checks.", "reason", err.Error())
+		conditions.MarkUnknown(r.ReconciliationSubject, infrav1.PreflightChecksPassedCondition,
+			infrav1.PreflightChecksFailedReason, "%s", err.Error())
+
+		return ctrl.Result{}, nil
+	}
+	if err := cloud.CheckCloudStackVersion(version); err != nil {
+		conditions.MarkFalse(r.ReconciliationSubject, infrav1.PreflightChecksPassedCondition,
+			infrav1.UnsupportedCloudStackVersionReason, clusterv1.ConditionSeverityError, "%s", err.Error())
+
+		return r.RequeueWithMessage("Unsupported CloudStack version.", "version", version)
+	}
+
+	missing, err := r.CSUser.ListMissingAPIs(cloud.RequiredAPIs(r.ReconciliationSubject.Spec, r.CSCluster))
+	if err != nil {
+		r.Log.Info("Skipping preflight permission check.", "reason", err.Error())
+		conditions.MarkUnknown(r.ReconciliationSubject, infrav1.PreflightChecksPassedCondition,
+			infrav1.PreflightChecksFailedReason, "%s", err.Error())
+
+		return ctrl.Result{}, nil
+	}
+	if len(missing) > 0 {
+		conditions.MarkFalse(r.ReconciliationSubject, infrav1.PreflightChecksPassedCondition,
+			infrav1.MissingPermissionsReason, clusterv1.ConditionSeverityError,
+			"the role of the CloudStack user doesn't grant: %s", strings.Join(missing, ", "))
+
+		return r.RequeueWithMessage("CloudStack user is missing permissions.", "missing", missing)
+	}
+	conditions.MarkTrue(r.ReconciliationSubject, infrav1.PreflightChecksPassedCondition)
 
 	return ctrl.Result{}, nil
 }
@@ -133,16 +375,160 @@ func (r *CloudStackFailureDomainReconciliationRunner) ReconcileDelete() (ctrl.Re
 		r.RequeueIfClusterNotReady,
 		r.RequeueIfMachineCannotBeRemoved,
 		r.ClearMachines,
+		r.HandOverNetwork,
+		r.HandOverLoadBalancers,
+		r.HandOverSSHKeyPairs,
 		r.DeleteOwnedObjects(
 			infrav1.GroupVersion.WithKind("CloudStackAffinityGroup"),
-			infrav1.GroupVersion.WithKind("CloudStackIsolatedNetwork")),
+			infrav1.GroupVersion.WithKind("CloudStackSSHKeyPair"),
+			infrav1.GroupVersion.WithKind("CloudStackIsolatedNetwork"),
+			infrav1.GroupVersion.WithKind("CloudStackVPC"),
+			infrav1.GroupVersion.WithKind("CloudStackLoadBalancer")),
 		r.CheckOwnedObjectsDeleted(
 			infrav1.GroupVersion.WithKind("CloudStackAffinityGroup"),
-			infrav1.GroupVersion.WithKind("CloudStackIsolatedNetwork")),
+			infrav1.GroupVersion.WithKind("CloudStackSSHKeyPair"),
+			infrav1.GroupVersion.WithKind("CloudStackIsolatedNetwork"),
+			infrav1.GroupVersion.WithKind("CloudStackVPC"),
+			infrav1.GroupVersion.WithKind("CloudStackLoadBalancer")),
 		r.RemoveFinalizer,
 	)
 }
 
+// successor returns the current generation of a retired failure domain, or nil if the failure domain is current or
+// was removed from the cluster.
+func (r *CloudStackFailureDomainReconciliationRunner) successor() (*infrav1.CloudStackFailureDomain, error) {
+	fd := r.ReconciliationSubject
+	fds := &infrav1.CloudStackFailureDomainList{}
+	for _, fdSpec := range r.CSCluster.Spec.FailureDomains {
+		if fdSpec.Name != fd.Spec.Name {
+			continue
+		}
+		if _, err := r.GetFailureDomains(fds)(); err != nil {
+			return nil, err
+		}
+		current := csCtrlrUtils.CurrentFailureDomainGeneration(fds, fdSpec)
+		if current != nil && current.UID != fd.UID {
+			return current, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// successorSharingNetwork returns the current generation of a retired failure domain when both use the same network.
+func (r *CloudStackFailureDomainReconciliationRunner) successorSharingNetwork() (*infrav1.CloudStackFailureDomain, error) {
+	current, err := r.successor()
+	if err != nil || current == nil || current.Spec.Zone.Network.Name != r.ReconciliationSubject.Spec.Zone.Network.Name {
+		return nil, err
+	}
+
+	return current, nil
+}
+
+// handOver replaces the retired failure domain generation among the owners of obj with its successor. Returns whether
+// the retired generation owned obj.
+func (r *CloudStackFailureDomainReconciliationRunner) handOver(obj client.Object, successor *infrav1.CloudStackFailureDomain) (bool, error) {
+	ownerRefs := []metav1.OwnerReference{}
+	for _, ref := range obj.GetOwnerReferences() {
+		if ref.UID != r.ReconciliationSubject.UID {
+			ownerRefs = append(ownerRefs, ref)
+		}
+	}
+	if len(ownerRefs) == len(obj.GetOwnerReferences()) {
+		return false, nil
+	}
+	obj.SetOwnerReferences(ownerRefs)
+	if err := controllerutil.SetOwnerReference(successor, obj, r.K8sClient.Scheme()); err != nil {
+		return false, errors.Wrap(err, "setting failure domain owner reference")
+	}
+
+	return true, r.K8sClient.Update(r.RequestCtx, obj)
+}
+
+// HandOverNetwork passes the isolated network or VPC of a retired failure domain generation on to the current
+// generation when both use the same network, so that it isn't deleted along with the old generation.
+func (r *CloudStackFailureDomainReconciliationRunner) HandOverNetwork() (ctrl.Result, error) {
+	fd := r.ReconciliationSubject
+	current, err := r.successorSharingNetwork()
+	if err != nil || current == nil {
+		return ctrl.Result{}, err
+	}
+
+	var network client.Object = r.IsoNet
+	metaName := r.IsoNetMetaName(fd.Spec.Zone.Network.Name)
+	if fd.Spec.Zone.Network.Type == infrav1.NetworkTypeVPCTier {
+		network, metaName = r.VPC, r.VPCMetaName(fd.Spec.Zone.Network.Name)
+	}
+	if res, err := r.GetObjectByName(metaName, network)(); r.ShouldReturn(res, err) {
+		return res, err
+	} else if network.GetName() == "" || !metav1.IsControlledBy(network, fd) {
+		return ctrl.Result{}, nil
+	}
+	ownerRefs := []metav1.OwnerReference{*metav1.NewControllerRef(current, infrav1.GroupVersion.WithKind("CloudStackFailureDomain"))}
+	for _, ref := range network.GetOwnerReferences() {
+		if ref.UID != fd.UID {
+			ownerRefs = append(ownerRefs, ref)
+		}
+	}
+	network.SetOwnerReferences(ownerRefs)
+	if err := r.K8sClient.Update(r.RequestCtx, network); err != nil {
+		return ctrl.Result{}, errors.Wrapf(err, "handing network %s over to failure domain %s", network.GetName(), current.Name)
+	}
+	r.Log.Info("Handed network over to the current failure domain generation.", "network", network.GetName(), "failureDomain", current.Name)
+
+	return ctrl.Result{}, nil
+}
+
+// HandOverLoadBalancers moves the CloudStackLoadBalancers of a retired failure domain generation over to the current
+// generation when both use the same network. The public IPs stay in that network, so the load balancers survive the
+// old generation.
+func (r *CloudStackFailureDomainReconciliationRunner) HandOverLoadBalancers() (ctrl.Result, error) {
+	fd := r.ReconciliationSubject
+	current, err := r.successorSharingNetwork()
+	if err != nil || current == nil {
+		return ctrl.Result{}, err
+	}
+
+	lbs := &infrav1.CloudStackLoadBalancerList{}
+	if err := r.K8sClient.List(r.RequestCtx, lbs, client.InNamespace(fd.Namespace)); err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "listing load balancers")
+	}
+	for idx := range lbs.Items {
+		lb := &lbs.Items[idx]
+		if handedOver, err := r.handOver(lb, current); err != nil {
+			return ctrl.Result{}, errors.Wrapf(err, "handing load balancer %s over to failure domain %s", lb.Name, current.Name)
+		} else if handedOver {
+			r.Log.Info("Handed load balancer over to the current failure domain generation.", "loadBalancer", lb.Name, "failureDomain", current.Name)
+		}
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// HandOverSSHKeyPairs moves the CloudStackSSHKeyPairs of a retired failure domain generation over to the current
+// generation, so that keypairs machines still reference aren't deleted along with the old generation.
+func (r *CloudStackFailureDomainReconciliationRunner) HandOverSSHKeyPairs() (ctrl.Result, error) {
+	current, err := r.successor()
+	if err != nil || current == nil {
+		return ctrl.Result{}, err
+	}
+
+	keyPairs := &infrav1.CloudStackSSHKeyPairList{}
+	if err := r.K8sClient.List(r.RequestCtx, keyPairs, client.InNamespace(r.ReconciliationSubject.Namespace)); err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "listing SSH keypairs")
+	}
+	for idx := range keyPairs.Items {
+		keyPair := &keyPairs.Items[idx]
+		if handedOver, err := r.handOver(keyPair, current); err != nil {
+			return ctrl.Result{}, errors.Wrapf(err, "handing SSH keypair %s over to failure domain %s", keyPair.Name, current.Name)
+		} else if handedOver {
+			r.Log.Info("Handed SSH keypair over to the current failure domain generation.", "sshKeyPair", keyPair.Name, "failureDomain", current.Name)
+		}
+	}
+
+	return ctrl.Result{}, nil
+}
+
 // GetAllMachinesInFailureDomain returns all cloudstackmachines deployed in this failure domain sorted by name.
 func (r *CloudStackFailureDomainReconciliationRunner) GetAllMachinesInFailureDomain() (ctrl.Result, error) {
 	machines := &infrav1.CloudStackMachineList{}
@@ -17,15 +17,21 @@ limitations under the License.
 package controllers_test
 
 import (
+	"fmt"
+
 	"github.com/golang/mock/gomock"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/ptr"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/cluster-api/util/patch"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	infrav1 "sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
 	"sigs.k8s.io/cluster-api-provider-cloudstack/pkg/cloud"
@@ -44,7 +50,16 @@ var _ = Describe("CloudStackFailureDomainReconciler", func() {
 			Ω(k8sClient.Create(ctx, dummies.ACSEndpointSecret1)).Should(Succeed())
 			Ω(k8sClient.Create(ctx, dummies.CSFailureDomain1)).Should(Succeed())
 
+			mockCloudClient.EXPECT().GetCloudStackVersion().Return("4.19.0.0", nil).AnyTimes()
+			mockCloudClient.EXPECT().ListMissingAPIs(gomock.Any()).AnyTimes()
 			mockCloudClient.EXPECT().ResolveZone(gomock.Any()).MinTimes(1)
+			mockCloudClient.EXPECT().GetResourceHeadroom().Return(&infrav1.CloudStackFailureDomainCapacity{
+				Account: &infrav1.CloudStackResourceHeadroom{Instances: ptr.To(int64(8))},
+			}, nil).AnyTimes()
+			mockCloudClient.EXPECT().ListZoneCapacity(gomock.Any()).Return(nil,
+				fmt.Errorf("The API [listCapacity] does not exist or is not available for the account")).AnyTimes()
+			mockCloudClient.EXPECT().GetZoneAllocationState(gomock.Any()).Return(cloud.ZoneAllocationStateEnabled, nil).AnyTimes()
+			mockCloudClient.EXPECT().GetNetworkState(gomock.Any()).Return("Setup", nil).AnyTimes()
 
 			mockCloudClient.EXPECT().ResolveNetworkForZone(gomock.Any()).AnyTimes().Do(
 				func(arg1 interface{}) {
@@ -71,6 +86,60 @@ var _ = Describe("CloudStackFailureDomainReconciler", func() {
 			}, timeout).WithPolling(pollInterval).Should(BeTrue())
 		})
 
+		It("Should hand the SSH keypairs of a retired generation over to the current one.", func() {
+			Eventually(func() bool {
+				return getFailuredomainStatus(dummies.CSFailureDomain1)
+			}, timeout).WithPolling(pollInterval).Should(BeTrue())
+
+			// Retire the failure domain in favor of a generation matching the cluster's current spec.
+			retired := &infrav1.CloudStackFailureDomain{}
+			Eventually(func() error {
+				if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(dummies.CSFailureDomain1), retired); err != nil {
+					return err
+				}
+				retired.Annotations = map[string]string{infrav1.FailureDomainSpecHashAnnotation: "retired"}
+
+				return k8sClient.Update(ctx, retired)
+			}, timeout).WithPolling(pollInterval).Should(Succeed())
+			current := dummies.CSFailureDomain1.DeepCopy()
+			current.ResourceVersion = ""
+			current.Name += "-current"
+			current.Annotations = map[string]string{
+				infrav1.FailureDomainSpecHashAnnotation: infrav1.FailureDomainSpecHash(dummies.CSCluster.Spec.FailureDomains[0]),
+			}
+			Ω(k8sClient.Create(ctx, current)).Should(Succeed())
+
+			keyPair := dummies.CSSSHKeyPair.DeepCopy()
+			Ω(controllerutil.SetOwnerReference(retired, keyPair, k8sClient.Scheme())).Should(Succeed())
+			Ω(k8sClient.Create(ctx, keyPair)).Should(Succeed())
+
+			Ω(k8sClient.Delete(ctx, retired)).Should(Succeed())
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, client.ObjectKeyFromObject(retired), &infrav1.CloudStackFailureDomain{})
+
+				return errors.IsNotFound(err)
+			}, timeout).WithPolling(pollInterval).Should(BeTrue())
+
+			Ω(k8sClient.Get(ctx, client.ObjectKeyFromObject(keyPair), keyPair)).Should(Succeed())
+			Ω(keyPair.DeletionTimestamp.IsZero()).Should(BeTrue())
+			Ω(keyPair.OwnerReferences).Should(ConsistOf(HaveField("UID", current.UID)))
+		})
+
+		It("Should report the resource headroom without the zone capacity.", func() {
+			Eventually(func() *infrav1.CloudStackFailureDomainCapacity {
+				tempfd := &infrav1.CloudStackFailureDomain{}
+				if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(dummies.CSFailureDomain1), tempfd); err != nil {
+					return nil
+				}
+
+				return tempfd.Status.Capacity
+			}, timeout).WithPolling(pollInterval).Should(And(
+				HaveField("Account.Instances", HaveValue(Equal(int64(8)))),
+				HaveField("Zone", BeEmpty()),
+				HaveField("LastUpdated", Not(BeNil())),
+			))
+		})
+
 		DescribeTable("Should function in different replicas conditions",
 			func(shouldDeleteVM bool, specReplicas, statusReplicas, statusReadyReplicas *int32, statusReady *bool, controlPlaneReady bool) {
 				Eventually(func() bool {
@@ -134,6 +203,233 @@ var _ = Describe("CloudStackFailureDomainReconciler", func() {
 			Entry("Should not delete machine if status.readyReplicas <> status.replicas", false, ptr.To(int32(2)), ptr.To(int32(2)), ptr.To(int32(1)), ptr.To(true), true),
 		)
 	})
+
+	Context("With a disabled zone.", func() {
+		BeforeEach(func() {
+			dummies.SetDummyVars()
+			SetupTestEnvironment()
+			Ω(FailureDomainReconciler.SetupWithManager(ctx, k8sManager, controller.Options{})).Should(Succeed())
+			dummies.CSFailureDomain1.Name = dummies.CSFailureDomain1.Name + "-" + dummies.CSCluster.Name
+
+			Ω(k8sClient.Create(ctx, dummies.ACSEndpointSecret1)).Should(Succeed())
+			Ω(k8sClient.Create(ctx, dummies.CSFailureDomain1)).Should(Succeed())
+
+			mockCloudClient.EXPECT().GetCloudStackVersion().Return("4.19.0.0", nil).AnyTimes()
+			mockCloudClient.EXPECT().ListMissingAPIs(gomock.Any()).AnyTimes()
+			mockCloudClient.EXPECT().ResolveZone(gomock.Any()).AnyTimes()
+			mockCloudClient.EXPECT().ResolveNetworkForZone(gomock.Any()).AnyTimes().Do(
+				func(arg1 interface{}) {
+					arg1.(*infrav1.CloudStackZoneSpec).Network.ID = "SomeID"
+					arg1.(*infrav1.CloudStackZoneSpec).Network.Type = cloud.NetworkTypeShared
+				})
+			mockCloudClient.EXPECT().GetResourceHeadroom().Return(&infrav1.CloudStackFailureDomainCapacity{}, nil).AnyTimes()
+			mockCloudClient.EXPECT().ListZoneCapacity(gomock.Any()).AnyTimes()
+			mockCloudClient.EXPECT().GetZoneAllocationState(gomock.Any()).Return("Disabled", nil).AnyTimes()
+			mockCloudClient.EXPECT().GetNetworkState(gomock.Any()).Return("Setup", nil).AnyTimes()
+		})
+
+		It("Should mark the failure domain unavailable.", func() {
+			Eventually(func() string {
+				tempfd := &infrav1.CloudStackFailureDomain{}
+				if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(dummies.CSFailureDomain1), tempfd); err != nil {
+					return ""
+				}
+				if conditions.IsFalse(tempfd, infrav1.FailureDomainAvailableCondition) {
+					return conditions.GetReason(tempfd, infrav1.FailureDomainAvailableCondition)
+				}
+
+				return ""
+			}, timeout).WithPolling(pollInterval).Should(Equal(infrav1.ZoneDisabledReason))
+		})
+	})
+
+	Context("With a failure domain annotated for evacuation.", func() {
+		BeforeEach(func() {
+			dummies.SetDummyVars()
+			SetupTestEnvironment()
+			Ω(FailureDomainReconciler.SetupWithManager(ctx, k8sManager, controller.Options{})).Should(Succeed())
+			dummies.CSFailureDomain1.Name = dummies.CSFailureDomain1.Name + "-" + dummies.CSCluster.Name
+			dummies.CSFailureDomain1.Annotations = map[string]string{infrav1.FailureDomainEvacuateAnnotation: ""}
+
+			Ω(k8sClient.Create(ctx, dummies.ACSEndpointSecret1)).Should(Succeed())
+			Ω(k8sClient.Create(ctx, dummies.CSFailureDomain1)).Should(Succeed())
+
+			mockCloudClient.EXPECT().GetCloudStackVersion().Return("4.19.0.0", nil).AnyTimes()
+			mockCloudClient.EXPECT().ListMissingAPIs(gomock.Any()).AnyTimes()
+			mockCloudClient.EXPECT().ResolveZone(gomock.Any()).AnyTimes()
+			mockCloudClient.EXPECT().ResolveNetworkForZone(gomock.Any()).AnyTimes().Do(
+				func(arg1 interface{}) {
+					arg1.(*infrav1.CloudStackZoneSpec).Network.ID = "SomeID"
+					arg1.(*infrav1.CloudStackZoneSpec).Network.Type = cloud.NetworkTypeShared
+				})
+			mockCloudClient.EXPECT().GetResourceHeadroom().Return(&infrav1.CloudStackFailureDomainCapacity{}, nil).AnyTimes()
+			mockCloudClient.EXPECT().ListZoneCapacity(gomock.Any()).AnyTimes()
+			mockCloudClient.EXPECT().GetZoneAllocationState(gomock.Any()).Return("Enabled", nil).AnyTimes()
+			mockCloudClient.EXPECT().GetNetworkState(gomock.Any()).Return("Setup", nil).AnyTimes()
+		})
+
+		It("Should report the failure domain evacuated once no machines are left.", func() {
+			Eventually(func() bool {
+				tempfd := &infrav1.CloudStackFailureDomain{}
+				if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(dummies.CSFailureDomain1), tempfd); err != nil {
+					return false
+				}
+
+				return conditions.IsTrue(tempfd, infrav1.FailureDomainEvacuatedCondition)
+			}, timeout).WithPolling(pollInterval).Should(BeTrue())
+		})
+
+		// evacuatedCondition returns the Evacuated condition of the failure domain, nil while it has none.
+		evacuatedCondition := func() *clusterv1.Condition {
+			tempfd := &infrav1.CloudStackFailureDomain{}
+			if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(dummies.CSFailureDomain1), tempfd); err != nil {
+				return nil
+			}
+
+			return conditions.Get(tempfd, infrav1.FailureDomainEvacuatedCondition)
+		}
+
+		// setMovableMachine creates a CloudStack machine in the failure domain whose CAPI Machine isn't pinned to it.
+		setMovableMachine := func(specReplicas int32) {
+			dummies.CAPIMachine.Spec.FailureDomain = nil
+			setCSMachineOwnerCRD(dummies.CSMachineOwner, &specReplicas, &specReplicas, &specReplicas, ptr.To(true))
+			setCAPIMachineAndCSMachineCRDs(dummies.CSMachine1, dummies.CAPIMachine)
+			setMachineOwnerReference(dummies.CSMachine1, dummies.CSMachineOwnerReference)
+			labelMachineFailuredomain(dummies.CSMachine1, dummies.CSFailureDomain1)
+		}
+
+		It("Should leave machines pinned by their MachineDeployment in place.", func() {
+			setCSMachineOwnerCRD(dummies.CSMachineOwner, ptr.To(int32(2)), ptr.To(int32(2)), ptr.To(int32(2)), ptr.To(true))
+			setCAPIMachineAndCSMachineCRDs(dummies.CSMachine1, dummies.CAPIMachine)
+			setMachineOwnerReference(dummies.CSMachine1, dummies.CSMachineOwnerReference)
+			labelMachineFailuredomain(dummies.CSMachine1, dummies.CSFailureDomain1)
+
+			Eventually(evacuatedCondition, timeout).WithPolling(pollInterval).Should(And(
+				HaveField("Status", Equal(corev1.ConditionFalse)),
+				HaveField("Reason", Equal(infrav1.MachinesPinnedReason)),
+			))
+			Ω(k8sClient.Get(ctx, client.ObjectKeyFromObject(dummies.CAPIMachine), dummies.CAPIMachine)).Should(Succeed())
+			Ω(dummies.CAPIMachine.DeletionTimestamp.IsZero()).Should(BeTrue())
+		})
+
+		It("Should report the evacuation blocked when a machine can't be removed safely.", func() {
+			setMovableMachine(1)
+
+			Eventually(evacuatedCondition, timeout).WithPolling(pollInterval).Should(And(
+				HaveField("Status", Equal(corev1.ConditionFalse)),
+				HaveField("Reason", Equal(infrav1.EvacuationBlockedReason)),
+				HaveField("Message", ContainSubstring("spec.replicas < 2")),
+			))
+			Ω(k8sClient.Get(ctx, client.ObjectKeyFromObject(dummies.CAPIMachine), dummies.CAPIMachine)).Should(Succeed())
+			Ω(dummies.CAPIMachine.DeletionTimestamp.IsZero()).Should(BeTrue())
+		})
+
+		It("Should delete the machines one at a time.", func() {
+			// The finalizer keeps the first CAPI Machine around as being deleted, as CAPI would while draining it.
+			dummies.CAPIMachine.Finalizers = []string{"test.cluster.x-k8s.io/block-deletion"}
+			setMovableMachine(2)
+
+			secondCAPIMachine := dummies.CAPIMachine.DeepCopy()
+			secondCAPIMachine.ObjectMeta = metav1.ObjectMeta{
+				GenerateName: dummies.CAPIMachine.GenerateName,
+				Namespace:    dummies.CAPIMachine.Namespace,
+				Labels:       dummies.CAPIMachine.Labels,
+			}
+			secondCAPIMachine.Spec.InfrastructureRef.Name = "test-machine-2"
+			Ω(k8sClient.Create(ctx, secondCAPIMachine)).Should(Succeed())
+			secondCSMachine := dummies.CSMachine1.DeepCopy()
+			secondCSMachine.ObjectMeta = metav1.ObjectMeta{
+				Name:      "test-machine-2",
+				Namespace: dummies.CSMachine1.Namespace,
+				Labels:    dummies.CSMachine1.Labels,
+				OwnerReferences: []metav1.OwnerReference{{
+					Kind:       "Machine",
+					APIVersion: clusterv1.GroupVersion.String(),
+					Name:       secondCAPIMachine.Name,
+					UID:        secondCAPIMachine.UID,
+				}, dummies.CSMachineOwnerReference},
+			}
+			secondCSMachine.Spec.Name = "test-machine-2"
+			Ω(k8sClient.Create(ctx, secondCSMachine)).Should(Succeed())
+
+			Eventually(func() bool {
+				if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(dummies.CAPIMachine), dummies.CAPIMachine); err != nil {
+					return false
+				}
+
+				return !dummies.CAPIMachine.DeletionTimestamp.IsZero()
+			}, timeout).WithPolling(pollInterval).Should(BeTrue())
+			Consistently(func() bool {
+				if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(secondCAPIMachine), secondCAPIMachine); err != nil {
+					return false
+				}
+
+				return secondCAPIMachine.DeletionTimestamp.IsZero()
+			}, timeout).WithPolling(pollInterval).Should(BeTrue())
+			Ω(evacuatedCondition()).Should(And(
+				HaveField("Reason", Equal(infrav1.EvacuatingReason)),
+				HaveField("Message", Equal("2 machines left to move")),
+			))
+		})
+
+		It("Should remove the Evacuated condition once the annotation is removed.", func() {
+			Eventually(evacuatedCondition, timeout).WithPolling(pollInterval).Should(HaveField("Status", Equal(corev1.ConditionTrue)))
+
+			Eventually(func() error {
+				tempfd := &infrav1.CloudStackFailureDomain{}
+				if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(dummies.CSFailureDomain1), tempfd); err != nil {
+					return err
+				}
+				delete(tempfd.Annotations, infrav1.FailureDomainEvacuateAnnotation)
+
+				return k8sClient.Update(ctx, tempfd)
+			}, timeout).WithPolling(pollInterval).Should(Succeed())
+
+			Eventually(evacuatedCondition, timeout).WithPolling(pollInterval).Should(BeNil())
+		})
+	})
+
+	Context("With a CloudStack user missing permissions.", func() {
+		BeforeEach(func() {
+			dummies.SetDummyVars()
+			SetupTestEnvironment()
+			Ω(FailureDomainReconciler.SetupWithManager(ctx, k8sManager, controller.Options{})).Should(Succeed())
+			dummies.CSFailureDomain1.Name = dummies.CSFailureDomain1.Name + "-" + dummies.CSCluster.Name
+
+			Ω(k8sClient.Create(ctx, dummies.ACSEndpointSecret1)).Should(Succeed())
+			Ω(k8sClient.Create(ctx, dummies.CSFailureDomain1)).Should(Succeed())
+
+			mockCloudClient.EXPECT().GetCloudStackVersion().Return("4.19.0.0", nil).AnyTimes()
+			mockCloudClient.EXPECT().ResolveZone(gomock.Any()).AnyTimes()
+			mockCloudClient.EXPECT().ResolveNetworkForZone(gomock.Any()).AnyTimes().Do(
+				func(arg1 interface{}) {
+					arg1.(*infrav1.CloudStackZoneSpec).Network.ID = "SomeID"
+					arg1.(*infrav1.CloudStackZoneSpec).Network.Type = cloud.NetworkTypeShared
+				})
+			mockCloudClient.EXPECT().ListMissingAPIs(gomock.Any()).DoAndReturn(func(required []string) ([]string, error) {
+				// The shared network doesn't need the APIs CAPC manages isolated networks with.
+				Ω(required).ShouldNot(ContainElement("createNetwork"))
+
+				return []string{"deployVirtualMachine", "updateVMAffinityGroup"}, nil
+			}).MinTimes(1)
+		})
+
+		It("Should report the missing APIs and not become ready.", func() {
+			Eventually(func() string {
+				tempfd := &infrav1.CloudStackFailureDomain{}
+				if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(dummies.CSFailureDomain1), tempfd); err != nil {
+					return ""
+				}
+				if condition := conditions.Get(tempfd, infrav1.PreflightChecksPassedCondition); condition != nil {
+					return condition.Reason + ": " + condition.Message
+				}
+
+				return ""
+			}, timeout).WithPolling(pollInterval).Should(Equal(
+				infrav1.MissingPermissionsReason + ": the role of the CloudStack user doesn't grant: deployVirtualMachine, updateVMAffinityGroup"))
+			Ω(getFailuredomainStatus(dummies.CSFailureDomain1)).Should(BeFalse())
+		})
+	})
 })
 
 func getFailuredomainStatus(failureDomain *infrav1.CloudStackFailureDomain) bool {
@@ -22,6 +22,9 @@ import (
 	"strings"
 
 	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/cluster-api/util/annotations"
 	"sigs.k8s.io/cluster-api/util/patch"
 	"sigs.k8s.io/cluster-api/util/predicates"
@@ -120,6 +123,9 @@ func (r *CloudStackIsoNetReconciliationRunner) Reconcile() (ctrl.Result, error)
 		if err := r.CSUser.ReconcileLoadBalancer(r.FailureDomain, r.ReconciliationSubject, r.CSCluster); err != nil {
 			return ctrl.Result{}, errors.Wrap(err, "reconciling load balancer")
 		}
+		if err := r.ReconcileLoadBalancerInstances(); err != nil {
+			return ctrl.Result{}, err
+		}
 	}
 
 	if err := csClusterPatcher.Patch(r.RequestCtx, r.CSCluster); err != nil {
@@ -131,6 +137,46 @@ func (r *CloudStackIsoNetReconciliationRunner) Reconcile() (ctrl.Result, error)
 	return ctrl.Result{}, nil
 }
 
+// ReconcileLoadBalancerInstances makes the ready control plane machines of the failure domains on this network the only
+// instances of the API server load balancer rules. Instances of machines that were deleted, or are no longer ready,
+// are removed explicitly rather than left for CloudStack to drop once their VM is expunged.
+func (r *CloudStackIsoNetReconciliationRunner) ReconcileLoadBalancerInstances() error {
+	isoNet := r.ReconciliationSubject
+	if !r.CSCluster.Spec.APIServerLoadBalancer.IsEnabled() || len(isoNet.Status.LoadBalancerRuleIDs) == 0 {
+		return nil
+	}
+
+	// Failure domains that name the same network share this isolated network and its load balancer.
+	fds := &infrav1.CloudStackFailureDomainList{}
+	if res, err := r.GetFailureDomains(fds)(); r.ShouldReturn(res, err) {
+		return err
+	}
+	fdNames := map[string]bool{}
+	for _, fd := range fds.Items {
+		if r.IsoNetMetaName(fd.Spec.Zone.Network.Name) == isoNet.Name {
+			fdNames[fd.Spec.Name] = true
+		}
+	}
+
+	req, _ := labels.NewRequirement(clusterv1.MachineControlPlaneLabel, selection.Exists, nil)
+	instanceIDs, err := r.ReadyMachineInstanceIDs(labels.NewSelector().Add(*req), func(fdName string) bool { return fdNames[fdName] })
+	if err != nil {
+		return err
+	}
+	assigned, removed, err := r.CSUser.ReconcileLoadBalancerRuleInstances(isoNet.Status.LoadBalancerRuleIDs, instanceIDs)
+	if err != nil {
+		return errors.Wrap(err, "reconciling load balancer instances")
+	}
+	if len(assigned) > 0 {
+		r.Recorder.Eventf(isoNet, "Normal", "InstancesAssigned", "assigned instances %s", strings.Join(assigned, ", "))
+	}
+	if len(removed) > 0 {
+		r.Recorder.Eventf(isoNet, "Normal", "InstancesRemoved", "removed instances %s", strings.Join(removed, ", "))
+	}
+
+	return nil
+}
+
 func (r *CloudStackIsoNetReconciliationRunner) ReconcileDelete() (ctrl.Result, error) {
 	r.Log.Info("Deleting IsolatedNetwork.")
 	if err := r.CSUser.DisposeIsoNetResources(r.ReconciliationSubject, r.CSCluster); err != nil {
@@ -176,6 +222,10 @@ func (reconciler *CloudStackIsoNetReconciler) SetupWithManager(ctx context.Conte
 				},
 			),
 		).
+		Watches(
+			&infrav1.CloudStackMachine{},
+			handler.EnqueueRequestsFromMapFunc(csCtrlrUtils.ControlPlaneCloudStackMachineToCloudStackIsolatedNetworks(reconciler.K8sClient, ctrl.LoggerFrom(ctx))),
+		).
 		WithEventFilter(predicates.ResourceNotPausedAndHasFilterLabel(ctrl.LoggerFrom(ctx), reconciler.WatchFilterValue)).
 		Complete(reconciler)
 	if err != nil {
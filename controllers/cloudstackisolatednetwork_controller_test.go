@@ -23,6 +23,8 @@ import (
 	. "github.com/onsi/gomega"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/patch"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -94,6 +96,89 @@ var _ = Describe("CloudStackIsolatedNetworkReconciler", func() {
 				return false
 			}, timeout).WithPolling(pollInterval).Should(BeTrue())
 		})
+
+		It("Should remove instances without a ready control plane machine from the load balancer rules.", func() {
+			mockCloudClient.EXPECT().GetOrCreateIsolatedNetwork(g.Any(), g.Any()).AnyTimes()
+			mockCloudClient.EXPECT().AddClusterTag(g.Any(), g.Any(), g.Any()).AnyTimes()
+			mockCloudClient.EXPECT().AssociatePublicIPAddress(g.Any(), g.Any(), g.Any()).AnyTimes().Return(&cloudstack.PublicIpAddress{
+				Id:                  dummies.PublicIPID,
+				Associatednetworkid: dummies.ISONet1.ID,
+				Ipaddress:           dummies.CSCluster.Spec.ControlPlaneEndpoint.Host,
+			}, nil)
+			mockCloudClient.EXPECT().ReconcileLoadBalancer(g.Any(), g.Any(), g.Any()).AnyTimes().DoAndReturn(
+				func(_ *infrav1.CloudStackFailureDomain, isoNet *infrav1.CloudStackIsolatedNetwork, _ *infrav1.CloudStackCluster) error {
+					isoNet.Status.LoadBalancerRuleIDs = []string{dummies.LBRuleID}
+
+					return nil
+				})
+			// No control plane machine is ready, so the stale instance is the only one to remove.
+			mockCloudClient.EXPECT().ReconcileLoadBalancerRuleInstances([]string{dummies.LBRuleID}, []string{}).MinTimes(1).
+				Return(nil, []string{"StaleInstanceID"}, nil)
+
+			dummies.CSISONet1.Spec.FailureDomainName = dummies.CSFailureDomain2.Spec.Name
+			Ω(k8sClient.Create(ctx, dummies.CSFailureDomain2)).Should(Succeed())
+			Ω(k8sClient.Create(ctx, dummies.CSISONet1)).Should(Succeed())
+
+			Eventually(func() bool {
+				tempIsoNet := &infrav1.CloudStackIsolatedNetwork{}
+				key := client.ObjectKeyFromObject(dummies.CSISONet1)
+				if err := k8sClient.Get(ctx, key, tempIsoNet); err == nil {
+					if tempIsoNet.Status.Ready {
+						return true
+					}
+				}
+
+				return false
+			}, timeout).WithPolling(pollInterval).Should(BeTrue())
+		})
+
+		It("Should keep the instance of a ready control plane machine in the load balancer rules.", func() {
+			mockCloudClient.EXPECT().GetOrCreateIsolatedNetwork(g.Any(), g.Any()).AnyTimes()
+			mockCloudClient.EXPECT().AddClusterTag(g.Any(), g.Any(), g.Any()).AnyTimes()
+			mockCloudClient.EXPECT().AssociatePublicIPAddress(g.Any(), g.Any(), g.Any()).AnyTimes().Return(&cloudstack.PublicIpAddress{
+				Id:                  dummies.PublicIPID,
+				Associatednetworkid: dummies.ISONet1.ID,
+				Ipaddress:           dummies.CSCluster.Spec.ControlPlaneEndpoint.Host,
+			}, nil)
+			mockCloudClient.EXPECT().ReconcileLoadBalancer(g.Any(), g.Any(), g.Any()).AnyTimes().DoAndReturn(
+				func(_ *infrav1.CloudStackFailureDomain, isoNet *infrav1.CloudStackIsolatedNetwork, _ *infrav1.CloudStackCluster) error {
+					isoNet.Status.LoadBalancerRuleIDs = []string{dummies.LBRuleID}
+
+					return nil
+				})
+			// The machine's failure domain is matched by its name, not by the metadata name of the failure domain.
+			mockCloudClient.EXPECT().ReconcileLoadBalancerRuleInstances([]string{dummies.LBRuleID}, []string{*dummies.CSMachine1.Spec.InstanceID}).
+				MinTimes(1).Return(nil, nil, nil)
+
+			dummies.CSFailureDomain2.Spec.Zone.Network = dummies.ISONet1
+			dummies.CSISONet1.Name = dummies.CSCluster.Name + "-" + dummies.ISONet1.Name
+			dummies.CSISONet1.Spec.FailureDomainName = dummies.CSFailureDomain2.Spec.Name
+			dummies.CSMachine1.Spec.FailureDomainName = dummies.CSFailureDomain2.Spec.Name
+			dummies.CAPIMachine.Labels = map[string]string{
+				clusterv1.ClusterNameLabel:         dummies.ClusterName,
+				clusterv1.MachineControlPlaneLabel: "",
+			}
+			setCAPIMachineAndCSMachineCRDs(dummies.CSMachine1, dummies.CAPIMachine)
+			ph, err := patch.NewHelper(dummies.CSMachine1, k8sClient)
+			Ω(err).ShouldNot(HaveOccurred())
+			dummies.CSMachine1.Status.Ready = true
+			Ω(ph.Patch(ctx, dummies.CSMachine1)).Should(Succeed())
+
+			Ω(k8sClient.Create(ctx, dummies.CSFailureDomain2)).Should(Succeed())
+			Ω(k8sClient.Create(ctx, dummies.CSISONet1)).Should(Succeed())
+
+			Eventually(func() bool {
+				tempIsoNet := &infrav1.CloudStackIsolatedNetwork{}
+				key := client.ObjectKeyFromObject(dummies.CSISONet1)
+				if err := k8sClient.Get(ctx, key, tempIsoNet); err == nil {
+					if tempIsoNet.Status.Ready {
+						return true
+					}
+				}
+
+				return false
+			}, timeout).WithPolling(pollInterval).Should(BeTrue())
+		})
 	})
 
 	Context("With a fake ctrlRuntimeClient and no test Env at all.", func() {
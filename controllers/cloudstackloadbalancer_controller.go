@@ -0,0 +1,178 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/predicates"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
+	csCtrlrUtils "sigs.k8s.io/cluster-api-provider-cloudstack/controllers/utils"
+	"sigs.k8s.io/cluster-api-provider-cloudstack/pkg/cloud"
+)
+
+//+kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=cloudstackloadbalancers,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=cloudstackloadbalancers/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=cloudstackloadbalancers/finalizers,verbs=update
+
+// CloudStackLoadBalancerReconciliationRunner is a ReconciliationRunner with extensions specific to CloudStack load
+// balancer reconciliation.
+type CloudStackLoadBalancerReconciliationRunner struct {
+	*csCtrlrUtils.ReconciliationRunner
+	ReconciliationSubject *infrav1.CloudStackLoadBalancer
+	FailureDomain         *infrav1.CloudStackFailureDomain
+}
+
+// CloudStackLoadBalancerReconciler is the base reconciler to adapt to k8s.
+type CloudStackLoadBalancerReconciler struct {
+	csCtrlrUtils.ReconcilerBase
+}
+
+// Initialize a new CloudStackLoadBalancer reconciliation runner with concrete types and initialized member fields.
+func NewCSLoadBalancerReconciliationRunner() *CloudStackLoadBalancerReconciliationRunner {
+	// Set concrete type and init pointers.
+	r := &CloudStackLoadBalancerReconciliationRunner{ReconciliationSubject: &infrav1.CloudStackLoadBalancer{}}
+	r.FailureDomain = &infrav1.CloudStackFailureDomain{}
+	// Setup the base runner. Initializes pointers and links reconciliation methods.
+	r.ReconciliationRunner = csCtrlrUtils.NewRunner(r, r.ReconciliationSubject, "CloudStackLoadBalancer")
+
+	return r
+}
+
+func (reconciler *CloudStackLoadBalancerReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	r := NewCSLoadBalancerReconciliationRunner()
+	r.UsingBaseReconciler(reconciler.ReconcilerBase).ForRequest(req).WithRequestCtx(ctx)
+	r.WithAdditionalCommonStages(
+		r.GetFailureDomainByName(func() string { return r.ReconciliationSubject.Spec.FailureDomainName }, r.FailureDomain),
+		r.AsFailureDomainUser(&r.FailureDomain.Spec))
+
+	return r.RunBaseReconciliationStages()
+}
+
+// Reconcile associates a public IP with the isolated network of the failure domain, forwards the ports on it, and keeps
+// the selected machines assigned to the load balancer rules.
+func (r *CloudStackLoadBalancerReconciliationRunner) Reconcile() (ctrl.Result, error) {
+	controllerutil.AddFinalizer(r.ReconciliationSubject, infrav1.LoadBalancerFinalizer)
+	// Tie the load balancer's lifetime to its failure domain so it is removed along with the cluster.
+	if err := controllerutil.SetOwnerReference(r.FailureDomain, r.ReconciliationSubject, r.K8sClient.Scheme()); err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "setting failure domain owner reference")
+	}
+
+	network := r.FailureDomain.Spec.Zone.Network
+	if network.Type != infrav1.NetworkTypeIsolated {
+		return ctrl.Result{}, errors.Errorf("failure domain %s does not use an isolated network", r.FailureDomain.Spec.Name)
+	}
+	if network.ID == "" || !r.FailureDomain.Status.Ready {
+		return r.RequeueWithMessage("Failure domain network not ready yet.")
+	}
+
+	lb := r.ReconciliationSubject
+	pubIP, err := r.CSUser.AssociateLoadBalancerPublicIPAddress(r.FailureDomain, lb)
+	if err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "associating public IP address")
+	}
+	lb.Status.IPAddress = pubIP.Ipaddress
+	lb.Status.IPAddressID = pubIP.Id
+	if err := r.CSUser.AddClusterTag(cloud.ResourceTypeIPAddress, pubIP.Id, r.CSCluster); err != nil {
+		return ctrl.Result{}, errors.Wrapf(err, "adding cluster tag to public IP address with ID %s", pubIP.Id)
+	}
+	if err := r.CSUser.ReconcileLoadBalancerPorts(r.FailureDomain, lb); err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "reconciling load balancer ports")
+	}
+
+	instanceIDs, err := r.SelectedInstanceIDs()
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	assigned, removed, err := r.CSUser.ReconcileLoadBalancerRuleInstances(lb.Status.LoadBalancerRuleIDs, instanceIDs)
+	if err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "reconciling load balancer instances")
+	}
+	if len(assigned) > 0 {
+		r.Recorder.Eventf(lb, "Normal", "InstancesAssigned", "assigned instances %s", strings.Join(assigned, ", "))
+	}
+	if len(removed) > 0 {
+		r.Recorder.Eventf(lb, "Normal", "InstancesRemoved", "removed instances %s", strings.Join(removed, ", "))
+	}
+	lb.Status.InstanceIDs = instanceIDs
+	lb.Status.Ready = true
+
+	return ctrl.Result{}, nil
+}
+
+// SelectedInstanceIDs returns the instances of the ready machines the load balancer selects in its failure domain.
+func (r *CloudStackLoadBalancerReconciliationRunner) SelectedInstanceIDs() ([]string, error) {
+	lb := r.ReconciliationSubject
+	selector := labels.NewSelector()
+	if lb.Spec.Selector != nil {
+		var err error
+		if selector, err = metav1.LabelSelectorAsSelector(lb.Spec.Selector); err != nil {
+			return nil, errors.Wrap(err, "parsing machine selector")
+		}
+	}
+	if lb.Spec.MachineDeploymentName != "" {
+		req, _ := labels.NewRequirement(clusterv1.MachineDeploymentNameLabel, selection.Equals, []string{lb.Spec.MachineDeploymentName})
+		selector = selector.Add(*req)
+	}
+	if lb.Spec.Selector == nil && lb.Spec.MachineDeploymentName == "" {
+		req, _ := labels.NewRequirement(clusterv1.MachineControlPlaneLabel, selection.DoesNotExist, nil)
+		selector = selector.Add(*req)
+	}
+
+	return r.ReadyMachineInstanceIDs(selector, func(fdName string) bool { return fdName == lb.Spec.FailureDomainName })
+}
+
+func (r *CloudStackLoadBalancerReconciliationRunner) ReconcileDelete() (ctrl.Result, error) {
+	r.Log.Info("Deleting load balancer.")
+	if err := r.CSUser.ReleaseLoadBalancerPublicIPAddress(r.ReconciliationSubject, r.CSCluster); err != nil {
+		if !strings.Contains(strings.ToLower(err.Error()), "no match found") {
+			return ctrl.Result{}, err
+		}
+	}
+	controllerutil.RemoveFinalizer(r.ReconciliationSubject, infrav1.LoadBalancerFinalizer)
+
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (reconciler *CloudStackLoadBalancerReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, opts controller.Options) error {
+	err := ctrl.NewControllerManagedBy(mgr).
+		WithOptions(opts).
+		For(&infrav1.CloudStackLoadBalancer{}).
+		Watches(
+			&infrav1.CloudStackMachine{},
+			handler.EnqueueRequestsFromMapFunc(csCtrlrUtils.CloudStackMachineToCloudStackLoadBalancers(reconciler.K8sClient, ctrl.LoggerFrom(ctx))),
+		).
+		WithEventFilter(predicates.ResourceNotPausedAndHasFilterLabel(ctrl.LoggerFrom(ctx), reconciler.WatchFilterValue)).
+		Complete(reconciler)
+	if err != nil {
+		return errors.Wrap(err, "failed setting up with a controller manager")
+	}
+
+	return nil
+}
@@ -34,6 +34,7 @@ import (
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/cluster-api/util"
 	"sigs.k8s.io/cluster-api/util/annotations"
+	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/cluster-api/util/predicates"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
@@ -83,6 +84,7 @@ type CloudStackMachineReconciliationRunner struct {
 	StateChecker          *infrav1.CloudStackMachineStateChecker
 	FailureDomain         *infrav1.CloudStackFailureDomain
 	IsoNet                *infrav1.CloudStackIsolatedNetwork
+	VPC                   *infrav1.CloudStackVPC
 	AffinityGroup         *infrav1.CloudStackAffinityGroup
 }
 
@@ -98,6 +100,7 @@ func NewCSMachineReconciliationRunner() *CloudStackMachineReconciliationRunner {
 	r.CAPIMachine = &clusterv1.Machine{}
 	r.StateChecker = &infrav1.CloudStackMachineStateChecker{}
 	r.IsoNet = &infrav1.CloudStackIsolatedNetwork{}
+	r.VPC = &infrav1.CloudStackVPC{}
 	r.AffinityGroup = &infrav1.CloudStackAffinityGroup{}
 	r.FailureDomain = &infrav1.CloudStackFailureDomain{}
 	// Set up the base runner. Initializes pointers and links reconciliation methods.
@@ -130,6 +133,7 @@ func (r *CloudStackMachineReconciliationRunner) Reconcile() (ctrl.Result, error)
 			func() string { return r.IsoNetMetaName(r.FailureDomain.Spec.Zone.Network.Name) }),
 		r.RunIf(func() bool { return r.FailureDomain.Spec.Zone.Network.Type == cloud.NetworkTypeIsolated },
 			r.CheckPresent(map[string]client.Object{"CloudStackIsolatedNetwork": r.IsoNet})),
+		r.RunIf(func() bool { return r.FailureDomain.Spec.Zone.Network.Type == cloud.NetworkTypeVPCTier }, r.UseVPCTier),
 		r.ConsiderAffinity,
 		r.GetOrCreateVMInstance,
 		r.RequeueIfInstanceNotRunning,
@@ -155,6 +159,11 @@ func (r *CloudStackMachineReconciliationRunner) ConsiderAffinity() (ctrl.Result,
 		if err != nil {
 			return ctrl.Result{}, err
 		}
+		// A later generation of the failure domain may act in another account, so it gets affinity groups of its own.
+		firstGeneration := infrav1.FailureDomainHashedMetaName(r.FailureDomain.Spec.Name, r.CAPICluster.Name)
+		if r.FailureDomain.Name != firstGeneration {
+			agName += strings.TrimPrefix(r.FailureDomain.Name, firstGeneration)
+		}
 	}
 
 	// Set failure domain name and owners.
@@ -178,7 +187,8 @@ func (r *CloudStackMachineReconciliationRunner) ConsiderAffinity() (ctrl.Result,
 	return ctrl.Result{}, nil
 }
 
-// SetFailureDomainOnCSMachine sets the failure domain the machine should launch in.
+// SetFailureDomainOnCSMachine sets the failure domain the machine should launch in. A failure domain CAPC picked is
+// patched back right away, so that machines of the same group reconciled concurrently count it when placed.
 func (r *CloudStackMachineReconciliationRunner) SetFailureDomainOnCSMachine() (ctrl.Result, error) {
 	if r.ReconciliationSubject.Spec.FailureDomainName == "" {
 		var name string
@@ -188,18 +198,183 @@ func (r *CloudStackMachineReconciliationRunner) SetFailureDomainOnCSMachine() (c
 				*r.CAPIMachine.Spec.FailureDomain != "") { // Or potentially another machine controller specified.
 			name = *r.CAPIMachine.Spec.FailureDomain
 			r.ReconciliationSubject.Spec.FailureDomainName = *r.CAPIMachine.Spec.FailureDomain
-		} else { // Not a control plane machine. Place randomly.
-			rand := rand.New(rand.NewSource(time.Now().Unix()))            // #nosec G404 -- weak crypt rand doesn't matter here.
-			randNum := (rand.Int() % len(r.CSCluster.Spec.FailureDomains)) // #nosec G404 -- weak crypt rand doesn't matter here.
-			name = r.CSCluster.Spec.FailureDomains[randNum].Name
+		} else { // Not a control plane machine. Place by the machine's placement strategy.
+			placement, placedName, err := r.PlaceMachine()
+			if err != nil {
+				return ctrl.Result{}, err
+			} else if placement == nil {
+				return r.RequeueWithMessage("No failure domain can take the machine.")
+			}
+			r.ReconciliationSubject.Status.Placement = placement
+			r.ReconciliationSubject.Spec.FailureDomainName = placedName
+			r.ReconciliationSubject.Labels[infrav1.FailureDomainLabelName] = r.FailureDomainMetaName(placedName)
+			r.Log.Info("Placed machine.", "failureDomain", placedName, "strategy", placement.Strategy, "reason", placement.Reason)
+
+			return r.PatchReconciliationSubject()
 		}
 		r.ReconciliationSubject.Spec.FailureDomainName = name
-		r.ReconciliationSubject.Labels[infrav1.FailureDomainLabelName] = infrav1.FailureDomainHashedMetaName(name, r.CAPICluster.Name)
+		r.ReconciliationSubject.Labels[infrav1.FailureDomainLabelName] = r.FailureDomainMetaName(name)
 	}
 
 	return ctrl.Result{}, nil
 }
 
+// PlaceMachine picks a failure domain for the machine by its placement strategy, leaving out unavailable and evacuating
+// failure domains. It returns a nil placement if no failure domain qualifies.
+func (r *CloudStackMachineReconciliationRunner) PlaceMachine() (*infrav1.CloudStackMachinePlacement, string, error) {
+	fds := &infrav1.CloudStackFailureDomainList{}
+	if res, err := r.GetFailureDomains(fds)(); r.ShouldReturn(res, err) {
+		return nil, "", err
+	}
+	var fdSpecs []infrav1.CloudStackFailureDomainSpec
+	for _, fdSpec := range r.CSCluster.Spec.FailureDomains {
+		if fd := utils.CurrentFailureDomainGeneration(fds, fdSpec); fd != nil &&
+			(conditions.IsFalse(fd, infrav1.FailureDomainAvailableCondition) || fd.IsEvacuating()) {
+			continue
+		}
+		fdSpecs = append(fdSpecs, fdSpec)
+	}
+	if len(fdSpecs) == 0 {
+		r.Log.Info("No failure domain is available.")
+
+		return nil, "", nil
+	}
+	rnd := rand.New(rand.NewSource(time.Now().UnixNano())) // #nosec G404 -- weak crypt rand doesn't matter here.
+
+	switch strategy := r.ReconciliationSubject.Spec.PlacementStrategy; strategy {
+	case infrav1.PlacementStrategySpread:
+		counts, err := r.countMachinesPerFailureDomain()
+		if err != nil {
+			return nil, "", err
+		}
+		name := fewestMachines(rnd, fdSpecs, counts)
+
+		return &infrav1.CloudStackMachinePlacement{
+			Strategy: strategy,
+			Reason:   fmt.Sprintf("fewest machines of the group (%d) among %d failure domains", counts[name], len(fdSpecs)),
+		}, name, nil
+
+	case infrav1.PlacementStrategyWeighted:
+		var total int64
+		for _, fdSpec := range fdSpecs {
+			total += int64(fdSpec.PlacementWeight())
+		}
+		if total == 0 {
+			r.Log.Info("Every failure domain has a weight of 0.")
+
+			return nil, "", nil
+		}
+		pick := rnd.Int63n(total)
+		for _, fdSpec := range fdSpecs {
+			if pick -= int64(fdSpec.PlacementWeight()); pick < 0 {
+				return &infrav1.CloudStackMachinePlacement{
+					Strategy: strategy,
+					Reason:   fmt.Sprintf("picked with weight %d of %d", fdSpec.PlacementWeight(), total),
+				}, fdSpec.Name, nil
+			}
+		}
+
+		return nil, "", errors.New("weighted placement fell through")
+
+	case infrav1.PlacementStrategyCapacityAware:
+		var candidates []infrav1.CloudStackFailureDomainSpec
+		var skipped []string
+		for _, fdSpec := range fdSpecs {
+			if fd := utils.CurrentFailureDomainGeneration(fds, fdSpec); fd != nil && fd.Status.Ready && hasQuotaLeft(fd.Status.Capacity) {
+				candidates = append(candidates, fdSpec)
+			} else {
+				skipped = append(skipped, fdSpec.Name)
+			}
+		}
+		if len(candidates) == 0 {
+			conditions.MarkFalse(r.ReconciliationSubject, infrav1.ResourceLimitsAvailableCondition,
+				infrav1.LimitExceededReason, clusterv1.ConditionSeverityWarning, "no failure domain has quota left")
+
+			return nil, "", nil
+		}
+		counts, err := r.countMachinesPerFailureDomain()
+		if err != nil {
+			return nil, "", err
+		}
+		name := fewestMachines(rnd, candidates, counts)
+		reason := fmt.Sprintf("fewest machines of the group (%d) among %d failure domains with quota left", counts[name], len(candidates))
+		if len(skipped) > 0 {
+			reason += fmt.Sprintf("; skipped %s", strings.Join(skipped, ", "))
+		}
+
+		return &infrav1.CloudStackMachinePlacement{Strategy: strategy, Reason: reason}, name, nil
+
+	default:
+		name := fdSpecs[rnd.Intn(len(fdSpecs))].Name
+
+		return &infrav1.CloudStackMachinePlacement{
+			Strategy: infrav1.PlacementStrategyRandom,
+			Reason:   fmt.Sprintf("picked at random among %d failure domains", len(fdSpecs)),
+		}, name, nil
+	}
+}
+
+// countMachinesPerFailureDomain counts the other CloudStackMachines of the machine's group by failure domain. The group
+// is the machine's MachineDeployment, else its MachineSet, else the cluster's machines that aren't in a MachineSet.
+func (r *CloudStackMachineReconciliationRunner) countMachinesPerFailureDomain() (map[string]int, error) {
+	selector := client.MatchingLabels{clusterv1.ClusterNameLabel: r.CAPICluster.Name}
+	groupLabels := r.ReconciliationSubject.GetLabels()
+	if deployment, found := groupLabels[clusterv1.MachineDeploymentNameLabel]; found {
+		selector[clusterv1.MachineDeploymentNameLabel] = deployment
+	} else if set, found := groupLabels[clusterv1.MachineSetNameLabel]; found {
+		selector[clusterv1.MachineSetNameLabel] = set
+	}
+
+	machines := &infrav1.CloudStackMachineList{}
+	if err := r.K8sClient.List(r.RequestCtx, machines, client.InNamespace(r.ReconciliationSubject.Namespace), selector); err != nil {
+		return nil, errors.Wrap(err, "listing machines to spread over failure domains")
+	}
+	counts := map[string]int{}
+	for _, machine := range machines.Items {
+		if machine.Name == r.ReconciliationSubject.Name || !machine.DeletionTimestamp.IsZero() {
+			continue
+		}
+		counts[machine.Spec.FailureDomainName]++
+	}
+
+	return counts, nil
+}
+
+// fewestMachines returns the failure domain with the fewest machines, breaking ties at random so that machines
+// created together don't all land in the same failure domain.
+func fewestMachines(rnd *rand.Rand, fdSpecs []infrav1.CloudStackFailureDomainSpec, counts map[string]int) string {
+	var fewest []string
+	for _, fdSpec := range fdSpecs {
+		if len(fewest) == 0 || counts[fdSpec.Name] < counts[fewest[0]] {
+			fewest = []string{fdSpec.Name}
+		} else if counts[fdSpec.Name] == counts[fewest[0]] {
+			fewest = append(fewest, fdSpec.Name)
+		}
+	}
+
+	return fewest[rnd.Intn(len(fewest))]
+}
+
+// hasQuotaLeft returns whether none of the reported resource headrooms is out of VMs, CPUs or memory. A failure
+// domain that hasn't reported its capacity is assumed to have quota left.
+func hasQuotaLeft(capacity *infrav1.CloudStackFailureDomainCapacity) bool {
+	if capacity == nil {
+		return true
+	}
+	for _, headroom := range []*infrav1.CloudStackResourceHeadroom{capacity.Account, capacity.Domain, capacity.Project} {
+		if headroom == nil {
+			continue
+		}
+		for _, available := range []*int64{headroom.Instances, headroom.CPU, headroom.MemoryMiB} {
+			if available != nil && *available <= 0 {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
 // DeleteMachineIfFailuredomainNotExist delete CAPI machine if machine is deployed in a failuredomain that does not exist anymore.
 func (r *CloudStackMachineReconciliationRunner) DeleteMachineIfFailuredomainNotExist() (ctrl.Result, error) {
 	if r.CAPIMachine.Spec.FailureDomain == nil {
@@ -250,6 +425,12 @@ func (r *CloudStackMachineReconciliationRunner) GetOrCreateVMInstance() (ctrl.Re
 		r.Log.Error(err, "GetOrCreateVMInstance returned error")
 		r.Recorder.Eventf(r.ReconciliationSubject, "Warning", "Creating", CSMachineCreationFailed, err.Error())
 	}
+	if cloud.IsLimitExceeded(err) {
+		conditions.MarkFalse(r.ReconciliationSubject, infrav1.ResourceLimitsAvailableCondition,
+			infrav1.LimitExceededReason, clusterv1.ConditionSeverityWarning, "%s", err.Error())
+	} else if err == nil {
+		conditions.MarkTrue(r.ReconciliationSubject, infrav1.ResourceLimitsAvailableCondition)
+	}
 	if err == nil && !controllerutil.ContainsFinalizer(r.ReconciliationSubject, infrav1.MachineFinalizer) { // Fetched or Created?
 		// Adding a finalizer will make reconcile-delete try to destroy the associated VM through instanceID.
 		// If err is not nil, it means CAPC could not get an associated VM through instanceID or name, so we should not add a finalizer to this CloudStackMachine,
@@ -299,8 +480,30 @@ func (r *CloudStackMachineReconciliationRunner) RequeueIfInstanceNotRunning() (c
 	return ctrl.Result{}, nil
 }
 
-// AddToLBIfNeeded adds instance to load balancer if it is a control plane node in an isolated network, and the load balancer is enabled.
+// UseVPCTier places the machine in the VPC tier for its role: control plane machines in the control plane tier, all
+// others in the worker tier.
+func (r *CloudStackMachineReconciliationRunner) UseVPCTier() (ctrl.Result, error) {
+	if res, err := r.GetObjectByName(r.VPCMetaName(r.FailureDomain.Spec.Zone.Network.Name), r.VPC)(); r.ShouldReturn(res, err) {
+		return res, err
+	}
+	if r.VPC.Name == "" || !r.VPC.Status.Ready {
+		return r.RequeueWithMessage("Required VPC not ready.")
+	}
+	// The failure domain isn't written back, so this only directs where the VM is deployed and looked up.
+	r.FailureDomain.Spec.Zone.Network.ID = r.VPC.TierNetworkID(util.IsControlPlaneMachine(r.CAPIMachine))
+
+	return ctrl.Result{}, nil
+}
+
+// AddToLBIfNeeded adds instance to load balancer if it is a control plane node in an isolated network or VPC, and the load balancer is enabled.
 func (r *CloudStackMachineReconciliationRunner) AddToLBIfNeeded() (ctrl.Result, error) {
+	if util.IsControlPlaneMachine(r.CAPIMachine) &&
+		r.FailureDomain.Spec.Zone.Network.Type == cloud.NetworkTypeVPCTier &&
+		r.CSCluster.Spec.APIServerLoadBalancer.IsEnabled() {
+		r.Log.V(4).Info("Assigning VM to VPC load balancer rules.")
+
+		return ctrl.Result{}, r.CSUser.AssignVMToVPCLoadBalancerRules(r.VPC, *r.ReconciliationSubject.Spec.InstanceID)
+	}
 	if util.IsControlPlaneMachine(r.CAPIMachine) &&
 		r.FailureDomain.Spec.Zone.Network.Type == cloud.NetworkTypeIsolated &&
 		r.CSCluster.Spec.APIServerLoadBalancer.IsEnabled() {
@@ -330,6 +533,8 @@ func (r *CloudStackMachineReconciliationRunner) GetOrCreateMachineStateChecker()
 	if err != nil {
 		if k8serrors.IsNotFound(err) {
 			csMachineStateChecker.ObjectMeta = r.NewChildObjectMeta(*checkerName)
+			// Check the instance as the failure domain generation it was created in.
+			csMachineStateChecker.Labels[infrav1.FailureDomainLabelName] = r.ReconciliationSubject.Labels[infrav1.FailureDomainLabelName]
 			csMachineStateChecker.Spec = infrav1.CloudStackMachineStateCheckerSpec{InstanceID: *checkerName}
 			csMachineStateChecker.Status = infrav1.CloudStackMachineStateCheckerStatus{Ready: false}
 
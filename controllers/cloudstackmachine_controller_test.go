@@ -28,6 +28,7 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/utils/ptr"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/cluster-api/util/patch"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -35,6 +36,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	infrav1 "sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
+	csReconcilers "sigs.k8s.io/cluster-api-provider-cloudstack/controllers"
 	"sigs.k8s.io/cluster-api-provider-cloudstack/pkg/cloud"
 	dummies "sigs.k8s.io/cluster-api-provider-cloudstack/test/dummies/v1beta3"
 )
@@ -84,6 +86,26 @@ var _ = Describe("CloudStackMachineReconciler", func() {
 			}, timeout).WithPolling(pollInterval).Should(BeTrue())
 		})
 
+		It("Should report exceeded resource limits in a condition", func() {
+			mockCloudClient.EXPECT().GetOrCreateVMInstance(
+				gomock.Any(), gomock.Any(), gomock.Any(),
+				gomock.Any(), gomock.Any()).Return(&cloud.LimitExceededError{
+				Scope: cloud.LimitScopeAccount, Resource: cloud.LimitResourceCPU, Available: 1, Required: 2,
+			}).AnyTimes()
+
+			setupMachineCRDs()
+
+			Eventually(func() string {
+				tempMachine := &infrav1.CloudStackMachine{}
+				key := client.ObjectKey{Namespace: dummies.ClusterNameSpace, Name: dummies.CSMachine1.Name}
+				if err := k8sClient.Get(ctx, key, tempMachine); err == nil && conditions.IsFalse(tempMachine, infrav1.ResourceLimitsAvailableCondition) {
+					return conditions.GetMessage(tempMachine, infrav1.ResourceLimitsAvailableCondition)
+				}
+
+				return ""
+			}, timeout).WithPolling(pollInterval).Should(Equal("CPU available (1) in account can't fulfil the requirement: 2"))
+		})
+
 		It("Should call DestroyVMInstance when CS machine deleted", func() {
 			// Mock a call to GetOrCreateVMInstance and set the machine to running.
 			mockCloudClient.EXPECT().GetOrCreateVMInstance(
@@ -230,6 +252,100 @@ var _ = Describe("CloudStackMachineReconciler", func() {
 			Ω(res.RequeueAfter).ShouldNot(BeZero())
 		})
 
+		It("Should place a worker machine in the failure domain with the fewest machines of its MachineDeployment.", func() {
+			key := client.ObjectKeyFromObject(dummies.CSCluster)
+			Ω(fakeCtrlClient.Get(ctx, key, dummies.CSCluster)).Should(Succeed())
+			dummies.CSCluster.Spec.FailureDomains = []infrav1.CloudStackFailureDomainSpec{
+				dummies.CSFailureDomain1.Spec, dummies.CSFailureDomain2.Spec}
+			Ω(fakeCtrlClient.Update(ctx, dummies.CSCluster)).Should(Succeed())
+			setClusterReady(fakeCtrlClient)
+
+			deploymentLabels := map[string]string{
+				clusterv1.ClusterNameLabel: dummies.ClusterName, clusterv1.MachineDeploymentNameLabel: "md-0"}
+			siblingMachine := dummies.CSMachine1.DeepCopy()
+			siblingMachine.Name = "test-machine-0"
+			siblingMachine.Labels = map[string]string{}
+			for k, v := range deploymentLabels {
+				siblingMachine.Labels[k] = v
+			}
+			Ω(fakeCtrlClient.Create(ctx, siblingMachine)).Should(Succeed())
+
+			dummies.CAPIMachine.Name = "someMachine"
+			dummies.CAPIMachine.Spec.FailureDomain = nil
+			dummies.CSMachine1.OwnerReferences = append(dummies.CSMachine1.OwnerReferences, metav1.OwnerReference{
+				Kind:       "Machine",
+				APIVersion: clusterv1.GroupVersion.String(),
+				Name:       dummies.CAPIMachine.Name,
+				UID:        "uniqueness",
+			})
+			dummies.CSMachine1.Labels = deploymentLabels
+			dummies.CSMachine1.Spec.FailureDomainName = ""
+			dummies.CSMachine1.Spec.PlacementStrategy = infrav1.PlacementStrategySpread
+			Ω(fakeCtrlClient.Create(ctx, dummies.CAPIMachine)).Should(Succeed())
+			Ω(fakeCtrlClient.Create(ctx, dummies.CSMachine1)).Should(Succeed())
+
+			// The reconciliation stops at getting the second failure domain, which doesn't exist, after placing the machine.
+			requestNamespacedName := types.NamespacedName{Namespace: dummies.ClusterNameSpace, Name: dummies.CSMachine1.Name}
+			_, _ = MachineReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: requestNamespacedName})
+
+			placedMachine := &infrav1.CloudStackMachine{}
+			Ω(fakeCtrlClient.Get(ctx, requestNamespacedName, placedMachine)).Should(Succeed())
+			Ω(placedMachine.Spec.FailureDomainName).Should(Equal(dummies.CSFailureDomain2.Spec.Name))
+			Ω(placedMachine.Status.Placement).ShouldNot(BeNil())
+			Ω(placedMachine.Status.Placement.Strategy).Should(Equal(infrav1.PlacementStrategySpread))
+		})
+
+		It("Should spread the machines of a MachineDeployment that are placed before any reconciliation finishes.", func() {
+			key := client.ObjectKeyFromObject(dummies.CSCluster)
+			Ω(fakeCtrlClient.Get(ctx, key, dummies.CSCluster)).Should(Succeed())
+			dummies.CSCluster.Spec.FailureDomains = []infrav1.CloudStackFailureDomainSpec{
+				dummies.CSFailureDomain1.Spec, dummies.CSFailureDomain2.Spec}
+			Ω(fakeCtrlClient.Update(ctx, dummies.CSCluster)).Should(Succeed())
+			setClusterReady(fakeCtrlClient)
+
+			// Get every runner up to placement first, as concurrent workers would, and never run the final patch.
+			var runners []*csReconcilers.CloudStackMachineReconciliationRunner
+			for i := 0; i < 4; i++ {
+				capiMachine := dummies.CAPIMachine.DeepCopy()
+				capiMachine.Name = fmt.Sprintf("md-0-machine-%d", i)
+				capiMachine.Spec.FailureDomain = nil
+				csMachine := dummies.CSMachine1.DeepCopy()
+				csMachine.Name = fmt.Sprintf("md-0-csmachine-%d", i)
+				csMachine.Labels = map[string]string{
+					clusterv1.ClusterNameLabel: dummies.ClusterName, clusterv1.MachineDeploymentNameLabel: "md-0"}
+				csMachine.OwnerReferences = append(csMachine.OwnerReferences, metav1.OwnerReference{
+					Kind:       "Machine",
+					APIVersion: clusterv1.GroupVersion.String(),
+					Name:       capiMachine.Name,
+					UID:        types.UID(fmt.Sprintf("uniqueness-%d", i)),
+				})
+				csMachine.Spec.FailureDomainName = ""
+				csMachine.Spec.PlacementStrategy = infrav1.PlacementStrategySpread
+				Ω(fakeCtrlClient.Create(ctx, capiMachine)).Should(Succeed())
+				Ω(fakeCtrlClient.Create(ctx, csMachine)).Should(Succeed())
+
+				r := csReconcilers.NewCSMachineReconciliationRunner()
+				r.UsingBaseReconciler(MachineReconciler.ReconcilerBase).
+					ForRequest(ctrl.Request{NamespacedName: client.ObjectKeyFromObject(csMachine)}).WithRequestCtx(ctx)
+				_, err := r.RunReconciliationStages(r.SetupLogger, r.GetReconciliationSubject, r.SetupPatcher,
+					r.GetCAPICluster, r.GetCSCluster, r.GetParent(r.ReconciliationSubject, r.CAPIMachine))
+				Ω(err).ShouldNot(HaveOccurred())
+				runners = append(runners, r)
+			}
+			for _, r := range runners {
+				_, err := r.SetFailureDomainOnCSMachine()
+				Ω(err).ShouldNot(HaveOccurred())
+			}
+
+			machines := &infrav1.CloudStackMachineList{}
+			Ω(fakeCtrlClient.List(ctx, machines, client.MatchingLabels{clusterv1.MachineDeploymentNameLabel: "md-0"})).Should(Succeed())
+			counts := map[string]int{}
+			for _, machine := range machines.Items {
+				counts[machine.Spec.FailureDomainName]++
+			}
+			Ω(counts).Should(Equal(map[string]int{dummies.CSFailureDomain1.Spec.Name: 2, dummies.CSFailureDomain2.Spec.Name: 2}))
+		})
+
 		It("Should create event Machine instance is Running", func() {
 			key := client.ObjectKeyFromObject(dummies.CSCluster)
 			dummies.CAPIMachine.Name = "someMachine"
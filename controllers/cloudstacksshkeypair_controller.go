@@ -0,0 +1,228 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/cluster-api/util/predicates"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
+	csCtrlrUtils "sigs.k8s.io/cluster-api-provider-cloudstack/controllers/utils"
+	"sigs.k8s.io/cluster-api-provider-cloudstack/pkg/cloud"
+)
+
+//+kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=cloudstacksshkeypairs,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=cloudstacksshkeypairs/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=cloudstacksshkeypairs/finalizers,verbs=update
+// Need to write the private key of generated keypairs.
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch
+
+// CloudStackSSHKeyPairReconciliationRunner is a ReconciliationRunner with extensions specific to CloudStack SSH keypair reconciliation.
+type CloudStackSSHKeyPairReconciliationRunner struct {
+	*csCtrlrUtils.ReconciliationRunner
+	ReconciliationSubject *infrav1.CloudStackSSHKeyPair
+	FailureDomain         *infrav1.CloudStackFailureDomain
+}
+
+// CloudStackSSHKeyPairReconciler is the base reconciler to adapt to k8s.
+type CloudStackSSHKeyPairReconciler struct {
+	csCtrlrUtils.ReconcilerBase
+}
+
+// Initialize a new CloudStackSSHKeyPair reconciliation runner with concrete types and initialized member fields.
+func NewCSSSHKeyPairReconciliationRunner() *CloudStackSSHKeyPairReconciliationRunner {
+	// Set concrete type and init pointers.
+	r := &CloudStackSSHKeyPairReconciliationRunner{ReconciliationSubject: &infrav1.CloudStackSSHKeyPair{}}
+	r.FailureDomain = &infrav1.CloudStackFailureDomain{}
+	// Setup the base runner. Initializes pointers and links reconciliation methods.
+	r.ReconciliationRunner = csCtrlrUtils.NewRunner(r, r.ReconciliationSubject, "CloudStackSSHKeyPair")
+
+	return r
+}
+
+func (reconciler *CloudStackSSHKeyPairReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	r := NewCSSSHKeyPairReconciliationRunner()
+	r.UsingBaseReconciler(reconciler.ReconcilerBase).ForRequest(req).WithRequestCtx(ctx)
+	r.WithAdditionalCommonStages(
+		r.GetFailureDomainByName(func() string { return r.ReconciliationSubject.Spec.FailureDomainName }, r.FailureDomain),
+		r.AsFailureDomainUser(&r.FailureDomain.Spec))
+
+	return r.RunBaseReconciliationStages()
+}
+
+func (r *CloudStackSSHKeyPairReconciliationRunner) Reconcile() (ctrl.Result, error) {
+	controllerutil.AddFinalizer(r.ReconciliationSubject, infrav1.SSHKeyPairFinalizer)
+	// Tie the keypair's lifetime to its failure domain so it is removed along with the cluster.
+	if err := controllerutil.SetOwnerReference(r.FailureDomain, r.ReconciliationSubject, r.K8sClient.Scheme()); err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "setting failure domain owner reference")
+	}
+
+	// Only the keypair whose fingerprint was recorded when it was created is accepted, CAPC doesn't take over keypairs
+	// that merely share the name.
+	keyPair := &cloud.SSHKeyPair{Name: r.ReconciliationSubject.Spec.Name, Fingerprint: r.ReconciliationSubject.Status.Fingerprint}
+	if r.ReconciliationSubject.GeneratesKeyPair() {
+		if err := r.CSUser.GetOrCreateSSHKeyPair(keyPair); err != nil {
+			return ctrl.Result{}, err
+		}
+		if res, err := r.RecordKeyPair(keyPair)(); r.ShouldReturn(res, err) {
+			return res, err
+		}
+		if res, err := r.StorePrivateKey(keyPair)(); r.ShouldReturn(res, err) {
+			return res, err
+		}
+	} else {
+		publicKey, err := r.GetPublicKey()
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		keyPair.PublicKey = publicKey
+		if err := r.CSUser.GetOrRegisterSSHKeyPair(keyPair); err != nil {
+			return ctrl.Result{}, err
+		}
+		if res, err := r.RecordKeyPair(keyPair)(); r.ShouldReturn(res, err) {
+			return res, err
+		}
+	}
+	r.ReconciliationSubject.Status.Ready = true
+
+	return ctrl.Result{}, nil
+}
+
+// RecordKeyPair records the ID and fingerprint of the keypair. A keypair that was just created is recorded right away,
+// as the fingerprint is what marks it as created by this CloudStackSSHKeyPair.
+func (r *CloudStackSSHKeyPairReconciliationRunner) RecordKeyPair(keyPair *cloud.SSHKeyPair) csCtrlrUtils.CloudStackReconcilerMethod {
+	return func() (ctrl.Result, error) {
+		created := r.ReconciliationSubject.Status.Fingerprint != keyPair.Fingerprint
+		r.ReconciliationSubject.Spec.ID = keyPair.ID
+		r.ReconciliationSubject.Status.Fingerprint = keyPair.Fingerprint
+		if created {
+			return r.PatchReconciliationSubject()
+		}
+
+		return ctrl.Result{}, nil
+	}
+}
+
+// GetPublicKey reads the public key to register from the referenced Secret.
+func (r *CloudStackSSHKeyPairReconciliationRunner) GetPublicKey() (string, error) {
+	ref := r.ReconciliationSubject.Spec.PublicKeySecretRef
+	secret := &corev1.Secret{}
+	key := client.ObjectKey{Namespace: r.ReconciliationSubject.Namespace, Name: ref.Name}
+	if err := r.K8sClient.Get(r.RequestCtx, key, secret); err != nil {
+		return "", errors.Wrapf(err, "getting public key secret %s", ref.Name)
+	}
+	publicKey := strings.TrimSpace(string(secret.Data[ref.Key]))
+	if publicKey == "" {
+		return "", errors.Errorf("public key secret %s has no data under key %s", ref.Name, ref.Key)
+	}
+
+	return publicKey, nil
+}
+
+// StorePrivateKey writes a freshly generated private key to the keypair's private key Secret. When CloudStack did not
+// just generate the key, it only checks that the Secret is still around. The key cannot be retrieved again, so a
+// keypair whose Secret is gone is deleted from CloudStack to be generated anew. Only keypairs created by this
+// CloudStackSSHKeyPair get here, others are refused when they're fetched.
+func (r *CloudStackSSHKeyPairReconciliationRunner) StorePrivateKey(keyPair *cloud.SSHKeyPair) csCtrlrUtils.CloudStackReconcilerMethod {
+	return func() (ctrl.Result, error) {
+		secretName := r.ReconciliationSubject.PrivateKeySecretName()
+		if keyPair.PrivateKey == "" {
+			secret := &corev1.Secret{}
+			key := client.ObjectKey{Namespace: r.ReconciliationSubject.Namespace, Name: secretName}
+			if err := r.K8sClient.Get(r.RequestCtx, key, secret); err == nil {
+				return ctrl.Result{}, nil
+			} else if !apierrors.IsNotFound(err) {
+				return r.ReturnWrappedError(err, "getting private key secret")
+			}
+			if err := r.CSUser.DeleteSSHKeyPair(keyPair); err != nil {
+				return r.ReturnWrappedError(err, "deleting keypair without private key secret")
+			}
+
+			return r.RequeueWithMessage(
+				fmt.Sprintf("Private key secret %s of keypair %s missing, regenerating keypair.", secretName, keyPair.Name))
+		}
+
+		secret := &corev1.Secret{
+			ObjectMeta: r.NewChildObjectMeta(secretName),
+			Type:       corev1.SecretTypeSSHAuth,
+			Data:       map[string][]byte{corev1.SSHAuthPrivateKey: []byte(keyPair.PrivateKey)},
+		}
+		if err := r.K8sClient.Create(r.RequestCtx, secret); err != nil {
+			if !csCtrlrUtils.ContainsAlreadyExistsSubstring(err) {
+				return r.ReturnWrappedError(err, "creating private key secret")
+			}
+			existing := &corev1.Secret{}
+			if err := r.K8sClient.Get(r.RequestCtx, client.ObjectKeyFromObject(secret), existing); err != nil {
+				return r.ReturnWrappedError(err, "getting private key secret")
+			}
+			existing.Data = secret.Data
+			if err := r.K8sClient.Update(r.RequestCtx, existing); err != nil {
+				return r.ReturnWrappedError(err, "updating private key secret")
+			}
+		}
+
+		return ctrl.Result{}, nil
+	}
+}
+
+// ReconcileDelete deletes the keypair from CloudStack if it's still the one this CloudStackSSHKeyPair created. Keypairs
+// it never created, or that were replaced since, are left alone.
+func (r *CloudStackSSHKeyPairReconciliationRunner) ReconcileDelete() (ctrl.Result, error) {
+	recorded := r.ReconciliationSubject.Status.Fingerprint
+	keyPair := &cloud.SSHKeyPair{Name: r.ReconciliationSubject.Spec.Name}
+	if recorded == "" {
+		// Keypair never created.
+		controllerutil.RemoveFinalizer(r.ReconciliationSubject, infrav1.SSHKeyPairFinalizer)
+
+		return ctrl.Result{}, nil
+	}
+	if err := r.CSUser.FetchSSHKeyPair(keyPair); errors.Is(err, cloud.ErrNotFound) {
+		// Keypair not found, must have been deleted already.
+		controllerutil.RemoveFinalizer(r.ReconciliationSubject, infrav1.SSHKeyPairFinalizer)
+
+		return ctrl.Result{}, nil
+	} else if err != nil {
+		return r.ReturnWrappedError(err, "fetching keypair")
+	}
+	if !strings.EqualFold(keyPair.Fingerprint, recorded) {
+		r.Log.Info("Leaving SSH keypair alone, it was not created by this CloudStackSSHKeyPair.", "name", keyPair.Name)
+	} else if err := r.CSUser.DeleteSSHKeyPair(keyPair); err != nil {
+		return ctrl.Result{}, err
+	}
+	controllerutil.RemoveFinalizer(r.ReconciliationSubject, infrav1.SSHKeyPairFinalizer)
+
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (reconciler *CloudStackSSHKeyPairReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, opts controller.Options) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		WithOptions(opts).
+		For(&infrav1.CloudStackSSHKeyPair{}).
+		WithEventFilter(predicates.ResourceNotPausedAndHasFilterLabel(ctrl.LoggerFrom(ctx), reconciler.WatchFilterValue)).
+		Complete(reconciler)
+}
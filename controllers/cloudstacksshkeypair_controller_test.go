@@ -0,0 +1,152 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"errors"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
+	"sigs.k8s.io/cluster-api-provider-cloudstack/pkg/cloud"
+	dummies "sigs.k8s.io/cluster-api-provider-cloudstack/test/dummies/v1beta3"
+)
+
+var _ = Describe("CloudStackSSHKeyPairReconciler", func() {
+	BeforeEach(func() {
+		SetupTestEnvironment() // Must happen before setting up managers/reconcilers.
+		dummies.SetDummyVars()
+		Ω(SSHKeyPairReconciler.SetupWithManager(ctx, k8sManager, controller.Options{})).Should(Succeed()) // Register CloudStack SSHKeyPairReconciler.
+	})
+
+	It("Should store the generated private key and patch back the keypair as ready.", func() {
+		mockCloudClient.EXPECT().GetOrCreateSSHKeyPair(gomock.Any()).Do(func(arg1 interface{}) {
+			arg1.(*cloud.SSHKeyPair).ID = "kp-id"
+			arg1.(*cloud.SSHKeyPair).PrivateKey = "private"
+		}).AnyTimes().Return(nil)
+
+		Ω(k8sClient.Create(ctx, dummies.CSFailureDomain1)).Should(Succeed())
+		Ω(k8sClient.Create(ctx, dummies.CSSSHKeyPair)).Should(Succeed())
+
+		Eventually(func() bool {
+			keyPair := &infrav1.CloudStackSSHKeyPair{}
+			key := client.ObjectKey{Namespace: dummies.ClusterNameSpace, Name: dummies.CSSSHKeyPair.Name}
+			if err := k8sClient.Get(ctx, key, keyPair); err == nil {
+				return keyPair.Status.Ready
+			}
+
+			return false
+		}, timeout).WithPolling(pollInterval).Should(BeTrue())
+
+		secret := &corev1.Secret{}
+		key := client.ObjectKey{Namespace: dummies.ClusterNameSpace, Name: dummies.CSSSHKeyPair.PrivateKeySecretName()}
+		Ω(k8sClient.Get(ctx, key, secret)).Should(Succeed())
+		Ω(secret.Data[corev1.SSHAuthPrivateKey]).Should(Equal([]byte("private")))
+	})
+
+	It("Should regenerate a keypair whose private key secret is missing.", func() {
+		gomock.InOrder(
+			// The keypair already exists in CloudStack, so no private key comes back.
+			mockCloudClient.EXPECT().GetOrCreateSSHKeyPair(gomock.Any()).Do(func(arg1 interface{}) {
+				arg1.(*cloud.SSHKeyPair).ID = "kp-id"
+				arg1.(*cloud.SSHKeyPair).Fingerprint = "fp"
+			}).Return(nil),
+			mockCloudClient.EXPECT().DeleteSSHKeyPair(gomock.Any()).Return(nil),
+			mockCloudClient.EXPECT().GetOrCreateSSHKeyPair(gomock.Any()).Do(func(arg1 interface{}) {
+				arg1.(*cloud.SSHKeyPair).ID = "new-kp-id"
+				arg1.(*cloud.SSHKeyPair).Fingerprint = "new-fp"
+				arg1.(*cloud.SSHKeyPair).PrivateKey = "regenerated"
+			}).AnyTimes().Return(nil),
+		)
+
+		Ω(k8sClient.Create(ctx, dummies.CSFailureDomain1)).Should(Succeed())
+		Ω(k8sClient.Create(ctx, dummies.CSSSHKeyPair)).Should(Succeed())
+
+		Eventually(func() []byte {
+			secret := &corev1.Secret{}
+			key := client.ObjectKey{Namespace: dummies.ClusterNameSpace, Name: dummies.CSSSHKeyPair.PrivateKeySecretName()}
+			if err := k8sClient.Get(ctx, key, secret); err != nil {
+				return nil
+			}
+
+			return secret.Data[corev1.SSHAuthPrivateKey]
+		}, timeout).WithPolling(pollInterval).Should(Equal([]byte("regenerated")))
+	})
+
+	It("Should keep the finalizer while the keypair can't be looked up.", func() {
+		mockCloudClient.EXPECT().GetOrCreateSSHKeyPair(gomock.Any()).Do(func(arg1 interface{}) {
+			arg1.(*cloud.SSHKeyPair).ID = "kp-id"
+			arg1.(*cloud.SSHKeyPair).Fingerprint = "fp"
+			arg1.(*cloud.SSHKeyPair).PrivateKey = "private"
+		}).AnyTimes().Return(nil)
+		mockCloudClient.EXPECT().FetchSSHKeyPair(gomock.Any()).AnyTimes().Return(errors.New("api down"))
+
+		Ω(k8sClient.Create(ctx, dummies.CSFailureDomain1)).Should(Succeed())
+		Ω(k8sClient.Create(ctx, dummies.CSSSHKeyPair)).Should(Succeed())
+		key := client.ObjectKey{Namespace: dummies.ClusterNameSpace, Name: dummies.CSSSHKeyPair.Name}
+		Eventually(func() []string {
+			keyPair := &infrav1.CloudStackSSHKeyPair{}
+			if err := k8sClient.Get(ctx, key, keyPair); err != nil {
+				return nil
+			}
+
+			return keyPair.Finalizers
+		}, timeout).WithPolling(pollInterval).Should(ContainElement(infrav1.SSHKeyPairFinalizer))
+
+		Ω(k8sClient.Delete(ctx, dummies.CSSSHKeyPair)).Should(Succeed())
+		Consistently(func() error {
+			return k8sClient.Get(ctx, key, &infrav1.CloudStackSSHKeyPair{})
+		}, timeout).WithPolling(pollInterval).Should(Succeed())
+	})
+
+	It("Should leave a keypair with the name alone on deletion when it's not the one it created.", func() {
+		mockCloudClient.EXPECT().GetOrCreateSSHKeyPair(gomock.Any()).Do(func(arg1 interface{}) {
+			arg1.(*cloud.SSHKeyPair).ID = "kp-id"
+			arg1.(*cloud.SSHKeyPair).Fingerprint = "fp"
+			arg1.(*cloud.SSHKeyPair).PrivateKey = "private"
+		}).AnyTimes().Return(nil)
+		// The keypair was replaced outside of CAPC since.
+		mockCloudClient.EXPECT().FetchSSHKeyPair(gomock.Any()).Do(func(arg1 interface{}) {
+			arg1.(*cloud.SSHKeyPair).ID = "other-kp-id"
+			arg1.(*cloud.SSHKeyPair).Fingerprint = "other-fp"
+		}).AnyTimes().Return(nil)
+		mockCloudClient.EXPECT().DeleteSSHKeyPair(gomock.Any()).Times(0)
+
+		Ω(k8sClient.Create(ctx, dummies.CSFailureDomain1)).Should(Succeed())
+		Ω(k8sClient.Create(ctx, dummies.CSSSHKeyPair)).Should(Succeed())
+		key := client.ObjectKey{Namespace: dummies.ClusterNameSpace, Name: dummies.CSSSHKeyPair.Name}
+		Eventually(func() string {
+			keyPair := &infrav1.CloudStackSSHKeyPair{}
+			if err := k8sClient.Get(ctx, key, keyPair); err != nil {
+				return ""
+			}
+
+			return keyPair.Status.Fingerprint
+		}, timeout).WithPolling(pollInterval).Should(Equal("fp"))
+
+		Ω(k8sClient.Delete(ctx, dummies.CSSSHKeyPair)).Should(Succeed())
+		Eventually(func() bool {
+			return apierrors.IsNotFound(k8sClient.Get(ctx, key, &infrav1.CloudStackSSHKeyPair{}))
+		}, timeout).WithPolling(pollInterval).Should(BeTrue())
+	})
+})
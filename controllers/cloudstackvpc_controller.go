@@ -0,0 +1,213 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/cluster-api/util/annotations"
+	"sigs.k8s.io/cluster-api/util/patch"
+	"sigs.k8s.io/cluster-api/util/predicates"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
+	csCtrlrUtils "sigs.k8s.io/cluster-api-provider-cloudstack/controllers/utils"
+	"sigs.k8s.io/cluster-api-provider-cloudstack/pkg/cloud"
+)
+
+//+kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=cloudstackvpcs,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=cloudstackvpcs/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=cloudstackvpcs/finalizers,verbs=update
+
+// CloudStackVPCReconciler reconciles a CloudStackVPC object.
+type CloudStackVPCReconciler struct {
+	csCtrlrUtils.ReconcilerBase
+}
+
+// CloudStackVPCReconciliationRunner is a ReconciliationRunner with extensions specific to CloudStack VPC reconciliation.
+type CloudStackVPCReconciliationRunner struct {
+	*csCtrlrUtils.ReconciliationRunner
+	FailureDomain         *infrav1.CloudStackFailureDomain
+	ReconciliationSubject *infrav1.CloudStackVPC
+}
+
+// Initialize a new CloudStackVPC reconciliation runner with concrete types and initialized member fields.
+func NewCSVPCReconciliationRunner() *CloudStackVPCReconciliationRunner {
+	// Set concrete type and init pointers.
+	r := &CloudStackVPCReconciliationRunner{ReconciliationSubject: &infrav1.CloudStackVPC{}}
+	r.FailureDomain = &infrav1.CloudStackFailureDomain{}
+	// Set up the base runner. Initializes pointers and links reconciliation methods.
+	r.ReconciliationRunner = csCtrlrUtils.NewRunner(r, r.ReconciliationSubject, "CloudStackVPC")
+
+	return r
+}
+
+func (reconciler *CloudStackVPCReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	r := NewCSVPCReconciliationRunner()
+	r.UsingBaseReconciler(reconciler.ReconcilerBase).ForRequest(req).WithRequestCtx(ctx)
+	r.WithAdditionalCommonStages(
+		r.GetFailureDomainByName(func() string { return r.ReconciliationSubject.Spec.FailureDomainName }, r.FailureDomain),
+		r.AsFailureDomainUser(&r.FailureDomain.Spec),
+	)
+
+	return r.RunBaseReconciliationStages()
+}
+
+// Reconcile sets up the VPC, its tiers and their network ACL lists, and the API server load balancer on a public IP of
+// the VPC. The CloudStackCluster endpoint is set to that IP if it isn't set yet.
+func (r *CloudStackVPCReconciliationRunner) Reconcile() (ctrl.Result, error) {
+	controllerutil.AddFinalizer(r.ReconciliationSubject, infrav1.VPCFinalizer)
+
+	csClusterPatcher, err := patch.NewHelper(r.CSCluster, r.K8sClient)
+	if err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "setting up CloudStackCluster patcher")
+	}
+	if r.FailureDomain.Spec.Zone.ID == "" {
+		return r.RequeueWithMessage("Zone ID not resolved yet.")
+	}
+
+	vpc := r.ReconciliationSubject
+	if err := r.CSUser.GetOrCreateVPC(r.FailureDomain, vpc); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := r.CSUser.AddClusterTag(cloud.ResourceTypeVPC, vpc.Spec.ID, r.CSCluster); err != nil {
+		return ctrl.Result{}, errors.Wrapf(err, "tagging VPC with id %s", vpc.Spec.ID)
+	}
+	controlPlaneTier := vpc.Spec.ControlPlaneTier
+	if len(controlPlaneTier.ACL) == 0 {
+		controlPlaneTier.ACL = cloud.ControlPlaneTierACL(vpc, r.CSCluster)
+	}
+	if err := r.CSUser.GetOrCreateVPCTier(r.FailureDomain, vpc, controlPlaneTier, &vpc.Status.ControlPlaneTier); err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "setting up control plane tier")
+	}
+	if err := r.CSUser.GetOrCreateVPCTier(r.FailureDomain, vpc, vpc.Spec.WorkerTier, &vpc.Status.WorkerTier); err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "setting up worker tier")
+	}
+	for _, tierID := range []string{vpc.Status.ControlPlaneTier.ID, vpc.Status.WorkerTier.ID} {
+		if err := r.CSUser.AddClusterTag(cloud.ResourceTypeNetwork, tierID, r.CSCluster); err != nil {
+			return ctrl.Result{}, errors.Wrapf(err, "tagging tier with id %s", tierID)
+		}
+	}
+
+	if !annotations.IsExternallyManaged(r.CSCluster) {
+		if res, err := r.ReconcileLoadBalancer(); r.ShouldReturn(res, err) {
+			return res, err
+		}
+	}
+
+	if err := csClusterPatcher.Patch(r.RequestCtx, r.CSCluster); err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "patching endpoint update to CloudStackCluster")
+	}
+
+	vpc.Status.Ready = true
+
+	return ctrl.Result{}, nil
+}
+
+// ReconcileLoadBalancer associates a public IP with the VPC to serve as the control plane endpoint and forwards the API
+// server ports on it to the control plane tier. Without an APIServerLoadBalancer, the endpoint is left to the user and
+// a previously associated IP is released.
+func (r *CloudStackVPCReconciliationRunner) ReconcileLoadBalancer() (ctrl.Result, error) {
+	vpc := r.ReconciliationSubject
+	if !r.CSCluster.Spec.APIServerLoadBalancer.IsEnabled() {
+		if vpc.Status.APIServerLoadBalancer == nil {
+			return ctrl.Result{}, nil
+		}
+		if err := r.CSUser.ReconcileVPCLoadBalancerRules(vpc, r.CSCluster); err != nil {
+			return ctrl.Result{}, errors.Wrap(err, "removing load balancer rules")
+		}
+		if err := r.CSUser.ReleaseVPCPublicIPAddress(vpc, r.CSCluster); err != nil {
+			return ctrl.Result{}, errors.Wrap(err, "releasing public IP address")
+		}
+		vpc.Status.APIServerLoadBalancer = nil
+
+		return ctrl.Result{}, nil
+	}
+	if r.CSCluster.Spec.ControlPlaneEndpoint.Port == 0 {
+		r.CSCluster.Spec.ControlPlaneEndpoint.Port = cloud.K8sDefaultAPIPort
+	}
+	vpc.Spec.ControlPlaneEndpoint.Port = r.CSCluster.Spec.ControlPlaneEndpoint.Port
+
+	pubIP, err := r.CSUser.AssociateVPCPublicIPAddress(r.FailureDomain, vpc, r.CSCluster.Spec.ControlPlaneEndpoint.Host)
+	if err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "failed to associate public IP address")
+	}
+	vpc.Spec.ControlPlaneEndpoint.Host = pubIP.Ipaddress
+	r.CSCluster.Spec.ControlPlaneEndpoint.Host = pubIP.Ipaddress
+	if vpc.Status.APIServerLoadBalancer == nil {
+		vpc.Status.APIServerLoadBalancer = &infrav1.LoadBalancer{}
+	}
+	vpc.Status.APIServerLoadBalancer.IPAddressID = pubIP.Id
+	vpc.Status.APIServerLoadBalancer.IPAddress = pubIP.Ipaddress
+	if err := r.CSUser.AddClusterTag(cloud.ResourceTypeIPAddress, pubIP.Id, r.CSCluster); err != nil {
+		return ctrl.Result{}, errors.Wrapf(err, "adding cluster tag to public IP address with ID %s", pubIP.Id)
+	}
+
+	if err := r.CSUser.ReconcileVPCLoadBalancerRules(vpc, r.CSCluster); err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "reconciling load balancer rules")
+	}
+
+	return ctrl.Result{}, nil
+}
+
+func (r *CloudStackVPCReconciliationRunner) ReconcileDelete() (ctrl.Result, error) {
+	r.Log.Info("Deleting VPC.")
+	if err := r.CSUser.DisposeVPCResources(r.ReconciliationSubject, r.CSCluster); err != nil {
+		if !strings.Contains(strings.ToLower(err.Error()), "no match found") {
+			return ctrl.Result{}, err
+		}
+	}
+	controllerutil.RemoveFinalizer(r.ReconciliationSubject, infrav1.VPCFinalizer)
+
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (reconciler *CloudStackVPCReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager) error {
+	err := ctrl.NewControllerManagedBy(mgr).
+		For(&infrav1.CloudStackVPC{}).
+		Watches(
+			&infrav1.CloudStackCluster{},
+			handler.EnqueueRequestsFromMapFunc(csCtrlrUtils.CloudStackClusterToCloudStackVPCs(reconciler.K8sClient, ctrl.LoggerFrom(ctx))),
+			builder.WithPredicates(
+				predicate.GenerationChangedPredicate{},
+				predicate.Funcs{
+					UpdateFunc: func(e event.UpdateEvent) bool {
+						oldCSCluster := e.ObjectOld.(*infrav1.CloudStackCluster)
+						newCSCluster := e.ObjectNew.(*infrav1.CloudStackCluster)
+
+						return !reflect.DeepEqual(oldCSCluster.Spec.APIServerLoadBalancer, newCSCluster.Spec.APIServerLoadBalancer)
+					},
+				},
+			),
+		).
+		WithEventFilter(predicates.ResourceNotPausedAndHasFilterLabel(ctrl.LoggerFrom(ctx), reconciler.WatchFilterValue)).
+		Complete(reconciler)
+	if err != nil {
+		return errors.Wrap(err, "failed setting up with a controller manager")
+	}
+
+	return nil
+}
@@ -125,6 +125,7 @@ var (
 	FailureDomainReconciler *csReconcilers.CloudStackFailureDomainReconciler
 	IsoNetReconciler        *csReconcilers.CloudStackIsoNetReconciler
 	AffinityGReconciler     *csReconcilers.CloudStackAffinityGroupReconciler
+	SSHKeyPairReconciler    *csReconcilers.CloudStackSSHKeyPairReconciler
 )
 
 var _ = BeforeSuite(func() {
@@ -212,6 +213,7 @@ func SetupTestEnvironment() {
 	FailureDomainReconciler = &csReconcilers.CloudStackFailureDomainReconciler{ReconcilerBase: base}
 	IsoNetReconciler = &csReconcilers.CloudStackIsoNetReconciler{ReconcilerBase: base}
 	AffinityGReconciler = &csReconcilers.CloudStackAffinityGroupReconciler{ReconcilerBase: base}
+	SSHKeyPairReconciler = &csReconcilers.CloudStackSSHKeyPairReconciler{ReconcilerBase: base}
 
 	ctx, cancel = context.WithCancel(context.TODO())
 
@@ -224,6 +226,7 @@ func SetupTestEnvironment() {
 	IsoNetReconciler.CSClient = mockCloudClient
 	MachineReconciler.CSClient = mockCloudClient
 	AffinityGReconciler.CSClient = mockCloudClient
+	SSHKeyPairReconciler.CSClient = mockCloudClient
 	FailureDomainReconciler.CSClient = mockCloudClient
 
 	setupClusterCRDs()
@@ -267,6 +270,7 @@ func setupFakeTestClient() {
 	FailureDomainReconciler = &csReconcilers.CloudStackFailureDomainReconciler{ReconcilerBase: base}
 	IsoNetReconciler = &csReconcilers.CloudStackIsoNetReconciler{ReconcilerBase: base}
 	AffinityGReconciler = &csReconcilers.CloudStackAffinityGroupReconciler{ReconcilerBase: base}
+	SSHKeyPairReconciler = &csReconcilers.CloudStackSSHKeyPairReconciler{ReconcilerBase: base}
 
 	// Set on reconcilers. The mock client wasn't available at suite startup, so set it now.
 	ClusterReconciler.CSClient = mockCloudClient
@@ -274,6 +278,7 @@ func setupFakeTestClient() {
 	MachineReconciler.CSClient = mockCloudClient
 	FailureDomainReconciler.CSClient = mockCloudClient
 	AffinityGReconciler.CSClient = mockCloudClient
+	SSHKeyPairReconciler.CSClient = mockCloudClient
 
 	DeferCleanup(func() {
 		cancel()
@@ -68,6 +68,7 @@ func (r *ReconciliationRunner) GetOrCreateAffinityGroup(
 		ag.Name = name
 		ag.Spec.Name = name
 		ag.ObjectMeta = r.NewChildObjectMeta(lowerName)
+		ag.Labels[infrav1.FailureDomainLabelName] = fd.Name
 
 		// Replace owner reference with controller of CAPI and CloudStack machines and FailureDomain.
 		for _, ref := range r.ReconciliationSubject.GetOwnerReferences() {
@@ -347,6 +347,16 @@ func (r *ReconciliationRunner) SetupPatcher() (ctrl.Result, error) {
 	return ctrl.Result{}, errors.Wrapf(err, "setting up patcher")
 }
 
+// PatchReconciliationSubject patches the changes to the ReconciliationSubject back right away instead of at the end of
+// the reconciliation, for records that must survive the failure of a later stage.
+func (r *ReconciliationRunner) PatchReconciliationSubject() (ctrl.Result, error) {
+	if err := r.Patcher.Patch(r.RequestCtx, r.ReconciliationSubject); err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "patching reconciliation subject")
+	}
+
+	return ctrl.Result{}, nil
+}
+
 // RequeueWithMessage is a convenience method to log requeue message and then return a result with RequeueAfter set.
 func (r *ReconciliationRunner) RequeueWithMessage(msg string, keysAndValues ...interface{}) (ctrl.Result, error) {
 	// Add requeuing to message if not present. Might turn this into a lint check later.
@@ -19,6 +19,8 @@ package utils
 import "time"
 
 const (
-	RequeueTimeout           = 5 * time.Second
-	DestroyVMRequeueInterval = 10 * time.Second
+	RequeueTimeout            = 5 * time.Second
+	DestroyVMRequeueInterval  = 10 * time.Second
+	CapacityReportInterval    = 5 * time.Minute
+	AvailabilityCheckInterval = time.Minute
 )
@@ -18,6 +18,7 @@ package utils
 
 import (
 	"fmt"
+	"reflect"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -32,21 +33,43 @@ import (
 )
 
 // CreateFailureDomain creates a specified CloudStackFailureDomain CRD owned by the ReconcilationSubject.
-func (r *ReconciliationRunner) CreateFailureDomain(fdSpec infrav1.CloudStackFailureDomainSpec) error {
-	metaHashName := infrav1.FailureDomainHashedMetaName(fdSpec.Name, r.CAPICluster.Name)
+func (r *ReconciliationRunner) CreateFailureDomain(fdSpec infrav1.CloudStackFailureDomainSpec, metaName string) error {
 	csFD := &infrav1.CloudStackFailureDomain{
-		ObjectMeta: r.NewChildObjectMeta(metaHashName),
+		ObjectMeta: r.NewChildObjectMeta(metaName),
 		Spec:       fdSpec,
 	}
+	csFD.Annotations = map[string]string{infrav1.FailureDomainSpecHashAnnotation: infrav1.FailureDomainSpecHash(fdSpec)}
 
 	return errors.Wrap(r.K8sClient.Create(r.RequestCtx, csFD), "creating CloudStackFailureDomain")
 }
 
-// CreateFailureDomains creates a CloudStackFailureDomain CRD for each of the ReconcilationSubject's FailureDomains.
+// CreateFailureDomains creates a CloudStackFailureDomain CRD for each of the ReconcilationSubject's FailureDomains
+// that lacks one for its current spec. A changed spec gets a new generation of the failure domain, leaving the old
+// one to be retired by RemoveExtraneousFailureDomains. Fields that can change in place are updated instead.
 func (r *ReconciliationRunner) CreateFailureDomains(fdSpecs []infrav1.CloudStackFailureDomainSpec) CloudStackReconcilerMethod {
 	return func() (ctrl.Result, error) {
+		fds := &infrav1.CloudStackFailureDomainList{}
+		if res, err := r.GetFailureDomains(fds)(); r.ShouldReturn(res, err) {
+			return res, err
+		}
 		for _, fdSpec := range fdSpecs {
-			if err := r.CreateFailureDomain(fdSpec); err != nil {
+			if fd := CurrentFailureDomainGeneration(fds, fdSpec); fd != nil {
+				if err := r.updateFailureDomainInPlace(fd, fdSpec); err != nil {
+					return ctrl.Result{}, err
+				}
+
+				continue
+			}
+			metaName := infrav1.FailureDomainHashedMetaName(fdSpec.Name, r.CAPICluster.Name)
+			for _, fd := range fds.Items {
+				if fd.Name == metaName {
+					metaName = infrav1.FailureDomainGenerationMetaName(fdSpec, r.CAPICluster.Name)
+					r.Log.Info("Starting a new generation of changed failure domain.", "failureDomain", fdSpec.Name, "name", metaName)
+
+					break
+				}
+			}
+			if err := r.CreateFailureDomain(fdSpec, metaName); err != nil {
 				if !strings.Contains(strings.ToLower(err.Error()), "already exists") {
 					return reconcile.Result{}, errors.Wrap(err, "creating CloudStackFailureDomains")
 				}
@@ -57,6 +80,68 @@ func (r *ReconciliationRunner) CreateFailureDomains(fdSpecs []infrav1.CloudStack
 	}
 }
 
+// updateFailureDomainInPlace applies changes to the fields of a failure domain spec that don't start a new generation.
+// Failure domains created before generations were tracked are adopted as the generation of the current spec.
+func (r *ReconciliationRunner) updateFailureDomainInPlace(fd *infrav1.CloudStackFailureDomain, fdSpec infrav1.CloudStackFailureDomainSpec) error {
+	specHash := infrav1.FailureDomainSpecHash(fdSpec)
+	if fd.Annotations[infrav1.FailureDomainSpecHashAnnotation] == specHash &&
+		reflect.DeepEqual(fd.Spec.ControlPlane, fdSpec.ControlPlane) && reflect.DeepEqual(fd.Spec.Weight, fdSpec.Weight) &&
+		reflect.DeepEqual(fd.Spec.Zone.Network.EgressRules, fdSpec.Zone.Network.EgressRules) &&
+		reflect.DeepEqual(vpcTierACLs(fd.Spec.Zone.Network), vpcTierACLs(fdSpec.Zone.Network)) {
+		return nil
+	}
+	if fd.Annotations == nil {
+		fd.Annotations = map[string]string{}
+	}
+	fd.Annotations[infrav1.FailureDomainSpecHashAnnotation] = specHash
+	fd.Spec.ControlPlane, fd.Spec.Weight = fdSpec.ControlPlane, fdSpec.Weight
+	fd.Spec.Zone.Network.EgressRules = fdSpec.Zone.Network.EgressRules
+	if fd.Spec.Zone.Network.VPC != nil && fdSpec.Zone.Network.VPC != nil {
+		fd.Spec.Zone.Network.VPC.ControlPlaneTier.ACL = fdSpec.Zone.Network.VPC.ControlPlaneTier.ACL
+		fd.Spec.Zone.Network.VPC.WorkerTier.ACL = fdSpec.Zone.Network.VPC.WorkerTier.ACL
+	}
+
+	return errors.Wrapf(r.K8sClient.Update(r.RequestCtx, fd), "updating CloudStackFailureDomain %s", fd.Name)
+}
+
+// vpcTierACLs returns the network ACL rules of the control plane and worker tiers of a VPC network, nil for other
+// networks.
+func vpcTierACLs(network infrav1.Network) [][]infrav1.NetworkACLRule {
+	if network.VPC == nil {
+		return nil
+	}
+
+	return [][]infrav1.NetworkACLRule{network.VPC.ControlPlaneTier.ACL, network.VPC.WorkerTier.ACL}
+}
+
+// CurrentFailureDomainGeneration returns the CloudStackFailureDomain that isn't being deleted and was created for the
+// failure domain spec, or nil if there's none yet. A CloudStackFailureDomain without a spec hash predates generations,
+// and so is the only one there is for the failure domain.
+func CurrentFailureDomainGeneration(fds *infrav1.CloudStackFailureDomainList, fdSpec infrav1.CloudStackFailureDomainSpec) *infrav1.CloudStackFailureDomain {
+	specHash := infrav1.FailureDomainSpecHash(fdSpec)
+	for idx := range fds.Items {
+		fd := &fds.Items[idx]
+		if fd.Spec.Name != fdSpec.Name || !fd.DeletionTimestamp.IsZero() {
+			continue
+		}
+		if hash, found := fd.Annotations[infrav1.FailureDomainSpecHashAnnotation]; !found || hash == specHash {
+			return fd
+		}
+	}
+
+	return nil
+}
+
+// FailureDomainMetaName returns the name of the current generation of the named failure domain, as published in the
+// CloudStackCluster's failure domains status.
+func (r *ReconciliationRunner) FailureDomainMetaName(fdName string) string {
+	if metaName := r.CSCluster.Status.FailureDomains[fdName].Attributes["MetaHashName"]; metaName != "" {
+		return metaName
+	}
+
+	return infrav1.FailureDomainHashedMetaName(fdName, r.CAPICluster.Name)
+}
+
 // GetFailureDomains gets CloudStackFailureDomains owned by a CloudStackCluster.
 func (r *ReconciliationRunner) GetFailureDomains(fds *infrav1.CloudStackFailureDomainList) CloudStackReconcilerMethod {
 	return func() (ctrl.Result, error) {
@@ -74,11 +159,15 @@ func (r *ReconciliationRunner) GetFailureDomains(fds *infrav1.CloudStackFailureD
 	}
 }
 
-// GetFailureDomainByName gets a single FailureDomain by name and requeues if it's not found.
+// GetFailureDomainByName gets a single FailureDomain by name and requeues if it's not found. Objects labelled with a
+// failure domain, like machines, get the generation they were created in. Others get the current generation.
 func (r *ReconciliationRunner) GetFailureDomainByName(nameFunc func() string, fd *infrav1.CloudStackFailureDomain) CloudStackReconcilerMethod {
 	return func() (ctrl.Result, error) {
-		metaHashName := infrav1.FailureDomainHashedMetaName(nameFunc(), r.CAPICluster.Name)
-		if err := r.K8sClient.Get(r.RequestCtx, client.ObjectKey{Namespace: r.Request.Namespace, Name: metaHashName}, fd); err != nil {
+		metaName := r.ReconciliationSubject.GetLabels()[infrav1.FailureDomainLabelName]
+		if metaName == "" {
+			metaName = r.FailureDomainMetaName(nameFunc())
+		}
+		if err := r.K8sClient.Get(r.RequestCtx, client.ObjectKey{Namespace: r.Request.Namespace, Name: metaName}, fd); err != nil {
 			return ctrl.Result{}, errors.Wrapf(err, "failed to get failure domain with name %s", nameFunc())
 		}
 
@@ -86,24 +175,34 @@ func (r *ReconciliationRunner) GetFailureDomainByName(nameFunc func() string, fd
 	}
 }
 
-// RemoveExtraneousFailureDomains deletes failure domains no longer listed under the CloudStackCluster's spec.
+// RemoveExtraneousFailureDomains deletes failure domains no longer listed under the CloudStackCluster's spec, and old
+// generations of changed failure domains once their current generation is ready. Deleting a failure domain moves its
+// machines off it first.
 func (r *ReconciliationRunner) RemoveExtraneousFailureDomains(fds *infrav1.CloudStackFailureDomainList) CloudStackReconcilerMethod {
 	return func() (ctrl.Result, error) {
-		// Toss together a precense map.
-		fdPresenceByName := map[string]bool{}
+		// Toss together a map of the current generations.
+		currentByName := map[string]*infrav1.CloudStackFailureDomain{}
 		for _, fdSpec := range r.CSCluster.Spec.FailureDomains {
-			name := fdSpec.Name
-			fdPresenceByName[name] = true
+			currentByName[fdSpec.Name] = CurrentFailureDomainGeneration(fds, fdSpec)
 		}
 
-		// Send a deletion request for each FailureDomain not speced for.
+		// Send a deletion request for each FailureDomain not speced for, or superseded by a ready generation.
 		for _, fd := range fds.Items {
-			if _, present := fdPresenceByName[fd.Spec.Name]; !present {
-				toDelete := fd
+			if !fd.DeletionTimestamp.IsZero() {
+				continue
+			}
+			current, present := currentByName[fd.Spec.Name]
+			if present && (current == nil || current.Name == fd.Name || !current.Status.Ready) {
+				continue
+			}
+			toDelete := fd
+			if present {
+				r.Log.Info(fmt.Sprintf("Retiring old generation of failure domain %s: %s.", fd.Spec.Name, fd.Name))
+			} else {
 				r.Log.Info(fmt.Sprintf("Deleting extraneous failure domain: %s.", fd.Name))
-				if err := r.K8sClient.Delete(r.RequestCtx, &toDelete); err != nil {
-					return ctrl.Result{}, errors.Wrap(err, "failed to delete obsolete failure domain")
-				}
+			}
+			if err := r.K8sClient.Delete(r.RequestCtx, &toDelete); err != nil {
+				return ctrl.Result{}, errors.Wrap(err, "failed to delete obsolete failure domain")
 			}
 		}
 
@@ -143,22 +242,22 @@ func (c *CloudClientImplementation) RegisterExtension(r *ReconciliationRunner) C
 // AsFailureDomainUser uses the credentials specified in the failure domain to set the ReconciliationSubject's CSUser client.
 func (c *CloudClientImplementation) AsFailureDomainUser(fdSpec *infrav1.CloudStackFailureDomainSpec) CloudStackReconcilerMethod {
 	return func() (ctrl.Result, error) {
-		endpointCredentials := &corev1.Secret{}
-		key := client.ObjectKey{Name: fdSpec.ACSEndpoint.Name, Namespace: fdSpec.ACSEndpoint.Namespace}
-		if err := c.K8sClient.Get(c.RequestCtx, key, endpointCredentials); err != nil {
-			return ctrl.Result{}, errors.Wrapf(err, "getting ACSEndpoint secret with ref: %v", fdSpec.ACSEndpoint)
+		endpointCredentials, clientConfig, err := c.GetEndpointCredentials(fdSpec)
+		if err != nil {
+			return ctrl.Result{}, err
 		}
 
-		clientConfig := &corev1.ConfigMap{}
-		key = client.ObjectKey{Name: cloud.ClientConfigMapName, Namespace: cloud.ClientConfigMapNamespace}
-		_ = c.K8sClient.Get(c.RequestCtx, key, clientConfig)
-
-		var err error
 		if c.CSClient, err = cloud.NewClientFromK8sSecret(endpointCredentials, clientConfig, cloud.WithProject(fdSpec.Project)); err != nil {
 			return ctrl.Result{}, errors.Wrapf(err, "parsing ACSEndpoint secret with ref: %v", fdSpec.ACSEndpoint)
 		}
 
-		if fdSpec.Account != "" { // Set r.CSUser CloudStack Client per Account and Domain.
+		if fdSpec.ManagedTenant != nil { // Set r.CSUser CloudStack Client to the project or account CAPC manages.
+			client, err := c.GetOrCreateManagedTenantClient(fdSpec, endpointCredentials, clientConfig)
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+			c.CSUser = client
+		} else if fdSpec.Account != "" { // Set r.CSUser CloudStack Client per Account and Domain.
 			client, err := c.CSClient.NewClientInDomainAndAccount(fdSpec.Domain, fdSpec.Account, cloud.WithProject(fdSpec.Project))
 			if err != nil {
 				return ctrl.Result{}, err
@@ -171,3 +270,90 @@ func (c *CloudClientImplementation) AsFailureDomainUser(fdSpec *infrav1.CloudSta
 		return ctrl.Result{}, nil
 	}
 }
+
+// GetEndpointCredentials fetches the failure domain's ACSEndpoint secret and the optional client config map. When the
+// failure domain references a CloudStackClusterIdentity, the identity's secret is used instead, provided the identity
+// allows the namespace being reconciled. Otherwise the ACSEndpoint secret must be in the namespace being reconciled.
+func (r *ReconciliationRunner) GetEndpointCredentials(fdSpec *infrav1.CloudStackFailureDomainSpec) (*corev1.Secret, *corev1.ConfigMap, error) {
+	secretRef := fdSpec.ACSEndpoint
+	if fdSpec.IdentityRef != nil {
+		identitySecretRef, err := r.GetIdentitySecretRef(fdSpec.IdentityRef)
+		if err != nil {
+			return nil, nil, err
+		}
+		secretRef = identitySecretRef
+	} else if secretRef.Namespace != r.Request.Namespace {
+		return nil, nil, errors.Errorf(
+			"ACSEndpoint secret %s/%s must be in namespace %s, use identityRef to share credentials across namespaces",
+			secretRef.Namespace, secretRef.Name, r.Request.Namespace)
+	}
+
+	endpointCredentials := &corev1.Secret{}
+	key := client.ObjectKey{Name: secretRef.Name, Namespace: secretRef.Namespace}
+	if err := r.K8sClient.Get(r.RequestCtx, key, endpointCredentials); err != nil {
+		return nil, nil, errors.Wrapf(err, "getting ACSEndpoint secret with ref: %v", secretRef)
+	}
+	if err := r.inlineTLSSecretRefs(endpointCredentials); err != nil {
+		return nil, nil, err
+	}
+
+	clientConfig := &corev1.ConfigMap{}
+	key = client.ObjectKey{Name: cloud.ClientConfigMapName, Namespace: cloud.ClientConfigMapNamespace}
+	_ = r.K8sClient.Get(r.RequestCtx, key, clientConfig)
+
+	return endpointCredentials, clientConfig, nil
+}
+
+// GetIdentitySecretRef fetches the referenced CloudStackClusterIdentity and returns its secret reference if the identity
+// allows the namespace being reconciled.
+func (r *ReconciliationRunner) GetIdentitySecretRef(ref *infrav1.CloudStackClusterIdentityReference) (corev1.SecretReference, error) {
+	identity := &infrav1.CloudStackClusterIdentity{}
+	if err := r.K8sClient.Get(r.RequestCtx, client.ObjectKey{Name: ref.Name}, identity); err != nil {
+		return corev1.SecretReference{}, errors.Wrapf(err, "getting CloudStackClusterIdentity %s", ref.Name)
+	}
+
+	namespace := &corev1.Namespace{}
+	if err := r.K8sClient.Get(r.RequestCtx, client.ObjectKey{Name: r.Request.Namespace}, namespace); err != nil {
+		return corev1.SecretReference{}, errors.Wrapf(err, "getting namespace %s", r.Request.Namespace)
+	}
+	allowed, err := identity.AllowsNamespace(namespace)
+	if err != nil {
+		return corev1.SecretReference{}, errors.Wrapf(err, "evaluating allowed namespaces of CloudStackClusterIdentity %s", ref.Name)
+	} else if !allowed {
+		return corev1.SecretReference{}, errors.Errorf(
+			"CloudStackClusterIdentity %s does not allow use from namespace %s", ref.Name, r.Request.Namespace)
+	}
+
+	return identity.Spec.SecretRef, nil
+}
+
+// inlineTLSSecretRefs copies the CA bundle and client certificate referenced by the endpoint secret into it, so that
+// they become part of the client config. Referenced secrets must be in the endpoint secret's namespace. The CA secret
+// is referenced as <name> or <name>/<key>, defaulting to the ca.crt key, and the client certificate as the name of a
+// kubernetes.io/tls secret.
+func (r *ReconciliationRunner) inlineTLSSecretRefs(endpointCredentials *corev1.Secret) error {
+	if ref := string(endpointCredentials.Data[cloud.CACertSecretRefKey]); ref != "" {
+		name, key, found := strings.Cut(ref, "/")
+		if !found {
+			key = "ca.crt"
+		}
+		caSecret := &corev1.Secret{}
+		if err := r.K8sClient.Get(r.RequestCtx, client.ObjectKey{Name: name, Namespace: endpointCredentials.Namespace}, caSecret); err != nil {
+			return errors.Wrapf(err, "getting CA secret %s", name)
+		} else if len(caSecret.Data[key]) == 0 {
+			return errors.Errorf("CA secret %s has no data under key %s", name, key)
+		}
+		endpointCredentials.Data[cloud.CACertKey] = caSecret.Data[key]
+	}
+
+	if name := string(endpointCredentials.Data[cloud.ClientCertSecretRefKey]); name != "" {
+		certSecret := &corev1.Secret{}
+		if err := r.K8sClient.Get(r.RequestCtx, client.ObjectKey{Name: name, Namespace: endpointCredentials.Namespace}, certSecret); err != nil {
+			return errors.Wrapf(err, "getting client certificate secret %s", name)
+		}
+		endpointCredentials.Data[cloud.ClientCertKey] = certSecret.Data[corev1.TLSCertKey]
+		endpointCredentials.Data[cloud.ClientKeyKey] = certSecret.Data[corev1.TLSPrivateKeyKey]
+	}
+
+	return nil
+}
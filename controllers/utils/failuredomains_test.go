@@ -0,0 +1,160 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
+	csCtrlrUtils "sigs.k8s.io/cluster-api-provider-cloudstack/controllers/utils"
+	"sigs.k8s.io/cluster-api-provider-cloudstack/pkg/cloud"
+	"sigs.k8s.io/cluster-api-provider-cloudstack/test/helpers"
+)
+
+func TestGetEndpointCredentialsInlinesTLSSecretRefs(t *testing.T) {
+	caCert, _, err := helpers.SelfSignedCertificate("endpoint-ca")
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientCert, clientKey, err := helpers.SelfSignedCertificate("capc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	caSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "endpoint-ca"},
+		Data:       map[string][]byte{"ca.crt": caCert, "bundle.pem": caCert},
+	}
+	certSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "capc-client"},
+		Type:       corev1.SecretTypeTLS,
+		Data:       map[string][]byte{corev1.TLSCertKey: clientCert, corev1.TLSPrivateKeyKey: clientKey},
+	}
+
+	tests := []struct {
+		name       string
+		refs       map[string]string
+		wantData   map[string][]byte
+		wantErr    string
+		noCASecret bool
+	}{
+		{
+			name:     "CA secret with the default key",
+			refs:     map[string]string{cloud.CACertSecretRefKey: "endpoint-ca"},
+			wantData: map[string][]byte{cloud.CACertKey: caCert},
+		},
+		{
+			name:     "CA secret with an explicit key",
+			refs:     map[string]string{cloud.CACertSecretRefKey: "endpoint-ca/bundle.pem"},
+			wantData: map[string][]byte{cloud.CACertKey: caCert},
+		},
+		{
+			name:     "Client certificate secret",
+			refs:     map[string]string{cloud.ClientCertSecretRefKey: "capc-client"},
+			wantData: map[string][]byte{cloud.ClientCertKey: clientCert, cloud.ClientKeyKey: clientKey},
+		},
+		{
+			name:    "CA secret without the referenced key",
+			refs:    map[string]string{cloud.CACertSecretRefKey: "endpoint-ca/missing.pem"},
+			wantErr: "CA secret endpoint-ca has no data under key missing.pem",
+		},
+		{
+			name:       "Missing CA secret",
+			refs:       map[string]string{cloud.CACertSecretRefKey: "endpoint-ca"},
+			wantErr:    "getting CA secret endpoint-ca",
+			noCASecret: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			endpointSecret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "endpoint"},
+				Data:       map[string][]byte{"api-url": []byte("https://cloudstack.example.com/client/api")},
+			}
+			for key, ref := range tt.refs {
+				endpointSecret.Data[key] = []byte(ref)
+			}
+			objects := []client.Object{endpointSecret, certSecret}
+			if !tt.noCASecret {
+				objects = append(objects, caSecret)
+			}
+
+			r := &csCtrlrUtils.ReconciliationRunner{
+				ReconcilerBase: &csCtrlrUtils.ReconcilerBase{
+					K8sClient: fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(objects...).Build(),
+				},
+				CloudStackBaseContext: csCtrlrUtils.CloudStackBaseContext{
+					RequestCtx: context.Background(),
+					Request:    ctrl.Request{NamespacedName: client.ObjectKey{Namespace: "default", Name: "cluster"}},
+				},
+			}
+			fdSpec := &infrav1.CloudStackFailureDomainSpec{
+				ACSEndpoint: corev1.SecretReference{Namespace: "default", Name: "endpoint"},
+			}
+			got, _, err := r.GetEndpointCredentials(fdSpec)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("GetEndpointCredentials() error = %v, want %q", err, tt.wantErr)
+				}
+
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetEndpointCredentials() unexpected error = %v", err)
+			}
+			for key, want := range tt.wantData {
+				if !bytes.Equal(got.Data[key], want) {
+					t.Errorf("GetEndpointCredentials() data[%s] = %q, want %q", key, got.Data[key], want)
+				}
+			}
+		})
+	}
+}
+
+func TestGetEndpointCredentialsRejectsSecretInAnotherNamespace(t *testing.T) {
+	endpointSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "capc-system", Name: "endpoint"},
+		Data:       map[string][]byte{"api-url": []byte("https://cloudstack.example.com/client/api")},
+	}
+	r := &csCtrlrUtils.ReconciliationRunner{
+		ReconcilerBase: &csCtrlrUtils.ReconcilerBase{
+			K8sClient: fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(endpointSecret).Build(),
+		},
+		CloudStackBaseContext: csCtrlrUtils.CloudStackBaseContext{
+			RequestCtx: context.Background(),
+			Request:    ctrl.Request{NamespacedName: client.ObjectKey{Namespace: "default", Name: "cluster"}},
+		},
+	}
+	fdSpec := &infrav1.CloudStackFailureDomainSpec{
+		ACSEndpoint: corev1.SecretReference{Namespace: "capc-system", Name: "endpoint"},
+	}
+
+	_, _, err := r.GetEndpointCredentials(fdSpec)
+	if err == nil || !strings.Contains(err.Error(), "must be in namespace default") {
+		t.Errorf("GetEndpointCredentials() error = %v, want a namespace error", err)
+	}
+}
@@ -18,6 +18,7 @@ package utils
 
 import (
 	"fmt"
+	"reflect"
 	"regexp"
 	"strings"
 
@@ -46,9 +47,14 @@ func (r *ReconciliationRunner) GenerateIsolatedNetwork(network infrav1.Network,
 		if network.CIDR != "" {
 			csIsoNet.Spec.CIDR = network.CIDR
 		}
+		csIsoNet.Spec.IPv6CIDR = network.IPv6CIDR
 		if network.Domain != "" {
 			csIsoNet.Spec.Domain = strings.ToLower(network.Domain)
 		}
+		if network.Offering != nil {
+			csIsoNet.Spec.Offering = network.Offering.DeepCopy()
+		}
+		csIsoNet.Spec.EgressRules = network.EgressRules
 		csIsoNet.Spec.ControlPlaneEndpoint.Host = r.CSCluster.Spec.ControlPlaneEndpoint.Host
 		csIsoNet.Spec.ControlPlaneEndpoint.Port = r.CSCluster.Spec.ControlPlaneEndpoint.Port
 
@@ -59,3 +65,19 @@ func (r *ReconciliationRunner) GenerateIsolatedNetwork(network infrav1.Network,
 		return ctrl.Result{}, nil
 	}
 }
+
+// UpdateIsolatedNetworkEgressRules updates the egress rules of an existing CloudStackIsolatedNetwork to the ones of the
+// network, as they can change after the CloudStackIsolatedNetwork is generated.
+func (r *ReconciliationRunner) UpdateIsolatedNetworkEgressRules(network infrav1.Network, csIsoNet *infrav1.CloudStackIsolatedNetwork) CloudStackReconcilerMethod {
+	return func() (ctrl.Result, error) {
+		if reflect.DeepEqual(csIsoNet.Spec.EgressRules, network.EgressRules) {
+			return ctrl.Result{}, nil
+		}
+		csIsoNet.Spec.EgressRules = network.EgressRules
+		if err := r.K8sClient.Update(r.RequestCtx, csIsoNet); err != nil {
+			return r.ReturnWrappedError(err, "updating egress rules of isolated network CRD")
+		}
+
+		return ctrl.Result{}, nil
+	}
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
+)
+
+// ReadyMachineInstanceIDs returns the instance IDs of the ready CloudStackMachines of the cluster's machines that match
+// the selector and whose failure domain is accepted by inFailureDomain.
+func (r *ReconciliationRunner) ReadyMachineInstanceIDs(selector labels.Selector, inFailureDomain func(string) bool) ([]string, error) {
+	csMachines, err := r.ReadyCloudStackMachines(selector, inFailureDomain)
+	if err != nil {
+		return nil, err
+	}
+
+	instanceIDs := []string{}
+	for _, csMachine := range csMachines {
+		instanceIDs = append(instanceIDs, *csMachine.Spec.InstanceID)
+	}
+
+	return instanceIDs, nil
+}
+
+// ReadyCloudStackMachines returns the ready CloudStackMachines of the cluster's machines that match the selector and
+// whose failure domain is accepted by inFailureDomain. Machines being deleted are left out, so they drop out of load
+// balancers before their instance is destroyed.
+func (r *ReconciliationRunner) ReadyCloudStackMachines(
+	selector labels.Selector,
+	inFailureDomain func(string) bool,
+) ([]*infrav1.CloudStackMachine, error) {
+	req, _ := labels.NewRequirement(clusterv1.ClusterNameLabel, selection.Equals, []string{r.CAPICluster.Name})
+	machines := &clusterv1.MachineList{}
+	if err := r.K8sClient.List(r.RequestCtx, machines,
+		client.InNamespace(r.Request.Namespace), client.MatchingLabelsSelector{Selector: selector.Add(*req)}); err != nil {
+		return nil, errors.Wrap(err, "listing machines")
+	}
+
+	csMachines := []*infrav1.CloudStackMachine{}
+	for _, machine := range machines.Items {
+		if machine.Spec.InfrastructureRef.Kind != "CloudStackMachine" || machine.Spec.InfrastructureRef.Name == "" {
+			continue
+		}
+		csMachine := &infrav1.CloudStackMachine{}
+		key := client.ObjectKey{Namespace: machine.Namespace, Name: machine.Spec.InfrastructureRef.Name}
+		if err := r.K8sClient.Get(r.RequestCtx, key, csMachine); err != nil {
+			if client.IgnoreNotFound(err) == nil {
+				continue
+			}
+
+			return nil, errors.Wrapf(err, "getting CloudStackMachine %s", key.Name)
+		}
+		if !machine.DeletionTimestamp.IsZero() || !csMachine.DeletionTimestamp.IsZero() || !csMachine.Status.Ready ||
+			csMachine.Spec.InstanceID == nil || !inFailureDomain(csMachine.Spec.FailureDomainName) {
+			continue
+		}
+		csMachines = append(csMachines, csMachine)
+	}
+
+	return csMachines, nil
+}
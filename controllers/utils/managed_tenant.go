@@ -0,0 +1,134 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"reflect"
+	"slices"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
+	"sigs.k8s.io/cluster-api-provider-cloudstack/pkg/cloud"
+)
+
+// GetOrCreateManagedTenantClient ensures the project or account CAPC manages for the failure domain's cluster exists
+// and returns a client acting within it. r.CSClient must already be set to the endpoint's client.
+func (r *ReconciliationRunner) GetOrCreateManagedTenantClient(
+	fdSpec *infrav1.CloudStackFailureDomainSpec,
+	endpointCredentials *corev1.Secret,
+	clientConfig *corev1.ConfigMap,
+) (cloud.Client, error) {
+	tenant := fdSpec.ManagedTenant
+	name := tenant.TenantName(r.CAPICluster.Namespace, r.CAPICluster.Name)
+
+	switch tenant.Type {
+	case infrav1.ManagedTenantTypeProject:
+		project := &cloud.Project{Name: name}
+		if err := r.CSClient.GetOrCreateProject(project, tenant.Limits); err != nil {
+			return nil, errors.Wrapf(err, "getting or creating managed project %s", name)
+		}
+		// Scope the endpoint config itself to the project so the client cache keeps it apart from the endpoint client.
+		scoped := endpointCredentials.DeepCopy()
+		if scoped.Data == nil {
+			scoped.Data = map[string][]byte{}
+		}
+		scoped.Data[cloud.ProjectIDKey] = []byte(project.ID)
+
+		return cloud.NewClientFromK8sSecret(scoped, clientConfig)
+	case infrav1.ManagedTenantTypeAccount:
+		account := &cloud.Account{Name: name, Domain: cloud.Domain{Path: fdSpec.Domain}}
+		if err := r.CSClient.GetOrCreateAccount(account, tenant.RoleID, tenant.Limits); err != nil {
+			return nil, errors.Wrapf(err, "getting or creating managed account %s", name)
+		}
+
+		return r.CSClient.NewClientInDomainAndAccount(account.Domain.Path, account.Name)
+	default:
+		return nil, errors.Errorf("unrecognized managed tenant type %s", tenant.Type)
+	}
+}
+
+// RecordManagedTenants adds the managed tenants of the passed failure domains to records, unless they're recorded
+// already, and returns the result.
+func (r *ReconciliationRunner) RecordManagedTenants(
+	records []infrav1.ManagedTenantRecord,
+	fdSpecs []infrav1.CloudStackFailureDomainSpec,
+) []infrav1.ManagedTenantRecord {
+	for _, fdSpec := range fdSpecs {
+		tenant := fdSpec.ManagedTenant
+		if tenant == nil {
+			continue
+		}
+		record := infrav1.ManagedTenantRecord{
+			Type:        tenant.Type,
+			Name:        tenant.TenantName(r.CAPICluster.Namespace, r.CAPICluster.Name),
+			ACSEndpoint: fdSpec.ACSEndpoint,
+			IdentityRef: fdSpec.IdentityRef,
+		}
+		if tenant.Type == infrav1.ManagedTenantTypeAccount {
+			record.Domain = fdSpec.Domain
+		}
+		if fdSpec.IdentityRef != nil {
+			record.ACSEndpoint = corev1.SecretReference{}
+		}
+		if !slices.ContainsFunc(records, func(recorded infrav1.ManagedTenantRecord) bool {
+			return reflect.DeepEqual(recorded, record)
+		}) {
+			records = append(records, record)
+		}
+	}
+
+	return records
+}
+
+// DeleteManagedTenants deletes the recorded projects and accounts, if CAPC created them. Meant to be run once all of the
+// cluster's failure domains, and so all resources within the tenants, are gone.
+func (r *ReconciliationRunner) DeleteManagedTenants(records []infrav1.ManagedTenantRecord) CloudStackReconcilerMethod {
+	return func() (ctrl.Result, error) {
+		for _, record := range records {
+			fdSpec := &infrav1.CloudStackFailureDomainSpec{ACSEndpoint: record.ACSEndpoint, IdentityRef: record.IdentityRef}
+			endpointCredentials, clientConfig, err := r.GetEndpointCredentials(fdSpec)
+			if apierrors.IsNotFound(errors.Cause(err)) {
+				r.Log.Info("ACSEndpoint secret gone, leaving managed tenant in place.", "tenant", record.Name, "type", record.Type)
+
+				continue
+			} else if err != nil {
+				return ctrl.Result{}, err
+			}
+			csClient, err := cloud.NewClientFromK8sSecret(endpointCredentials, clientConfig)
+			if err != nil {
+				return ctrl.Result{}, errors.Wrapf(err, "parsing ACSEndpoint secret %s", endpointCredentials.Name)
+			}
+
+			// Tenants CAPC didn't create are left in place by the client.
+			r.Log.Info("Deleting managed tenant if CAPC created it.", "tenant", record.Name, "type", record.Type)
+			if record.Type == infrav1.ManagedTenantTypeProject {
+				err = csClient.DeleteProject(&cloud.Project{Name: record.Name})
+			} else {
+				err = csClient.DeleteAccount(&cloud.Account{Name: record.Name, Domain: cloud.Domain{Path: record.Domain}})
+			}
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+
+		return ctrl.Result{}, nil
+	}
+}
@@ -0,0 +1,149 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
+	csCtrlrUtils "sigs.k8s.io/cluster-api-provider-cloudstack/controllers/utils"
+	"sigs.k8s.io/cluster-api-provider-cloudstack/pkg/cloud"
+	"sigs.k8s.io/cluster-api-provider-cloudstack/pkg/mocks"
+)
+
+func TestGetOrCreateManagedTenantClient(t *testing.T) {
+	const tenantName = "capc-default-test-cluster"
+
+	tests := []struct {
+		name    string
+		tenant  infrav1.CloudStackManagedTenant
+		expect  func(endpointClient *mocks.MockClient, tenantClient *mocks.MockClient)
+		wantErr string
+	}{
+		{
+			name:   "Account in the failure domain's domain",
+			tenant: infrav1.CloudStackManagedTenant{Type: infrav1.ManagedTenantTypeAccount, RoleID: "role-id"},
+			expect: func(endpointClient *mocks.MockClient, tenantClient *mocks.MockClient) {
+				endpointClient.EXPECT().GetOrCreateAccount(
+					&cloud.Account{Name: tenantName, Domain: cloud.Domain{Path: "ROOT/capc"}}, "role-id", nil).Return(nil)
+				endpointClient.EXPECT().NewClientInDomainAndAccount("ROOT/capc", tenantName).Return(tenantClient, nil)
+			},
+		},
+		{
+			name:   "Account that can't be created",
+			tenant: infrav1.CloudStackManagedTenant{Type: infrav1.ManagedTenantTypeAccount},
+			expect: func(endpointClient *mocks.MockClient, _ *mocks.MockClient) {
+				endpointClient.EXPECT().GetOrCreateAccount(gomock.Any(), "", nil).Return(errors.New("api down"))
+			},
+			wantErr: "getting or creating managed account " + tenantName,
+		},
+		{
+			name:   "Project that can't be created",
+			tenant: infrav1.CloudStackManagedTenant{Type: infrav1.ManagedTenantTypeProject},
+			expect: func(endpointClient *mocks.MockClient, _ *mocks.MockClient) {
+				endpointClient.EXPECT().GetOrCreateProject(&cloud.Project{Name: tenantName}, nil).Return(errors.New("api down"))
+			},
+			wantErr: "getting or creating managed project " + tenantName,
+		},
+		{
+			name:    "Unknown tenant type",
+			tenant:  infrav1.CloudStackManagedTenant{Type: "Domain"},
+			expect:  func(*mocks.MockClient, *mocks.MockClient) {},
+			wantErr: "unrecognized managed tenant type Domain",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			endpointClient := mocks.NewMockClient(mockCtrl)
+			tenantClient := mocks.NewMockClient(mockCtrl)
+			tt.expect(endpointClient, tenantClient)
+
+			r := &csCtrlrUtils.ReconciliationRunner{
+				ReconcilerBase: &csCtrlrUtils.ReconcilerBase{CSClient: endpointClient},
+				CloudStackBaseContext: csCtrlrUtils.CloudStackBaseContext{
+					CAPICluster: &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test-cluster"}},
+				},
+			}
+			fdSpec := &infrav1.CloudStackFailureDomainSpec{Domain: "ROOT/capc", ManagedTenant: &tt.tenant}
+			got, err := r.GetOrCreateManagedTenantClient(fdSpec, &corev1.Secret{}, nil)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("GetOrCreateManagedTenantClient() error = %v, want %q", err, tt.wantErr)
+				}
+
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetOrCreateManagedTenantClient() unexpected error = %v", err)
+			}
+			if got != tenantClient {
+				t.Errorf("GetOrCreateManagedTenantClient() = %v, want the client of the managed account", got)
+			}
+		})
+	}
+}
+
+func TestRecordManagedTenants(t *testing.T) {
+	r := &csCtrlrUtils.ReconciliationRunner{
+		CloudStackBaseContext: csCtrlrUtils.CloudStackBaseContext{
+			CAPICluster: &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test-cluster"}},
+		},
+	}
+	endpoint := corev1.SecretReference{Namespace: "default", Name: "endpoint"}
+	project := infrav1.ManagedTenantRecord{Type: infrav1.ManagedTenantTypeProject, Name: "capc-default-test-cluster", ACSEndpoint: endpoint}
+	fdSpecs := []infrav1.CloudStackFailureDomainSpec{
+		{Name: "fd1", ACSEndpoint: endpoint, Domain: "ROOT/capc", ManagedTenant: &infrav1.CloudStackManagedTenant{Type: infrav1.ManagedTenantTypeProject}},
+		// Shares the project of fd1, projects aren't bound to the domain of the failure domain.
+		{Name: "fd2", ACSEndpoint: endpoint, ManagedTenant: &infrav1.CloudStackManagedTenant{Type: infrav1.ManagedTenantTypeProject}},
+		{Name: "fd3", ACSEndpoint: endpoint},
+	}
+
+	got := r.RecordManagedTenants(nil, fdSpecs)
+	if !reflect.DeepEqual(got, []infrav1.ManagedTenantRecord{project}) {
+		t.Errorf("RecordManagedTenants() = %+v, want %+v", got, []infrav1.ManagedTenantRecord{project})
+	}
+
+	// The failure domains of the project are renamed to use an account instead. The project stays on record so it's
+	// still deleted with the cluster.
+	account := infrav1.ManagedTenantRecord{
+		Type:        infrav1.ManagedTenantTypeAccount,
+		Name:        "tenant",
+		Domain:      "ROOT/capc",
+		IdentityRef: &infrav1.CloudStackClusterIdentityReference{Name: "identity"},
+	}
+	fdSpecs = []infrav1.CloudStackFailureDomainSpec{{
+		Name:          "fd1-renamed",
+		ACSEndpoint:   endpoint,
+		IdentityRef:   account.IdentityRef,
+		Domain:        "ROOT/capc",
+		ManagedTenant: &infrav1.CloudStackManagedTenant{Type: infrav1.ManagedTenantTypeAccount, Name: "tenant"},
+	}}
+	got = r.RecordManagedTenants(got, fdSpecs)
+	if want := []infrav1.ManagedTenantRecord{project, account}; !reflect.DeepEqual(got, want) {
+		t.Errorf("RecordManagedTenants() = %+v, want %+v", got, want)
+	}
+}
@@ -230,6 +230,149 @@ func CloudStackClusterToCloudStackIsolatedNetworks(c client.Client, obj client.O
 	}, nil
 }
 
+// CloudStackClusterToCloudStackVPCs is a handler.ToRequestsFunc to be used to enqueue requests for reconciliation of
+// CloudStackVPCs.
+func CloudStackClusterToCloudStackVPCs(c client.Client, log logr.Logger) handler.MapFunc {
+	return func(ctx context.Context, o client.Object) []reconcile.Request {
+		csCluster, ok := o.(*infrav1.CloudStackCluster)
+		if !ok {
+			log.Error(fmt.Errorf("expected a CloudStackCluster but got a %T", o), "Error in CloudStackClusterToCloudStackVPCs")
+
+			return nil
+		}
+
+		// Don't handle deleted CloudStackClusters
+		if !csCluster.ObjectMeta.DeletionTimestamp.IsZero() {
+			return nil
+		}
+
+		clusterName, err := GetOwnerClusterName(csCluster.ObjectMeta)
+		if err != nil {
+			log.Error(err, "Failed to get owning cluster, skipping mapping.")
+
+			return nil
+		}
+
+		vpcList := &infrav1.CloudStackVPCList{}
+		if err := c.List(ctx, vpcList, client.InNamespace(csCluster.Namespace), client.MatchingLabels{clusterv1.ClusterNameLabel: clusterName}); err != nil {
+			return nil
+		}
+
+		results := make([]reconcile.Request, 0, len(vpcList.Items))
+		for _, vpc := range vpcList.Items {
+			results = append(results, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: vpc.Namespace, Name: vpc.Name}})
+		}
+
+		return results
+	}
+}
+
+// CloudStackMachineToCloudStackLoadBalancers is a handler.ToRequestsFunc to be used to enqueue requests for
+// reconciliation of the CloudStackLoadBalancers in the failure domain of a CloudStackMachine.
+func CloudStackMachineToCloudStackLoadBalancers(c client.Client, log logr.Logger) handler.MapFunc {
+	return func(ctx context.Context, o client.Object) []reconcile.Request {
+		csMachine, ok := o.(*infrav1.CloudStackMachine)
+		if !ok {
+			log.Error(fmt.Errorf("expected a CloudStackMachine but got a %T", o), "Error in CloudStackMachineToCloudStackLoadBalancers")
+
+			return nil
+		}
+
+		clusterName, ok := csMachine.GetLabels()[clusterv1.ClusterNameLabel]
+		if !ok {
+			log.Error(errors.New("failed to find cluster name label"), "CloudStackMachine is missing cluster name label, skipping mapping.")
+
+			return nil
+		}
+
+		lbList := &infrav1.CloudStackLoadBalancerList{}
+		if err := c.List(ctx, lbList, client.InNamespace(csMachine.Namespace), client.MatchingLabels{clusterv1.ClusterNameLabel: clusterName}); err != nil {
+			return nil
+		}
+
+		results := make([]reconcile.Request, 0, len(lbList.Items))
+		for _, lb := range lbList.Items {
+			if lb.Spec.FailureDomainName != csMachine.Spec.FailureDomainName {
+				continue
+			}
+			results = append(results, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: lb.Namespace, Name: lb.Name}})
+		}
+
+		return results
+	}
+}
+
+// ControlPlaneCloudStackMachineToCloudStackIsolatedNetworks is a handler.ToRequestsFunc to be used to enqueue requests
+// for reconciliation of the CloudStackIsolatedNetworks of the cluster of a control plane CloudStackMachine.
+func ControlPlaneCloudStackMachineToCloudStackIsolatedNetworks(c client.Client, log logr.Logger) handler.MapFunc {
+	return func(ctx context.Context, o client.Object) []reconcile.Request {
+		csMachine, ok := o.(*infrav1.CloudStackMachine)
+		if !ok {
+			log.Error(fmt.Errorf("expected a CloudStackMachine but got a %T", o), "Error in ControlPlaneCloudStackMachineToCloudStackIsolatedNetworks")
+
+			return nil
+		}
+		if _, ok := csMachine.GetLabels()[clusterv1.MachineControlPlaneLabel]; !ok {
+			return nil
+		}
+
+		clusterName, ok := csMachine.GetLabels()[clusterv1.ClusterNameLabel]
+		if !ok {
+			log.Error(errors.New("failed to find cluster name label"), "CloudStackMachine is missing cluster name label, skipping mapping.")
+
+			return nil
+		}
+
+		isoNetList := &infrav1.CloudStackIsolatedNetworkList{}
+		if err := c.List(ctx, isoNetList, client.InNamespace(csMachine.Namespace), client.MatchingLabels{clusterv1.ClusterNameLabel: clusterName}); err != nil {
+			return nil
+		}
+
+		results := make([]reconcile.Request, 0, len(isoNetList.Items))
+		for _, isoNet := range isoNetList.Items {
+			results = append(results, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: isoNet.Namespace, Name: isoNet.Name}})
+		}
+
+		return results
+	}
+}
+
+// ControlPlaneCloudStackMachineToCloudStackCluster is a handler.ToRequestsFunc to be used to enqueue requests for
+// reconciliation of the CloudStackCluster of a control plane CloudStackMachine.
+func ControlPlaneCloudStackMachineToCloudStackCluster(c client.Client, log logr.Logger) handler.MapFunc {
+	return func(ctx context.Context, o client.Object) []reconcile.Request {
+		csMachine, ok := o.(*infrav1.CloudStackMachine)
+		if !ok {
+			log.Error(fmt.Errorf("expected a CloudStackMachine but got a %T", o), "Error in ControlPlaneCloudStackMachineToCloudStackCluster")
+
+			return nil
+		}
+		if _, ok := csMachine.GetLabels()[clusterv1.MachineControlPlaneLabel]; !ok {
+			return nil
+		}
+
+		clusterName, ok := csMachine.GetLabels()[clusterv1.ClusterNameLabel]
+		if !ok {
+			log.Error(errors.New("failed to find cluster name label"), "CloudStackMachine is missing cluster name label, skipping mapping.")
+
+			return nil
+		}
+
+		capiCluster := &clusterv1.Cluster{}
+		if err := c.Get(ctx, client.ObjectKey{Namespace: csMachine.Namespace, Name: clusterName}, capiCluster); err != nil {
+			return nil
+		}
+		if capiCluster.Spec.InfrastructureRef == nil || capiCluster.Spec.InfrastructureRef.Name == "" {
+			return nil
+		}
+
+		return []reconcile.Request{{NamespacedName: types.NamespacedName{
+			Namespace: csMachine.Namespace,
+			Name:      capiCluster.Spec.InfrastructureRef.Name,
+		}}}
+	}
+}
+
 // CloudStackIsolatedNetworkToControlPlaneCloudStackMachines is a handler.ToRequestsFunc to be used to enqueue requests for reconciliation
 // of CloudStackMachines that are part of the control plane.
 func CloudStackIsolatedNetworkToControlPlaneCloudStackMachines(c client.Client, log logr.Logger) handler.MapFunc {
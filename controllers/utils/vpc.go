@@ -0,0 +1,89 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"reflect"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
+)
+
+// VPCMetaName returns the name of the CloudStackVPC of the cluster for the VPC with the given name.
+func (r *ReconciliationRunner) VPCMetaName(name string) string {
+	return r.IsoNetMetaName(name)
+}
+
+// GenerateVPC creates a CloudStackVPC object that is owned by the ReconciliationSubject, filling in the defaults of
+// unset VPC and tier settings.
+func (r *ReconciliationRunner) GenerateVPC(network infrav1.Network, fdName string) CloudStackReconcilerMethod {
+	return func() (ctrl.Result, error) {
+		csVPC := &infrav1.CloudStackVPC{}
+		csVPC.ObjectMeta = r.NewChildObjectMeta(r.VPCMetaName(network.Name))
+		if network.VPC != nil {
+			csVPC.Spec.VPCSpec = *network.VPC.DeepCopy()
+		}
+		csVPC.Spec.Name = network.Name
+		csVPC.Spec.FailureDomainName = fdName
+		if csVPC.Spec.CIDR == "" {
+			csVPC.Spec.CIDR = infrav1.DefaultVPCCIDR
+		}
+		if csVPC.Spec.Offering == "" {
+			csVPC.Spec.Offering = infrav1.DefaultVPCOffering
+		}
+		defaultVPCTier(&csVPC.Spec.ControlPlaneTier, network.Name+"-control-plane", infrav1.DefaultControlPlaneTierCIDR)
+		defaultVPCTier(&csVPC.Spec.WorkerTier, network.Name+"-workers", infrav1.DefaultWorkerTierCIDR)
+		csVPC.Spec.ControlPlaneEndpoint.Host = r.CSCluster.Spec.ControlPlaneEndpoint.Host
+		csVPC.Spec.ControlPlaneEndpoint.Port = r.CSCluster.Spec.ControlPlaneEndpoint.Port
+
+		if err := r.K8sClient.Create(r.RequestCtx, csVPC); err != nil && !ContainsAlreadyExistsSubstring(err) {
+			return r.ReturnWrappedError(err, "creating VPC CRD")
+		}
+
+		return ctrl.Result{}, nil
+	}
+}
+
+// UpdateVPCTierACLs updates the network ACL rules of the tiers of an existing CloudStackVPC to the ones of the network,
+// as they can change after the CloudStackVPC is generated.
+func (r *ReconciliationRunner) UpdateVPCTierACLs(network infrav1.Network, csVPC *infrav1.CloudStackVPC) CloudStackReconcilerMethod {
+	return func() (ctrl.Result, error) {
+		var controlPlaneACL, workerACL []infrav1.NetworkACLRule
+		if network.VPC != nil {
+			controlPlaneACL, workerACL = network.VPC.ControlPlaneTier.ACL, network.VPC.WorkerTier.ACL
+		}
+		if reflect.DeepEqual(csVPC.Spec.ControlPlaneTier.ACL, controlPlaneACL) && reflect.DeepEqual(csVPC.Spec.WorkerTier.ACL, workerACL) {
+			return ctrl.Result{}, nil
+		}
+		csVPC.Spec.ControlPlaneTier.ACL, csVPC.Spec.WorkerTier.ACL = controlPlaneACL, workerACL
+		if err := r.K8sClient.Update(r.RequestCtx, csVPC); err != nil {
+			return r.ReturnWrappedError(err, "updating tier ACLs of VPC CRD")
+		}
+
+		return ctrl.Result{}, nil
+	}
+}
+
+func defaultVPCTier(tier *infrav1.VPCTierSpec, name, cidr string) {
+	if tier.Name == "" {
+		tier.Name = name
+	}
+	if tier.CIDR == "" {
+		tier.CIDR = cidr
+	}
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
+	csCtrlrUtils "sigs.k8s.io/cluster-api-provider-cloudstack/controllers/utils"
+)
+
+func TestUpdateVPCTierACLs(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := infrav1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	csVPC := &infrav1.CloudStackVPC{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "vpc1"},
+		Spec: infrav1.CloudStackVPCSpec{
+			Name: "vpc1",
+			VPCSpec: infrav1.VPCSpec{
+				CIDR:             infrav1.DefaultVPCCIDR,
+				ControlPlaneTier: infrav1.VPCTierSpec{Name: "vpc1-control-plane", CIDR: infrav1.DefaultControlPlaneTierCIDR},
+				WorkerTier:       infrav1.VPCTierSpec{Name: "vpc1-workers", CIDR: infrav1.DefaultWorkerTierCIDR},
+			},
+		},
+	}
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(csVPC).Build()
+	r := &csCtrlrUtils.ReconciliationRunner{
+		ReconcilerBase:        &csCtrlrUtils.ReconcilerBase{K8sClient: k8sClient},
+		CloudStackBaseContext: csCtrlrUtils.CloudStackBaseContext{RequestCtx: context.Background()},
+	}
+
+	controlPlaneACL := []infrav1.NetworkACLRule{{Protocol: "tcp", CIDRs: []string{"192.168.0.0/24"}, StartPort: 6443}}
+	network := infrav1.Network{Name: "vpc1", Type: infrav1.NetworkTypeVPCTier, VPC: &infrav1.VPCSpec{
+		ControlPlaneTier: infrav1.VPCTierSpec{ACL: controlPlaneACL},
+	}}
+	fdSpec := infrav1.CloudStackFailureDomainSpec{Name: "fd1", Zone: infrav1.CloudStackZoneSpec{Name: "zone1", Network: network}}
+	specHash := infrav1.FailureDomainSpecHash(fdSpec)
+
+	if err := k8sClient.Get(context.Background(), client.ObjectKeyFromObject(csVPC), csVPC); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.UpdateVPCTierACLs(network, csVPC)(); err != nil {
+		t.Fatalf("UpdateVPCTierACLs() unexpected error = %v", err)
+	}
+	got := &infrav1.CloudStackVPC{}
+	if err := k8sClient.Get(context.Background(), client.ObjectKeyFromObject(csVPC), got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got.Spec.ControlPlaneTier.ACL, controlPlaneACL) || got.Spec.WorkerTier.ACL != nil {
+		t.Errorf("UpdateVPCTierACLs() tier ACLs = %v, %v, want %v, nil", got.Spec.ControlPlaneTier.ACL, got.Spec.WorkerTier.ACL, controlPlaneACL)
+	}
+	if got.Spec.ControlPlaneTier.Name != "vpc1-control-plane" || got.Spec.CIDR != infrav1.DefaultVPCCIDR {
+		t.Errorf("UpdateVPCTierACLs() changed more than the tier ACLs: %+v", got.Spec)
+	}
+
+	// Changing the ACLs updates the VPC in place rather than starting a new failure domain generation.
+	network.VPC.ControlPlaneTier.ACL = nil
+	fdSpec.Zone.Network = network
+	if hash := infrav1.FailureDomainSpecHash(fdSpec); hash != specHash {
+		t.Errorf("FailureDomainSpecHash() = %s after changing the tier ACLs, want %s", hash, specHash)
+	}
+	if _, err := r.UpdateVPCTierACLs(network, got)(); err != nil {
+		t.Fatalf("UpdateVPCTierACLs() unexpected error = %v", err)
+	}
+	if err := k8sClient.Get(context.Background(), client.ObjectKeyFromObject(csVPC), got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Spec.ControlPlaneTier.ACL != nil {
+		t.Errorf("UpdateVPCTierACLs() control plane tier ACL = %v, want nil", got.Spec.ControlPlaneTier.ACL)
+	}
+}
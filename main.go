@@ -95,6 +95,8 @@ var (
 	cloudStackMachineConcurrency       int
 	cloudStackAffinityGroupConcurrency int
 	cloudStackFailureDomainConcurrency int
+	cloudStackSSHKeyPairConcurrency    int
+	cloudStackLoadBalancerConcurrency  int
 )
 
 func initFlags(fs *pflag.FlagSet) {
@@ -145,6 +147,14 @@ func initFlags(fs *pflag.FlagSet) {
 		"Maximum concurrent reconciles for CloudStackFailureDomain resources",
 	)
 
+	fs.IntVar(&cloudStackSSHKeyPairConcurrency, "cloudstacksshkeypair-concurrency", 5,
+		"Maximum concurrent reconciles for CloudStackSSHKeyPair resources",
+	)
+
+	fs.IntVar(&cloudStackLoadBalancerConcurrency, "cloudstackloadbalancer-concurrency", 5,
+		"Maximum concurrent reconciles for CloudStackLoadBalancer resources",
+	)
+
 	fs.DurationVar(&syncPeriod, "sync-period", 10*time.Minute,
 		"The minimum interval at which watched resources are reconciled (e.g. 15m)",
 	)
@@ -319,6 +329,10 @@ func setupReconcilers(ctx context.Context, base utils.ReconcilerBase, mgr manage
 		setupLog.Error(err, "unable to create controller", "controller", "CloudStackIsoNetReconciler")
 		klog.FlushAndExit(klog.ExitFlushTimeout, 1)
 	}
+	if err := (&controllers.CloudStackVPCReconciler{ReconcilerBase: base}).SetupWithManager(ctx, mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "CloudStackVPCReconciler")
+		klog.FlushAndExit(klog.ExitFlushTimeout, 1)
+	}
 	if err := (&controllers.CloudStackAffinityGroupReconciler{ReconcilerBase: base}).SetupWithManager(ctx, mgr, controller.Options{MaxConcurrentReconciles: cloudStackAffinityGroupConcurrency}); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "CloudStackAffinityGroup")
 		klog.FlushAndExit(klog.ExitFlushTimeout, 1)
@@ -327,6 +341,18 @@ func setupReconcilers(ctx context.Context, base utils.ReconcilerBase, mgr manage
 		setupLog.Error(err, "unable to create controller", "controller", "CloudStackFailureDomain")
 		klog.FlushAndExit(klog.ExitFlushTimeout, 1)
 	}
+	if err := (&controllers.CloudStackSSHKeyPairReconciler{ReconcilerBase: base}).SetupWithManager(ctx, mgr, controller.Options{MaxConcurrentReconciles: cloudStackSSHKeyPairConcurrency}); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "CloudStackSSHKeyPair")
+		klog.FlushAndExit(klog.ExitFlushTimeout, 1)
+	}
+	if err := (&controllers.CloudStackLoadBalancerReconciler{ReconcilerBase: base}).SetupWithManager(ctx, mgr, controller.Options{MaxConcurrentReconciles: cloudStackLoadBalancerConcurrency}); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "CloudStackLoadBalancer")
+		klog.FlushAndExit(klog.ExitFlushTimeout, 1)
+	}
+	if err := (&controllers.ClientCacheReconciler{ReconcilerBase: base}).SetupWithManager(ctx, mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ClientCache")
+		klog.FlushAndExit(klog.ExitFlushTimeout, 1)
+	}
 }
 
 func setupWebhooks(mgr ctrl.Manager) {
@@ -342,4 +368,12 @@ func setupWebhooks(mgr ctrl.Manager) {
 		setupLog.Error(err, "unable to create webhook", "webhook", "CloudStackMachineTemplate")
 		klog.FlushAndExit(klog.ExitFlushTimeout, 1)
 	}
+	if err := (&infrav1b3.CloudStackClusterIdentity{}).SetupWebhookWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create webhook", "webhook", "CloudStackClusterIdentity")
+		klog.FlushAndExit(klog.ExitFlushTimeout, 1)
+	}
+	if err := (&infrav1b3.CloudStackLoadBalancer{}).SetupWebhookWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create webhook", "webhook", "CloudStackLoadBalancer")
+		klog.FlushAndExit(klog.ExitFlushTimeout, 1)
+	}
 }
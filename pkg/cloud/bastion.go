@@ -0,0 +1,180 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloud
+
+import (
+	"strings"
+
+	"github.com/apache/cloudstack-go/v2/cloudstack"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
+)
+
+const bastionSSHPort = 22
+
+type BastionIface interface {
+	GetOrCreateBastion(fd *infrav1.CloudStackFailureDomain, csCluster *infrav1.CloudStackCluster) error
+	DeleteBastion(csCluster *infrav1.CloudStackCluster) error
+}
+
+// bastionMachine describes the bastion of a cluster as a CloudStackMachine, so that it's deployed, looked up and
+// destroyed the same way as the cluster's machines.
+func bastionMachine(csCluster *infrav1.CloudStackCluster) *infrav1.CloudStackMachine {
+	machine := &infrav1.CloudStackMachine{
+		ObjectMeta: metav1.ObjectMeta{Name: csCluster.Name + "-bastion", Namespace: csCluster.Namespace},
+		// The bastion has no user data to compress.
+		Spec: infrav1.CloudStackMachineSpec{UncompressedUserData: ptr.To(true)},
+	}
+	if bastion := csCluster.Spec.Bastion; bastion != nil {
+		machine.Spec.Offering = bastion.Offering
+		machine.Spec.Template = bastion.Template
+		machine.Spec.SSHKey = bastion.SSHKey
+	}
+	if status := csCluster.Status.Bastion; status != nil && status.InstanceID != "" {
+		machine.Spec.InstanceID = ptr.To(status.InstanceID)
+	}
+
+	return machine
+}
+
+// GetOrCreateBastion deploys the cluster's bastion VM in the network of the failure domain, maps a public IP address
+// to it with static NAT, and opens SSH on that address to the allowed CIDRs.
+func (c *client) GetOrCreateBastion(fd *infrav1.CloudStackFailureDomain, csCluster *infrav1.CloudStackCluster) error {
+	if csCluster.Status.Bastion == nil {
+		csCluster.Status.Bastion = &infrav1.BastionStatus{}
+	}
+	status := csCluster.Status.Bastion
+	status.FailureDomainName = fd.Spec.Name
+
+	machine := bastionMachine(csCluster)
+	err := c.GetOrCreateVMInstance(machine, &clusterv1.Machine{ObjectMeta: machine.ObjectMeta}, fd, nil, "")
+	// An incomplete deployment still yields an instance, which is recorded so that it gets cleaned up.
+	if machine.Spec.InstanceID != nil {
+		status.InstanceID = *machine.Spec.InstanceID
+	}
+	if err != nil {
+		return errors.Wrap(err, "deploying bastion VM")
+	}
+	if len(machine.Status.Addresses) > 0 {
+		status.PrivateIPAddress = machine.Status.Addresses[0].Address
+	}
+
+	publicIP, err := c.bastionPublicIP(fd, status)
+	if err != nil {
+		return err
+	}
+	status.IPAddress = publicIP.Ipaddress
+	status.IPAddressID = publicIP.Id
+	if err := c.AddClusterTag(ResourceTypeIPAddress, publicIP.Id, csCluster); err != nil {
+		return errors.Wrapf(err, "adding cluster tag to public IP address with ID %s", publicIP.Id)
+	}
+	if err := c.enableStaticNat(publicIP, status.InstanceID, fd.Spec.Zone.Network.ID); err != nil {
+		return err
+	}
+	if err := c.reconcileServiceFirewallRules(publicIP.Id,
+		[]infrav1.LoadBalancerPort{{Port: bastionSSHPort}}, csCluster.Spec.Bastion.AllowedCIDRs); err != nil {
+		return err
+	}
+	status.Ready = machine.Status.InstanceState == "Running"
+
+	return nil
+}
+
+// bastionPublicIP returns the public IP address of the bastion, and associates a new one with the network of the
+// failure domain if it has none yet or its address was released.
+func (c *client) bastionPublicIP(fd *infrav1.CloudStackFailureDomain, status *infrav1.BastionStatus) (*cloudstack.PublicIpAddress, error) {
+	if status.IPAddressID != "" {
+		publicIP, _, err := c.cs.Address.GetPublicIpAddressByID(status.IPAddressID, cloudstack.WithProject(c.user.Project.ID))
+		if err == nil {
+			return publicIP, nil
+		} else if !strings.Contains(strings.ToLower(err.Error()), "no match found") {
+			c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+
+			return nil, errors.Wrapf(err, "getting public IP address with ID %s", status.IPAddressID)
+		}
+	}
+	publicIP, err := c.associatePublicIPAddressWithNetwork(fd, "")
+	if err != nil {
+		return nil, errors.Wrap(err, "associating bastion public IP address")
+	}
+
+	return publicIP, nil
+}
+
+// enableStaticNat maps the public IP address to the instance, moving it off any other instance first.
+func (c *client) enableStaticNat(publicIP *cloudstack.PublicIpAddress, instanceID string, networkID string) error {
+	if publicIP.Isstaticnat && publicIP.Virtualmachineid == instanceID {
+		return nil
+	}
+	if publicIP.Isstaticnat {
+		if _, err := c.csAsync.NAT.DisableStaticNat(c.csAsync.NAT.NewDisableStaticNatParams(publicIP.Id)); err != nil {
+			c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+
+			return errors.Wrapf(err, "disabling static NAT on public IP address with ID %s", publicIP.Id)
+		}
+	}
+	p := c.cs.NAT.NewEnableStaticNatParams(publicIP.Id, instanceID)
+	p.SetNetworkid(networkID)
+	if _, err := c.cs.NAT.EnableStaticNat(p); err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+
+		return errors.Wrapf(err, "enabling static NAT from public IP address with ID %s to instance %s", publicIP.Id, instanceID)
+	}
+
+	return nil
+}
+
+// DeleteBastion releases the public IP address of the cluster's bastion, which takes its static NAT and firewall rules
+// along, and destroys the bastion VM. The bastion status is cleared once the VM is gone.
+func (c *client) DeleteBastion(csCluster *infrav1.CloudStackCluster) error {
+	status := csCluster.Status.Bastion
+	if status == nil {
+		return nil
+	}
+	if status.IPAddressID != "" {
+		if err := c.DeleteClusterTag(ResourceTypeIPAddress, status.IPAddressID, csCluster); err != nil {
+			return err
+		}
+		if _, err := c.DisassociatePublicIPAddressIfNotInUse(status.IPAddressID); err != nil {
+			return err
+		}
+		status.IPAddress, status.IPAddressID, status.Ready = "", "", false
+	}
+
+	machine := bastionMachine(csCluster)
+	if machine.Spec.InstanceID == nil {
+		// The VM may have been deployed without its ID being recorded.
+		if err := c.ResolveVMInstanceDetails(machine); err != nil {
+			if !strings.Contains(strings.ToLower(err.Error()), "no match") {
+				return err
+			}
+			csCluster.Status.Bastion = nil
+
+			return nil
+		}
+	}
+	if err := c.DestroyVMInstance(machine); err != nil {
+		return errors.Wrap(err, "destroying bastion VM")
+	}
+	csCluster.Status.Bastion = nil
+
+	return nil
+}
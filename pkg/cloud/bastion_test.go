@@ -0,0 +1,153 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloud_test
+
+import (
+	"errors"
+
+	csapi "github.com/apache/cloudstack-go/v2/cloudstack"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
+	"sigs.k8s.io/cluster-api-provider-cloudstack/pkg/cloud"
+	dummies "sigs.k8s.io/cluster-api-provider-cloudstack/test/dummies/v1beta3"
+)
+
+var _ = Describe("Bastion", func() {
+	var (
+		mockCtrl   *gomock.Controller
+		mockClient *csapi.CloudStackClient
+		vms        *csapi.MockVirtualMachineServiceIface
+		as         *csapi.MockAddressServiceIface
+		ns         *csapi.MockNATServiceIface
+		fs         *csapi.MockFirewallServiceIface
+		rs         *csapi.MockResourcetagsServiceIface
+		client     cloud.Client
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockClient = csapi.NewMockClient(mockCtrl)
+		vms = mockClient.VirtualMachine.(*csapi.MockVirtualMachineServiceIface)
+		as = mockClient.Address.(*csapi.MockAddressServiceIface)
+		ns = mockClient.NAT.(*csapi.MockNATServiceIface)
+		fs = mockClient.Firewall.(*csapi.MockFirewallServiceIface)
+		rs = mockClient.Resourcetags.(*csapi.MockResourcetagsServiceIface)
+		client = cloud.NewClientFromCSAPIClient(mockClient, nil)
+		dummies.SetDummyVars()
+		dummies.CSFailureDomain1.Spec.Zone.Network = dummies.ISONet1
+		dummies.CSCluster.Spec.Bastion = dummies.Bastion
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	Context("Reconciling the bastion", func() {
+		It("maps the public IP address to the bastion VM and opens SSH to the allowed CIDRs", func() {
+			dummies.CSCluster.Status.Bastion = &infrav1.BastionStatus{InstanceID: "bastion-id", IPAddressID: "ip-id"}
+			vms.EXPECT().GetVirtualMachinesMetricByID("bastion-id", gomock.Any()).Return(&csapi.VirtualMachinesMetric{
+				Id: "bastion-id", Ipaddress: "10.0.0.5", State: "Running",
+			}, 1, nil)
+			as.EXPECT().GetPublicIpAddressByID("ip-id", gomock.Any()).Return(&csapi.PublicIpAddress{
+				Id: "ip-id", Ipaddress: "203.0.113.10",
+			}, 1, nil)
+			rs.EXPECT().NewListTagsParams().Return(&csapi.ListTagsParams{})
+			rs.EXPECT().ListTags(gomock.Any()).Return(
+				&csapi.ListTagsResponse{Tags: []*csapi.Tag{{Key: cloud.CreatedByCAPCTagName, Value: "1"}}}, nil)
+			rs.EXPECT().NewCreateTagsParams([]string{"ip-id"}, string(cloud.ResourceTypeIPAddress), gomock.Any()).
+				Return(&csapi.CreateTagsParams{})
+			rs.EXPECT().CreateTags(gomock.Any()).Return(&csapi.CreateTagsResponse{}, nil)
+			ns.EXPECT().NewEnableStaticNatParams("ip-id", "bastion-id").Return(&csapi.EnableStaticNatParams{})
+			ns.EXPECT().EnableStaticNat(gomock.Any()).DoAndReturn(func(p *csapi.EnableStaticNatParams) (*csapi.EnableStaticNatResponse, error) {
+				networkID, _ := p.GetNetworkid()
+				Ω(networkID).Should(Equal(dummies.ISONet1.ID))
+
+				return &csapi.EnableStaticNatResponse{Success: true}, nil
+			})
+			fs.EXPECT().NewListFirewallRulesParams().Return(&csapi.ListFirewallRulesParams{})
+			fs.EXPECT().ListFirewallRules(gomock.Any()).Return(&csapi.ListFirewallRulesResponse{}, nil)
+			fs.EXPECT().NewCreateFirewallRuleParams("ip-id", "tcp").Return(&csapi.CreateFirewallRuleParams{})
+			fs.EXPECT().CreateFirewallRule(gomock.Any()).DoAndReturn(func(p *csapi.CreateFirewallRuleParams) (*csapi.CreateFirewallRuleResponse, error) {
+				cidrs, _ := p.GetCidrlist()
+				port, _ := p.GetStartport()
+				Ω(cidrs).Should(Equal(dummies.Bastion.AllowedCIDRs))
+				Ω(port).Should(Equal(22))
+
+				return &csapi.CreateFirewallRuleResponse{Id: "fw-rule"}, nil
+			})
+			rs.EXPECT().NewCreateTagsParams([]string{"fw-rule"}, string(cloud.ResourceTypeFirewallRule), gomock.Any()).
+				Return(&csapi.CreateTagsParams{})
+			rs.EXPECT().CreateTags(gomock.Any()).Return(&csapi.CreateTagsResponse{}, nil)
+
+			Ω(client.GetOrCreateBastion(dummies.CSFailureDomain1, dummies.CSCluster)).Should(Succeed())
+			Ω(*dummies.CSCluster.Status.Bastion).Should(Equal(infrav1.BastionStatus{
+				FailureDomainName: dummies.CSFailureDomain1.Spec.Name,
+				InstanceID:        "bastion-id",
+				PrivateIPAddress:  "10.0.0.5",
+				IPAddress:         "203.0.113.10",
+				IPAddressID:       "ip-id",
+				Ready:             true,
+			}))
+		})
+
+		It("leaves static NAT and firewall rules that are already in place alone", func() {
+			dummies.CSCluster.Status.Bastion = &infrav1.BastionStatus{InstanceID: "bastion-id", IPAddressID: "ip-id"}
+			vms.EXPECT().GetVirtualMachinesMetricByID("bastion-id", gomock.Any()).Return(&csapi.VirtualMachinesMetric{
+				Id: "bastion-id", Ipaddress: "10.0.0.5", State: "Starting",
+			}, 1, nil)
+			as.EXPECT().GetPublicIpAddressByID("ip-id", gomock.Any()).Return(&csapi.PublicIpAddress{
+				Id: "ip-id", Ipaddress: "203.0.113.10", Isstaticnat: true, Virtualmachineid: "bastion-id",
+			}, 1, nil)
+			rs.EXPECT().NewListTagsParams().Return(&csapi.ListTagsParams{})
+			rs.EXPECT().ListTags(gomock.Any()).Return(&csapi.ListTagsResponse{}, nil)
+			fs.EXPECT().NewListFirewallRulesParams().Return(&csapi.ListFirewallRulesParams{})
+			fs.EXPECT().ListFirewallRules(gomock.Any()).Return(&csapi.ListFirewallRulesResponse{
+				FirewallRules: []*csapi.FirewallRule{
+					{Id: "fw-rule", Protocol: "tcp", Startport: 22, Endport: 22, Cidrlist: "198.51.100.0/24", Tags: dummies.CreatedByCAPCTag},
+				},
+			}, nil)
+
+			Ω(client.GetOrCreateBastion(dummies.CSFailureDomain1, dummies.CSCluster)).Should(Succeed())
+			Ω(dummies.CSCluster.Status.Bastion.Ready).Should(BeFalse())
+		})
+
+		It("returns an error when the public IP address can't be looked up", func() {
+			dummies.CSCluster.Status.Bastion = &infrav1.BastionStatus{InstanceID: "bastion-id", IPAddressID: "ip-id"}
+			vms.EXPECT().GetVirtualMachinesMetricByID("bastion-id", gomock.Any()).Return(&csapi.VirtualMachinesMetric{
+				Id: "bastion-id", Ipaddress: "10.0.0.5", State: "Running",
+			}, 1, nil)
+			as.EXPECT().GetPublicIpAddressByID("ip-id", gomock.Any()).Return(nil, -1, errors.New("api down"))
+
+			Ω(client.GetOrCreateBastion(dummies.CSFailureDomain1, dummies.CSCluster)).
+				Should(MatchError(ContainSubstring("getting public IP address with ID ip-id")))
+		})
+	})
+
+	Context("Deleting the bastion", func() {
+		It("clears the status when the bastion VM was never deployed", func() {
+			dummies.CSCluster.Status.Bastion = &infrav1.BastionStatus{FailureDomainName: dummies.CSFailureDomain1.Spec.Name}
+			vms.EXPECT().GetVirtualMachinesMetricByName(dummies.CSCluster.Name+"-bastion", gomock.Any()).
+				Return(nil, -1, errors.New("No match found for test-cluster-bastion"))
+
+			Ω(client.DeleteBastion(dummies.CSCluster)).Should(Succeed())
+			Ω(dummies.CSCluster.Status.Bastion).Should(BeNil())
+		})
+	})
+})
@@ -0,0 +1,161 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloud
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
+)
+
+// ErrZoneCapacityNotAvailable is returned when the client's user may not call listCapacity.
+var ErrZoneCapacityNotAvailable = errors.New("zone capacity is not available to the user")
+
+type CapacityIface interface {
+	GetResourceHeadroom() (*infrav1.CloudStackFailureDomainCapacity, error)
+	ListZoneCapacity(zoneID string) ([]infrav1.CloudStackZoneCapacity, error)
+}
+
+// zoneCapacityTypes names the listCapacity capacity types reported in failure domain status.
+var zoneCapacityTypes = map[int]string{
+	0:  "Memory",
+	1:  "CPU",
+	2:  "PrimaryStorage",
+	3:  "PrimaryStorageAllocated",
+	4:  "PublicIP",
+	5:  "PrivateIP",
+	6:  "SecondaryStorage",
+	7:  "VLAN",
+	8:  "DirectAttachedPublicIP",
+	9:  "LocalStorage",
+	19: "GPU",
+	90: "CPUCore",
+}
+
+// GetResourceHeadroom looks up the current resource limits of the client's account, its domain and the project, and
+// returns how much of each resource is left. Unlike the availability cached in the client's user, which is only
+// resolved when the client is created, the headroom is always fresh. The domain is left out if the user may not list
+// it.
+func (c *client) GetResourceHeadroom() (*infrav1.CloudStackFailureDomainCapacity, error) {
+	capacity := &infrav1.CloudStackFailureDomainCapacity{}
+
+	// Start from the identifying fields only, so a domain that can't be listed isn't reported with stale values.
+	account := Account{
+		ID:     c.user.Account.ID,
+		Name:   c.user.Account.Name,
+		Domain: Domain{ID: c.user.Account.Domain.ID, Name: c.user.Account.Domain.Name, Path: c.user.Account.Domain.Path},
+	}
+	if err := c.ResolveAccount(&account); err != nil {
+		return nil, errors.Wrapf(err, "resolving limits of account %s", account.Name)
+	}
+	capacity.Account = headroomOf(account.VMAvailable, account.CPUAvailable, account.MemoryAvailable,
+		account.PrimaryStorageAvailable, account.VolumeAvailable, account.IPAvailable, account.NetworkAvailable)
+	domain := account.Domain
+	capacity.Domain = headroomOf(domain.VMAvailable, domain.CPUAvailable, domain.MemoryAvailable,
+		domain.PrimaryStorageAvailable, domain.VolumeAvailable, domain.IPAvailable, domain.NetworkAvailable)
+
+	if c.user.Project.ID == "" {
+		return capacity, nil
+	}
+	p := c.cs.Project.NewListProjectsParams()
+	p.SetListall(true)
+	p.SetId(c.user.Project.ID)
+	resp, err := c.cs.Project.ListProjects(p)
+	if err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+
+		return nil, errors.Wrapf(err, "resolving limits of project %s", c.user.Project.ID)
+	} else if resp.Count != 1 {
+		return nil, errors.Errorf("expected 1 Project with ID '%s', but got %d", c.user.Project.ID, resp.Count)
+	}
+	project := resp.Projects[0]
+	capacity.Project = headroomOf(project.Vmavailable, project.Cpuavailable, project.Memoryavailable,
+		project.Primarystorageavailable, project.Volumeavailable, project.Ipavailable, project.Networkavailable)
+
+	return capacity, nil
+}
+
+// headroomOf converts the availability reported by CloudStack into a headroom. It returns nil if the availability is
+// unknown.
+func headroomOf(vm, cpu, memory, primaryStorage, volume, ip, network string) *infrav1.CloudStackResourceHeadroom {
+	if vm == "" && cpu == "" && memory == "" {
+		return nil
+	}
+
+	return &infrav1.CloudStackResourceHeadroom{
+		Instances:         availableAmount(vm),
+		CPU:               availableAmount(cpu),
+		MemoryMiB:         availableAmount(memory),
+		PrimaryStorageGiB: availableAmount(primaryStorage),
+		Volumes:           availableAmount(volume),
+		PublicIPs:         availableAmount(ip),
+		Networks:          availableAmount(network),
+	}
+}
+
+// availableAmount returns the amount of a resource left, or nil if it's unlimited.
+func availableAmount(available string) *int64 {
+	amount, err := strconv.ParseInt(available, 10, 64)
+	if err != nil {
+		return nil
+	}
+
+	return &amount
+}
+
+// ListZoneCapacity returns the capacity of a zone. listCapacity is restricted to root admins by default, so other users
+// get ErrZoneCapacityNotAvailable, which isn't counted as a reconciliation error.
+func (c *client) ListZoneCapacity(zoneID string) ([]infrav1.CloudStackZoneCapacity, error) {
+	p := c.cs.SystemCapacity.NewListCapacityParams()
+	p.SetZoneid(zoneID)
+	resp, err := c.cs.SystemCapacity.ListCapacity(p)
+	if err != nil && isAPINotAvailable(err, "listCapacity") {
+		return nil, errors.Wrapf(ErrZoneCapacityNotAvailable, "listing capacity of zone %s", zoneID)
+	} else if err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+
+		return nil, errors.Wrapf(err, "listing capacity of zone %s", zoneID)
+	}
+
+	var capacities []infrav1.CloudStackZoneCapacity
+	for _, capacity := range resp.Capacity {
+		capacityType, known := zoneCapacityTypes[capacity.Type]
+		if !known {
+			continue
+		}
+		capacities = append(capacities, infrav1.CloudStackZoneCapacity{
+			Type:        capacityType,
+			Used:        capacity.Capacityused,
+			Total:       capacity.Capacitytotal,
+			PercentUsed: capacity.Percentused,
+		})
+	}
+
+	return capacities, nil
+}
+
+// isAPINotAvailable returns whether err reports that the user may not call api. CloudStack words this as either "The
+// API [api] does not exist or is not available for the account ..." or "The given command:api does not exist or it is
+// not available for user ...".
+func isAPINotAvailable(err error, api string) bool {
+	msg := err.Error()
+
+	return strings.Contains(msg, api) && strings.Contains(msg, "does not exist or") && strings.Contains(msg, "not available for")
+}
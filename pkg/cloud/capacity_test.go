@@ -0,0 +1,176 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloud_test
+
+import (
+	csapi "github.com/apache/cloudstack-go/v2/cloudstack"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"k8s.io/utils/ptr"
+	crtlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
+	"sigs.k8s.io/cluster-api-provider-cloudstack/pkg/cloud"
+)
+
+var _ = Describe("Capacity", func() {
+	var (
+		mockCtrl   *gomock.Controller
+		mockClient *csapi.CloudStackClient
+		ds         *csapi.MockDomainServiceIface
+		as         *csapi.MockAccountServiceIface
+		ps         *csapi.MockProjectServiceIface
+		scs        *csapi.MockSystemCapacityServiceIface
+		user       *cloud.User
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockClient = csapi.NewMockClient(mockCtrl)
+		ds = mockClient.Domain.(*csapi.MockDomainServiceIface)
+		as = mockClient.Account.(*csapi.MockAccountServiceIface)
+		ps = mockClient.Project.(*csapi.MockProjectServiceIface)
+		scs = mockClient.SystemCapacity.(*csapi.MockSystemCapacityServiceIface)
+		user = &cloud.User{Account: cloud.Account{
+			ID:     "account-id",
+			Name:   "tenant-admin",
+			Domain: cloud.Domain{ID: "domain-id", Name: "tenant", Path: "ROOT/tenant", VMAvailable: "stale"},
+		}}
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	Context("getting the resource headroom", func() {
+		BeforeEach(func() {
+			as.EXPECT().NewListAccountsParams().Return(&csapi.ListAccountsParams{})
+			as.EXPECT().ListAccounts(gomock.Any()).Return(&csapi.ListAccountsResponse{Count: 1, Accounts: []*csapi.Account{{
+				Id: "account-id", Name: "tenant-admin",
+				Vmavailable: "3", Cpuavailable: "12", Memoryavailable: "Unlimited", Primarystorageavailable: "200",
+				Volumeavailable: "Unlimited", Ipavailable: "1", Networkavailable: "Unlimited",
+			}}}, nil)
+			ds.EXPECT().NewListDomainsParams().Return(&csapi.ListDomainsParams{})
+		})
+
+		It("returns the headroom of the account and its domain", func() {
+			ds.EXPECT().ListDomains(gomock.Any()).Return(&csapi.ListDomainsResponse{Count: 1, Domains: []*csapi.Domain{{
+				Id: "domain-id", Name: "tenant", Path: "ROOT/tenant",
+				Vmavailable: "Unlimited", Cpuavailable: "40", Memoryavailable: "65536", Primarystorageavailable: "Unlimited",
+				Volumeavailable: "20", Ipavailable: "Unlimited", Networkavailable: "5",
+			}}}, nil)
+
+			capacity, err := cloud.NewClientFromCSAPIClient(mockClient, user).GetResourceHeadroom()
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(capacity.Account).Should(Equal(&infrav1.CloudStackResourceHeadroom{
+				Instances: ptr.To(int64(3)), CPU: ptr.To(int64(12)), PrimaryStorageGiB: ptr.To(int64(200)), PublicIPs: ptr.To(int64(1)),
+			}))
+			Ω(capacity.Domain).Should(Equal(&infrav1.CloudStackResourceHeadroom{
+				CPU: ptr.To(int64(40)), MemoryMiB: ptr.To(int64(65536)), Volumes: ptr.To(int64(20)), Networks: ptr.To(int64(5)),
+			}))
+			Ω(capacity.Project).Should(BeNil())
+		})
+
+		It("leaves out the domain if it can't be listed", func() {
+			ds.EXPECT().ListDomains(gomock.Any()).Return(nil,
+				errors.New("The API [listDomains] does not exist or is not available for the account Account"))
+
+			capacity, err := cloud.NewClientFromCSAPIClient(mockClient, user).GetResourceHeadroom()
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(capacity.Account).ShouldNot(BeNil())
+			Ω(capacity.Domain).Should(BeNil())
+		})
+
+		It("returns the headroom of the project", func() {
+			user.Project.ID = "project-id"
+			ds.EXPECT().ListDomains(gomock.Any()).Return(&csapi.ListDomainsResponse{Count: 1, Domains: []*csapi.Domain{{
+				Id: "domain-id", Name: "tenant", Path: "ROOT/tenant", Vmavailable: "Unlimited",
+			}}}, nil)
+			ps.EXPECT().NewListProjectsParams().Return(&csapi.ListProjectsParams{})
+			ps.EXPECT().ListProjects(gomock.Any()).Return(&csapi.ListProjectsResponse{Count: 1, Projects: []*csapi.Project{{
+				Id: "project-id", Vmavailable: "0", Cpuavailable: "Unlimited", Memoryavailable: "2048",
+			}}}, nil)
+
+			capacity, err := cloud.NewClientFromCSAPIClient(mockClient, user).GetResourceHeadroom()
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(capacity.Project).Should(Equal(&infrav1.CloudStackResourceHeadroom{
+				Instances: ptr.To(int64(0)), MemoryMiB: ptr.To(int64(2048)),
+			}))
+		})
+	})
+
+	Context("listing the zone capacity", func() {
+		BeforeEach(func() {
+			scs.EXPECT().NewListCapacityParams().Return(&csapi.ListCapacityParams{})
+		})
+
+		It("returns the known capacity types", func() {
+			scs.EXPECT().ListCapacity(gomock.Any()).Return(&csapi.ListCapacityResponse{Count: 3, Capacity: []*csapi.Capacity{
+				{Type: 0, Capacityused: 1 << 30, Capacitytotal: 4 << 30, Percentused: "25"},
+				{Type: 1, Capacityused: 8000, Capacitytotal: 10000, Percentused: "80"},
+				{Type: 42, Capacityused: 1, Capacitytotal: 2},
+			}}, nil)
+
+			capacity, err := cloud.NewClientFromCSAPIClient(mockClient, nil).ListZoneCapacity("zone-id")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(capacity).Should(Equal([]infrav1.CloudStackZoneCapacity{
+				{Type: "Memory", Used: 1 << 30, Total: 4 << 30, PercentUsed: "25"},
+				{Type: "CPU", Used: 8000, Total: 10000, PercentUsed: "80"},
+			}))
+		})
+
+		It("reports the capacity as not available without counting an error if the user may not list it", func() {
+			scs.EXPECT().ListCapacity(gomock.Any()).Return(nil,
+				errors.New("The API [listCapacity] does not exist or is not available for the account Account"))
+			errorsBefore := acsReconciliationErrors()
+
+			_, err := cloud.NewClientFromCSAPIClient(mockClient, nil).ListZoneCapacity("zone-id")
+			Ω(err).Should(MatchError(cloud.ErrZoneCapacityNotAvailable))
+			Ω(err).Should(MatchError(ContainSubstring("listing capacity of zone zone-id")))
+			Ω(acsReconciliationErrors()).Should(Equal(errorsBefore))
+		})
+
+		It("returns and counts other errors of listCapacity", func() {
+			scs.EXPECT().ListCapacity(gomock.Any()).Return(nil, errors.New("CloudStack API error 530 (CSExceptionErrorCode: 9999): internal error"))
+			errorsBefore := acsReconciliationErrors()
+
+			_, err := cloud.NewClientFromCSAPIClient(mockClient, nil).ListZoneCapacity("zone-id")
+			Ω(err).ShouldNot(MatchError(cloud.ErrZoneCapacityNotAvailable))
+			Ω(err).Should(MatchError(ContainSubstring("listing capacity of zone zone-id")))
+			Ω(acsReconciliationErrors()).Should(Equal(errorsBefore + 1))
+		})
+	})
+})
+
+// acsReconciliationErrors returns the total of the acs_reconciliation_errors_total counter across error codes.
+func acsReconciliationErrors() float64 {
+	families, err := crtlmetrics.Registry.Gather()
+	Ω(err).ShouldNot(HaveOccurred())
+	total := 0.0
+	for _, family := range families {
+		if family.GetName() != "acs_reconciliation_errors_total" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			total += metric.GetCounter().GetValue()
+		}
+	}
+
+	return total
+}
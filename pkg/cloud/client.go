@@ -18,6 +18,7 @@ package cloud
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"os"
@@ -42,17 +43,46 @@ type Client interface {
 	TagIface
 	ZoneIFace
 	IsoNetworkIface
+	VPCIface
+	LoadBalancerIface
+	BastionIface
+	VMLoadBalancerIface
 	UserCredIFace
+	SSHKeyPairIface
+	PreflightIface
+	CapacityIface
 	NewClientInDomainAndAccount(domain string, account string, options ...ClientOption) (Client, error)
 }
 
-// Config is the cloud-config ini structure.
+// Config is the cloud-config structure, read from either yaml or INI.
 type Config struct {
 	APIUrl    string `yaml:"api-url"`
 	APIKey    string `yaml:"api-key"`
 	SecretKey string `yaml:"secret-key"`
 	VerifySSL string `yaml:"verify-ssl"`
 	ProjectID string `yaml:"project-id"`
+
+	// CACert is a PEM bundle of CAs trusted in addition to the system roots.
+	CACert string `yaml:"ca-cert"`
+	// ClientCert and ClientKey are a PEM client certificate and key presented to the endpoint.
+	ClientCert string `yaml:"client-cert"`
+	ClientKey  string `yaml:"client-key"`
+	// TLSMinVersion is the minimum TLS version, e.g. 1.2.
+	TLSMinVersion string `yaml:"tls-min-version"`
+
+	// HTTPProxy is the URL of the proxy to reach the endpoint through. Defaults to the proxy environment variables.
+	HTTPProxy string `yaml:"http-proxy"`
+	// ConnectTimeout and ReadTimeout bound connecting to the endpoint and each API request, as durations.
+	ConnectTimeout string `yaml:"connect-timeout"`
+	ReadTimeout    string `yaml:"read-timeout"`
+	// AsyncJobTimeout bounds waiting for an async job, as a duration.
+	AsyncJobTimeout string `yaml:"async-job-timeout"`
+
+	// Username and Password log in to a session instead of signing requests with APIKey and SecretKey. Domain is the
+	// path of the user's domain, defaulting to ROOT.
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	Domain   string `yaml:"domain"`
 }
 
 type client struct {
@@ -72,8 +102,14 @@ type SecretConfig struct {
 }
 
 var (
-	clientCache *ttlcache.Cache[string, *client]
-	cacheMutex  sync.Mutex
+	clientCache        *ttlcache.Cache[string, *client]
+	clientCacheTTL     time.Duration
+	clientCacheMetrics = metrics.NewClientCacheMetrics()
+	// clientCacheDependents maps an endpoint secret's namespaced name, or the cache key of a client, to the cache keys
+	// of the clients built from it. Used to evict every client derived from a secret once it changes. Clients are
+	// dropped from it once they leave the cache.
+	clientCacheDependents = map[string]map[string]struct{}{}
+	cacheMutex            sync.Mutex
 )
 
 var (
@@ -86,6 +122,9 @@ const (
 	ClientConfigMapNamespace = "capc-system"
 	ClientCacheTTLKey        = "client-cache-ttl"
 	DefaultClientCacheTTL    = 1 * time.Hour
+	// ProjectIDKey is the endpoint secret key holding the ID of the project clients are scoped to. Next to an INI
+	// cloud-config it overrides the config's project-id.
+	ProjectIDKey = "project-id"
 )
 
 // UnmarshalAllSecretConfigs parses a yaml document for each secret.
@@ -108,38 +147,95 @@ func UnmarshalAllSecretConfigs(in []byte, out *[]SecretConfig) error {
 	return nil
 }
 
-// NewClientFromK8sSecret returns a client from a k8s secret.
+// NewClientFromK8sSecret returns a client from a k8s secret. The client is evicted from the client cache when
+// InvalidateClientsForSecret is called for the secret.
 func NewClientFromK8sSecret(endpointSecret *corev1.Secret, clientConfig *corev1.ConfigMap, options ...ClientOption) (Client, error) {
+	config, err := configFromK8sSecret(endpointSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	return newClientFromConf(config, clientConfig, secretDependentsKey(endpointSecret.Namespace, endpointSecret.Name), options...)
+}
+
+// configFromK8sSecret reads the config from a secret holding either one key per config field, or an INI cloud-config
+// as shared with the CloudStack CCM and CSI driver. The INI section named after the secret takes precedence over the
+// plain [Global] section.
+func configFromK8sSecret(endpointSecret *corev1.Secret) (Config, error) {
+	if cloudConfig, found := endpointSecret.Data[CloudConfigSecretKey]; found && endpointSecret.Data["api-url"] == nil {
+		configs, err := ParseCloudConfigINI(cloudConfig)
+		if err != nil {
+			return Config{}, errors.Wrapf(err, "parsing %s of secret %s", CloudConfigSecretKey, endpointSecret.Name)
+		}
+
+		config, err := selectCloudConfig(configs, endpointSecret.Name)
+		if err != nil {
+			return Config{}, err
+		}
+		// PEM data doesn't fit INI, so TLS settings are read from their own secret keys. The project ID of a tenant
+		// client is set the same way.
+		setIfNotEmpty(string(endpointSecret.Data[CACertKey]), func(v string) { config.CACert = v })
+		setIfNotEmpty(string(endpointSecret.Data[ClientCertKey]), func(v string) { config.ClientCert = v })
+		setIfNotEmpty(string(endpointSecret.Data[ClientKeyKey]), func(v string) { config.ClientKey = v })
+		setIfNotEmpty(string(endpointSecret.Data[TLSMinVersionKey]), func(v string) { config.TLSMinVersion = v })
+		setIfNotEmpty(string(endpointSecret.Data[ProjectIDKey]), func(v string) { config.ProjectID = v })
+
+		return config, nil
+	}
+
 	endpointSecretStrings := map[string]string{}
 	for k, v := range endpointSecret.Data {
 		endpointSecretStrings[k] = string(v)
 	}
 	bytes, err := yaml.Marshal(endpointSecretStrings)
 	if err != nil {
-		return nil, err
+		return Config{}, err
 	}
 
-	return NewClientFromBytesConfig(bytes, clientConfig, options...)
+	return parseBytesConfig(bytes)
 }
 
 // NewClientFromBytesConfig returns a client from a bytes array that unmarshals to a yaml config.
 func NewClientFromBytesConfig(conf []byte, clientConfig *corev1.ConfigMap, options ...ClientOption) (Client, error) {
+	config, err := parseBytesConfig(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewClientFromConf(config, clientConfig, options...)
+}
+
+// parseBytesConfig unmarshals a yaml config.
+func parseBytesConfig(conf []byte) (Config, error) {
 	r := bytes.NewReader(conf)
 	dec := yaml.NewDecoder(r)
 	var config Config
 	if err := dec.Decode(&config); err != nil {
-		return nil, err
+		return Config{}, err
 	}
 
-	return NewClientFromConf(config, clientConfig, options...)
+	return config, nil
 }
 
-// NewClientFromYamlPath returns a client from a yaml config at path.
+// NewClientFromYamlPath returns a client from a yaml config at path. An INI cloud-config is accepted as well, in which
+// case secretName selects the [Global "secretName"] section, falling back to the plain [Global] section.
 func NewClientFromYamlPath(confPath string, secretName string, options ...ClientOption) (Client, error) {
 	content, err := os.ReadFile(confPath)
 	if err != nil {
 		return nil, err
 	}
+	if IsINICloudConfig(content) {
+		configs, err := ParseCloudConfigINI(content)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing cloud-config %s", confPath)
+		}
+		conf, err := selectCloudConfig(configs, secretName)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewClientFromConf(conf, nil, options...)
+	}
 	configs := &[]SecretConfig{}
 	if err := UnmarshalAllSecretConfigs(content, configs); err != nil {
 		return nil, err
@@ -152,7 +248,7 @@ func NewClientFromYamlPath(confPath string, secretName string, options ...Client
 			break
 		}
 	}
-	if conf.APIKey == "" {
+	if conf.APIKey == "" && conf.Username == "" {
 		return nil, errors.Errorf("config with secret name %s not found", secretName)
 	}
 
@@ -161,6 +257,11 @@ func NewClientFromYamlPath(confPath string, secretName string, options ...Client
 
 // NewClientFromConf creates a new Cloud Client form a map of strings to strings.
 func NewClientFromConf(conf Config, clientConfig *corev1.ConfigMap, options ...ClientOption) (Client, error) {
+	return newClientFromConf(conf, clientConfig, "", options...)
+}
+
+// newClientFromConf creates a new Cloud Client, recording it as a dependent of dependsOn in the client cache if set.
+func newClientFromConf(conf Config, clientConfig *corev1.ConfigMap, dependsOn string, options ...ClientOption) (Client, error) {
 	cacheMutex.Lock()
 	defer cacheMutex.Unlock()
 
@@ -168,37 +269,58 @@ func NewClientFromConf(conf Config, clientConfig *corev1.ConfigMap, options ...C
 		clientCache = newClientCache(clientConfig)
 	}
 
+	conf = conf.withClientConfigDefaults(clientConfig)
 	clientCacheKey := generateClientCacheKey(conf)
 	if item := clientCache.Get(clientCacheKey); item != nil {
+		clientCacheMetrics.IncrementHits()
+		addClientCacheDependent(dependsOn, clientCacheKey)
+
 		return item.Value(), nil
 	}
+	clientCacheMetrics.IncrementMisses()
 
 	verifySSL := true
 	if conf.VerifySSL == "false" {
 		verifySSL = false
 	}
+	csOptions, session, err := conf.csClientOptions(verifySSL)
+	if err != nil {
+		return nil, errors.Wrap(err, "configuring CloudStack client")
+	}
 
 	// The client returned from NewAsyncClient works in a synchronous way. On the other hand,
 	// a client returned from NewClient works in an asynchronous way. Dive into the constructor definition
 	// comments for more details
 	c := &client{config: conf}
-	c.cs = NewClient(conf.APIUrl, conf.APIKey, conf.SecretKey, verifySSL)
-	c.csAsync = NewAsyncClient(conf.APIUrl, conf.APIKey, conf.SecretKey, verifySSL)
+	c.cs = NewClient(conf.APIUrl, conf.APIKey, conf.SecretKey, verifySSL, csOptions...)
+	c.csAsync = NewAsyncClient(conf.APIUrl, conf.APIKey, conf.SecretKey, verifySSL, csOptions...)
 	c.customMetrics = metrics.NewCustomMetrics()
 
-	p := c.cs.User.NewListUsersParams()
-	userResponse, err := c.cs.User.ListUsers(p)
-	if err != nil {
-		return c, err
-	}
-	user := &User{
-		ID: userResponse.Users[0].Id,
-		Account: Account{
-			Name: userResponse.Users[0].Account,
-			Domain: Domain{
-				ID: userResponse.Users[0].Domainid,
+	var user *User
+	var userDomain string
+	if session != nil {
+		// The login response identifies the user, whereas listing users as an admin returns more than just the caller.
+		login, err := session.Login()
+		if err != nil {
+			return nil, err
+		}
+		user = &User{ID: login.UserID, Account: Account{Name: login.Account, Domain: Domain{ID: login.DomainID}}}
+	} else {
+		p := c.cs.User.NewListUsersParams()
+		userResponse, err := c.cs.User.ListUsers(p)
+		if err != nil {
+			return c, err
+		}
+		user = &User{
+			ID: userResponse.Users[0].Id,
+			Account: Account{
+				Name: userResponse.Users[0].Account,
+				Domain: Domain{
+					ID: userResponse.Users[0].Domainid,
+				},
 			},
-		},
+		}
+		userDomain = userResponse.Users[0].Domain
 	}
 
 	// Add project config if a ProjectID is defined in the client config.
@@ -215,13 +337,22 @@ func NewClientFromConf(conf Config, clientConfig *corev1.ConfigMap, options ...C
 		}
 	}
 
-	if found, err := c.GetUserWithKeys(user); err != nil {
+	if session != nil {
+		// A session acts as the logged in user, so there are no API keys to look up.
+		if err := c.ResolveAccount(&user.Account); err != nil {
+			return nil, errors.Wrapf(err, "resolving account %s details", user.Account.Name)
+		}
+		if err := c.ResolveProject(user); err != nil {
+			return nil, errors.Wrapf(err, "resolving project %s details", user.Project.Name)
+		}
+	} else if found, err := c.GetUserWithKeys(user); err != nil {
 		return nil, err
 	} else if !found {
 		return nil, errors.Errorf(
-			"could not find sufficient user (with API keys) in domain/account %s/%s", userResponse.Users[0].Domain, userResponse.Users[0].Account)
+			"could not find sufficient user (with API keys) in domain/account %s/%s", userDomain, user.Account.Name)
 	}
 	clientCache.Set(clientCacheKey, c, ttlcache.DefaultTTL)
+	addClientCacheDependent(dependsOn, clientCacheKey)
 
 	return c, nil
 }
@@ -242,17 +373,28 @@ func (c *client) NewClientInDomainAndAccount(domain string, account string, opti
 	user = c.user
 	c.user = oldUser
 
-	if found, err := c.GetUserWithKeys(user); err != nil {
+	found, err := c.GetUserWithKeys(user)
+	if err != nil {
 		return nil, err
-	} else if !found {
+	}
+	// Build the new config from a copy so this (possibly cached) client keeps acting as its own user. The project is
+	// part of the config so clients for different projects of the same account are cached separately.
+	conf := c.config
+	switch {
+	case found:
+		conf.APIKey = user.APIKey
+		conf.SecretKey = user.SecretKey
+		conf.Username, conf.Password, conf.Domain = "", "", ""
+	case c.config.usesSessionAuth() && user.Account.ID == c.user.Account.ID:
+		// A session client can keep using its own login for its own account, e.g. to act in one of its projects.
+	default:
 		return nil, errors.Errorf(
 			"could not find sufficient user (with API keys) in domain/account %s/%s", domain, account)
 	}
-	c.config.APIKey = user.APIKey
-	c.config.SecretKey = user.SecretKey
-	c.user = user
+	conf.ProjectID = user.Project.ID
 
-	return NewClientFromConf(c.config, nil)
+	// Evict the new client along with this one, so it doesn't outlive the credentials it was resolved with.
+	return newClientFromConf(conf, nil, generateClientCacheKey(c.config))
 }
 
 // NewClientFromCSAPIClient creates a client from a CloudStack-Go API client. Used only for testing.
@@ -261,13 +403,21 @@ func NewClientFromCSAPIClient(cs *cloudstack.CloudStackClient, user *User) Clien
 		user = &User{
 			Account: Account{
 				Domain: Domain{
-					CPUAvailable:    LimitUnlimited,
-					MemoryAvailable: LimitUnlimited,
-					VMAvailable:     LimitUnlimited,
+					CPUAvailable:            LimitUnlimited,
+					MemoryAvailable:         LimitUnlimited,
+					VMAvailable:             LimitUnlimited,
+					PrimaryStorageAvailable: LimitUnlimited,
+					VolumeAvailable:         LimitUnlimited,
+					IPAvailable:             LimitUnlimited,
+					NetworkAvailable:        LimitUnlimited,
 				},
-				CPUAvailable:    LimitUnlimited,
-				MemoryAvailable: LimitUnlimited,
-				VMAvailable:     LimitUnlimited,
+				CPUAvailable:            LimitUnlimited,
+				MemoryAvailable:         LimitUnlimited,
+				VMAvailable:             LimitUnlimited,
+				PrimaryStorageAvailable: LimitUnlimited,
+				VolumeAvailable:         LimitUnlimited,
+				IPAvailable:             LimitUnlimited,
+				NetworkAvailable:        LimitUnlimited,
 			},
 		}
 	}
@@ -286,18 +436,120 @@ func generateClientCacheKey(conf Config) string {
 	return fmt.Sprintf("%+v", conf)
 }
 
-// newClientCache returns a new instance of client cache.
+// newClientCache returns a new instance of client cache. Must be called with cacheMutex held.
 func newClientCache(clientConfig *corev1.ConfigMap) *ttlcache.Cache[string, *client] {
+	clientCacheTTL = GetClientCacheTTL(clientConfig)
 	cache := ttlcache.New[string, *client](
-		ttlcache.WithTTL[string, *client](GetClientCacheTTL(clientConfig)),
+		ttlcache.WithTTL[string, *client](clientCacheTTL),
 		ttlcache.WithDisableTouchOnHit[string, *client](),
 	)
+	cache.OnEviction(func(_ context.Context, reason ttlcache.EvictionReason, item *ttlcache.Item[string, *client]) {
+		clientCacheMetrics.IncrementEvictions(evictionReasonLabel(reason))
+
+		// Eviction callbacks run in their own goroutine, so the lock isn't held by the caller of Delete.
+		cacheMutex.Lock()
+		defer cacheMutex.Unlock()
+		forgetClientCacheDependent(item.Key())
+	})
 
 	go cache.Start() // starts automatic expired item deletion
 
 	return cache
 }
 
+// evictionReasonLabel returns the metrics label for a cache eviction reason.
+func evictionReasonLabel(reason ttlcache.EvictionReason) string {
+	switch reason {
+	case ttlcache.EvictionReasonExpired:
+		return "expired"
+	case ttlcache.EvictionReasonCapacityReached:
+		return "capacity"
+	default:
+		return "invalidated"
+	}
+}
+
+// secretDependentsKey returns the key under which clients built from an endpoint secret are recorded.
+func secretDependentsKey(namespace, name string) string {
+	return "secret:" + namespace + "/" + name
+}
+
+// addClientCacheDependent records the cached client key as built from dependsOn. Must be called with cacheMutex held.
+func addClientCacheDependent(dependsOn, clientCacheKey string) {
+	if dependsOn == "" || dependsOn == clientCacheKey {
+		return
+	}
+	if clientCacheDependents[dependsOn] == nil {
+		clientCacheDependents[dependsOn] = map[string]struct{}{}
+	}
+	clientCacheDependents[dependsOn][clientCacheKey] = struct{}{}
+}
+
+// forgetClientCacheDependent drops an evicted client from the recorded dependents. The clients built from it are
+// recorded under what it was built from instead, so they're still evicted along with the secret. Must be called with
+// cacheMutex held.
+func forgetClientCacheDependent(clientCacheKey string) {
+	if clientCache != nil && clientCache.Has(clientCacheKey) {
+		return // Cached again since it was evicted.
+	}
+	dependents := clientCacheDependents[clientCacheKey]
+	delete(clientCacheDependents, clientCacheKey)
+	for dependsOn, keys := range clientCacheDependents {
+		if _, found := keys[clientCacheKey]; !found {
+			continue
+		}
+		delete(keys, clientCacheKey)
+		for dependent := range dependents {
+			addClientCacheDependent(dependsOn, dependent)
+		}
+		if len(keys) == 0 {
+			delete(clientCacheDependents, dependsOn)
+		}
+	}
+}
+
+// evictClientCacheDependents evicts every client built from dependsOn, and the clients built from those in turn.
+// Returns the number of clients evicted. Must be called with cacheMutex held.
+func evictClientCacheDependents(dependsOn string) int {
+	dependents := clientCacheDependents[dependsOn]
+	delete(clientCacheDependents, dependsOn)
+	evicted := 0
+	for clientCacheKey := range dependents {
+		if clientCache != nil && clientCache.Has(clientCacheKey) {
+			clientCache.Delete(clientCacheKey)
+			evicted++
+		}
+		evicted += evictClientCacheDependents(clientCacheKey)
+	}
+
+	return evicted
+}
+
+// InvalidateClientsForSecret evicts the cached clients built from the named endpoint secret, including clients created
+// from them for other accounts, so that the next lookup uses the secret's current credentials. Returns the number of
+// clients evicted.
+func InvalidateClientsForSecret(namespace, name string) int {
+	cacheMutex.Lock()
+	defer cacheMutex.Unlock()
+
+	return evictClientCacheDependents(secretDependentsKey(namespace, name))
+}
+
+// ReloadClientConfig applies a changed client config map. If the client cache TTL changed, the cache is replaced and
+// all cached clients are dropped.
+func ReloadClientConfig(clientConfig *corev1.ConfigMap) {
+	cacheMutex.Lock()
+	defer cacheMutex.Unlock()
+
+	if clientCache == nil || clientCacheTTL == GetClientCacheTTL(clientConfig) {
+		return
+	}
+	clientCache.DeleteAll()
+	clientCache.Stop()
+	clientCache = newClientCache(clientConfig)
+	clientCacheDependents = map[string]map[string]struct{}{}
+}
+
 // GetClientCacheTTL returns a client cache TTL duration from the passed config map.
 func GetClientCacheTTL(clientConfig *corev1.ConfigMap) time.Duration {
 	var cacheTTL time.Duration
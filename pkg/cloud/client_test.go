@@ -41,6 +41,7 @@ var _ = Describe("Client", func() {
 	)
 
 	BeforeEach(func() {
+		dummies.SetDummyUserVars()
 		mockCtrl = gomock.NewController(GinkgoT())
 		mockClient = cloudstack.NewMockClient(mockCtrl)
 		us = mockClient.User.(*cloudstack.MockUserServiceIface)
@@ -182,5 +183,172 @@ var _ = Describe("Client", func() {
 			result2, _ := cloud.NewClientFromConf(config2, clientConfig)
 			Ω(result1).Should(Equal(result2))
 		})
+
+		It("Evicts the clients built from a secret once it's invalidated", func() {
+			secret := &corev1.Secret{}
+			secret.Namespace = "default"
+			secret.Name = "rotated-secret"
+			secret.Data = map[string][]byte{"api-url": []byte("http://5.5.5.5")}
+			result1, err := cloud.NewClientFromK8sSecret(secret, clientConfig)
+			Ω(err).ShouldNot(HaveOccurred())
+
+			Ω(cloud.InvalidateClientsForSecret("default", "rotated-secret")).Should(Equal(1))
+			Ω(cloud.InvalidateClientsForSecret("default", "rotated-secret")).Should(BeZero())
+
+			result2, _ := cloud.NewClientFromK8sSecret(secret, clientConfig)
+			Ω(result2).ShouldNot(BeIdenticalTo(result1))
+		})
+
+		It("Rejects a CA bundle without certificates", func() {
+			config := cloud.Config{
+				APIUrl: "http://9.9.9.1",
+				CACert: "not a certificate",
+			}
+			_, err := cloud.NewClientFromConf(config, clientConfig)
+			Ω(err).Should(MatchError(ContainSubstring("contains no valid PEM certificates")))
+		})
+
+		It("Rejects an unsupported minimum TLS version", func() {
+			config := cloud.Config{
+				APIUrl:        "http://9.9.9.2",
+				TLSMinVersion: "1.4",
+			}
+			_, err := cloud.NewClientFromConf(config, clientConfig)
+			Ω(err).Should(MatchError(ContainSubstring("unsupported tls-min-version 1.4")))
+		})
+
+		It("Rejects a client certificate without a key", func() {
+			config := cloud.Config{
+				APIUrl:     "http://9.9.9.3",
+				ClientCert: "not a certificate",
+			}
+			_, err := cloud.NewClientFromConf(config, clientConfig)
+			Ω(err).Should(MatchError(ContainSubstring("loading client-cert and client-key")))
+		})
+
+		It("Accepts a valid CA bundle and client certificate", func() {
+			caCert, _, err := helpers.SelfSignedCertificate("endpoint-ca")
+			Ω(err).ShouldNot(HaveOccurred())
+			clientCert, clientKey, err := helpers.SelfSignedCertificate("capc")
+			Ω(err).ShouldNot(HaveOccurred())
+			config := cloud.Config{
+				APIUrl:     "http://9.9.9.5",
+				CACert:     string(caCert),
+				ClientCert: string(clientCert),
+				ClientKey:  string(clientKey),
+			}
+			result, err := cloud.NewClientFromConf(config, clientConfig)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(result).ShouldNot(BeNil())
+		})
+
+		It("Reads TLS settings next to an INI cloud-config secret", func() {
+			secret := &corev1.Secret{}
+			secret.Namespace = "default"
+			secret.Name = "tls-endpoint"
+			secret.Data = map[string][]byte{
+				cloud.CloudConfigSecretKey: []byte("[Global]\napi-url = http://9.9.9.4"),
+				cloud.TLSMinVersionKey:     []byte("1.4"),
+			}
+			_, err := cloud.NewClientFromK8sSecret(secret, clientConfig)
+			Ω(err).Should(MatchError(ContainSubstring("unsupported tls-min-version 1.4")))
+		})
+
+		It("Rejects an invalid connect timeout", func() {
+			config := cloud.Config{
+				APIUrl:         "http://9.9.9.5",
+				ConnectTimeout: "ten seconds",
+			}
+			_, err := cloud.NewClientFromConf(config, clientConfig)
+			Ω(err).Should(MatchError(ContainSubstring("parsing connect-timeout")))
+		})
+
+		It("Applies HTTP client defaults from the client config map", func() {
+			defaultsConfig := &corev1.ConfigMap{Data: map[string]string{
+				cloud.ClientCacheTTLKey:  clientConfig.Data[cloud.ClientCacheTTLKey],
+				cloud.AsyncJobTimeoutKey: "-1s",
+			}}
+			config := cloud.Config{
+				APIUrl: "http://9.9.9.6",
+			}
+			_, err := cloud.NewClientFromConf(config, defaultsConfig)
+			Ω(err).Should(MatchError(ContainSubstring("async-job-timeout must be positive")))
+
+			config.AsyncJobTimeout = "10m"
+			_, err = cloud.NewClientFromConf(config, defaultsConfig)
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+
+		It("Returns a client for an INI cloud-config secret", func() {
+			secret := &corev1.Secret{}
+			secret.Namespace = "default"
+			secret.Name = "endpoint2"
+			secret.Data = map[string][]byte{cloud.CloudConfigSecretKey: []byte(`
+[Global "endpoint1"]
+api-url = http://8.8.8.1
+[Global "endpoint2"]
+api-url = http://8.8.8.2
+`)}
+			result1, err := cloud.NewClientFromK8sSecret(secret, clientConfig)
+			Ω(err).ShouldNot(HaveOccurred())
+			result2, _ := cloud.NewClientFromConf(cloud.Config{APIUrl: "http://8.8.8.2"}, clientConfig)
+			Ω(result2).Should(BeIdenticalTo(result1))
+		})
+
+		It("Scopes an INI cloud-config secret to the project-id next to it", func() {
+			secret := &corev1.Secret{}
+			secret.Namespace = "default"
+			secret.Name = "tenant-endpoint"
+			secret.Data = map[string][]byte{
+				cloud.CloudConfigSecretKey: []byte("[Global]\napi-url = http://8.8.8.3\nproject-id = ignored"),
+				cloud.ProjectIDKey:         []byte("tenant-project"),
+			}
+			ps := mockClient.Project.(*cloudstack.MockProjectServiceIface)
+			ps.EXPECT().NewListProjectsParams().Return(&cloudstack.ListProjectsParams{})
+			ps.EXPECT().ListProjects(gomock.Any()).DoAndReturn(
+				func(p *cloudstack.ListProjectsParams) (*cloudstack.ListProjectsResponse, error) {
+					id, _ := p.GetId()
+					Ω(id).Should(Equal("tenant-project"))
+
+					return &cloudstack.ListProjectsResponse{Count: 1, Projects: []*cloudstack.Project{{Id: id}}}, nil
+				})
+
+			result, err := cloud.NewClientFromK8sSecret(secret, clientConfig)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(result).ShouldNot(BeNil())
+		})
+
+		It("Doesn't evict clients built from other secrets", func() {
+			secret := &corev1.Secret{}
+			secret.Namespace = "default"
+			secret.Name = "other-secret"
+			secret.Data = map[string][]byte{"api-url": []byte("http://6.6.6.6")}
+			result1, _ := cloud.NewClientFromK8sSecret(secret, clientConfig)
+
+			Ω(cloud.InvalidateClientsForSecret("default", "unrelated-secret")).Should(BeZero())
+
+			result2, _ := cloud.NewClientFromK8sSecret(secret, clientConfig)
+			Ω(result2).Should(BeIdenticalTo(result1))
+		})
+
+		It("Drops cached clients when the client cache TTL changes", func() {
+			config := cloud.Config{
+				APIUrl: "http://7.7.7.7",
+			}
+			// A TTL long enough that the first client can't expire before it's looked up again.
+			longTTLConfig := &corev1.ConfigMap{Data: map[string]string{cloud.ClientCacheTTLKey: "1m"}}
+			cloud.ReloadClientConfig(longTTLConfig)
+			result1, _ := cloud.NewClientFromConf(config, longTTLConfig)
+
+			cloud.ReloadClientConfig(longTTLConfig) // Unchanged TTL.
+			result2, _ := cloud.NewClientFromConf(config, longTTLConfig)
+			Ω(result2).Should(BeIdenticalTo(result1))
+
+			changedConfig := &corev1.ConfigMap{Data: map[string]string{cloud.ClientCacheTTLKey: "200ms"}}
+			cloud.ReloadClientConfig(changedConfig)
+			result3, _ := cloud.NewClientFromConf(config, clientConfig)
+			Ω(result3).ShouldNot(BeIdenticalTo(result1))
+			cloud.ReloadClientConfig(clientConfig)
+		})
 	})
 })
@@ -0,0 +1,161 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloud
+
+import (
+	"bufio"
+	"bytes"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// CloudConfigSecretKey is the secret key the CloudStack CCM and CSI driver read their INI cloud-config from.
+const CloudConfigSecretKey = "cloud-config"
+
+const cloudConfigGlobalSection = "global"
+
+// IsINICloudConfig returns whether the content looks like an INI cloud-config rather than yaml.
+func IsINICloudConfig(content []byte) bool {
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		return strings.HasPrefix(line, "[")
+	}
+
+	return false
+}
+
+// ParseCloudConfigINI parses an INI cloud-config as used by the CloudStack CCM and CSI driver. A plain [Global]
+// section is returned under the empty name, and each [Global "name"] section under its name, so that one file can hold
+// several endpoints. Other sections and unknown keys are ignored.
+func ParseCloudConfigINI(content []byte) (map[string]Config, error) {
+	configs := map[string]Config{}
+	var (
+		section   string
+		inSection bool
+		inGlobal  bool
+	)
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") {
+				return nil, errors.Errorf("line %d: unterminated section header", lineNum)
+			}
+			header := strings.TrimSpace(line[1 : len(line)-1])
+			name, subsection, _ := strings.Cut(header, " ")
+			inSection = true
+			inGlobal = strings.EqualFold(name, cloudConfigGlobalSection)
+			if !inGlobal {
+				continue
+			}
+			section = ""
+			if subsection = strings.TrimSpace(subsection); subsection != "" {
+				unquoted, err := strconv.Unquote(subsection)
+				if err != nil {
+					return nil, errors.Errorf("line %d: section name %s must be quoted", lineNum, subsection)
+				}
+				section = unquoted
+			}
+			if _, exists := configs[section]; !exists {
+				configs[section] = Config{}
+			}
+
+			continue
+		}
+
+		if !inSection {
+			return nil, errors.Errorf("line %d: key outside of a section", lineNum)
+		} else if !inGlobal {
+			continue
+		}
+		key, value, found := strings.Cut(line, "=")
+		if !found {
+			return nil, errors.Errorf("line %d: expected key = value", lineNum)
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+		if unquoted, err := strconv.Unquote(value); err == nil {
+			value = unquoted
+		}
+
+		config := configs[section]
+		switch key {
+		case "api-url":
+			config.APIUrl = value
+		case "api-key":
+			config.APIKey = value
+		case "secret-key":
+			config.SecretKey = value
+		case "project-id":
+			config.ProjectID = value
+		case "verify-ssl":
+			config.VerifySSL = value
+		case HTTPProxyKey:
+			config.HTTPProxy = value
+		case ConnectTimeoutKey:
+			config.ConnectTimeout = value
+		case ReadTimeoutKey:
+			config.ReadTimeout = value
+		case AsyncJobTimeoutKey:
+			config.AsyncJobTimeout = value
+		case UsernameKey:
+			config.Username = value
+		case PasswordKey:
+			config.Password = value
+		case DomainKey:
+			config.Domain = value
+		case "ssl-no-verify":
+			noVerify, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, errors.Wrapf(err, "line %d: parsing ssl-no-verify", lineNum)
+			}
+			config.VerifySSL = strconv.FormatBool(!noVerify)
+		}
+		configs[section] = config
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(configs) == 0 {
+		return nil, errors.New("cloud-config has no [Global] section")
+	}
+
+	return configs, nil
+}
+
+// selectCloudConfig returns the config of the named endpoint, falling back to the plain [Global] section.
+func selectCloudConfig(configs map[string]Config, name string) (Config, error) {
+	if config, found := configs[name]; found {
+		return config, nil
+	}
+	if config, found := configs[""]; found {
+		return config, nil
+	}
+
+	return Config{}, errors.Errorf("cloud-config has neither a [Global] nor a [Global %q] section", name)
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cloud_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"sigs.k8s.io/cluster-api-provider-cloudstack/pkg/cloud"
+)
+
+var _ = Describe("CloudConfig", func() {
+	Context("IsINICloudConfig", func() {
+		It("recognizes an INI cloud-config", func() {
+			Ω(cloud.IsINICloudConfig([]byte("# comment\n\n[Global]\napi-url = http://1.1.1.1"))).Should(BeTrue())
+		})
+
+		It("doesn't mistake yaml for INI", func() {
+			Ω(cloud.IsINICloudConfig([]byte("api-url: http://1.1.1.1\napi-key: key"))).Should(BeFalse())
+		})
+	})
+
+	Context("ParseCloudConfigINI", func() {
+		It("parses the [Global] section used by the CCM and CSI driver", func() {
+			configs, err := cloud.ParseCloudConfigINI([]byte(`
+[Global]
+api-url = http://1.1.1.1:8080/client/api
+api-key = "key"
+secret-key = secret
+ssl-no-verify = true
+project-id = project
+http-proxy = http://proxy:3128
+async-job-timeout = 10m
+zone = ignored
+`))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(configs).Should(HaveKeyWithValue("", cloud.Config{
+				APIUrl:          "http://1.1.1.1:8080/client/api",
+				APIKey:          "key",
+				SecretKey:       "secret",
+				VerifySSL:       "false",
+				ProjectID:       "project",
+				HTTPProxy:       "http://proxy:3128",
+				AsyncJobTimeout: "10m",
+			}))
+		})
+
+		It("parses one section per endpoint", func() {
+			configs, err := cloud.ParseCloudConfigINI([]byte(`
+[Global "endpoint1"]
+api-url = http://1.1.1.1
+; Other sections are ignored.
+[Labels]
+key = value
+[Global "endpoint2"]
+api-url = http://2.2.2.2
+`))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(configs).Should(HaveLen(2))
+			Ω(configs["endpoint1"].APIUrl).Should(Equal("http://1.1.1.1"))
+			Ω(configs["endpoint2"].APIUrl).Should(Equal("http://2.2.2.2"))
+		})
+
+		It("errors without a [Global] section", func() {
+			_, err := cloud.ParseCloudConfigINI([]byte("[Labels]\nkey = value"))
+			Ω(err).Should(MatchError(ContainSubstring("no [Global] section")))
+		})
+
+		It("errors on keys outside of a section", func() {
+			_, err := cloud.ParseCloudConfigINI([]byte("api-url = http://1.1.1.1\n[Global]"))
+			Ω(err).Should(MatchError(ContainSubstring("key outside of a section")))
+		})
+
+		It("errors on an invalid ssl-no-verify", func() {
+			_, err := cloud.ParseCloudConfigINI([]byte("[Global]\nssl-no-verify = maybe"))
+			Ω(err).Should(MatchError(ContainSubstring("parsing ssl-no-verify")))
+		})
+	})
+})
@@ -0,0 +1,197 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloud
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/apache/cloudstack-go/v2/cloudstack"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Endpoint secret keys of the TLS settings. CACertSecretRefKey and ClientCertSecretRefKey reference other secrets in
+// the endpoint secret's namespace, and are inlined into the CACertKey, ClientCertKey and ClientKeyKey keys before the
+// client is built.
+const (
+	CACertKey              = "ca-cert"
+	ClientCertKey          = "client-cert"
+	ClientKeyKey           = "client-key"
+	TLSMinVersionKey       = "tls-min-version"
+	CACertSecretRefKey     = "ca-cert-secret-ref"
+	ClientCertSecretRefKey = "client-cert-secret-ref"
+)
+
+// Keys of the HTTP client settings, both in the endpoint secret and, as defaults for all endpoints, in the client
+// config map.
+const (
+	HTTPProxyKey       = "http-proxy"
+	ConnectTimeoutKey  = "connect-timeout"
+	ReadTimeoutKey     = "read-timeout"
+	AsyncJobTimeoutKey = "async-job-timeout"
+)
+
+// Defaults match the clients cloudstack-go builds itself.
+const (
+	defaultConnectTimeout = 30 * time.Second
+	defaultReadTimeout    = 60 * time.Second
+)
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// hasTransportSettings returns whether any TLS setting beyond verify-ssl, or any HTTP transport setting is configured.
+func (conf Config) hasTransportSettings() bool {
+	return conf.CACert != "" || conf.ClientCert != "" || conf.ClientKey != "" || conf.TLSMinVersion != "" ||
+		conf.HTTPProxy != "" || conf.ConnectTimeout != "" || conf.ReadTimeout != ""
+}
+
+// withClientConfigDefaults returns the config with unset HTTP client settings taken from the client config map.
+func (conf Config) withClientConfigDefaults(clientConfig *corev1.ConfigMap) Config {
+	if clientConfig == nil {
+		return conf
+	}
+	for key, field := range map[string]*string{
+		HTTPProxyKey:       &conf.HTTPProxy,
+		ConnectTimeoutKey:  &conf.ConnectTimeout,
+		ReadTimeoutKey:     &conf.ReadTimeout,
+		AsyncJobTimeoutKey: &conf.AsyncJobTimeout,
+	} {
+		if *field == "" {
+			*field = clientConfig.Data[key]
+		}
+	}
+
+	return conf
+}
+
+// parseDuration parses an optional duration setting, returning the default if it's unset.
+func parseDuration(key, value string, defaultValue time.Duration) (time.Duration, error) {
+	if value == "" {
+		return defaultValue, nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, errors.Wrapf(err, "parsing %s", key)
+	} else if duration <= 0 {
+		return 0, errors.Errorf("%s must be positive", key)
+	}
+
+	return duration, nil
+}
+
+// tlsConfig builds the TLS config for the endpoint.
+func (conf Config) tlsConfig(verifySSL bool) (*tls.Config, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: !verifySSL} //nolint:gosec // Verification is up to the user.
+
+	if conf.TLSMinVersion != "" {
+		version, found := tlsVersions[conf.TLSMinVersion]
+		if !found {
+			return nil, errors.Errorf("unsupported %s %s, expected one of 1.0, 1.1, 1.2 or 1.3", TLSMinVersionKey, conf.TLSMinVersion)
+		}
+		tlsConfig.MinVersion = version
+	}
+
+	if conf.CACert != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM([]byte(conf.CACert)) {
+			return nil, errors.Errorf("%s contains no valid PEM certificates", CACertKey)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if conf.ClientCert != "" || conf.ClientKey != "" {
+		cert, err := tls.X509KeyPair([]byte(conf.ClientCert), []byte(conf.ClientKey))
+		if err != nil {
+			return nil, errors.Wrapf(err, "loading %s and %s", ClientCertKey, ClientKeyKey)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+// csClientOptions returns the options to build the CloudStack API clients with, and the login session if the endpoint
+// uses session authentication. The sync and async clients are built with the same options, so they share the session.
+func (conf Config) csClientOptions(verifySSL bool) ([]cloudstack.ClientOption, *session, error) {
+	var options []cloudstack.ClientOption
+
+	asyncJobTimeout, err := parseDuration(AsyncJobTimeoutKey, conf.AsyncJobTimeout, 0)
+	if err != nil {
+		return nil, nil, err
+	} else if asyncJobTimeout > 0 {
+		options = append(options, cloudstack.WithAsyncTimeout(int64(asyncJobTimeout.Seconds())))
+	}
+
+	if !conf.hasTransportSettings() && !conf.usesSessionAuth() {
+		return options, nil, nil
+	}
+	httpClient, err := conf.httpClient(verifySSL)
+	if err != nil {
+		return nil, nil, err
+	}
+	var sess *session
+	if conf.usesSessionAuth() {
+		if sess, err = newSession(conf, httpClient.Transport); err != nil {
+			return nil, nil, err
+		}
+		httpClient.Transport = sess
+	}
+
+	return append(options, cloudstack.WithHTTPClient(httpClient)), sess, nil
+}
+
+// httpClient builds the HTTP client for the endpoint.
+func (conf Config) httpClient(verifySSL bool) (*http.Client, error) {
+	tlsConfig, err := conf.tlsConfig(verifySSL)
+	if err != nil {
+		return nil, err
+	}
+	connectTimeout, err := parseDuration(ConnectTimeoutKey, conf.ConnectTimeout, defaultConnectTimeout)
+	if err != nil {
+		return nil, err
+	}
+	readTimeout, err := parseDuration(ReadTimeoutKey, conf.ReadTimeout, defaultReadTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	transport.DialContext = (&net.Dialer{Timeout: connectTimeout, KeepAlive: 30 * time.Second}).DialContext
+	if conf.HTTPProxy != "" {
+		proxyURL, err := url.Parse(conf.HTTPProxy)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing %s", HTTPProxyKey)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	return &http.Client{Transport: transport, Timeout: readTimeout}, nil
+}
@@ -19,7 +19,6 @@ package cloud
 import (
 	"encoding/base64"
 	"fmt"
-	"strconv"
 	"strings"
 
 	"github.com/apache/cloudstack-go/v2/cloudstack"
@@ -61,6 +60,13 @@ func setMachineDataFromVMMetrics(vmResponse *cloudstack.VirtualMachinesMetric, c
 	// InstanceID is later used as required parameter to destroy VM.
 	csMachine.Spec.InstanceID = ptr.To(vmResponse.Id)
 	csMachine.Status.Addresses = []corev1.NodeAddress{{Type: corev1.NodeInternalIP, Address: vmResponse.Ipaddress}}
+	// Machines on dual-stack networks also have an IPv6 address on their default NIC.
+	for _, nic := range vmResponse.Nic {
+		if nic.Isdefault && nic.Ip6address != "" {
+			csMachine.Status.Addresses = append(csMachine.Status.Addresses,
+				corev1.NodeAddress{Type: corev1.NodeInternalIP, Address: nic.Ip6address})
+		}
+	}
 	newInstanceState := vmResponse.State
 	if newInstanceState != csMachine.Status.InstanceState || (newInstanceState != "" && csMachine.Status.InstanceStateLastUpdated.IsZero()) {
 		csMachine.Status.InstanceState = newInstanceState
@@ -192,9 +198,10 @@ func (c *client) resolveTemplate(
 // resolveDiskOffering retrieves a diskOffering by using disk offering ID if ID is provided, and checks if the returned
 // disk offering name matches the name provided in the machine spec.
 // If disk offering ID is not provided, the disk offering name is used to retrieve the disk offering ID.
-func (c *client) resolveDiskOffering(csMachine *infrav1.CloudStackMachine, zoneID string) (diskOfferingID string, retErr error) {
+// Returns the disk offering ID along with the disk offering, or neither if the machine has no data disk.
+func (c *client) resolveDiskOffering(csMachine *infrav1.CloudStackMachine, zoneID string) (diskOfferingID string, diskOffering *cloudstack.DiskOffering, retErr error) {
 	if csMachine.Spec.DiskOffering == nil {
-		return "", nil
+		return "", nil, nil
 	}
 	diskOfferingID = csMachine.Spec.DiskOffering.ID
 	if len(csMachine.Spec.DiskOffering.Name) > 0 {
@@ -202,106 +209,83 @@ func (c *client) resolveDiskOffering(csMachine *infrav1.CloudStackMachine, zoneI
 		if err != nil {
 			c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
 
-			return "", multierror.Append(retErr, errors.Wrapf(
+			return "", nil, multierror.Append(retErr, errors.Wrapf(
 				err, "could not get DiskOffering ID from %s", csMachine.Spec.DiskOffering.Name))
 		} else if count != 1 {
-			return "", multierror.Append(retErr, errors.Errorf(
+			return "", nil, multierror.Append(retErr, errors.Errorf(
 				"expected 1 DiskOffering with name %s in zone %s, but got %d", csMachine.Spec.DiskOffering.Name, zoneID, count))
 		} else if len(csMachine.Spec.DiskOffering.ID) > 0 && diskID != csMachine.Spec.DiskOffering.ID {
-			return "", multierror.Append(retErr, errors.Errorf(
+			return "", nil, multierror.Append(retErr, errors.Errorf(
 				"diskOffering ID %s does not match ID %s returned using name %s in zone %s",
 				csMachine.Spec.DiskOffering.ID, diskID, csMachine.Spec.DiskOffering.Name, zoneID))
 		} else if len(diskID) == 0 {
-			return "", multierror.Append(retErr, errors.Errorf(
+			return "", nil, multierror.Append(retErr, errors.Errorf(
 				"empty diskOffering ID %s returned using name %s in zone %s",
 				diskID, csMachine.Spec.DiskOffering.Name, zoneID))
 		}
 		diskOfferingID = diskID
 	}
 	if len(diskOfferingID) == 0 {
-		return "", nil
+		return "", nil, nil
+	}
+
+	diskOffering, err := verifyDiskoffering(csMachine, c, diskOfferingID, retErr)
+	if err != nil {
+		return "", nil, err
 	}
 
-	return verifyDiskoffering(csMachine, c, diskOfferingID, retErr)
+	return diskOfferingID, diskOffering, nil
 }
 
-func verifyDiskoffering(csMachine *infrav1.CloudStackMachine, c *client, diskOfferingID string, retErr error) (string, error) {
+func verifyDiskoffering(csMachine *infrav1.CloudStackMachine, c *client, diskOfferingID string, retErr error) (*cloudstack.DiskOffering, error) {
 	csDiskOffering, count, err := c.cs.DiskOffering.GetDiskOfferingByID(diskOfferingID, cloudstack.WithProject(c.user.Project.ID))
 	if err != nil {
 		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
 
-		return "", multierror.Append(retErr, errors.Wrapf(
+		return nil, multierror.Append(retErr, errors.Wrapf(
 			err, "could not get DiskOffering by ID %s", diskOfferingID))
 	} else if count != 1 {
-		return "", multierror.Append(retErr, errors.Errorf(
+		return nil, multierror.Append(retErr, errors.Errorf(
 			"expected 1 DiskOffering with UUID %s, but got %d", diskOfferingID, count))
 	}
 
 	if csDiskOffering.Iscustomized && csMachine.Spec.DiskOffering.CustomSize == 0 {
-		return "", multierror.Append(retErr, errors.Errorf(
+		return nil, multierror.Append(retErr, errors.Errorf(
 			"diskOffering with UUID %s is customized, disk size can not be 0 GB",
 			diskOfferingID))
 	}
 
 	if !csDiskOffering.Iscustomized && csMachine.Spec.DiskOffering.CustomSize > 0 {
-		return "", multierror.Append(retErr, errors.Errorf(
+		return nil, multierror.Append(retErr, errors.Errorf(
 			"diskOffering with UUID %s is not customized, disk size can not be specified",
 			diskOfferingID))
 	}
 
-	return diskOfferingID, nil
+	return csDiskOffering, nil
 }
 
 // checkAccountLimits checks the account's limit of VM, CPU & Memory.
 func (c *client) checkAccountLimits(offering *cloudstack.ServiceOffering) error {
-	if c.user.Account.CPUAvailable != LimitUnlimited {
-		cpuAvailable, err := strconv.ParseInt(c.user.Account.CPUAvailable, 10, 0)
-		if err == nil && int64(offering.Cpunumber) > cpuAvailable {
-			return fmt.Errorf("CPU available (%d) in account can't fulfil the requirement: %d", cpuAvailable, offering.Cpunumber)
-		}
-	}
-
-	if c.user.Account.MemoryAvailable != LimitUnlimited {
-		memoryAvailable, err := strconv.ParseInt(c.user.Account.MemoryAvailable, 10, 0)
-		if err == nil && int64(offering.Memory) > memoryAvailable {
-			return fmt.Errorf("memory available (%d) in account can't fulfil the requirement: %d", memoryAvailable, offering.Memory)
-		}
+	if err := checkLimit(LimitScopeAccount, LimitResourceCPU, c.user.Account.CPUAvailable, int64(offering.Cpunumber)); err != nil {
+		return err
 	}
-
-	if c.user.Account.VMAvailable != LimitUnlimited {
-		vmAvailable, err := strconv.ParseInt(c.user.Account.VMAvailable, 10, 0)
-		if err == nil && vmAvailable < 1 {
-			return errors.New("VM limit in account has reached its maximum value")
-		}
+	if err := checkLimit(LimitScopeAccount, LimitResourceMemory, c.user.Account.MemoryAvailable, int64(offering.Memory)); err != nil {
+		return err
 	}
 
-	return nil
+	return checkLimit(LimitScopeAccount, LimitResourceVM, c.user.Account.VMAvailable, 1)
 }
 
 // checkDomainLimits checks the domain's limit of VM, CPU & Memory.
 func (c *client) checkDomainLimits(offering *cloudstack.ServiceOffering) error {
-	if c.user.Account.Domain.CPUAvailable != LimitUnlimited {
-		cpuAvailable, err := strconv.ParseInt(c.user.Account.Domain.CPUAvailable, 10, 0)
-		if err == nil && int64(offering.Cpunumber) > cpuAvailable {
-			return fmt.Errorf("CPU available (%d) in domain can't fulfil the requirement: %d", cpuAvailable, offering.Cpunumber)
-		}
-	}
-
-	if c.user.Account.Domain.MemoryAvailable != LimitUnlimited {
-		memoryAvailable, err := strconv.ParseInt(c.user.Account.Domain.MemoryAvailable, 10, 0)
-		if err == nil && int64(offering.Memory) > memoryAvailable {
-			return fmt.Errorf("memory available (%d) in domain can't fulfil the requirement: %d", memoryAvailable, offering.Memory)
-		}
+	if err := checkLimit(LimitScopeDomain, LimitResourceCPU, c.user.Account.Domain.CPUAvailable, int64(offering.Cpunumber)); err != nil {
+		return err
 	}
-
-	if c.user.Account.Domain.VMAvailable != LimitUnlimited {
-		vmAvailable, err := strconv.ParseInt(c.user.Account.Domain.VMAvailable, 10, 0)
-		if err == nil && vmAvailable < 1 {
-			return errors.New("VM limit in domain has reached its maximum value")
-		}
+	if err := checkLimit(LimitScopeDomain, LimitResourceMemory, c.user.Account.Domain.MemoryAvailable, int64(offering.Memory)); err != nil {
+		return err
 	}
 
-	return nil
+	return checkLimit(LimitScopeDomain, LimitResourceVM, c.user.Account.Domain.VMAvailable, 1)
 }
 
 // CheckProjectLimits Checks the project's limit of VM, CPU & Memory.
@@ -309,31 +293,17 @@ func (c *client) CheckProjectLimits(offering *cloudstack.ServiceOffering) error
 	if c.user.Project.ID == "" {
 		return nil
 	}
-
-	if c.user.Project.CPUAvailable != LimitUnlimited {
-		cpuAvailable, err := strconv.ParseInt(c.user.Project.CPUAvailable, 10, 0)
-		if err == nil && int64(offering.Cpunumber) > cpuAvailable {
-			return fmt.Errorf("CPU available (%d) in project can't fulfil the requirement: %d", cpuAvailable, offering.Cpunumber)
-		}
+	if err := checkLimit(LimitScopeProject, LimitResourceCPU, c.user.Project.CPUAvailable, int64(offering.Cpunumber)); err != nil {
+		return err
 	}
-
-	if c.user.Project.MemoryAvailable != LimitUnlimited {
-		memoryAvailable, err := strconv.ParseInt(c.user.Project.MemoryAvailable, 10, 0)
-		if err == nil && int64(offering.Memory) > memoryAvailable {
-			return fmt.Errorf("memory available (%d) in project can't fulfil the requirement: %d", memoryAvailable, offering.Memory)
-		}
+	if err := checkLimit(LimitScopeProject, LimitResourceMemory, c.user.Project.MemoryAvailable, int64(offering.Memory)); err != nil {
+		return err
 	}
 
-	if c.user.Project.VMAvailable != LimitUnlimited {
-		vmAvailable, err := strconv.ParseInt(c.user.Project.VMAvailable, 10, 0)
-		if err == nil && vmAvailable < 1 {
-			return fmt.Errorf("VM Limit in project has reached it's maximum value")
-		}
-	}
-	return nil
+	return checkLimit(LimitScopeProject, LimitResourceVM, c.user.Project.VMAvailable, 1)
 }
 
-// checkLimits will check the account & domain limits.
+// checkLimits will check the account & domain limits. Failures are returned as a LimitExceededError.
 func (c *client) checkLimits(
 	offering *cloudstack.ServiceOffering,
 ) error {
@@ -359,6 +329,49 @@ func (c *client) checkLimits(
 	return nil
 }
 
+// checkStorageLimits checks the volume and primary storage limits for the VM's root and data disks. Disk sizes are only
+// looked up when a primary storage limit applies. The root disk is as large as the service offering's root disk, or
+// else the template.
+func (c *client) checkStorageLimits(
+	csMachine *infrav1.CloudStackMachine,
+	offering *cloudstack.ServiceOffering,
+	templateID string,
+	diskOffering *cloudstack.DiskOffering,
+) error {
+	volumes := int64(1)
+	if diskOffering != nil {
+		volumes++
+	}
+	if err := c.checkResourceLimit(LimitResourceVolume, c.volumeAvailability(), volumes); err != nil {
+		return err
+	}
+	if !c.isResourceLimited(c.primaryStorageAvailability()) {
+		return nil
+	}
+
+	storage := offering.Rootdisksize
+	if storage <= 0 {
+		template, count, err := c.cs.Template.GetTemplateByID(templateID, "executable", cloudstack.WithProject(c.user.Project.ID))
+		if err != nil {
+			c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+
+			return errors.Wrapf(err, "could not get Template by ID %s to check primary storage limits", templateID)
+		} else if count != 1 {
+			return errors.Errorf("expected 1 Template with UUID %s, but got %d", templateID, count)
+		}
+		storage = bytesToGiB(template.Size)
+	}
+	if diskOffering != nil {
+		if csMachine.Spec.DiskOffering.CustomSize > 0 {
+			storage += csMachine.Spec.DiskOffering.CustomSize
+		} else {
+			storage += diskOffering.Disksize
+		}
+	}
+
+	return c.checkResourceLimit(LimitResourcePrimaryStorage, c.primaryStorageAvailability(), storage)
+}
+
 // deployVM will create a VM instance, and sets the infrastructure machine spec and status accordingly.
 func (c *client) deployVM(
 	csMachine *infrav1.CloudStackMachine,
@@ -372,10 +385,13 @@ func (c *client) deployVM(
 	if err != nil {
 		return err
 	}
-	diskOfferingID, err := c.resolveDiskOffering(csMachine, fd.Spec.Zone.ID)
+	diskOfferingID, diskOffering, err := c.resolveDiskOffering(csMachine, fd.Spec.Zone.ID)
 	if err != nil {
 		return err
 	}
+	if err := c.checkStorageLimits(csMachine, offering, templateID, diskOffering); err != nil {
+		return err
+	}
 
 	p := c.cs.VirtualMachine.NewDeployVirtualMachineParams(offering.Id, templateID, fd.Spec.Zone.ID)
 	p.SetNetworkids([]string{fd.Spec.Zone.Network.ID})
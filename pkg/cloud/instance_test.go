@@ -24,6 +24,7 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/utils/ptr"
 
 	infrav1 "sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
@@ -96,6 +97,23 @@ var _ = Describe("Instance", func() {
 			Ω(dummies.CSMachine1.Spec.InstanceID).Should(Equal(ptr.To(vmsResp.Id)))
 		})
 
+		It("reports the IPv6 address of the default NIC on dual-stack networks", func() {
+			vmsResp := &cloudstack.VirtualMachinesMetric{
+				Id:        *dummies.CSMachine1.Spec.InstanceID,
+				Ipaddress: "10.1.1.10",
+				Nic: []cloudstack.Nic{
+					{Isdefault: false, Ip6address: "2001:db8:1::10"},
+					{Isdefault: true, Ipaddress: "10.1.1.10", Ip6address: "2001:db8::10"},
+				},
+			}
+			vms.EXPECT().GetVirtualMachinesMetricByID(*dummies.CSMachine1.Spec.InstanceID, gomock.Any()).Return(vmsResp, 1, nil)
+			Ω(client.ResolveVMInstanceDetails(dummies.CSMachine1)).Should(Succeed())
+			Ω(dummies.CSMachine1.Status.Addresses).Should(Equal([]corev1.NodeAddress{
+				{Type: corev1.NodeInternalIP, Address: "10.1.1.10"},
+				{Type: corev1.NodeInternalIP, Address: "2001:db8::10"},
+			}))
+		})
+
 		It("handles an unknown error when fetching by name", func() {
 			vms.EXPECT().GetVirtualMachinesMetricByID(*dummies.CSMachine1.Spec.InstanceID, gomock.Any()).Return(nil, -1, notFoundError)
 			vms.EXPECT().GetVirtualMachinesMetricByName(dummies.CSMachine1.Name, gomock.Any()).Return(nil, -1, unknownError)
@@ -590,6 +608,47 @@ var _ = Describe("Instance", func() {
 				Should(MatchError(unknownErrorMessage))
 		})
 
+		It("returns a LimitExceededError when the account has too few volumes left", func() {
+			expectVMNotFound()
+			dummies.CSMachine1.Spec.DiskOffering.CustomSize = 0
+			sos.EXPECT().GetServiceOfferingByName(dummies.CSMachine1.Spec.Offering.Name, gomock.Any()).
+				Return(&cloudstack.ServiceOffering{Id: offeringFakeID, Cpunumber: 1, Memory: 1024}, 1, nil)
+			ts.EXPECT().GetTemplateID(dummies.CSMachine1.Spec.Template.Name, executableFilter, dummies.Zone1.ID, gomock.Any(), gomock.Any()).
+				Return(templateFakeID, 1, nil)
+			dos.EXPECT().GetDiskOfferingID(dummies.CSMachine1.Spec.DiskOffering.Name, gomock.Any()).
+				Return(diskOfferingFakeID, 1, nil)
+			dos.EXPECT().GetDiskOfferingByID(diskOfferingFakeID, gomock.Any()).
+				Return(&cloudstack.DiskOffering{Iscustomized: false}, 1, nil)
+
+			c := cloud.NewClientFromCSAPIClient(mockClient, &cloud.User{Account: cloud.Account{VolumeAvailable: "1"}})
+			err := c.GetOrCreateVMInstance(dummies.CSMachine1, dummies.CAPIMachine, dummies.CSFailureDomain1, dummies.CSAffinityGroup, "")
+			Ω(err).Should(MatchError("volume available (1) in account can't fulfil the requirement: 2"))
+			Ω(cloud.IsLimitExceeded(err)).Should(BeTrue())
+		})
+
+		It("returns a LimitExceededError when the root and data disks exceed the domain's primary storage", func() {
+			expectVMNotFound()
+			dummies.CSMachine1.Spec.DiskOffering.CustomSize = 0
+			sos.EXPECT().GetServiceOfferingByName(dummies.CSMachine1.Spec.Offering.Name, gomock.Any()).
+				Return(&cloudstack.ServiceOffering{Id: offeringFakeID, Cpunumber: 1, Memory: 1024}, 1, nil)
+			ts.EXPECT().GetTemplateID(dummies.CSMachine1.Spec.Template.Name, executableFilter, dummies.Zone1.ID, gomock.Any(), gomock.Any()).
+				Return(templateFakeID, 1, nil)
+			dos.EXPECT().GetDiskOfferingID(dummies.CSMachine1.Spec.DiskOffering.Name, gomock.Any()).
+				Return(diskOfferingFakeID, 1, nil)
+			dos.EXPECT().GetDiskOfferingByID(diskOfferingFakeID, gomock.Any()).
+				Return(&cloudstack.DiskOffering{Iscustomized: false, Disksize: 15}, 1, nil)
+			// A little over 10 GiB counts as 11.
+			ts.EXPECT().GetTemplateByID(templateFakeID, executableFilter, gomock.Any()).
+				Return(&cloudstack.Template{Size: 10<<30 + 1}, 1, nil)
+
+			c := cloud.NewClientFromCSAPIClient(mockClient, &cloud.User{
+				Account: cloud.Account{Domain: cloud.Domain{PrimaryStorageAvailable: "20"}, PrimaryStorageAvailable: cloud.LimitUnlimited},
+			})
+			err := c.GetOrCreateVMInstance(dummies.CSMachine1, dummies.CAPIMachine, dummies.CSFailureDomain1, dummies.CSAffinityGroup, "")
+			Ω(err).Should(MatchError("primary storage (GiB) available (20) in domain can't fulfil the requirement: 26"))
+			Ω(cloud.IsLimitExceeded(err)).Should(BeTrue())
+		})
+
 		Context("when using UUIDs and/or names to locate service offerings and templates", func() {
 			BeforeEach(func() {
 				gomock.InOrder(
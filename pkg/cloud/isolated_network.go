@@ -38,12 +38,13 @@ type IsoNetworkIface interface {
 	ReconcileLoadBalancer(fd *infrav1.CloudStackFailureDomain, isoNet *infrav1.CloudStackIsolatedNetwork, csCluster *infrav1.CloudStackCluster) error
 
 	AssociatePublicIPAddress(fd *infrav1.CloudStackFailureDomain, isoNet *infrav1.CloudStackIsolatedNetwork, desiredIP string) (*cloudstack.PublicIpAddress, error)
-	CreateEgressFirewallRules(isoNet *infrav1.CloudStackIsolatedNetwork) error
+	ReconcileEgressFirewallRules(isoNet *infrav1.CloudStackIsolatedNetwork) error
 	GetPublicIP(fd *infrav1.CloudStackFailureDomain, desiredIP string) (*cloudstack.PublicIpAddress, error)
 	GetLoadBalancerRules(isoNet *infrav1.CloudStackIsolatedNetwork) ([]*cloudstack.LoadBalancerRule, error)
 	ReconcileLoadBalancerRules(isoNet *infrav1.CloudStackIsolatedNetwork, csCluster *infrav1.CloudStackCluster) error
 	GetFirewallRules(isoNet *infrav1.CloudStackIsolatedNetwork) ([]*cloudstack.FirewallRule, error)
 	ReconcileFirewallRules(isoNet *infrav1.CloudStackIsolatedNetwork, csCluster *infrav1.CloudStackCluster) error
+	ReconcileIPv6FirewallRules(isoNet *infrav1.CloudStackIsolatedNetwork, csCluster *infrav1.CloudStackCluster) error
 
 	AssignVMToLoadBalancerRules(isoNet *infrav1.CloudStackIsolatedNetwork, instanceID string) error
 	DeleteNetwork(net infrav1.Network) error
@@ -52,20 +53,65 @@ type IsoNetworkIface interface {
 
 const (
 	K8sDefaultAPIPort = 6443
+
+	FirewallTrafficTypeIngress = "Ingress"
 )
 
-// getNetworkOfferingID fetches the id of a network offering.
-func (c *client) getNetworkOfferingID() (string, error) {
-	offeringID, count, retErr := c.cs.NetworkOffering.GetNetworkOfferingID(NetOffering)
-	if retErr != nil {
-		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(retErr)
+// networkOfferingServices lists the services an isolated network offering must provide for CAPC to set up the network.
+var networkOfferingServices = []string{"SourceNat", "Lb", "Firewall"}
 
-		return "", retErr
-	} else if count != 1 {
-		return "", errors.New("found more than one network offering")
+// resolveNetworkOffering looks up the network offering of an isolated network by ID first and name second, falling
+// back to the default offering, and checks that it provides the services CAPC relies on.
+func (c *client) resolveNetworkOffering(isoNet *infrav1.CloudStackIsolatedNetwork) (*cloudstack.NetworkOffering, error) {
+	offering := infrav1.CloudStackResourceIdentifier{Name: NetOffering}
+	if isoNet.Spec.Offering != nil {
+		offering = *isoNet.Spec.Offering
 	}
 
-	return offeringID, nil
+	var csOffering *cloudstack.NetworkOffering
+	if offering.ID != "" {
+		no, count, err := c.cs.NetworkOffering.GetNetworkOfferingByID(offering.ID)
+		if err != nil {
+			c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+
+			return nil, errors.Wrapf(err, "could not get Network Offering by ID %s", offering.ID)
+		} else if count != 1 {
+			return nil, errors.Errorf("expected 1 Network Offering with UUID %s, but got %d", offering.ID, count)
+		}
+		if offering.Name != "" && offering.Name != no.Name {
+			return nil, errors.Errorf(
+				"network offering name %s does not match name %s returned using UUID %s", offering.Name, no.Name, offering.ID)
+		}
+		csOffering = no
+	} else {
+		no, count, err := c.cs.NetworkOffering.GetNetworkOfferingByName(offering.Name)
+		if err != nil {
+			c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+
+			return nil, errors.Wrapf(err, "could not get Network Offering ID from %s", offering.Name)
+		} else if count != 1 {
+			return nil, errors.Errorf("expected 1 Network Offering with name %s, but got %d", offering.Name, count)
+		}
+		csOffering = no
+	}
+
+	var missing []string
+	for _, service := range networkOfferingServices {
+		if !slices.ContainsFunc(csOffering.Service, func(s cloudstack.NetworkOfferingServiceInternal) bool {
+			return strings.EqualFold(s.Name, service)
+		}) {
+			missing = append(missing, service)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, errors.Errorf("network offering %s does not provide the %s service(s)",
+			csOffering.Name, strings.Join(missing, ", "))
+	}
+	if isoNet.Spec.IPv6CIDR != "" && !strings.EqualFold(csOffering.Internetprotocol, InternetProtocolDualStack) {
+		return nil, errors.Errorf("network offering %s does not support dual-stack networks", csOffering.Name)
+	}
+
+	return csOffering, nil
 }
 
 // AssociatePublicIPAddress gets a public IP and associates it to the isolated network.
@@ -86,6 +132,9 @@ func (c *client) AssociatePublicIPAddress(
 	}
 
 	// Public IP found, but not yet associated with network -- associate it.
+	if err := c.checkResourceLimit(LimitResourcePublicIP, c.ipAvailability(), 1); err != nil {
+		return nil, err
+	}
 	p := c.cs.Address.NewAssociateIpAddressParams()
 	p.SetIpaddress(publicAddress.Ipaddress)
 	p.SetNetworkid(isoNet.Spec.ID)
@@ -106,13 +155,20 @@ func (c *client) AssociatePublicIPAddress(
 
 // CreateIsolatedNetwork creates an isolated network in the relevant FailureDomain per passed network specification.
 func (c *client) CreateIsolatedNetwork(fd *infrav1.CloudStackFailureDomain, isoNet *infrav1.CloudStackIsolatedNetwork) error {
-	offeringID, err := c.getNetworkOfferingID()
+	// The network takes up a public IP for its source NAT as well.
+	if err := c.checkResourceLimit(LimitResourceNetwork, c.networkAvailability(), 1); err != nil {
+		return err
+	}
+	if err := c.checkResourceLimit(LimitResourcePublicIP, c.ipAvailability(), 1); err != nil {
+		return err
+	}
+	offering, err := c.resolveNetworkOffering(isoNet)
 	if err != nil {
 		return err
 	}
 
 	// Do isolated network creation.
-	p := c.cs.Network.NewCreateNetworkParams(isoNet.Spec.Name, offeringID, fd.Spec.Zone.ID)
+	p := c.cs.Network.NewCreateNetworkParams(isoNet.Spec.Name, offering.Id, fd.Spec.Zone.ID)
 	p.SetDisplaytext(isoNet.Spec.Name)
 	setIfNotEmpty(c.user.Project.ID, p.SetProjectid)
 	setIfNotEmpty(isoNet.Spec.Domain, p.SetNetworkdomain)
@@ -127,6 +183,14 @@ func (c *client) CreateIsolatedNetwork(fd *infrav1.CloudStackFailureDomain, isoN
 		p.SetStartip(m["startip"])
 		p.SetEndip(m["endip"])
 	}
+	if isoNet.Spec.IPv6CIDR != "" {
+		gateway, err := ipv6Gateway(isoNet.Spec.IPv6CIDR)
+		if err != nil {
+			return errors.Wrap(err, "parsing IPv6 CIDR")
+		}
+		p.SetIp6cidr(isoNet.Spec.IPv6CIDR)
+		p.SetIp6gateway(gateway)
+	}
 	resp, err := c.cs.Network.CreateNetwork(p)
 	if err != nil {
 		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
@@ -135,34 +199,153 @@ func (c *client) CreateIsolatedNetwork(fd *infrav1.CloudStackFailureDomain, isoN
 	}
 	isoNet.Spec.ID = resp.Id
 	isoNet.Spec.CIDR = resp.Cidr
+	isoNet.Spec.IPv6CIDR = resp.Ip6cidr
+	isoNet.Status.OfferingID = offering.Id
 
 	return c.AddCreatedByCAPCTag(ResourceTypeNetwork, isoNet.Spec.ID)
 }
 
-// CreateEgressFirewallRules sets the egress firewall rules for an isolated network.
-func (c *client) CreateEgressFirewallRules(isoNet *infrav1.CloudStackIsolatedNetwork) (retErr error) {
-	protocols := []string{NetworkProtocolTCP, NetworkProtocolUDP, NetworkProtocolICMP}
-	for _, proto := range protocols {
-		p := c.cs.Firewall.NewCreateEgressFirewallRuleParams(isoNet.Spec.ID, proto)
+// defaultEgressRules open the egress firewall of isolated networks without egress rules in their spec.
+var defaultEgressRules = []infrav1.EgressRule{
+	{Protocol: NetworkProtocolTCP},
+	{Protocol: NetworkProtocolUDP},
+	{Protocol: NetworkProtocolICMP},
+}
+
+// ReconcileEgressFirewallRules makes the egress firewall rules of an isolated network match its spec. Rules CAPC created
+// that aren't in the spec are deleted first, then the missing ones are created. Rules CAPC didn't create are left alone,
+// though ones matching the spec aren't created again.
+func (c *client) ReconcileEgressFirewallRules(isoNet *infrav1.CloudStackIsolatedNetwork) error {
+	rules := isoNet.Spec.EgressRules
+	if len(rules) == 0 {
+		rules = defaultEgressRules
+	}
+	desired := make(map[string]struct{}, len(rules))
+	for _, rule := range rules {
+		desired[egressRuleKey(rule)] = struct{}{}
+	}
 
-		if proto == "icmp" {
-			p.SetIcmptype(-1)
-			p.SetIcmpcode(-1)
+	p := c.cs.Firewall.NewListEgressFirewallRulesParams()
+	p.SetNetworkid(isoNet.Spec.ID)
+	setIfNotEmpty(c.user.Project.ID, p.SetProjectid)
+	resp, err := c.cs.Firewall.ListEgressFirewallRules(p)
+	if err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+
+		return errors.Wrapf(err, "listing egress firewall rules of network ID %s", isoNet.Spec.ID)
+	}
+	existing := make(map[string]struct{}, len(resp.EgressFirewallRules))
+	for _, rule := range resp.EgressFirewallRules {
+		key := existingEgressRuleKey(rule)
+		_, wanted := desired[key]
+		_, duplicate := existing[key]
+		if wanted && !duplicate {
+			existing[key] = struct{}{}
+
+			continue
+		}
+		if !createdByCAPC(rule.Tags) {
+			continue
+		}
+		if err := c.deleteEgressFirewallRule(rule.Id); err != nil {
+			return err
 		}
+	}
 
-		_, err := c.cs.Firewall.CreateEgressFirewallRule(p)
-		if err != nil &&
-			// Ignore errors regarding already existing fw rules for TCP/UDP
-			!strings.Contains(strings.ToLower(err.Error()), "there is already") &&
-			// Ignore errors regarding already existing fw rule for ICMP
-			!strings.Contains(strings.ToLower(err.Error()), "new rule conflicts with existing rule") {
-			retErr = multierror.Append(retErr, errors.Wrapf(
-				err, "failed creating egress firewall rule for network ID %s protocol %s", isoNet.Spec.ID, proto))
+	for _, rule := range rules {
+		key := egressRuleKey(rule)
+		if _, found := existing[key]; found {
+			continue
+		}
+		if err := c.createEgressFirewallRule(isoNet, rule); err != nil {
+			return err
 		}
+		existing[key] = struct{}{}
 	}
-	c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(retErr)
 
-	return retErr
+	return nil
+}
+
+// createEgressFirewallRule adds an egress firewall rule to an isolated network.
+func (c *client) createEgressFirewallRule(isoNet *infrav1.CloudStackIsolatedNetwork, rule infrav1.EgressRule) error {
+	protocol := strings.ToLower(rule.Protocol)
+	p := c.cs.Firewall.NewCreateEgressFirewallRuleParams(isoNet.Spec.ID, protocol)
+	p.SetDestcidrlist(egressRuleCIDRs(rule))
+	if start, end := egressRulePorts(rule); start != 0 {
+		p.SetStartport(start)
+		p.SetEndport(end)
+	}
+	if protocol == NetworkProtocolICMP {
+		p.SetIcmptype(-1)
+		p.SetIcmpcode(-1)
+	}
+	resp, err := c.cs.Firewall.CreateEgressFirewallRule(p)
+	if err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+
+		return errors.Wrapf(err, "creating egress firewall rule for network ID %s protocol %s", isoNet.Spec.ID, protocol)
+	}
+
+	return c.AddCreatedByCAPCTag(ResourceTypeFirewallRule, resp.Id)
+}
+
+// deleteEgressFirewallRule removes an egress firewall rule.
+func (c *client) deleteEgressFirewallRule(id string) error {
+	p := c.csAsync.Firewall.NewDeleteEgressFirewallRuleParams(id)
+	if _, err := c.csAsync.Firewall.DeleteEgressFirewallRule(p); err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+
+		return errors.Wrapf(err, "deleting egress firewall rule with ID %s", id)
+	}
+
+	return nil
+}
+
+// egressRuleKey identifies an egress rule by everything that makes it up, so that changed rules are replaced.
+func egressRuleKey(rule infrav1.EgressRule) string {
+	start, end := egressRulePorts(rule)
+	icmp := ""
+	if strings.EqualFold(rule.Protocol, NetworkProtocolICMP) {
+		icmp = "-1/-1"
+	}
+
+	return fmt.Sprintf("%s/%d-%d/%s/%s", strings.ToLower(rule.Protocol), start, end, strings.Join(egressRuleCIDRs(rule), ","), icmp)
+}
+
+// existingEgressRuleKey identifies an egress firewall rule of a network like egressRuleKey does.
+func existingEgressRuleKey(rule *cloudstack.EgressFirewallRule) string {
+	cidrs := []string{"0.0.0.0/0"}
+	if destCIDRs := strings.ReplaceAll(rule.Destcidrlist, " ", ""); destCIDRs != "" {
+		cidrs = capcstrings.Canonicalize(strings.Split(destCIDRs, ","))
+	}
+	icmp := ""
+	if strings.EqualFold(rule.Protocol, NetworkProtocolICMP) {
+		icmp = fmt.Sprintf("%d/%d", rule.Icmptype, rule.Icmpcode)
+	}
+
+	return fmt.Sprintf("%s/%d-%d/%s/%s", strings.ToLower(rule.Protocol), rule.Startport, rule.Endport, strings.Join(cidrs, ","), icmp)
+}
+
+// egressRuleCIDRs returns the sorted destination CIDRs of a rule.
+func egressRuleCIDRs(rule infrav1.EgressRule) []string {
+	if len(rule.DestinationCIDRs) == 0 {
+		return []string{"0.0.0.0/0"}
+	}
+
+	return capcstrings.Canonicalize(slices.Clone(rule.DestinationCIDRs))
+}
+
+// egressRulePorts returns the port range of a rule, zero if the rule applies to all ports.
+func egressRulePorts(rule infrav1.EgressRule) (int, int) {
+	protocol := strings.ToLower(rule.Protocol)
+	if rule.StartPort == 0 || (protocol != NetworkProtocolTCP && protocol != NetworkProtocolUDP) {
+		return 0, 0
+	}
+	if rule.EndPort == 0 {
+		return rule.StartPort, rule.StartPort
+	}
+
+	return rule.StartPort, rule.EndPort
 }
 
 // GetPublicIP gets a public IP. If desiredIP is empty, it will pick the next available IP.
@@ -198,7 +381,8 @@ func (c *client) GetPublicIP(
 	return nil, errors.New("no public addresses found in available networks")
 }
 
-// GetIsolatedNetwork gets an isolated network in the relevant Zone.
+// GetIsolatedNetwork gets an isolated network in the relevant Zone, by name or else by ID, and records its ID, CIDRs and
+// network offering.
 func (c *client) GetIsolatedNetwork(isoNet *infrav1.CloudStackIsolatedNetwork) (retErr error) {
 	netDetails, count, err := c.cs.Network.GetNetworkByName(isoNet.Spec.Name, cloudstack.WithProject(c.user.Project.ID))
 	if err != nil {
@@ -210,6 +394,8 @@ func (c *client) GetIsolatedNetwork(isoNet *infrav1.CloudStackIsolatedNetwork) (
 	} else { // Got netID from the network's name.
 		isoNet.Spec.ID = netDetails.Id
 		isoNet.Spec.CIDR = netDetails.Cidr
+		isoNet.Spec.IPv6CIDR = netDetails.Ip6cidr
+		isoNet.Status.OfferingID = netDetails.Networkofferingid
 
 		return nil
 	}
@@ -224,6 +410,8 @@ func (c *client) GetIsolatedNetwork(isoNet *infrav1.CloudStackIsolatedNetwork) (
 	}
 	isoNet.Spec.Name = netDetails.Name
 	isoNet.Spec.CIDR = netDetails.Cidr
+	isoNet.Spec.IPv6CIDR = netDetails.Ip6cidr
+	isoNet.Status.OfferingID = netDetails.Networkofferingid
 
 	return nil
 }
@@ -272,6 +460,10 @@ func (c *client) ReconcileLoadBalancerRules(isoNet *infrav1.CloudStackIsolatedNe
 			return err
 		}
 
+		if err := c.reconcileAPIServerLoadBalancerRuleSettings(lbr, lbRuleIDs, csCluster.Spec.APIServerLoadBalancer, isoNet.Status.APIServerLoadBalancer); err != nil {
+			return errors.Wrap(err, "reconciling load balancer rule settings")
+		}
+
 		if len(lbRuleIDs) > 1 {
 			capcstrings.Canonicalize(lbRuleIDs)
 		}
@@ -314,7 +506,7 @@ func mapExistingLoadBalancerRules(lbr []*cloudstack.LoadBalancerRule) map[string
 func (c *client) ensureLoadBalancerRules(isoNet *infrav1.CloudStackIsolatedNetwork, ports []int, portsAndIDs map[string]string, csCluster *infrav1.CloudStackCluster) ([]string, error) {
 	lbRuleIDs := make([]string, 0)
 	for _, port := range ports {
-		ruleID, err := c.getOrCreateLoadBalancerRule(isoNet, port, portsAndIDs)
+		ruleID, err := c.getOrCreateLoadBalancerRule(isoNet, port, csCluster.Spec.APIServerLoadBalancer.AlgorithmOrDefault(), portsAndIDs)
 		if err != nil {
 			return nil, err
 		}
@@ -330,14 +522,14 @@ func (c *client) ensureLoadBalancerRules(isoNet *infrav1.CloudStackIsolatedNetwo
 }
 
 // getOrCreateLoadBalancerRule retrieves or creates a load balancer rule for a given port.
-func (c *client) getOrCreateLoadBalancerRule(isoNet *infrav1.CloudStackIsolatedNetwork, port int, portsAndIDs map[string]string) (string, error) {
+func (c *client) getOrCreateLoadBalancerRule(isoNet *infrav1.CloudStackIsolatedNetwork, port int, algorithm string, portsAndIDs map[string]string) (string, error) {
 	portStr := strconv.Itoa(port)
 	ruleID, found := portsAndIDs[portStr]
 	if found {
 		return ruleID, nil
 	}
 	// If not found, create the lb rule for port
-	ruleID, err := c.CreateLoadBalancerRule(isoNet, port)
+	ruleID, err := c.CreateLoadBalancerRule(isoNet, port, algorithm)
 	if err != nil {
 		return "", errors.Wrap(err, "creating load balancer rule")
 	}
@@ -386,15 +578,16 @@ func (c *client) deleteLoadBalancerRuleByID(ruleID string) error {
 	return nil
 }
 
-// CreateLoadBalancerRule configures the loadbalancer to accept traffic to a certain IP:port.
+// CreateLoadBalancerRule configures the loadbalancer to accept traffic to a certain IP:port, balanced with the given
+// algorithm.
 //
 // Note that due to the lack of a cidrlist parameter in UpdateLoadbalancerRule, we can't use
 // loadbalancer ACLs to implement the allowedCIDR functionality, and are forced to use firewall
 // rules instead. See https://github.com/apache/cloudstack/issues/8382 for details.
-func (c *client) CreateLoadBalancerRule(isoNet *infrav1.CloudStackIsolatedNetwork, port int) (string, error) {
+func (c *client) CreateLoadBalancerRule(isoNet *infrav1.CloudStackIsolatedNetwork, port int, algorithm string) (string, error) {
 	name := fmt.Sprintf("K8s_API_%d", port)
 	p := c.cs.LoadBalancer.NewCreateLoadBalancerRuleParams(
-		"roundrobin", name, port, port)
+		algorithm, name, port, port)
 	p.SetPublicport(port)
 	p.SetNetworkid(isoNet.Spec.ID)
 
@@ -472,7 +665,7 @@ func (c *client) ReconcileFirewallRules(isoNet *infrav1.CloudStackIsolatedNetwor
 	if csCluster.Spec.APIServerLoadBalancer.IsEnabled() {
 		// Load balancer enabled, reconcile firewall rules.
 		ports := gatherPorts(csCluster)
-		allowedCIDRS := getCanonicalAllowedCIDRs(isoNet, csCluster)
+		allowedCIDRS := getCanonicalAllowedCIDRs(isoNet.Status.PublicIPAddress, csCluster)
 
 		// A note on the implementation here:
 		// Due to the lack of a `cidrlist` parameter in UpdateFirewallRule, we have to manage
@@ -510,20 +703,22 @@ func gatherPorts(csCluster *infrav1.CloudStackCluster) []int {
 	return ports
 }
 
+// createdByCAPC checks the tags of a firewall rule for the tag CAPC adds to the rules it creates.
+func createdByCAPC(tags []cloudstack.Tags) bool {
+	for _, t := range tags {
+		if t.Key == CreatedByCAPCTagName && t.Value == "1" {
+			return true
+		}
+	}
+
+	return false
+}
+
 // mapExistingFirewallRules creates a lookup map for existing firewall rules based on their port.
 func mapExistingFirewallRules(fwr []*cloudstack.FirewallRule) map[int][]string {
 	portsAndIDs := make(map[int][]string)
 	for _, rule := range fwr {
-		// Check if the rule is managed by CAPC.
-		capcManaged := false
-		for _, t := range rule.Tags {
-			if t.Key == CreatedByCAPCTagName && t.Value == "1" {
-				capcManaged = true
-
-				break
-			}
-		}
-		if capcManaged && rule.Startport == rule.Endport {
+		if createdByCAPC(rule.Tags) && rule.Startport == rule.Endport {
 			portsAndIDs[rule.Startport] = append(portsAndIDs[rule.Startport], rule.Id)
 		}
 	}
@@ -667,18 +862,156 @@ func (c *client) DeleteFirewallRule(id string) (bool, error) {
 	return resp.Success, nil
 }
 
+// ReconcileIPv6FirewallRules opens the API server ports of a dual-stack isolated network to the allowed IPv6 CIDRs.
+// The load balancer only forwards IPv4, so IPv6 clients reach the control plane machines directly. CloudStack doesn't
+// return the ports of IPv6 firewall rules, so each rule carries its port in a tag.
+func (c *client) ReconcileIPv6FirewallRules(isoNet *infrav1.CloudStackIsolatedNetwork, csCluster *infrav1.CloudStackCluster) error {
+	if isoNet.Spec.IPv6CIDR == "" {
+		return nil
+	}
+	desired := map[string]string{}
+	if cidrs := getCanonicalAllowedIPv6CIDRs(csCluster); csCluster.Spec.APIServerLoadBalancer.IsEnabled() && len(cidrs) > 0 {
+		for _, port := range gatherPorts(csCluster) {
+			desired[strconv.Itoa(port)] = strings.Join(cidrs, ",")
+		}
+	}
+
+	p := c.cs.Firewall.NewListIpv6FirewallRulesParams()
+	p.SetNetworkid(isoNet.Spec.ID)
+	p.SetTraffictype(FirewallTrafficTypeIngress)
+	setIfNotEmpty(c.user.Project.ID, p.SetProjectid)
+	resp, err := c.cs.Firewall.ListIpv6FirewallRules(p)
+	if err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+
+		return errors.Wrap(err, "listing IPv6 firewall rules")
+	}
+
+	found := map[string]bool{}
+	for _, rule := range resp.Ipv6FirewallRules {
+		port := apiServerPortTag(rule.Tags)
+		if !isTaggedCreatedByCAPC(rule.Tags) || port == "" {
+			continue
+		}
+		if cidrs, ok := desired[port]; ok && !found[port] && canonicalCIDRList(rule.Cidrlist) == cidrs {
+			found[port] = true
+
+			continue
+		}
+		if err := c.deleteIPv6FirewallRule(rule.Id); err != nil {
+			return err
+		}
+	}
+
+	ports := make([]string, 0, len(desired))
+	for port := range desired {
+		if !found[port] {
+			ports = append(ports, port)
+		}
+	}
+	slices.Sort(ports)
+	for _, port := range ports {
+		if err := c.createIPv6FirewallRule(isoNet, port, desired[port]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// createIPv6FirewallRule allows TCP traffic from a comma separated list of IPv6 CIDRs to a port of the machines in an
+// isolated network.
+func (c *client) createIPv6FirewallRule(isoNet *infrav1.CloudStackIsolatedNetwork, port string, cidrs string) error {
+	portNumber, err := strconv.Atoi(port)
+	if err != nil {
+		return errors.Wrapf(err, "parsing port %s", port)
+	}
+	p := c.cs.Firewall.NewCreateIpv6FirewallRuleParams(isoNet.Spec.ID, NetworkProtocolTCP)
+	p.SetStartport(portNumber)
+	p.SetEndport(portNumber)
+	p.SetCidrlist(strings.Split(cidrs, ","))
+	p.SetTraffictype(FirewallTrafficTypeIngress)
+	resp, err := c.cs.Firewall.CreateIpv6FirewallRule(p)
+	if err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+
+		return errors.Wrapf(err, "creating IPv6 firewall rule for port %s", port)
+	}
+	if err := c.AddTags(ResourceTypeFirewallRule, resp.Id, map[string]string{
+		CreatedByCAPCTagName: "1",
+		APIServerPortTagName: port,
+	}); err != nil {
+		return errors.Wrapf(err, "tagging IPv6 firewall rule with ID %s", resp.Id)
+	}
+
+	return nil
+}
+
+// deleteIPv6FirewallRule removes an IPv6 firewall rule.
+func (c *client) deleteIPv6FirewallRule(id string) error {
+	p := c.csAsync.Firewall.NewDeleteIpv6FirewallRuleParams(id)
+	if _, err := c.csAsync.Firewall.DeleteIpv6FirewallRule(p); err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+
+		return errors.Wrapf(err, "deleting IPv6 firewall rule with ID %s", id)
+	}
+
+	return nil
+}
+
+// apiServerPortTag returns the API server port an IPv6 firewall rule was created for, if any.
+func apiServerPortTag(tags []cloudstack.Tags) string {
+	for _, t := range tags {
+		if t.Key == APIServerPortTagName {
+			return t.Value
+		}
+	}
+
+	return ""
+}
+
+// canonicalCIDRList sorts and deduplicates a comma separated list of CIDRs as returned by CloudStack.
+func canonicalCIDRList(cidrList string) string {
+	cidrs := strings.Split(cidrList, ",")
+	for idx := range cidrs {
+		cidrs[idx] = strings.TrimSpace(cidrs[idx])
+	}
+
+	return strings.Join(capcstrings.Canonicalize(cidrs), ",")
+}
+
+// getCanonicalAllowedIPv6CIDRs gets the IPv6 CIDRs which may access the API server of a dual-stack isolated network.
+// Without allowed CIDRs the API server is open to all; with only IPv4 ones, it isn't reachable over IPv6 at all.
+func getCanonicalAllowedIPv6CIDRs(csCluster *infrav1.CloudStackCluster) []string {
+	if csCluster.Spec.APIServerLoadBalancer == nil || len(csCluster.Spec.APIServerLoadBalancer.AllowedCIDRs) == 0 {
+		return []string{"::/0"}
+	}
+	cidrs := []string{}
+	for _, v := range csCluster.Spec.APIServerLoadBalancer.AllowedCIDRs {
+		switch {
+		case utilsnet.IsIPv6String(v):
+			cidrs = append(cidrs, v+"/128")
+		case utilsnet.IsIPv6CIDRString(v):
+			cidrs = append(cidrs, v)
+		}
+	}
+
+	return capcstrings.Canonicalize(cidrs)
+}
+
 // getCanonicalAllowedCIDRs gets a filtered list of CIDRs which should be allowed to access the API server loadbalancer.
 // Invalid CIDRs are filtered from the list and emil a warning event.
-// It returns a canonical representation that can be directly compared with other canonicalized lists.
-func getCanonicalAllowedCIDRs(isoNet *infrav1.CloudStackIsolatedNetwork, csCluster *infrav1.CloudStackCluster) []string {
+// It returns a canonical representation that can be directly compared with other canonicalized lists. The public IP
+// the cluster's own traffic leaves from is allowed along with the given CIDRs.
+func getCanonicalAllowedCIDRs(publicIP string, csCluster *infrav1.CloudStackCluster) []string {
 	allowedCIDRs := []string{}
 
 	if csCluster.Spec.APIServerLoadBalancer != nil && len(csCluster.Spec.APIServerLoadBalancer.AllowedCIDRs) > 0 {
 		allowedCIDRs = append(allowedCIDRs, csCluster.Spec.APIServerLoadBalancer.AllowedCIDRs...)
 
 		// Add our own outgoing IP
-		if len(isoNet.Status.PublicIPAddress) > 0 {
-			allowedCIDRs = append(allowedCIDRs, isoNet.Status.PublicIPAddress)
+		if len(publicIP) > 0 {
+			allowedCIDRs = append(allowedCIDRs, publicIP)
 		}
 	} else {
 		// If there are no specific CIDRs defined to allow traffic from, default to allow all.
@@ -693,6 +1026,8 @@ func getCanonicalAllowedCIDRs(isoNet *infrav1.CloudStackIsolatedNetwork, csClust
 			validCIDRs = append(validCIDRs, v+"/32")
 		case utilsnet.IsIPv4CIDRString(v):
 			validCIDRs = append(validCIDRs, v)
+		case utilsnet.IsIPv6String(v), utilsnet.IsIPv6CIDRString(v):
+			// Applied by the IPv6 firewall rules of dual-stack networks.
 		default:
 			record.Warnf(csCluster, "FailedIPAddressValidation", "%s is not a valid IPv4 nor CIDR address and will not get applied to firewall rules", v)
 		}
@@ -708,19 +1043,13 @@ func (c *client) GetOrCreateIsolatedNetwork(
 	isoNet *infrav1.CloudStackIsolatedNetwork,
 ) error {
 	// Get or create the isolated network itself and resolve details into passed custom resources.
-	network := isoNet.Network()
-	if err := c.ResolveNetwork(network); err != nil { // Doesn't exist, create isolated network.
+	if err := c.GetIsolatedNetwork(isoNet); err != nil { // Doesn't exist, create isolated network.
 		if err = c.CreateIsolatedNetwork(fd, isoNet); err != nil {
 			return errors.Wrap(err, "creating a new isolated network")
 		}
-	} else {
-		// Network existed and was resolved. Set ID on isoNet CloudStackIsolatedNetwork in case it only had name set.
-		isoNet.Spec.ID = network.ID
-		isoNet.Spec.CIDR = network.CIDR
 	}
 
-	// Open the Isolated Network egress firewall.
-	return errors.Wrap(c.CreateEgressFirewallRules(isoNet), "opening the isolated network's egress firewall")
+	return errors.Wrap(c.ReconcileEgressFirewallRules(isoNet), "reconciling the isolated network's egress firewall")
 }
 
 // ReconcileLoadBalancer configures the API server load balancer.
@@ -758,6 +1087,9 @@ func (c *client) ReconcileLoadBalancer(
 	if err := c.ReconcileFirewallRules(isoNet, csCluster); err != nil {
 		return errors.Wrap(err, "reconciling firewall rules")
 	}
+	if err := c.ReconcileIPv6FirewallRules(isoNet, csCluster); err != nil {
+		return errors.Wrap(err, "reconciling IPv6 firewall rules")
+	}
 
 	if !csCluster.Spec.APIServerLoadBalancer.IsEnabled() && isoNet.Status.APIServerLoadBalancer != nil {
 		// If the APIServerLoadBalancer has been disabled, release its IP unless it's the SNAT IP.
@@ -942,3 +1274,18 @@ func parseCIDR(cidr string) (map[string]string, error) {
 
 	return m, nil
 }
+
+// ipv6Gateway returns the first address of an IPv6 CIDR, which CloudStack uses as the gateway of the network.
+func ipv6Gateway(cidr string) (string, error) {
+	_, ipnet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return "", fmt.Errorf("unable to parse cidr %s: %w", cidr, err)
+	}
+	if ipnet.IP.To4() != nil {
+		return "", fmt.Errorf("cidr %s is not an IPv6 range", cidr)
+	}
+	gateway := slices.Clone(ipnet.IP.To16())
+	gateway[len(gateway)-1]++
+
+	return gateway.String(), nil
+}
@@ -26,6 +26,7 @@ import (
 	"github.com/pkg/errors"
 	"k8s.io/utils/ptr"
 
+	infrav1 "sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
 	"sigs.k8s.io/cluster-api-provider-cloudstack/pkg/cloud"
 	dummies "sigs.k8s.io/cluster-api-provider-cloudstack/test/dummies/v1beta3"
 )
@@ -67,37 +68,40 @@ var _ = Describe("Network", func() {
 		mockCtrl.Finish()
 	})
 
+	// expectOpenEgressFirewall expects the default egress rules to be created on a network without egress rules.
+	expectOpenEgressFirewall := func() {
+		fs.EXPECT().NewListEgressFirewallRulesParams().Return(&csapi.ListEgressFirewallRulesParams{})
+		fs.EXPECT().ListEgressFirewallRules(gomock.Any()).Return(&csapi.ListEgressFirewallRulesResponse{}, nil)
+		for _, protocol := range []string{cloud.NetworkProtocolTCP, cloud.NetworkProtocolUDP, cloud.NetworkProtocolICMP} {
+			fs.EXPECT().NewCreateEgressFirewallRuleParams(dummies.ISONet1.ID, protocol).Return(&csapi.CreateEgressFirewallRuleParams{})
+		}
+		fs.EXPECT().CreateEgressFirewallRule(gomock.Any()).DoAndReturn(func(p *csapi.CreateEgressFirewallRuleParams) (*csapi.CreateEgressFirewallRuleResponse, error) {
+			destCIDRs, _ := p.GetDestcidrlist()
+			Ω(destCIDRs).Should(Equal([]string{"0.0.0.0/0"}))
+
+			return &csapi.CreateEgressFirewallRuleResponse{}, nil
+		}).Times(3)
+		rs.EXPECT().NewCreateTagsParams(gomock.Any(), string(cloud.ResourceTypeFirewallRule), gomock.Any()).
+			Return(&csapi.CreateTagsParams{}).Times(3)
+		rs.EXPECT().CreateTags(gomock.Any()).Return(&csapi.CreateTagsResponse{}, nil).Times(3)
+	}
+
 	Context("Get or Create Isolated network in CloudStack", func() {
 		It("calls to create an isolated network when not found", func() {
 			dummies.Zone1.Network = dummies.ISONet1
 			dummies.Zone1.Network.ID = ""
 
-			nos.EXPECT().GetNetworkOfferingID(gomock.Any()).Return("someOfferingID", 1, nil)
+			nos.EXPECT().GetNetworkOfferingByName(cloud.NetOffering).Return(&csapi.NetworkOffering{
+				Id: "someOfferingID", Name: cloud.NetOffering,
+				Service: []csapi.NetworkOfferingServiceInternal{{Name: "SourceNat"}, {Name: "Lb"}, {Name: "Firewall"}},
+			}, 1, nil)
 			ns.EXPECT().NewCreateNetworkParams(gomock.Any(), gomock.Any(), gomock.Any()).
 				Return(&csapi.CreateNetworkParams{})
 			ns.EXPECT().GetNetworkByName(dummies.ISONet1.Name, gomock.Any()).Return(nil, 0, nil)
 			ns.EXPECT().GetNetworkByID(dummies.ISONet1.ID, gomock.Any()).Return(nil, 0, nil)
 			ns.EXPECT().CreateNetwork(gomock.Any()).Return(&csapi.CreateNetworkResponse{Id: dummies.ISONet1.ID}, nil)
 
-			fs.EXPECT().NewCreateEgressFirewallRuleParams(dummies.ISONet1.ID, gomock.Any()).
-				DoAndReturn(func(_ string, protocol string) *csapi.CreateEgressFirewallRuleParams {
-					p := &csapi.CreateEgressFirewallRuleParams{}
-					if protocol == cloud.NetworkProtocolICMP {
-						p.SetIcmptype(-1)
-						p.SetIcmpcode(-1)
-					}
-
-					return p
-				}).Times(3)
-
-			ruleParamsICMP := &csapi.CreateEgressFirewallRuleParams{}
-			ruleParamsICMP.SetIcmptype(-1)
-			ruleParamsICMP.SetIcmpcode(-1)
-			gomock.InOrder(
-				fs.EXPECT().CreateEgressFirewallRule(&csapi.CreateEgressFirewallRuleParams{}).
-					Return(&csapi.CreateEgressFirewallRuleResponse{}, nil).Times(2),
-				fs.EXPECT().CreateEgressFirewallRule(ruleParamsICMP).
-					Return(&csapi.CreateEgressFirewallRuleResponse{}, nil))
+			expectOpenEgressFirewall()
 
 			// Will add creation tags to network.
 			rs.EXPECT().NewCreateTagsParams(gomock.Any(), gomock.Any(), gomock.Any()).
@@ -106,100 +110,149 @@ var _ = Describe("Network", func() {
 
 			Ω(client.GetOrCreateIsolatedNetwork(dummies.CSFailureDomain1, dummies.CSISONet1)).Should(Succeed())
 			Ω(dummies.CSISONet1.Spec.ID).ShouldNot(BeEmpty())
+			Ω(dummies.CSISONet1.Status.OfferingID).Should(Equal("someOfferingID"))
 		})
 
 		It("resolves the existing isolated network", func() {
 			dummies.SetClusterSpecToNet(&dummies.ISONet1)
 
-			ns.EXPECT().GetNetworkByName(dummies.ISONet1.Name, gomock.Any()).Return(dummies.CAPCNetToCSAPINet(&dummies.ISONet1), 1, nil)
-
-			fs.EXPECT().NewCreateEgressFirewallRuleParams(dummies.ISONet1.ID, gomock.Any()).
-				DoAndReturn(func(_ string, protocol string) *csapi.CreateEgressFirewallRuleParams {
-					p := &csapi.CreateEgressFirewallRuleParams{}
-					if protocol == cloud.NetworkProtocolICMP {
-						p.SetIcmptype(-1)
-						p.SetIcmpcode(-1)
-					}
+			existingNet := dummies.CAPCNetToCSAPINet(&dummies.ISONet1)
+			existingNet.Networkofferingid = "existingOfferingID"
+			ns.EXPECT().GetNetworkByName(dummies.ISONet1.Name, gomock.Any()).Return(existingNet, 1, nil)
 
-					return p
-				}).Times(3)
-
-			ruleParamsICMP := &csapi.CreateEgressFirewallRuleParams{}
-			ruleParamsICMP.SetIcmptype(-1)
-			ruleParamsICMP.SetIcmpcode(-1)
-			gomock.InOrder(
-				fs.EXPECT().CreateEgressFirewallRule(&csapi.CreateEgressFirewallRuleParams{}).
-					Return(&csapi.CreateEgressFirewallRuleResponse{}, nil).Times(2),
-				fs.EXPECT().CreateEgressFirewallRule(ruleParamsICMP).
-					Return(&csapi.CreateEgressFirewallRuleResponse{}, nil))
+			expectOpenEgressFirewall()
 
 			Ω(client.GetOrCreateIsolatedNetwork(dummies.CSFailureDomain1, dummies.CSISONet1)).Should(Succeed())
 			Ω(dummies.CSISONet1.Spec.ID).ShouldNot(BeEmpty())
+			Ω(dummies.CSISONet1.Status.OfferingID).Should(Equal("existingOfferingID"))
 		})
 
 		It("fails to get network offering from CloudStack", func() {
 			ns.EXPECT().GetNetworkByName(dummies.ISONet1.Name, gomock.Any()).Return(nil, 0, nil)
 			ns.EXPECT().GetNetworkByID(dummies.ISONet1.ID, gomock.Any()).Return(nil, 0, nil)
-			nos.EXPECT().GetNetworkOfferingID(gomock.Any()).Return("", -1, fakeError)
+			nos.EXPECT().GetNetworkOfferingByName(gomock.Any()).Return(nil, -1, fakeError)
 
 			err := client.GetOrCreateIsolatedNetwork(dummies.CSFailureDomain1, dummies.CSISONet1)
 			Ω(err).ShouldNot(Succeed())
 			Ω(err.Error()).Should(ContainSubstring("creating a new isolated network"))
 		})
+		It("fails to create a network with an offering lacking the load balancer service", func() {
+			dummies.CSISONet1.Spec.Offering = &infrav1.CloudStackResourceIdentifier{ID: "customOfferingID"}
+			ns.EXPECT().GetNetworkByName(dummies.ISONet1.Name, gomock.Any()).Return(nil, 0, nil)
+			ns.EXPECT().GetNetworkByID(dummies.ISONet1.ID, gomock.Any()).Return(nil, 0, nil)
+			nos.EXPECT().GetNetworkOfferingByID("customOfferingID").Return(&csapi.NetworkOffering{
+				Id: "customOfferingID", Name: "custom",
+				Service: []csapi.NetworkOfferingServiceInternal{{Name: "SourceNat"}, {Name: "Firewall"}},
+			}, 1, nil)
+
+			err := client.GetOrCreateIsolatedNetwork(dummies.CSFailureDomain1, dummies.CSISONet1)
+			Ω(err).Should(MatchError(ContainSubstring("network offering custom does not provide the Lb service(s)")))
+		})
+		It("creates a dual-stack network with the IPv6 CIDR and its gateway", func() {
+			dummies.CSISONet1.Spec.IPv6CIDR = "2001:db8::/64"
+			nos.EXPECT().GetNetworkOfferingByName(cloud.NetOffering).Return(&csapi.NetworkOffering{
+				Id: "someOfferingID", Name: cloud.NetOffering, Internetprotocol: cloud.InternetProtocolDualStack,
+				Service: []csapi.NetworkOfferingServiceInternal{{Name: "SourceNat"}, {Name: "Lb"}, {Name: "Firewall"}},
+			}, 1, nil)
+			ns.EXPECT().GetNetworkByName(dummies.ISONet1.Name, gomock.Any()).Return(nil, 0, nil)
+			ns.EXPECT().GetNetworkByID(dummies.ISONet1.ID, gomock.Any()).Return(nil, 0, nil)
+			ns.EXPECT().NewCreateNetworkParams(gomock.Any(), gomock.Any(), gomock.Any()).Return(&csapi.CreateNetworkParams{})
+			ns.EXPECT().CreateNetwork(gomock.Any()).DoAndReturn(func(p *csapi.CreateNetworkParams) (*csapi.CreateNetworkResponse, error) {
+				ip6cidr, _ := p.GetIp6cidr()
+				Ω(ip6cidr).Should(Equal("2001:db8::/64"))
+				ip6gateway, _ := p.GetIp6gateway()
+				Ω(ip6gateway).Should(Equal("2001:db8::1"))
+
+				return &csapi.CreateNetworkResponse{Id: dummies.ISONet1.ID, Ip6cidr: "2001:db8::/64"}, nil
+			})
+			rs.EXPECT().NewCreateTagsParams(gomock.Any(), gomock.Any(), gomock.Any()).Return(&csapi.CreateTagsParams{})
+			rs.EXPECT().CreateTags(gomock.Any()).Return(&csapi.CreateTagsResponse{}, nil)
+			expectOpenEgressFirewall()
+
+			Ω(client.GetOrCreateIsolatedNetwork(dummies.CSFailureDomain1, dummies.CSISONet1)).Should(Succeed())
+			Ω(dummies.CSISONet1.Spec.IPv6CIDR).Should(Equal("2001:db8::/64"))
+		})
+		It("fails to create a dual-stack network with an IPv4 only offering", func() {
+			dummies.CSISONet1.Spec.IPv6CIDR = "2001:db8::/64"
+			nos.EXPECT().GetNetworkOfferingByName(cloud.NetOffering).Return(&csapi.NetworkOffering{
+				Id: "someOfferingID", Name: cloud.NetOffering, Internetprotocol: "IPv4",
+				Service: []csapi.NetworkOfferingServiceInternal{{Name: "SourceNat"}, {Name: "Lb"}, {Name: "Firewall"}},
+			}, 1, nil)
+			ns.EXPECT().GetNetworkByName(dummies.ISONet1.Name, gomock.Any()).Return(nil, 0, nil)
+			ns.EXPECT().GetNetworkByID(dummies.ISONet1.ID, gomock.Any()).Return(nil, 0, nil)
+
+			err := client.GetOrCreateIsolatedNetwork(dummies.CSFailureDomain1, dummies.CSISONet1)
+			Ω(err).Should(MatchError(ContainSubstring("does not support dual-stack networks")))
+		})
+		It("fails before creating a network beyond the account's network limit", func() {
+			ns.EXPECT().GetNetworkByName(dummies.ISONet1.Name, gomock.Any()).Return(nil, 0, nil)
+			ns.EXPECT().GetNetworkByID(dummies.ISONet1.ID, gomock.Any()).Return(nil, 0, nil)
+
+			client = cloud.NewClientFromCSAPIClient(mockClient, &cloud.User{Account: cloud.Account{NetworkAvailable: "0"}})
+			err := client.GetOrCreateIsolatedNetwork(dummies.CSFailureDomain1, dummies.CSISONet1)
+			Ω(err).Should(MatchError(ContainSubstring("network available (0) in account can't fulfil the requirement: 1")))
+			Ω(cloud.IsLimitExceeded(err)).Should(BeTrue())
+		})
 	})
 
 	Context("for a closed egress firewall", func() {
-		It("CreateEgressFirewallRules asks CloudStack to open the egress firewall", func() {
+		It("ReconcileEgressFirewallRules asks CloudStack to open the egress firewall", func() {
 			dummies.Zone1.Network = dummies.ISONet1
-			fs.EXPECT().NewCreateEgressFirewallRuleParams(dummies.ISONet1.ID, gomock.Any()).
-				DoAndReturn(func(_ string, protocol string) *csapi.CreateEgressFirewallRuleParams {
-					p := &csapi.CreateEgressFirewallRuleParams{}
-					if protocol == cloud.NetworkProtocolICMP {
-						p.SetIcmptype(-1)
-						p.SetIcmpcode(-1)
-					}
+			expectOpenEgressFirewall()
 
-					return p
-				}).Times(3)
-
-			ruleParamsICMP := &csapi.CreateEgressFirewallRuleParams{}
-			ruleParamsICMP.SetIcmptype(-1)
-			ruleParamsICMP.SetIcmpcode(-1)
-			gomock.InOrder(
-				fs.EXPECT().CreateEgressFirewallRule(&csapi.CreateEgressFirewallRuleParams{}).
-					Return(&csapi.CreateEgressFirewallRuleResponse{}, nil).Times(2),
-				fs.EXPECT().CreateEgressFirewallRule(ruleParamsICMP).
-					Return(&csapi.CreateEgressFirewallRuleResponse{}, nil))
-
-			Ω(client.CreateEgressFirewallRules(dummies.CSISONet1)).Should(Succeed())
+			Ω(client.ReconcileEgressFirewallRules(dummies.CSISONet1)).Should(Succeed())
 		})
 	})
 
 	Context("for an open egress firewall", func() {
-		It("CreateEgressFirewallRules asks CloudStack to open the firewall anyway, but doesn't fail", func() {
+		It("ReconcileEgressFirewallRules keeps the rules opening the firewall", func() {
 			dummies.Zone1.Network = dummies.ISONet1
+			fs.EXPECT().NewListEgressFirewallRulesParams().Return(&csapi.ListEgressFirewallRulesParams{})
+			fs.EXPECT().ListEgressFirewallRules(gomock.Any()).Return(&csapi.ListEgressFirewallRulesResponse{
+				EgressFirewallRules: []*csapi.EgressFirewallRule{
+					{Id: "tcp", Protocol: "tcp"},
+					{Id: "udp", Protocol: "udp", Destcidrlist: "0.0.0.0/0"},
+					{Id: "icmp", Protocol: "icmp", Icmptype: -1, Icmpcode: -1},
+				},
+			}, nil)
 
-			fs.EXPECT().NewCreateEgressFirewallRuleParams(dummies.ISONet1.ID, gomock.Any()).
-				DoAndReturn(func(_ string, protocol string) *csapi.CreateEgressFirewallRuleParams {
-					p := &csapi.CreateEgressFirewallRuleParams{}
-					if protocol == cloud.NetworkProtocolICMP {
-						p.SetIcmptype(-1)
-						p.SetIcmpcode(-1)
-					}
-
-					return p
-				}).Times(3)
+			Ω(client.ReconcileEgressFirewallRules(dummies.CSISONet1)).Should(Succeed())
+		})
+	})
 
-			ruleParamsICMP := &csapi.CreateEgressFirewallRuleParams{}
-			ruleParamsICMP.SetIcmptype(-1)
-			ruleParamsICMP.SetIcmpcode(-1)
-			gomock.InOrder(
-				fs.EXPECT().CreateEgressFirewallRule(&csapi.CreateEgressFirewallRuleParams{}).
-					Return(&csapi.CreateEgressFirewallRuleResponse{}, nil).Times(2),
-				fs.EXPECT().CreateEgressFirewallRule(ruleParamsICMP).
-					Return(&csapi.CreateEgressFirewallRuleResponse{}, nil))
+	Context("for an egress firewall restricted by the spec", func() {
+		It("ReconcileEgressFirewallRules replaces the rules CAPC created that aren't in the spec", func() {
+			dummies.CSISONet1.Spec.EgressRules = []infrav1.EgressRule{
+				{Protocol: "tcp", StartPort: 443, DestinationCIDRs: []string{"198.51.100.0/24", "192.0.2.0/24"}},
+				{Protocol: "udp", StartPort: 53},
+			}
+			fs.EXPECT().NewListEgressFirewallRulesParams().Return(&csapi.ListEgressFirewallRulesParams{})
+			fs.EXPECT().ListEgressFirewallRules(gomock.Any()).Return(&csapi.ListEgressFirewallRulesResponse{
+				EgressFirewallRules: []*csapi.EgressFirewallRule{
+					{Id: "tcp", Protocol: "tcp", Tags: []csapi.Tags{{Key: cloud.CreatedByCAPCTagName, Value: "1"}}},
+					{Id: "https", Protocol: "tcp", Startport: 443, Endport: 443, Destcidrlist: "198.51.100.0/24, 192.0.2.0/24"},
+					{Id: "ntp", Protocol: "udp", Startport: 123, Endport: 123},
+				},
+			}, nil)
+			fs.EXPECT().NewDeleteEgressFirewallRuleParams("tcp").Return(&csapi.DeleteEgressFirewallRuleParams{})
+			fs.EXPECT().DeleteEgressFirewallRule(gomock.Any()).Return(&csapi.DeleteEgressFirewallRuleResponse{}, nil)
+			fs.EXPECT().NewCreateEgressFirewallRuleParams(dummies.CSISONet1.Spec.ID, cloud.NetworkProtocolUDP).
+				Return(&csapi.CreateEgressFirewallRuleParams{})
+			fs.EXPECT().CreateEgressFirewallRule(gomock.Any()).DoAndReturn(func(p *csapi.CreateEgressFirewallRuleParams) (*csapi.CreateEgressFirewallRuleResponse, error) {
+				startPort, _ := p.GetStartport()
+				endPort, _ := p.GetEndport()
+				destCIDRs, _ := p.GetDestcidrlist()
+				Ω(startPort).Should(Equal(53))
+				Ω(endPort).Should(Equal(53))
+				Ω(destCIDRs).Should(Equal([]string{"0.0.0.0/0"}))
+
+				return &csapi.CreateEgressFirewallRuleResponse{Id: "dns"}, nil
+			})
+			rs.EXPECT().NewCreateTagsParams([]string{"dns"}, string(cloud.ResourceTypeFirewallRule), gomock.Any()).
+				Return(&csapi.CreateTagsParams{})
+			rs.EXPECT().CreateTags(gomock.Any()).Return(&csapi.CreateTagsResponse{}, nil)
 
-			Ω(client.CreateEgressFirewallRules(dummies.CSISONet1)).Should(Succeed())
+			Ω(client.ReconcileEgressFirewallRules(dummies.CSISONet1)).Should(Succeed())
 		})
 	})
 
@@ -311,6 +364,7 @@ var _ = Describe("Network", func() {
 				&csapi.ListLoadBalancerRulesResponse{LoadBalancerRules: []*csapi.LoadBalancerRule{
 					{
 						Id:         dummies.LBRuleID,
+						Algorithm:  "roundrobin",
 						Publicport: strconv.Itoa(int(dummies.EndPointPort)),
 						Tags:       dummies.CreatedByCAPCTag,
 					},
@@ -333,6 +387,73 @@ var _ = Describe("Network", func() {
 		})
 	})
 
+	Context("for a dual-stack isolated network", func() {
+		BeforeEach(func() {
+			dummies.CSISONet1.Spec.IPv6CIDR = "2001:db8::/64"
+			fs.EXPECT().NewListIpv6FirewallRulesParams().Return(&csapi.ListIpv6FirewallRulesParams{})
+		})
+
+		It("ReconcileIPv6FirewallRules opens the API server ports to all IPv6 addresses", func() {
+			dummies.CSCluster.Spec.APIServerLoadBalancer.AdditionalPorts = []int{8443}
+			fs.EXPECT().ListIpv6FirewallRules(gomock.Any()).Return(&csapi.ListIpv6FirewallRulesResponse{}, nil)
+			for _, port := range []int{int(dummies.EndPointPort), 8443} {
+				fs.EXPECT().NewCreateIpv6FirewallRuleParams(dummies.ISONet1.ID, cloud.NetworkProtocolTCP).
+					Return(&csapi.CreateIpv6FirewallRuleParams{})
+				rs.EXPECT().NewCreateTagsParams([]string{"IPv6RuleID" + strconv.Itoa(port)}, string(cloud.ResourceTypeFirewallRule),
+					map[string]string{cloud.CreatedByCAPCTagName: "1", cloud.APIServerPortTagName: strconv.Itoa(port)}).
+					Return(&csapi.CreateTagsParams{})
+			}
+			fs.EXPECT().CreateIpv6FirewallRule(gomock.Any()).DoAndReturn(
+				func(p *csapi.CreateIpv6FirewallRuleParams) (*csapi.CreateIpv6FirewallRuleResponse, error) {
+					cidrs, _ := p.GetCidrlist()
+					Ω(cidrs).Should(Equal([]string{"::/0"}))
+					port, _ := p.GetStartport()
+
+					return &csapi.CreateIpv6FirewallRuleResponse{Id: "IPv6RuleID" + strconv.Itoa(port)}, nil
+				}).Times(2)
+			rs.EXPECT().CreateTags(gomock.Any()).Return(&csapi.CreateTagsResponse{}, nil).Times(2)
+
+			Ω(client.ReconcileIPv6FirewallRules(dummies.CSISONet1, dummies.CSCluster)).Should(Succeed())
+		})
+
+		It("ReconcileIPv6FirewallRules replaces rules whose CIDRs are no longer allowed", func() {
+			dummies.CSCluster.Spec.APIServerLoadBalancer.AllowedCIDRs = []string{"192.0.2.0/24", "2001:db8:ffff::/48"}
+			port := strconv.Itoa(int(dummies.EndPointPort))
+			portTag := csapi.Tags{Key: cloud.APIServerPortTagName, Value: port}
+			fs.EXPECT().ListIpv6FirewallRules(gomock.Any()).Return(&csapi.ListIpv6FirewallRulesResponse{
+				Ipv6FirewallRules: []*csapi.Ipv6FirewallRule{
+					{Id: "StaleRuleID", Cidrlist: "::/0", Tags: append([]csapi.Tags{portTag}, dummies.CreatedByCAPCTag...)},
+					{Id: "UserRuleID", Cidrlist: "::/0"},
+				},
+			}, nil)
+			fs.EXPECT().NewDeleteIpv6FirewallRuleParams("StaleRuleID").Return(&csapi.DeleteIpv6FirewallRuleParams{})
+			fs.EXPECT().DeleteIpv6FirewallRule(gomock.Any()).Return(&csapi.DeleteIpv6FirewallRuleResponse{}, nil)
+			fs.EXPECT().NewCreateIpv6FirewallRuleParams(dummies.ISONet1.ID, cloud.NetworkProtocolTCP).
+				Return(&csapi.CreateIpv6FirewallRuleParams{})
+			fs.EXPECT().CreateIpv6FirewallRule(gomock.Any()).DoAndReturn(
+				func(p *csapi.CreateIpv6FirewallRuleParams) (*csapi.CreateIpv6FirewallRuleResponse, error) {
+					cidrs, _ := p.GetCidrlist()
+					Ω(cidrs).Should(Equal([]string{"2001:db8:ffff::/48"}))
+
+					return &csapi.CreateIpv6FirewallRuleResponse{Id: "NewRuleID"}, nil
+				})
+			rs.EXPECT().NewCreateTagsParams([]string{"NewRuleID"}, gomock.Any(), gomock.Any()).Return(&csapi.CreateTagsParams{})
+			rs.EXPECT().CreateTags(gomock.Any()).Return(&csapi.CreateTagsResponse{}, nil)
+
+			Ω(client.ReconcileIPv6FirewallRules(dummies.CSISONet1, dummies.CSCluster)).Should(Succeed())
+		})
+
+		It("ReconcileIPv6FirewallRules keeps matching rules", func() {
+			tags := append([]csapi.Tags{{Key: cloud.APIServerPortTagName, Value: strconv.Itoa(int(dummies.EndPointPort))}},
+				dummies.CreatedByCAPCTag...)
+			fs.EXPECT().ListIpv6FirewallRules(gomock.Any()).Return(&csapi.ListIpv6FirewallRulesResponse{
+				Ipv6FirewallRules: []*csapi.Ipv6FirewallRule{{Id: "RuleID", Cidrlist: "::/0", Tags: tags}},
+			}, nil)
+
+			Ω(client.ReconcileIPv6FirewallRules(dummies.CSISONet1, dummies.CSCluster)).Should(Succeed())
+		})
+	})
+
 	Context("With a disabled API load balancer", func() {
 		It("deletes existing load balancer and firewall rules, disassociates IP", func() {
 			dummies.SetClusterSpecToNet(&dummies.ISONet1)
@@ -348,6 +469,7 @@ var _ = Describe("Network", func() {
 				&csapi.ListLoadBalancerRulesResponse{LoadBalancerRules: []*csapi.LoadBalancerRule{
 					{
 						Id:         dummies.LBRuleID,
+						Algorithm:  "roundrobin",
 						Publicport: strconv.Itoa(int(dummies.EndPointPort)),
 						Tags:       dummies.CreatedByCAPCTag,
 					},
@@ -400,6 +522,7 @@ var _ = Describe("Network", func() {
 				&csapi.ListLoadBalancerRulesResponse{LoadBalancerRules: []*csapi.LoadBalancerRule{
 					{
 						Id:         dummies.LBRuleID,
+						Algorithm:  "roundrobin",
 						Publicport: strconv.Itoa(int(dummies.EndPointPort)),
 						Tags:       dummies.CreatedByCAPCTag,
 					},
@@ -419,11 +542,13 @@ var _ = Describe("Network", func() {
 				&csapi.ListLoadBalancerRulesResponse{LoadBalancerRules: []*csapi.LoadBalancerRule{
 					{
 						Id:         dummies.LBRuleID,
+						Algorithm:  "roundrobin",
 						Publicport: strconv.Itoa(int(dummies.EndPointPort)),
 						Tags:       dummies.CreatedByCAPCTag,
 					},
 					{
 						Id:         "FakeLBRuleID2",
+						Algorithm:  "roundrobin",
 						Publicport: strconv.Itoa(456),
 						Tags:       dummies.CreatedByCAPCTag,
 					},
@@ -452,6 +577,7 @@ var _ = Describe("Network", func() {
 					LoadBalancerRules: []*csapi.LoadBalancerRule{
 						{
 							Id:         dummies.LBRuleID,
+							Algorithm:  "roundrobin",
 							Publicport: strconv.Itoa(int(dummies.EndPointPort)),
 							Tags:       dummies.CreatedByCAPCTag,
 						},
@@ -463,6 +589,91 @@ var _ = Describe("Network", func() {
 		})
 	})
 
+	Context("API server load balancer rule settings", func() {
+		BeforeEach(func() {
+			dummies.CSISONet1.Status.APIServerLoadBalancer.IPAddressID = dummies.LoadBalancerIPID
+			lbs.EXPECT().NewListLoadBalancerRulesParams().Return(&csapi.ListLoadBalancerRulesParams{})
+			lbs.EXPECT().ListLoadBalancerRules(gomock.Any()).Return(
+				&csapi.ListLoadBalancerRulesResponse{LoadBalancerRules: []*csapi.LoadBalancerRule{
+					{
+						Id:         dummies.LBRuleID,
+						Algorithm:  "roundrobin",
+						Publicport: strconv.Itoa(int(dummies.EndPointPort)),
+						Tags:       dummies.CreatedByCAPCTag,
+					},
+				}}, nil)
+		})
+
+		It("updates the algorithm and adds the health check and stickiness policies", func() {
+			dummies.CSCluster.Spec.APIServerLoadBalancer.Algorithm = "leastconn"
+			dummies.CSCluster.Spec.APIServerLoadBalancer.HealthCheck = &infrav1.LoadBalancerHealthCheck{
+				Type:               infrav1.LoadBalancerHealthCheckTCP,
+				UnhealthyThreshold: 2,
+			}
+			dummies.CSCluster.Spec.APIServerLoadBalancer.Stickiness = &infrav1.LoadBalancerStickiness{Method: "SourceBased"}
+
+			lbs.EXPECT().NewUpdateLoadBalancerRuleParams(dummies.LBRuleID).Return(&csapi.UpdateLoadBalancerRuleParams{})
+			lbs.EXPECT().UpdateLoadBalancerRule(gomock.Any()).DoAndReturn(func(p *csapi.UpdateLoadBalancerRuleParams) (*csapi.UpdateLoadBalancerRuleResponse, error) {
+				algorithm, _ := p.GetAlgorithm()
+				Ω(algorithm).Should(Equal("leastconn"))
+
+				return &csapi.UpdateLoadBalancerRuleResponse{}, nil
+			})
+			lbs.EXPECT().NewListLBHealthCheckPoliciesParams().Return(&csapi.ListLBHealthCheckPoliciesParams{})
+			lbs.EXPECT().ListLBHealthCheckPolicies(gomock.Any()).Return(&csapi.ListLBHealthCheckPoliciesResponse{}, nil)
+			lbs.EXPECT().NewCreateLBHealthCheckPolicyParams(dummies.LBRuleID).Return(&csapi.CreateLBHealthCheckPolicyParams{})
+			lbs.EXPECT().CreateLBHealthCheckPolicy(gomock.Any()).DoAndReturn(func(p *csapi.CreateLBHealthCheckPolicyParams) (*csapi.CreateLBHealthCheckPolicyResponse, error) {
+				pingPath, _ := p.GetPingpath()
+				unhealthyThreshold, _ := p.GetUnhealthythreshold()
+				interval, _ := p.GetIntervaltime()
+				Ω(pingPath).Should(BeEmpty())
+				Ω(unhealthyThreshold).Should(Equal(2))
+				Ω(interval).Should(Equal(5))
+
+				return &csapi.CreateLBHealthCheckPolicyResponse{}, nil
+			})
+			lbs.EXPECT().NewListLBStickinessPoliciesParams().Return(&csapi.ListLBStickinessPoliciesParams{})
+			lbs.EXPECT().ListLBStickinessPolicies(gomock.Any()).Return(&csapi.ListLBStickinessPoliciesResponse{}, nil)
+			lbs.EXPECT().NewCreateLBStickinessPolicyParams(dummies.LBRuleID, "SourceBased", "capc-sourcebased").
+				Return(&csapi.CreateLBStickinessPolicyParams{})
+			lbs.EXPECT().CreateLBStickinessPolicy(gomock.Any()).Return(&csapi.CreateLBStickinessPolicyResponse{}, nil)
+
+			Ω(client.ReconcileLoadBalancerRules(dummies.CSISONet1, dummies.CSCluster)).Should(Succeed())
+			Ω(dummies.CSISONet1.Status.APIServerLoadBalancer.HealthCheckPolicy).Should(BeTrue())
+			Ω(dummies.CSISONet1.Status.APIServerLoadBalancer.StickinessPolicy).Should(BeTrue())
+		})
+
+		It("keeps a matching health check policy and removes stickiness that is no longer configured", func() {
+			dummies.CSCluster.Spec.APIServerLoadBalancer.HealthCheck = &infrav1.LoadBalancerHealthCheck{Type: infrav1.LoadBalancerHealthCheckTCP}
+			dummies.CSISONet1.Status.APIServerLoadBalancer.StickinessPolicy = true
+
+			lbs.EXPECT().NewListLBHealthCheckPoliciesParams().Return(&csapi.ListLBHealthCheckPoliciesParams{})
+			lbs.EXPECT().ListLBHealthCheckPolicies(gomock.Any()).Return(&csapi.ListLBHealthCheckPoliciesResponse{
+				LBHealthCheckPolicies: []*csapi.LBHealthCheckPolicy{{
+					Healthcheckpolicy: []csapi.LBHealthCheckPolicyHealthcheckpolicy{{
+						Id: "healthcheck", Pingpath: "/", Healthcheckinterval: 5, Responsetime: 2,
+						Healthcheckthresshold: 2, Unhealthcheckthresshold: 3,
+					}},
+				}},
+			}, nil)
+			lbs.EXPECT().NewListLBStickinessPoliciesParams().Return(&csapi.ListLBStickinessPoliciesParams{})
+			lbs.EXPECT().ListLBStickinessPolicies(gomock.Any()).Return(&csapi.ListLBStickinessPoliciesResponse{
+				LBStickinessPolicies: []*csapi.LBStickinessPolicy{{
+					Stickinesspolicy: []csapi.LBStickinessPolicyStickinesspolicy{{Id: "stickiness", Methodname: "LbCookie"}},
+				}},
+			}, nil)
+			lbs.EXPECT().NewDeleteLBStickinessPolicyParams("stickiness").Return(&csapi.DeleteLBStickinessPolicyParams{})
+			lbs.EXPECT().DeleteLBStickinessPolicy(gomock.Any()).Return(&csapi.DeleteLBStickinessPolicyResponse{}, nil)
+
+			Ω(client.ReconcileLoadBalancerRules(dummies.CSISONet1, dummies.CSCluster)).Should(Succeed())
+			Ω(dummies.CSISONet1.Status.APIServerLoadBalancer.StickinessPolicy).Should(BeFalse())
+		})
+
+		It("doesn't list the policies when none are configured or were applied", func() {
+			Ω(client.ReconcileLoadBalancerRules(dummies.CSISONet1, dummies.CSCluster)).Should(Succeed())
+		})
+	})
+
 	Context("Assign VM to Load Balancer rule", func() {
 		It("Associates VM to LB rule", func() {
 			dummies.CSISONet1.Status.LoadBalancerRuleIDs = []string{"lbruleid"}
@@ -561,6 +772,7 @@ var _ = Describe("Network", func() {
 				&csapi.ListLoadBalancerRulesResponse{LoadBalancerRules: []*csapi.LoadBalancerRule{
 					{
 						Id:         dummies.LBRuleID,
+						Algorithm:  "roundrobin",
 						Publicport: strconv.Itoa(int(dummies.EndPointPort)),
 						Tags:       dummies.CreatedByCAPCTag,
 					},
@@ -590,6 +802,7 @@ var _ = Describe("Network", func() {
 				&csapi.ListLoadBalancerRulesResponse{LoadBalancerRules: []*csapi.LoadBalancerRule{
 					{
 						Id:         dummies.LBRuleID,
+						Algorithm:  "roundrobin",
 						Publicport: strconv.Itoa(int(dummies.EndPointPort)),
 						Tags:       dummies.CreatedByCAPCTag,
 					},
@@ -636,7 +849,7 @@ var _ = Describe("Network", func() {
 			lbs.EXPECT().NewListLoadBalancerRulesParams().Return(&csapi.ListLoadBalancerRulesParams{})
 			lbs.EXPECT().ListLoadBalancerRules(gomock.Any()).
 				Return(&csapi.ListLoadBalancerRulesResponse{
-					LoadBalancerRules: []*csapi.LoadBalancerRule{{Publicport: "7443", Id: dummies.LBRuleID}},
+					LoadBalancerRules: []*csapi.LoadBalancerRule{{Publicport: "7443", Id: dummies.LBRuleID, Algorithm: "roundrobin"}},
 				}, nil)
 			lbs.EXPECT().NewCreateLoadBalancerRuleParams(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
 				Return(&csapi.CreateLoadBalancerRuleParams{})
@@ -938,6 +1151,7 @@ var _ = Describe("Network", func() {
 				&csapi.ListLoadBalancerRulesResponse{LoadBalancerRules: []*csapi.LoadBalancerRule{
 					{
 						Id:         dummies.LBRuleID,
+						Algorithm:  "roundrobin",
 						Publicport: strconv.Itoa(int(dummies.EndPointPort)),
 						Tags:       dummies.CreatedByCAPCTag,
 					},
@@ -0,0 +1,156 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloud
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// Resources checked against CloudStack resource limits.
+const (
+	LimitResourceCPU            = "CPU"
+	LimitResourceMemory         = "memory"
+	LimitResourceVM             = "VM"
+	LimitResourcePrimaryStorage = "primary storage (GiB)"
+	LimitResourceVolume         = "volume"
+	LimitResourcePublicIP       = "public IP"
+	LimitResourceNetwork        = "network"
+)
+
+// Scopes CloudStack resource limits apply to.
+const (
+	LimitScopeAccount = "account"
+	LimitScopeDomain  = "domain"
+	LimitScopeProject = "project"
+)
+
+// LimitExceededError reports that creating a resource would exceed a CloudStack resource limit.
+type LimitExceededError struct {
+	Scope     string
+	Resource  string
+	Available int64
+	Required  int64
+}
+
+func (e *LimitExceededError) Error() string {
+	if e.Resource == LimitResourceVM {
+		if e.Scope == LimitScopeProject { // Unchanged from before limit errors were typed, as it may be matched on.
+			return "VM Limit in project has reached it's maximum value"
+		}
+
+		return fmt.Sprintf("VM limit in %s has reached its maximum value", e.Scope)
+	}
+
+	return fmt.Sprintf("%s available (%d) in %s can't fulfil the requirement: %d", e.Resource, e.Available, e.Scope, e.Required)
+}
+
+// IsLimitExceeded returns whether the error is, or wraps, a LimitExceededError.
+func IsLimitExceeded(err error) bool {
+	var limitErr *LimitExceededError
+
+	return errors.As(err, &limitErr)
+}
+
+// checkLimit returns a LimitExceededError if more of a resource is required than is available. Unlimited resources, and
+// those whose availability is unknown, always pass.
+func checkLimit(scope, resource, available string, required int64) error {
+	if available == LimitUnlimited {
+		return nil
+	}
+	availableAmount, err := strconv.ParseInt(available, 10, 64)
+	if err != nil || required <= availableAmount {
+		return nil
+	}
+
+	return &LimitExceededError{Scope: scope, Resource: resource, Available: availableAmount, Required: required}
+}
+
+// isLimited returns whether a resource's availability is bounded by a limit.
+func isLimited(available string) bool {
+	_, err := strconv.ParseInt(available, 10, 64)
+
+	return err == nil
+}
+
+// resourceAvailability holds the availability of a resource in the account, its domain and the project.
+type resourceAvailability struct {
+	account, domain, project string
+}
+
+// checkResourceLimit checks the required amount of a resource against the account and domain limits, and the project
+// limits if the client acts in a project.
+func (c *client) checkResourceLimit(resource string, available resourceAvailability, required int64) error {
+	if err := checkLimit(LimitScopeAccount, resource, available.account, required); err != nil {
+		return err
+	}
+	if err := checkLimit(LimitScopeDomain, resource, available.domain, required); err != nil {
+		return err
+	}
+	if c.user.Project.ID == "" {
+		return nil
+	}
+
+	return checkLimit(LimitScopeProject, resource, available.project, required)
+}
+
+// isResourceLimited returns whether any limit applicable to the client bounds the resource.
+func (c *client) isResourceLimited(available resourceAvailability) bool {
+	return isLimited(available.account) || isLimited(available.domain) ||
+		(c.user.Project.ID != "" && isLimited(available.project))
+}
+
+func (c *client) primaryStorageAvailability() resourceAvailability {
+	return resourceAvailability{
+		account: c.user.Account.PrimaryStorageAvailable,
+		domain:  c.user.Account.Domain.PrimaryStorageAvailable,
+		project: c.user.Project.PrimaryStorageAvailable,
+	}
+}
+
+func (c *client) volumeAvailability() resourceAvailability {
+	return resourceAvailability{
+		account: c.user.Account.VolumeAvailable,
+		domain:  c.user.Account.Domain.VolumeAvailable,
+		project: c.user.Project.VolumeAvailable,
+	}
+}
+
+func (c *client) ipAvailability() resourceAvailability {
+	return resourceAvailability{
+		account: c.user.Account.IPAvailable,
+		domain:  c.user.Account.Domain.IPAvailable,
+		project: c.user.Project.IPAvailable,
+	}
+}
+
+func (c *client) networkAvailability() resourceAvailability {
+	return resourceAvailability{
+		account: c.user.Account.NetworkAvailable,
+		domain:  c.user.Account.Domain.NetworkAvailable,
+		project: c.user.Project.NetworkAvailable,
+	}
+}
+
+// bytesToGiB converts a size in bytes to GiB, rounding up.
+func bytesToGiB(size int64) int64 {
+	const gib = 1 << 30
+
+	return (size + gib - 1) / gib
+}
@@ -0,0 +1,526 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloud
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/apache/cloudstack-go/v2/cloudstack"
+	"github.com/pkg/errors"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
+	capcstrings "sigs.k8s.io/cluster-api-provider-cloudstack/pkg/utils/strings"
+)
+
+type LoadBalancerIface interface {
+	AssociateLoadBalancerPublicIPAddress(fd *infrav1.CloudStackFailureDomain, lb *infrav1.CloudStackLoadBalancer) (*cloudstack.PublicIpAddress, error)
+	ReconcileLoadBalancerPorts(fd *infrav1.CloudStackFailureDomain, lb *infrav1.CloudStackLoadBalancer) error
+	ReconcileLoadBalancerRuleInstances(ruleIDs []string, instanceIDs []string) (assigned []string, removed []string, err error)
+	ReleaseLoadBalancerPublicIPAddress(lb *infrav1.CloudStackLoadBalancer, csCluster *infrav1.CloudStackCluster) error
+}
+
+// AssociateLoadBalancerPublicIPAddress gets a public IP and associates it to the network of the failure domain.
+func (c *client) AssociateLoadBalancerPublicIPAddress(
+	fd *infrav1.CloudStackFailureDomain,
+	lb *infrav1.CloudStackLoadBalancer,
+) (*cloudstack.PublicIpAddress, error) {
+	desiredIP := lb.Spec.IPAddress
+	if desiredIP == "" {
+		desiredIP = lb.Status.IPAddress
+	}
+
+	return c.associatePublicIPAddressWithNetwork(fd, desiredIP)
+}
+
+// associatePublicIPAddressWithNetwork gets a public IP, the desired one if given, and associates it to the network of
+// the failure domain unless it already is.
+func (c *client) associatePublicIPAddressWithNetwork(fd *infrav1.CloudStackFailureDomain, desiredIP string) (*cloudstack.PublicIpAddress, error) {
+	publicAddress, err := c.GetPublicIP(fd, desiredIP)
+	if err != nil {
+		return nil, errors.Wrap(err, "fetching a public IP address")
+	}
+	if publicAddress.Associatednetworkid == fd.Spec.Zone.Network.ID {
+		return publicAddress, nil
+	}
+
+	if err := c.checkResourceLimit(LimitResourcePublicIP, c.ipAvailability(), 1); err != nil {
+		return nil, err
+	}
+	p := c.cs.Address.NewAssociateIpAddressParams()
+	p.SetIpaddress(publicAddress.Ipaddress)
+	p.SetNetworkid(fd.Spec.Zone.Network.ID)
+	setIfNotEmpty(c.user.Project.ID, p.SetProjectid)
+	if _, err := c.cs.Address.AssociateIpAddress(p); err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+
+		return nil, errors.Wrapf(err,
+			"associating public IP address with ID %s to network with ID %s",
+			publicAddress.Id, fd.Spec.Zone.Network.ID)
+	} else if err := c.AddCreatedByCAPCTag(ResourceTypeIPAddress, publicAddress.Id); err != nil {
+		return nil, errors.Wrapf(err,
+			"adding tag to public IP address with ID %s", publicAddress.Id)
+	}
+
+	return publicAddress, nil
+}
+
+// ReconcileLoadBalancerPorts manages a load balancer rule and the firewall rules for the allowed CIDRs for each port of
+// the load balancer. Rules created by CAPC for ports that are gone are deleted.
+func (c *client) ReconcileLoadBalancerPorts(fd *infrav1.CloudStackFailureDomain, lb *infrav1.CloudStackLoadBalancer) error {
+	if lb.Status.IPAddressID == "" {
+		return nil
+	}
+	ruleIDs, err := c.reconcileServiceLoadBalancerRules(lb, fd.Spec.Zone.Network.ID, lb.Spec.Ports)
+	if err != nil {
+		return err
+	}
+	lb.Status.LoadBalancerRuleIDs = ruleIDs
+
+	return c.reconcileServiceFirewallRules(lb.Status.IPAddressID, lb.Spec.Ports, lb.Spec.AllowedCIDRs)
+}
+
+// reconcileServiceLoadBalancerRules makes the CAPC managed load balancer rules on the load balancer's public IP match
+// the ports, and returns their IDs.
+func (c *client) reconcileServiceLoadBalancerRules(
+	lb *infrav1.CloudStackLoadBalancer,
+	networkID string,
+	ports []infrav1.LoadBalancerPort,
+) ([]string, error) {
+	p := c.cs.LoadBalancer.NewListLoadBalancerRulesParams()
+	p.SetPublicipid(lb.Status.IPAddressID)
+	setIfNotEmpty(c.user.Project.ID, p.SetProjectid)
+	resp, err := c.cs.LoadBalancer.ListLoadBalancerRules(p)
+	if err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+
+		return nil, errors.Wrap(err, "listing load balancer rules")
+	}
+
+	desired := make(map[string]struct{}, len(ports))
+	for _, port := range ports {
+		desired[serviceLoadBalancerRuleKey(port.ProtocolOrDefault(), port.Port, port.TargetPortOrDefault())] = struct{}{}
+	}
+	existing := make(map[string]string, len(resp.LoadBalancerRules))
+	for _, rule := range resp.LoadBalancerRules {
+		if !isTaggedCreatedByCAPC(rule.Tags) {
+			continue
+		}
+		publicPort, _ := strconv.Atoi(rule.Publicport)
+		privatePort, _ := strconv.Atoi(rule.Privateport)
+		key := serviceLoadBalancerRuleKey(rule.Protocol, publicPort, privatePort)
+		if _, wanted := desired[key]; wanted {
+			existing[key] = rule.Id
+
+			continue
+		}
+		if err := c.deleteLoadBalancerRuleByID(rule.Id); err != nil {
+			return nil, err
+		}
+	}
+
+	ruleIDs := make([]string, 0, len(ports))
+	for _, port := range ports {
+		key := serviceLoadBalancerRuleKey(port.ProtocolOrDefault(), port.Port, port.TargetPortOrDefault())
+		ruleID, found := existing[key]
+		if !found {
+			if ruleID, err = c.createServiceLoadBalancerRule(lb, networkID, port); err != nil {
+				return nil, err
+			}
+			existing[key] = ruleID
+		}
+		ruleIDs = append(ruleIDs, ruleID)
+	}
+
+	return capcstrings.Canonicalize(ruleIDs), nil
+}
+
+// createServiceLoadBalancerRule forwards a port of the load balancer's public IP to the target port of the machines.
+func (c *client) createServiceLoadBalancerRule(lb *infrav1.CloudStackLoadBalancer, networkID string, port infrav1.LoadBalancerPort) (string, error) {
+	name := fmt.Sprintf("%s_%s_%d", lb.Name, port.ProtocolOrDefault(), port.Port)
+	p := c.cs.LoadBalancer.NewCreateLoadBalancerRuleParams("roundrobin", name, port.TargetPortOrDefault(), port.Port)
+	p.SetNetworkid(networkID)
+	p.SetPublicipid(lb.Status.IPAddressID)
+	p.SetProtocol(port.ProtocolOrDefault())
+	// Firewall rules are managed separately, for the allowed CIDRs only.
+	p.SetOpenfirewall(false)
+	resp, err := c.cs.LoadBalancer.CreateLoadBalancerRule(p)
+	if err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+
+		return "", errors.Wrapf(err, "creating load balancer rule for %s port %d", port.ProtocolOrDefault(), port.Port)
+	}
+	if err := c.AddCreatedByCAPCTag(ResourceTypeLoadBalancerRule, resp.Id); err != nil {
+		return "", errors.Wrap(err, "adding created by CAPC tag")
+	}
+
+	return resp.Id, nil
+}
+
+// reconcileServiceFirewallRules makes the CAPC managed firewall rules on a public IP open the ports to the allowed
+// CIDRs, or to everyone if there are none.
+func (c *client) reconcileServiceFirewallRules(ipAddressID string, ports []infrav1.LoadBalancerPort, allowedCIDRs []string) error {
+	p := c.cs.Firewall.NewListFirewallRulesParams()
+	p.SetIpaddressid(ipAddressID)
+	setIfNotEmpty(c.user.Project.ID, p.SetProjectid)
+	resp, err := c.cs.Firewall.ListFirewallRules(p)
+	if err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+
+		return errors.Wrap(err, "listing firewall rules")
+	}
+
+	cidrs := allowedCIDRs
+	if len(cidrs) == 0 {
+		cidrs = []string{"0.0.0.0/0"}
+	}
+	desired := map[string]struct{}{}
+	for _, port := range ports {
+		for _, cidr := range cidrs {
+			desired[serviceFirewallRuleKey(port.ProtocolOrDefault(), port.Port, cidr)] = struct{}{}
+		}
+	}
+	existing := map[string]struct{}{}
+	for _, rule := range resp.FirewallRules {
+		if !isTaggedCreatedByCAPC(rule.Tags) {
+			continue
+		}
+		key := serviceFirewallRuleKey(rule.Protocol, rule.Startport, rule.Cidrlist)
+		if _, wanted := desired[key]; wanted && rule.Startport == rule.Endport {
+			existing[key] = struct{}{}
+
+			continue
+		}
+		if err := c.deleteFirewallRuleByID(rule.Id); err != nil {
+			return err
+		}
+	}
+
+	for _, port := range ports {
+		for _, cidr := range cidrs {
+			key := serviceFirewallRuleKey(port.ProtocolOrDefault(), port.Port, cidr)
+			if _, found := existing[key]; found {
+				continue
+			}
+			if err := c.createServiceFirewallRule(ipAddressID, port, cidr); err != nil {
+				return err
+			}
+			existing[key] = struct{}{}
+		}
+	}
+
+	return nil
+}
+
+// createServiceFirewallRule allows traffic from a CIDR to a port of a public IP.
+func (c *client) createServiceFirewallRule(ipAddressID string, port infrav1.LoadBalancerPort, cidr string) error {
+	p := c.cs.Firewall.NewCreateFirewallRuleParams(ipAddressID, port.ProtocolOrDefault())
+	p.SetStartport(port.Port)
+	p.SetEndport(port.Port)
+	p.SetCidrlist([]string{cidr})
+	resp, err := c.cs.Firewall.CreateFirewallRule(p)
+	if err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+
+		return errors.Wrapf(err, "creating firewall rule for %s port %d from %s", port.ProtocolOrDefault(), port.Port, cidr)
+	}
+	if err := c.AddCreatedByCAPCTag(ResourceTypeFirewallRule, resp.Id); err != nil {
+		return errors.Wrap(err, "adding created by CAPC tag")
+	}
+
+	return nil
+}
+
+// ReconcileLoadBalancerRuleInstances makes the given instances the only ones assigned to each of the load balancer
+// rules. It returns the instances that were assigned to or removed from any rule.
+func (c *client) ReconcileLoadBalancerRuleInstances(ruleIDs []string, instanceIDs []string) ([]string, []string, error) {
+	var assigned, removed []string
+	for _, ruleID := range ruleIDs {
+		resp, err := c.cs.LoadBalancer.ListLoadBalancerRuleInstances(
+			c.cs.LoadBalancer.NewListLoadBalancerRuleInstancesParams(ruleID))
+		if err != nil {
+			c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+
+			return nil, nil, errors.Wrapf(err, "listing instances of load balancer rule %s", ruleID)
+		}
+		current := make([]string, 0, len(resp.LoadBalancerRuleInstances))
+		for _, instance := range resp.LoadBalancerRuleInstances {
+			current = append(current, instance.Id)
+		}
+		stale, missing := capcstrings.SliceDiff(current, instanceIDs)
+
+		if len(missing) > 0 {
+			p := c.cs.LoadBalancer.NewAssignToLoadBalancerRuleParams(ruleID)
+			p.SetVirtualmachineids(missing)
+			if _, err := c.cs.LoadBalancer.AssignToLoadBalancerRule(p); err != nil {
+				c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+
+				return nil, nil, errors.Wrapf(err, "assigning instances to load balancer rule %s", ruleID)
+			}
+			assigned = append(assigned, missing...)
+		}
+		if len(stale) > 0 {
+			p := c.cs.LoadBalancer.NewRemoveFromLoadBalancerRuleParams(ruleID)
+			p.SetVirtualmachineids(stale)
+			if _, err := c.cs.LoadBalancer.RemoveFromLoadBalancerRule(p); err != nil {
+				c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+
+				return nil, nil, errors.Wrapf(err, "removing instances from load balancer rule %s", ruleID)
+			}
+			removed = append(removed, stale...)
+		}
+	}
+
+	return capcstrings.Canonicalize(assigned), capcstrings.Canonicalize(removed), nil
+}
+
+// ReleaseLoadBalancerPublicIPAddress deletes the rules CAPC created on the load balancer's public IP, removes the
+// cluster's tag from it, and releases it unless another cluster still uses it.
+func (c *client) ReleaseLoadBalancerPublicIPAddress(lb *infrav1.CloudStackLoadBalancer, csCluster *infrav1.CloudStackCluster) error {
+	if lb.Status.IPAddressID == "" {
+		return nil
+	}
+	if _, err := c.reconcileServiceLoadBalancerRules(lb, "", nil); err != nil {
+		return err
+	}
+	if err := c.reconcileServiceFirewallRules(lb.Status.IPAddressID, nil, nil); err != nil {
+		return err
+	}
+	if err := c.DeleteClusterTag(ResourceTypeIPAddress, lb.Status.IPAddressID, csCluster); err != nil {
+		return err
+	}
+	_, err := c.DisassociatePublicIPAddressIfNotInUse(lb.Status.IPAddressID)
+
+	return err
+}
+
+// Health check defaults, applied to unset fields of an APIServerLoadBalancer health check.
+const (
+	defaultHealthCheckInterval           = 5
+	defaultHealthCheckTimeout            = 2
+	defaultHealthCheckHealthyThreshold   = 2
+	defaultHealthCheckUnhealthyThreshold = 3
+)
+
+// reconcileAPIServerLoadBalancerRuleSettings applies the algorithm, health check and stickiness of the API server load
+// balancer to its rules. Existing rules are looked up in rules to update their algorithm in place. The policies are
+// only looked at when they are configured, or when lbStatus records that CAPC applied one that has to be removed, so
+// accounts without access to the policy APIs can still run a load balancer without them.
+func (c *client) reconcileAPIServerLoadBalancerRuleSettings(
+	rules []*cloudstack.LoadBalancerRule,
+	ruleIDs []string,
+	apiLB *infrav1.APIServerLoadBalancer,
+	lbStatus *infrav1.LoadBalancer,
+) error {
+	algorithms := make(map[string]string, len(rules))
+	for _, rule := range rules {
+		algorithms[rule.Id] = rule.Algorithm
+	}
+	for _, ruleID := range ruleIDs {
+		if algorithm, found := algorithms[ruleID]; found && algorithm != apiLB.AlgorithmOrDefault() {
+			p := c.cs.LoadBalancer.NewUpdateLoadBalancerRuleParams(ruleID)
+			p.SetAlgorithm(apiLB.AlgorithmOrDefault())
+			if _, err := c.cs.LoadBalancer.UpdateLoadBalancerRule(p); err != nil {
+				c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+
+				return errors.Wrapf(err, "updating the algorithm of load balancer rule %s", ruleID)
+			}
+		}
+	}
+
+	if apiLB.HealthCheck != nil || lbStatus.HealthCheckPolicy {
+		for _, ruleID := range ruleIDs {
+			if err := c.reconcileLBHealthCheckPolicy(ruleID, apiLB.HealthCheck); err != nil {
+				return err
+			}
+		}
+		lbStatus.HealthCheckPolicy = apiLB.HealthCheck != nil
+	}
+	if apiLB.Stickiness != nil || lbStatus.StickinessPolicy {
+		for _, ruleID := range ruleIDs {
+			if err := c.reconcileLBStickinessPolicy(ruleID, apiLB.Stickiness); err != nil {
+				return err
+			}
+		}
+		lbStatus.StickinessPolicy = apiLB.Stickiness != nil
+	}
+
+	return nil
+}
+
+// lbHealthCheck holds the settings of a health check policy in the form CloudStack reports them.
+type lbHealthCheck struct {
+	pingPath           string
+	interval           int
+	timeout            int
+	healthyThreshold   int
+	unhealthyThreshold int
+}
+
+// desiredLBHealthCheck fills in the defaults of a health check. TCP checks go without a ping path.
+func desiredLBHealthCheck(hc *infrav1.LoadBalancerHealthCheck) lbHealthCheck {
+	desired := lbHealthCheck{
+		interval:           defaultHealthCheckInterval,
+		timeout:            defaultHealthCheckTimeout,
+		healthyThreshold:   defaultHealthCheckHealthyThreshold,
+		unhealthyThreshold: defaultHealthCheckUnhealthyThreshold,
+	}
+	if hc.IntervalSeconds != 0 {
+		desired.interval = hc.IntervalSeconds
+	}
+	if hc.TimeoutSeconds != 0 {
+		desired.timeout = hc.TimeoutSeconds
+	}
+	if hc.HealthyThreshold != 0 {
+		desired.healthyThreshold = hc.HealthyThreshold
+	}
+	if hc.UnhealthyThreshold != 0 {
+		desired.unhealthyThreshold = hc.UnhealthyThreshold
+	}
+
+	return desired
+}
+
+// existingLBHealthCheck converts a health check policy reported by CloudStack. CloudStack reports the default ping
+// path "/" for policies created without one.
+func existingLBHealthCheck(policy cloudstack.LBHealthCheckPolicyHealthcheckpolicy) lbHealthCheck {
+	existing := lbHealthCheck{
+		pingPath:           policy.Pingpath,
+		interval:           policy.Healthcheckinterval,
+		timeout:            policy.Responsetime,
+		healthyThreshold:   policy.Healthcheckthresshold,
+		unhealthyThreshold: policy.Unhealthcheckthresshold,
+	}
+	if existing.pingPath == "/" {
+		existing.pingPath = ""
+	}
+
+	return existing
+}
+
+// reconcileLBHealthCheckPolicy makes the health check policy of a load balancer rule match hc, removing it when hc is
+// nil.
+func (c *client) reconcileLBHealthCheckPolicy(ruleID string, hc *infrav1.LoadBalancerHealthCheck) error {
+	p := c.cs.LoadBalancer.NewListLBHealthCheckPoliciesParams()
+	p.SetLbruleid(ruleID)
+	resp, err := c.cs.LoadBalancer.ListLBHealthCheckPolicies(p)
+	if err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+
+		return errors.Wrapf(err, "listing health check policies of load balancer rule %s", ruleID)
+	}
+
+	found := false
+	for _, policies := range resp.LBHealthCheckPolicies {
+		for _, policy := range policies.Healthcheckpolicy {
+			if policy.State == "Revoke" {
+				continue
+			}
+			if hc != nil && !found && existingLBHealthCheck(policy) == desiredLBHealthCheck(hc) {
+				found = true
+
+				continue
+			}
+			dp := c.csAsync.LoadBalancer.NewDeleteLBHealthCheckPolicyParams(policy.Id)
+			if _, err := c.csAsync.LoadBalancer.DeleteLBHealthCheckPolicy(dp); err != nil {
+				c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+
+				return errors.Wrapf(err, "deleting health check policy %s", policy.Id)
+			}
+		}
+	}
+	if hc == nil || found {
+		return nil
+	}
+
+	desired := desiredLBHealthCheck(hc)
+	cp := c.cs.LoadBalancer.NewCreateLBHealthCheckPolicyParams(ruleID)
+	cp.SetIntervaltime(desired.interval)
+	cp.SetResponsetimeout(desired.timeout)
+	cp.SetHealthythreshold(desired.healthyThreshold)
+	cp.SetUnhealthythreshold(desired.unhealthyThreshold)
+	if _, err := c.cs.LoadBalancer.CreateLBHealthCheckPolicy(cp); err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+
+		return errors.Wrapf(err, "creating health check policy for load balancer rule %s", ruleID)
+	}
+
+	return nil
+}
+
+// reconcileLBStickinessPolicy makes the stickiness policy of a load balancer rule match stickiness, removing it when
+// stickiness is nil.
+func (c *client) reconcileLBStickinessPolicy(ruleID string, stickiness *infrav1.LoadBalancerStickiness) error {
+	p := c.cs.LoadBalancer.NewListLBStickinessPoliciesParams()
+	p.SetLbruleid(ruleID)
+	resp, err := c.cs.LoadBalancer.ListLBStickinessPolicies(p)
+	if err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+
+		return errors.Wrapf(err, "listing stickiness policies of load balancer rule %s", ruleID)
+	}
+
+	found := false
+	for _, policies := range resp.LBStickinessPolicies {
+		for _, policy := range policies.Stickinesspolicy {
+			if policy.State == "Revoke" {
+				continue
+			}
+			if stickiness != nil && !found && strings.EqualFold(policy.Methodname, stickiness.Method) {
+				found = true
+
+				continue
+			}
+			dp := c.csAsync.LoadBalancer.NewDeleteLBStickinessPolicyParams(policy.Id)
+			if _, err := c.csAsync.LoadBalancer.DeleteLBStickinessPolicy(dp); err != nil {
+				c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+
+				return errors.Wrapf(err, "deleting stickiness policy %s", policy.Id)
+			}
+		}
+	}
+	if stickiness == nil || found {
+		return nil
+	}
+
+	name := "capc-" + strings.ToLower(stickiness.Method)
+	cp := c.cs.LoadBalancer.NewCreateLBStickinessPolicyParams(ruleID, stickiness.Method, name)
+	if _, err := c.cs.LoadBalancer.CreateLBStickinessPolicy(cp); err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+
+		return errors.Wrapf(err, "creating stickiness policy for load balancer rule %s", ruleID)
+	}
+
+	return nil
+}
+
+func serviceLoadBalancerRuleKey(protocol string, publicPort, privatePort int) string {
+	return fmt.Sprintf("%s/%d/%d", strings.ToLower(protocol), publicPort, privatePort)
+}
+
+func serviceFirewallRuleKey(protocol string, port int, cidr string) string {
+	return fmt.Sprintf("%s/%d/%s", strings.ToLower(protocol), port, cidr)
+}
+
+// isTaggedCreatedByCAPC returns whether the tags of a resource mark it as created by CAPC.
+func isTaggedCreatedByCAPC(tags []cloudstack.Tags) bool {
+	return slices.ContainsFunc(tags, func(t cloudstack.Tags) bool {
+		return t.Key == CreatedByCAPCTagName && t.Value == "1"
+	})
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloud_test
+
+import (
+	csapi "github.com/apache/cloudstack-go/v2/cloudstack"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
+	"sigs.k8s.io/cluster-api-provider-cloudstack/pkg/cloud"
+	dummies "sigs.k8s.io/cluster-api-provider-cloudstack/test/dummies/v1beta3"
+)
+
+var _ = Describe("Load balancer", func() {
+	var (
+		mockCtrl   *gomock.Controller
+		mockClient *csapi.CloudStackClient
+		lbs        *csapi.MockLoadBalancerServiceIface
+		fs         *csapi.MockFirewallServiceIface
+		rs         *csapi.MockResourcetagsServiceIface
+		client     cloud.Client
+		lb         *infrav1.CloudStackLoadBalancer
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockClient = csapi.NewMockClient(mockCtrl)
+		lbs = mockClient.LoadBalancer.(*csapi.MockLoadBalancerServiceIface)
+		fs = mockClient.Firewall.(*csapi.MockFirewallServiceIface)
+		rs = mockClient.Resourcetags.(*csapi.MockResourcetagsServiceIface)
+		client = cloud.NewClientFromCSAPIClient(mockClient, nil)
+		dummies.SetDummyVars()
+		lb = &infrav1.CloudStackLoadBalancer{
+			ObjectMeta: metav1.ObjectMeta{Name: "ingress"},
+			Spec: infrav1.CloudStackLoadBalancerSpec{
+				FailureDomainName: dummies.CSFailureDomain1.Spec.Name,
+				Ports:             []infrav1.LoadBalancerPort{{Port: 443, TargetPort: 30443}},
+				AllowedCIDRs:      []string{"192.0.2.0/24"},
+			},
+			Status: infrav1.CloudStackLoadBalancerStatus{IPAddressID: "ip-id"},
+		}
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	Context("Reconciling the ports", func() {
+		It("keeps matching rules and creates missing ones", func() {
+			lbs.EXPECT().NewListLoadBalancerRulesParams().Return(&csapi.ListLoadBalancerRulesParams{})
+			lbs.EXPECT().ListLoadBalancerRules(gomock.Any()).Return(&csapi.ListLoadBalancerRulesResponse{
+				LoadBalancerRules: []*csapi.LoadBalancerRule{
+					{Id: "lb-rule", Protocol: "tcp", Publicport: "443", Privateport: "30443", Tags: dummies.CreatedByCAPCTag},
+				},
+			}, nil)
+			fs.EXPECT().NewListFirewallRulesParams().Return(&csapi.ListFirewallRulesParams{})
+			fs.EXPECT().ListFirewallRules(gomock.Any()).Return(&csapi.ListFirewallRulesResponse{}, nil)
+			fs.EXPECT().NewCreateFirewallRuleParams("ip-id", "tcp").Return(&csapi.CreateFirewallRuleParams{})
+			fs.EXPECT().CreateFirewallRule(gomock.Any()).DoAndReturn(func(p *csapi.CreateFirewallRuleParams) (*csapi.CreateFirewallRuleResponse, error) {
+				cidrs, _ := p.GetCidrlist()
+				port, _ := p.GetStartport()
+				Ω(cidrs).Should(Equal([]string{"192.0.2.0/24"}))
+				Ω(port).Should(Equal(443))
+
+				return &csapi.CreateFirewallRuleResponse{Id: "fw-rule"}, nil
+			})
+			rs.EXPECT().NewCreateTagsParams([]string{"fw-rule"}, string(cloud.ResourceTypeFirewallRule), gomock.Any()).
+				Return(&csapi.CreateTagsParams{})
+			rs.EXPECT().CreateTags(gomock.Any()).Return(&csapi.CreateTagsResponse{}, nil)
+
+			Ω(client.ReconcileLoadBalancerPorts(dummies.CSFailureDomain1, lb)).Should(Succeed())
+			Ω(lb.Status.LoadBalancerRuleIDs).Should(Equal([]string{"lb-rule"}))
+		})
+
+		It("replaces a rule whose target port changed", func() {
+			rs.EXPECT().NewListTagsParams().Return(&csapi.ListTagsParams{})
+			rs.EXPECT().ListTags(gomock.Any()).Return(
+				&csapi.ListTagsResponse{Tags: []*csapi.Tag{{Key: cloud.CreatedByCAPCTagName, Value: "1"}}}, nil)
+			lbs.EXPECT().NewListLoadBalancerRulesParams().Return(&csapi.ListLoadBalancerRulesParams{})
+			lbs.EXPECT().ListLoadBalancerRules(gomock.Any()).Return(&csapi.ListLoadBalancerRulesResponse{
+				LoadBalancerRules: []*csapi.LoadBalancerRule{
+					{Id: "stale", Protocol: "tcp", Publicport: "443", Privateport: "30080", Tags: dummies.CreatedByCAPCTag},
+				},
+			}, nil)
+			lbs.EXPECT().NewDeleteLoadBalancerRuleParams("stale").Return(&csapi.DeleteLoadBalancerRuleParams{})
+			lbs.EXPECT().DeleteLoadBalancerRule(gomock.Any()).Return(&csapi.DeleteLoadBalancerRuleResponse{Success: true}, nil)
+			lbs.EXPECT().NewCreateLoadBalancerRuleParams("roundrobin", "ingress_tcp_443", 30443, 443).
+				Return(&csapi.CreateLoadBalancerRuleParams{})
+			lbs.EXPECT().CreateLoadBalancerRule(gomock.Any()).Return(&csapi.CreateLoadBalancerRuleResponse{Id: "lb-rule"}, nil)
+			rs.EXPECT().NewCreateTagsParams([]string{"lb-rule"}, string(cloud.ResourceTypeLoadBalancerRule), gomock.Any()).
+				Return(&csapi.CreateTagsParams{})
+			rs.EXPECT().CreateTags(gomock.Any()).Return(&csapi.CreateTagsResponse{}, nil)
+			fs.EXPECT().NewListFirewallRulesParams().Return(&csapi.ListFirewallRulesParams{})
+			fs.EXPECT().ListFirewallRules(gomock.Any()).Return(&csapi.ListFirewallRulesResponse{
+				FirewallRules: []*csapi.FirewallRule{
+					{Id: "fw-rule", Protocol: "tcp", Startport: 443, Endport: 443, Cidrlist: "192.0.2.0/24", Tags: dummies.CreatedByCAPCTag},
+				},
+			}, nil)
+
+			Ω(client.ReconcileLoadBalancerPorts(dummies.CSFailureDomain1, lb)).Should(Succeed())
+			Ω(lb.Status.LoadBalancerRuleIDs).Should(Equal([]string{"lb-rule"}))
+		})
+	})
+
+	Context("Reconciling the instances of the load balancer rules", func() {
+		It("assigns missing instances and removes stale ones", func() {
+			lbs.EXPECT().NewListLoadBalancerRuleInstancesParams("lb-rule").Return(&csapi.ListLoadBalancerRuleInstancesParams{})
+			lbs.EXPECT().ListLoadBalancerRuleInstances(gomock.Any()).Return(&csapi.ListLoadBalancerRuleInstancesResponse{
+				LoadBalancerRuleInstances: []*csapi.VirtualMachine{{Id: "kept"}, {Id: "stale"}},
+			}, nil)
+			lbs.EXPECT().NewAssignToLoadBalancerRuleParams("lb-rule").Return(&csapi.AssignToLoadBalancerRuleParams{})
+			lbs.EXPECT().AssignToLoadBalancerRule(gomock.Any()).Return(&csapi.AssignToLoadBalancerRuleResponse{}, nil)
+			lbs.EXPECT().NewRemoveFromLoadBalancerRuleParams("lb-rule").Return(&csapi.RemoveFromLoadBalancerRuleParams{})
+			lbs.EXPECT().RemoveFromLoadBalancerRule(gomock.Any()).Return(&csapi.RemoveFromLoadBalancerRuleResponse{}, nil)
+
+			assigned, removed, err := client.ReconcileLoadBalancerRuleInstances([]string{"lb-rule"}, []string{"kept", "new"})
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(assigned).Should(Equal([]string{"new"}))
+			Ω(removed).Should(Equal([]string{"stale"}))
+		})
+	})
+})
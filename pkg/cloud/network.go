@@ -30,15 +30,18 @@ type NetworkIface interface {
 }
 
 const (
-	NetOffering         = "DefaultIsolatedNetworkOfferingWithSourceNatService"
-	NetworkTypeIsolated = "Isolated"
-	NetworkTypeShared   = "Shared"
-	NetworkProtocolTCP  = "tcp"
-	NetworkProtocolUDP  = "udp"
-	NetworkProtocolICMP = "icmp"
+	NetOffering               = "DefaultIsolatedNetworkOfferingWithSourceNatService"
+	NetworkTypeIsolated       = "Isolated"
+	NetworkTypeShared         = "Shared"
+	NetworkTypeVPCTier        = "VPCTier"
+	VPCTierNetOffering        = "DefaultIsolatedNetworkOfferingForVpcNetworks"
+	NetworkProtocolTCP        = "tcp"
+	NetworkProtocolUDP        = "udp"
+	NetworkProtocolICMP       = "icmp"
+	InternetProtocolDualStack = "DualStack"
 )
 
-// ResolveNetwork fetches networks' ID, Name, Type and Domain.
+// ResolveNetwork fetches networks' ID, Name, Type, CIDRs and Domain.
 func (c *client) ResolveNetwork(net *infrav1.Network) (retErr error) {
 	// TODO rebuild this to consider cases with networks in many zones.
 	// Use ListNetworks instead.
@@ -54,6 +57,7 @@ func (c *client) ResolveNetwork(net *infrav1.Network) (retErr error) {
 		net.ID = netDetails.Id
 		net.Type = netDetails.Type
 		net.CIDR = netDetails.Cidr
+		net.IPv6CIDR = netDetails.Ip6cidr
 		net.Domain = netDetails.Networkdomain
 
 		return nil
@@ -72,6 +76,7 @@ func (c *client) ResolveNetwork(net *infrav1.Network) (retErr error) {
 	net.ID = netDetails.Id
 	net.Type = netDetails.Type
 	net.CIDR = netDetails.Cidr
+	net.IPv6CIDR = netDetails.Ip6cidr
 	net.Domain = netDetails.Networkdomain
 
 	return nil
@@ -0,0 +1,249 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloud
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
+)
+
+type PreflightIface interface {
+	ListMissingAPIs(required []string) ([]string, error)
+	GetCloudStackVersion() (string, error)
+}
+
+// MinimumCloudStackVersion is the oldest CloudStack release CAPC supports.
+const MinimumCloudStackVersion = "4.14"
+
+// requiredAPIs are the APIs the role of every failure domain user must grant. The SSH keypair APIs are included since
+// CloudStackSSHKeyPairs may reference any failure domain at any time. The APIs below are only required by the network
+// types and features that use them. Keep in sync with docs/book/src/topics/cloudstack-permissions.md.
+var requiredAPIs = []string{
+	"createAffinityGroup",
+	"createSSHKeyPair",
+	"createTags",
+	"deleteAffinityGroup",
+	"deleteSSHKeyPair",
+	"deleteTags",
+	"deployVirtualMachine",
+	"destroyVirtualMachine",
+	"getUserKeys",
+	"listAccounts",
+	"listAffinityGroups",
+	"listDiskOfferings",
+	"listNetworkOfferings",
+	"listNetworks",
+	"listServiceOfferings",
+	"listSSHKeyPairs",
+	"listTags",
+	"listTemplates",
+	"listUsers",
+	"listVirtualMachines",
+	"listVirtualMachinesMetrics",
+	"listVolumes",
+	"listZones",
+	"queryAsyncJobResult",
+	"registerSSHKeyPair",
+	"startVirtualMachine",
+	"stopVirtualMachine",
+	"updateVMAffinityGroup",
+}
+
+// routedNetworkAPIs are required by failure domains whose network CAPC creates or puts a public IP address in front of,
+// i.e. isolated networks and VPC tiers.
+var routedNetworkAPIs = []string{
+	"associateIpAddress",
+	"createNetwork",
+	"deleteNetwork",
+	"disassociateIpAddress",
+	"listLoadBalancerRules",
+	"listPublicIpAddresses",
+}
+
+// isolatedNetworkAPIs manage the egress firewall of isolated networks.
+var isolatedNetworkAPIs = []string{
+	"createEgressFirewallRule",
+	"deleteEgressFirewallRule",
+	"listEgressFirewallRules",
+}
+
+// vpcTierAPIs manage the VPC and network ACLs of VPC tiers.
+var vpcTierAPIs = []string{
+	"createNetworkACL",
+	"createNetworkACLList",
+	"createVPC",
+	"deleteNetworkACL",
+	"deleteNetworkACLList",
+	"deleteVPC",
+	"listNetworkACLLists",
+	"listNetworkACLs",
+	"listVPCOfferings",
+	"listVPCs",
+	"replaceNetworkACLList",
+}
+
+// apiServerLoadBalancerAPIs manage the API server load balancer rules of isolated networks and VPC tiers.
+var apiServerLoadBalancerAPIs = []string{
+	"assignToLoadBalancerRule",
+	"createLoadBalancerRule",
+	"listLoadBalancerRuleInstances",
+	"removeFromLoadBalancerRule",
+}
+
+// lbHealthCheckAPIs manage the health check policy of the API server load balancer rules.
+var lbHealthCheckAPIs = []string{
+	"createLBHealthCheckPolicy",
+	"deleteLBHealthCheckPolicy",
+	"listLBHealthCheckPolicies",
+}
+
+// lbStickinessAPIs manage the stickiness policy of the API server load balancer rules.
+var lbStickinessAPIs = []string{
+	"createLBStickinessPolicy",
+	"deleteLBStickinessPolicy",
+	"listLBStickinessPolicies",
+}
+
+// ipv6FirewallAPIs manage the IPv6 firewall of dual-stack isolated networks.
+var ipv6FirewallAPIs = []string{
+	"createIpv6FirewallRule",
+	"deleteIpv6FirewallRule",
+	"listIpv6FirewallRules",
+}
+
+// bastionAPIs expose the bastion VM through static NAT.
+var bastionAPIs = []string{
+	"createFirewallRule",
+	"deleteFirewallRule",
+	"disableStaticNat",
+	"enableStaticNat",
+	"listFirewallRules",
+}
+
+// RequiredAPIs returns the sorted APIs the role of the failure domain user must grant, given the network type of the
+// failure domain and the features of the cluster it belongs to. The network type is the resolved one, a network that
+// doesn't exist yet is created as an isolated network.
+func RequiredAPIs(fdSpec infrav1.CloudStackFailureDomainSpec, csCluster *infrav1.CloudStackCluster) []string {
+	required := append([]string{}, requiredAPIs...)
+	network := fdSpec.Zone.Network
+	switch network.Type {
+	case infrav1.NetworkTypeShared:
+	case infrav1.NetworkTypeVPCTier:
+		required = append(append(required, routedNetworkAPIs...), vpcTierAPIs...)
+	default:
+		required = append(append(required, routedNetworkAPIs...), isolatedNetworkAPIs...)
+		if network.IPv6CIDR != "" {
+			required = append(required, ipv6FirewallAPIs...)
+		}
+	}
+	if apiLB := csCluster.Spec.APIServerLoadBalancer; network.Type != infrav1.NetworkTypeShared && apiLB.IsEnabled() {
+		required = append(required, apiServerLoadBalancerAPIs...)
+		if apiLB.HealthCheck != nil {
+			required = append(required, lbHealthCheckAPIs...)
+		}
+		if apiLB.Stickiness != nil {
+			required = append(required, lbStickinessAPIs...)
+		}
+	}
+	if bastion := csCluster.Spec.Bastion; bastion != nil && bastion.FailureDomainName == fdSpec.Name {
+		required = append(required, bastionAPIs...)
+	}
+	if vmLB := csCluster.Spec.APIServerVMLoadBalancer; vmLB != nil && vmLB.FailureDomainName == fdSpec.Name {
+		required = append(required, "updateVirtualMachine")
+	}
+	sort.Strings(required)
+
+	return required
+}
+
+// ListMissingAPIs returns the sorted APIs of required that the client's user may not call.
+func (c *client) ListMissingAPIs(required []string) ([]string, error) {
+	p := c.cs.APIDiscovery.NewListApisParams()
+	resp, err := c.cs.APIDiscovery.ListApis(p)
+	if err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+
+		return nil, errors.Wrap(err, "listing available APIs")
+	}
+
+	available := make(map[string]struct{}, len(resp.Apis))
+	for _, api := range resp.Apis {
+		// API names are case insensitive.
+		available[strings.ToLower(api.Name)] = struct{}{}
+	}
+	var missing []string
+	for _, api := range required {
+		if _, found := available[strings.ToLower(api)]; !found {
+			missing = append(missing, api)
+		}
+	}
+	sort.Strings(missing)
+
+	return missing, nil
+}
+
+// GetCloudStackVersion returns the version of the CloudStack management server, e.g. 4.19.1.0.
+func (c *client) GetCloudStackVersion() (string, error) {
+	p := c.cs.Configuration.NewListCapabilitiesParams()
+	resp, err := c.cs.Configuration.ListCapabilities(p)
+	if err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+
+		return "", errors.Wrap(err, "listing capabilities")
+	} else if resp.Capabilities == nil {
+		return "", errors.New("listCapabilities returned no capabilities")
+	}
+
+	return resp.Capabilities.Cloudstackversion, nil
+}
+
+// CheckCloudStackVersion returns an error if the CloudStack version is older than MinimumCloudStackVersion.
+func CheckCloudStackVersion(version string) error {
+	major, minor, err := parseMajorMinor(version)
+	if err != nil {
+		return errors.Wrapf(err, "parsing CloudStack version %q", version)
+	}
+	minMajor, minMinor, _ := parseMajorMinor(MinimumCloudStackVersion)
+	if major < minMajor || (major == minMajor && minor < minMinor) {
+		return errors.Errorf("CloudStack version %s is older than the minimum supported version %s", version, MinimumCloudStackVersion)
+	}
+
+	return nil
+}
+
+// parseMajorMinor parses the major and minor release of a version like 4.19.1.0 or 4.20.0.0-SNAPSHOT.
+func parseMajorMinor(version string) (int, int, error) {
+	parts := strings.SplitN(version, ".", 3)
+	if len(parts) < 2 {
+		return 0, 0, errors.New("expected at least a major and minor release")
+	}
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, 0, err
+	}
+	minor, err := strconv.Atoi(strings.SplitN(parts[1], "-", 2)[0])
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return major, minor, nil
+}
@@ -0,0 +1,170 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloud_test
+
+import (
+	"sort"
+
+	csapi "github.com/apache/cloudstack-go/v2/cloudstack"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
+	"sigs.k8s.io/cluster-api-provider-cloudstack/pkg/cloud"
+	dummies "sigs.k8s.io/cluster-api-provider-cloudstack/test/dummies/v1beta3"
+)
+
+var _ = Describe("Preflight", func() {
+	var (
+		client     cloud.Client
+		mockCtrl   *gomock.Controller
+		mockClient *csapi.CloudStackClient
+		ads        *csapi.MockAPIDiscoveryServiceIface
+		cs         *csapi.MockConfigurationServiceIface
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockClient = csapi.NewMockClient(mockCtrl)
+		ads = mockClient.APIDiscovery.(*csapi.MockAPIDiscoveryServiceIface)
+		cs = mockClient.Configuration.(*csapi.MockConfigurationServiceIface)
+		client = cloud.NewClientFromCSAPIClient(mockClient, nil)
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	Context("listing missing APIs", func() {
+		BeforeEach(func() {
+			ads.EXPECT().NewListApisParams().Return(&csapi.ListApisParams{})
+		})
+
+		It("returns the required APIs the user can't call", func() {
+			ads.EXPECT().ListApis(gomock.Any()).Return(&csapi.ListApisResponse{Count: 2, Apis: []*csapi.Api{
+				{Name: "listZones"}, {Name: "deployvirtualmachine"},
+			}}, nil)
+
+			missing, err := client.ListMissingAPIs([]string{"listZones", "deployVirtualMachine", "stopVirtualMachine", "createNetwork"})
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(missing).Should(Equal([]string{"createNetwork", "stopVirtualMachine"}))
+		})
+
+		It("returns nothing when every API is granted", func() {
+			ads.EXPECT().ListApis(gomock.Any()).Return(&csapi.ListApisResponse{Count: 1, Apis: []*csapi.Api{{Name: "listZones"}}}, nil)
+
+			Ω(client.ListMissingAPIs([]string{"listZones"})).Should(BeEmpty())
+		})
+
+		It("returns the error of listApis", func() {
+			ads.EXPECT().ListApis(gomock.Any()).Return(nil, errors.New("The API [listApis] does not exist or is not available for the account"))
+
+			_, err := client.ListMissingAPIs([]string{"listZones"})
+			Ω(err).Should(MatchError(ContainSubstring("listing available APIs")))
+		})
+	})
+
+	Context("building the required APIs", func() {
+		BeforeEach(func() {
+			dummies.SetDummyVars()
+		})
+
+		It("leaves the network management APIs out for shared networks", func() {
+			dummies.CSFailureDomain1.Spec.Zone.Network.Type = infrav1.NetworkTypeShared
+
+			required := cloud.RequiredAPIs(dummies.CSFailureDomain1.Spec, dummies.CSCluster)
+			Ω(required).Should(ContainElements("deployVirtualMachine", "listNetworks",
+				"createSSHKeyPair", "registerSSHKeyPair", "deleteSSHKeyPair"))
+			Ω(required).ShouldNot(ContainElements("createNetwork", "createEgressFirewallRule", "listEgressFirewallRules",
+				"listLBHealthCheckPolicies", "removeFromLoadBalancerRule"))
+			Ω(sort.StringsAreSorted(required)).Should(BeTrue())
+		})
+
+		It("requires the egress firewall and load balancer APIs for isolated networks", func() {
+			dummies.CSFailureDomain1.Spec.Zone.Network.Type = infrav1.NetworkTypeIsolated
+
+			required := cloud.RequiredAPIs(dummies.CSFailureDomain1.Spec, dummies.CSCluster)
+			Ω(required).Should(ContainElements("createNetwork", "listEgressFirewallRules", "removeFromLoadBalancerRule"))
+			Ω(required).ShouldNot(ContainElements("createVPC", "createIpv6FirewallRule"))
+		})
+
+		It("leaves the load balancer APIs out when the API server load balancer is disabled", func() {
+			dummies.CSFailureDomain1.Spec.Zone.Network.Type = infrav1.NetworkTypeIsolated
+			dummies.CSCluster.Spec.APIServerLoadBalancer = nil
+
+			Ω(cloud.RequiredAPIs(dummies.CSFailureDomain1.Spec, dummies.CSCluster)).ShouldNot(
+				ContainElements("assignToLoadBalancerRule", "createLoadBalancerRule"))
+		})
+
+		It("requires the load balancer policy APIs only for the configured policies", func() {
+			dummies.CSFailureDomain1.Spec.Zone.Network.Type = infrav1.NetworkTypeIsolated
+
+			Ω(cloud.RequiredAPIs(dummies.CSFailureDomain1.Spec, dummies.CSCluster)).ShouldNot(
+				ContainElements("listLBHealthCheckPolicies", "listLBStickinessPolicies"))
+
+			dummies.CSCluster.Spec.APIServerLoadBalancer.HealthCheck = &infrav1.LoadBalancerHealthCheck{Type: infrav1.LoadBalancerHealthCheckTCP}
+			required := cloud.RequiredAPIs(dummies.CSFailureDomain1.Spec, dummies.CSCluster)
+			Ω(required).Should(ContainElements("listLBHealthCheckPolicies", "createLBHealthCheckPolicy"))
+			Ω(required).ShouldNot(ContainElement("listLBStickinessPolicies"))
+		})
+
+		It("requires the VPC APIs but not the egress firewall APIs for VPC tiers", func() {
+			dummies.CSFailureDomain1.Spec.Zone.Network.Type = infrav1.NetworkTypeVPCTier
+
+			required := cloud.RequiredAPIs(dummies.CSFailureDomain1.Spec, dummies.CSCluster)
+			Ω(required).Should(ContainElements("createVPC", "replaceNetworkACLList"))
+			Ω(required).ShouldNot(ContainElement("createEgressFirewallRule"))
+		})
+
+		It("requires the bastion APIs only in the failure domain of the bastion", func() {
+			dummies.CSCluster.Spec.Bastion = &infrav1.Bastion{FailureDomainName: dummies.CSFailureDomain1.Spec.Name}
+
+			Ω(cloud.RequiredAPIs(dummies.CSFailureDomain1.Spec, dummies.CSCluster)).Should(ContainElement("enableStaticNat"))
+			Ω(cloud.RequiredAPIs(dummies.CSFailureDomain2.Spec, dummies.CSCluster)).ShouldNot(ContainElement("enableStaticNat"))
+		})
+	})
+
+	Context("getting the CloudStack version", func() {
+		It("returns the version from listCapabilities", func() {
+			cs.EXPECT().NewListCapabilitiesParams().Return(&csapi.ListCapabilitiesParams{})
+			cs.EXPECT().ListCapabilities(gomock.Any()).Return(&csapi.ListCapabilitiesResponse{
+				Capabilities: &csapi.Capability{Cloudstackversion: "4.19.1.0"},
+			}, nil)
+
+			Ω(client.GetCloudStackVersion()).Should(Equal("4.19.1.0"))
+		})
+	})
+
+	DescribeTable("checking the CloudStack version",
+		func(version string, supported bool) {
+			err := cloud.CheckCloudStackVersion(version)
+			if supported {
+				Ω(err).ShouldNot(HaveOccurred())
+			} else {
+				Ω(err).Should(HaveOccurred())
+			}
+		},
+		Entry("accepts the minimum version", "4.14.0.0", true),
+		Entry("accepts newer minor versions", "4.19.1.0", true),
+		Entry("accepts snapshot builds", "4.20-SNAPSHOT", true),
+		Entry("accepts newer major versions", "5.0.0", true),
+		Entry("rejects older versions", "4.13.1.0", false),
+		Entry("rejects garbage", "unknown", false),
+	)
+})
@@ -0,0 +1,202 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloud
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Endpoint secret keys of the session authentication settings, used instead of api-key and secret-key.
+const (
+	UsernameKey = "username"
+	PasswordKey = "password"
+	DomainKey   = "domain"
+)
+
+// usesSessionAuth returns whether the endpoint logs in with a username and password rather than signing requests
+// with API keys.
+func (conf Config) usesSessionAuth() bool {
+	return conf.APIKey == "" && conf.Username != ""
+}
+
+// loginResponse is the part of the login API response the client needs.
+type loginResponse struct {
+	SessionKey string `json:"sessionkey"`
+	UserID     string `json:"userid"`
+	Account    string `json:"account"`
+	DomainID   string `json:"domainid"`
+}
+
+// session is an http.RoundTripper that authenticates API requests with a CloudStack login session. cloudstack-go
+// always signs requests with the API keys it was built with, so the session strips the (empty) key and signature and
+// adds the session key instead. The session logs in on first use, and again whenever CloudStack rejects the session
+// key, e.g. once the session timed out.
+type session struct {
+	base     http.RoundTripper
+	jar      http.CookieJar
+	apiURL   string
+	username string
+	password string
+	domain   string
+
+	mu    sync.Mutex
+	login *loginResponse
+}
+
+// newSession returns a session for the endpoint, sending requests through base.
+func newSession(conf Config, base http.RoundTripper) (*session, error) {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &session{
+		base:     base,
+		jar:      jar,
+		apiURL:   conf.APIUrl,
+		username: conf.Username,
+		password: conf.Password,
+		domain:   conf.Domain,
+	}, nil
+}
+
+// Login returns the current login, logging in if there's none yet.
+func (s *session) Login() (*loginResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.login != nil {
+		return s.login, nil
+	}
+
+	return s.relogin()
+}
+
+// refresh logs in again unless another request already replaced the stale session key.
+func (s *session) refresh(staleKey string) (*loginResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.login != nil && s.login.SessionKey != staleKey {
+		return s.login, nil
+	}
+
+	return s.relogin()
+}
+
+// relogin calls the login API. Must be called with mu held.
+func (s *session) relogin() (*loginResponse, error) {
+	form := url.Values{}
+	form.Set("command", "login")
+	form.Set("response", "json")
+	form.Set("username", s.username)
+	form.Set("password", s.password)
+	// An empty domain logs in to the ROOT domain.
+	setIfNotEmpty(s.domain, func(domain string) { form.Set("domain", domain) })
+
+	req, err := http.NewRequest(http.MethodPost, s.apiURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := (&http.Client{Transport: s.base, Jar: s.jar}).Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "logging in to %s as %s", s.apiURL, s.username)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("logging in to %s as %s failed with status %d: %s", s.apiURL, s.username, resp.StatusCode, body)
+	}
+
+	var decoded struct {
+		LoginResponse loginResponse `json:"loginresponse"`
+	}
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		return nil, errors.Wrap(err, "decoding login response")
+	} else if decoded.LoginResponse.SessionKey == "" {
+		return nil, errors.Errorf("login response from %s has no session key", s.apiURL)
+	}
+	s.login = &decoded.LoginResponse
+
+	return s.login, nil
+}
+
+// RoundTrip sends the request with the session key, logging in again and retrying once if the session expired.
+func (s *session) RoundTrip(req *http.Request) (*http.Response, error) {
+	login, err := s.Login()
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.base.RoundTrip(s.authenticate(req, login.SessionKey))
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	resp.Body.Close()
+
+	if login, err = s.refresh(login.SessionKey); err != nil {
+		return nil, err
+	}
+
+	return s.base.RoundTrip(s.authenticate(req, login.SessionKey))
+}
+
+// authenticate returns a copy of the request authenticated with the session key and cookie.
+func (s *session) authenticate(req *http.Request, sessionKey string) *http.Request {
+	authenticated := req.Clone(req.Context())
+	withSessionKey := func(params url.Values) string {
+		params.Del("apiKey")
+		params.Del("signature")
+		params.Set("sessionkey", sessionKey)
+
+		return params.Encode()
+	}
+
+	if req.Method == http.MethodPost && req.GetBody != nil {
+		// POSTs carry the parameters in a form body.
+		if body, err := req.GetBody(); err == nil {
+			content, _ := io.ReadAll(body)
+			if params, err := url.ParseQuery(string(content)); err == nil {
+				encoded := withSessionKey(params)
+				authenticated.Body = io.NopCloser(strings.NewReader(encoded))
+				authenticated.ContentLength = int64(len(encoded))
+				authenticated.GetBody = func() (io.ReadCloser, error) {
+					return io.NopCloser(strings.NewReader(encoded)), nil
+				}
+			}
+		}
+	} else {
+		authenticated.URL.RawQuery = withSessionKey(req.URL.Query())
+	}
+
+	// The session key is only accepted along with the session cookie set by login.
+	authenticated.Header.Del("Cookie")
+	for _, cookie := range s.jar.Cookies(req.URL) {
+		authenticated.AddCookie(cookie)
+	}
+
+	return authenticated
+}
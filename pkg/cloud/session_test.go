@@ -0,0 +1,153 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloud_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	"github.com/apache/cloudstack-go/v2/cloudstack"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
+	"sigs.k8s.io/cluster-api-provider-cloudstack/pkg/cloud"
+)
+
+var _ = Describe("Session authentication", func() {
+	var (
+		server         *httptest.Server
+		mu             sync.Mutex
+		logins         int
+		sessionKey     string
+		apiKeyRequests int
+		conf           cloud.Config
+		origNewClient  = cloud.NewClient
+		origNewAsyncCl = cloud.NewAsyncClient
+	)
+
+	// fakeCloudStack answers login, and the few list APIs the client calls, for requests carrying the current session.
+	fakeCloudStack := func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		Ω(r.ParseForm()).Should(Succeed())
+		w.Header().Set("Content-Type", "application/json")
+
+		if r.Form.Get("command") == "login" {
+			Ω(r.Method).Should(Equal(http.MethodPost))
+			if r.Form.Get("username") != "capc" || r.Form.Get("password") != "s3cret" || r.Form.Get("domain") != "ROOT/tenant" {
+				w.WriteHeader(http.StatusUnauthorized)
+				fmt.Fprint(w, `{"loginresponse":{"errorcode":401,"errortext":"unable to verify user credentials"}}`)
+
+				return
+			}
+			logins++
+			sessionKey = fmt.Sprintf("session-%d", logins)
+			http.SetCookie(w, &http.Cookie{Name: "JSESSIONID", Value: sessionKey, Path: "/"})
+			fmt.Fprintf(w, `{"loginresponse":{"sessionkey":%q,"userid":"user-id","account":"tenant-admin","domainid":"domain-id"}}`, sessionKey)
+
+			return
+		}
+
+		if r.Form.Has("apiKey") || r.Form.Has("signature") {
+			apiKeyRequests++
+		}
+		cookie, err := r.Cookie("JSESSIONID")
+		if err != nil || cookie.Value != sessionKey || r.Form.Get("sessionkey") != sessionKey {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprintf(w, `{"%sresponse":{"errorcode":401,"errortext":"unable to verify user credentials"}}`, r.Form.Get("command"))
+
+			return
+		}
+		switch r.Form.Get("command") {
+		case "listDomains":
+			fmt.Fprint(w, `{"listdomainsresponse":{"count":1,"domain":[{"id":"domain-id","name":"tenant","path":"ROOT/tenant"}]}}`)
+		case "listAccounts":
+			fmt.Fprint(w, `{"listaccountsresponse":{"count":1,"account":[{"id":"account-id","name":"tenant-admin"}]}}`)
+		case "listUsers":
+			fmt.Fprint(w, `{"listusersresponse":{"count":1,"user":[{"id":"user-id","username":"capc","account":"tenant-admin"}]}}`)
+		case "getUserKeys":
+			fmt.Fprint(w, `{"getuserkeysresponse":{"userkeys":{}}}`)
+		case "listZones":
+			fmt.Fprint(w, `{"listzonesresponse":{"count":1,"zone":[{"id":"zone-id","name":"zone1"}]}}`)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, `{"errorresponse":{"errorcode":432,"errortext":"unexpected command %s"}}`, r.Form.Get("command"))
+		}
+	}
+
+	BeforeEach(func() {
+		// Talk to the fake CloudStack for real rather than through the mock client used by the other client tests.
+		origNewClient, origNewAsyncCl = cloud.NewClient, cloud.NewAsyncClient
+		cloud.NewClient, cloud.NewAsyncClient = cloudstack.NewClient, cloudstack.NewAsyncClient
+		logins, sessionKey, apiKeyRequests = 0, "", 0
+		server = httptest.NewServer(http.HandlerFunc(fakeCloudStack))
+		conf = cloud.Config{APIUrl: server.URL + "/client/api", Username: "capc", Password: "s3cret", Domain: "ROOT/tenant"}
+	})
+
+	AfterEach(func() {
+		server.Close()
+		cloud.NewClient, cloud.NewAsyncClient = origNewClient, origNewAsyncCl
+	})
+
+	It("logs in and calls APIs with the session instead of API keys", func() {
+		client, err := cloud.NewClientFromConf(conf, nil)
+		Ω(err).ShouldNot(HaveOccurred())
+
+		zone := &infrav1.CloudStackZoneSpec{Name: "zone1"}
+		Ω(client.ResolveZone(zone)).Should(Succeed())
+		Ω(zone.ID).Should(Equal("zone-id"))
+		Ω(logins).Should(Equal(1))
+		Ω(apiKeyRequests).Should(BeZero())
+	})
+
+	It("logs in again once the session expires", func() {
+		client, err := cloud.NewClientFromConf(conf, nil)
+		Ω(err).ShouldNot(HaveOccurred())
+
+		mu.Lock()
+		sessionKey = "expired"
+		mu.Unlock()
+		Ω(client.ResolveZone(&infrav1.CloudStackZoneSpec{Name: "zone1"})).Should(Succeed())
+		Ω(logins).Should(Equal(2))
+	})
+
+	It("fails with the wrong password", func() {
+		conf.Password = "wrong"
+		_, err := cloud.NewClientFromConf(conf, nil)
+		Ω(err).Should(MatchError(ContainSubstring("status 401")))
+	})
+
+	It("returns a client in its own account without API keys", func() {
+		client, err := cloud.NewClientFromConf(conf, nil)
+		Ω(err).ShouldNot(HaveOccurred())
+
+		accountClient, err := client.NewClientInDomainAndAccount("ROOT/tenant", "tenant-admin")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(accountClient).Should(BeIdenticalTo(client))
+	})
+
+	It("reads the session settings from an INI cloud-config", func() {
+		configs, err := cloud.ParseCloudConfigINI([]byte("[Global]\napi-url = https://cloudstack/client/api\nusername = capc\npassword = \"s3cret\"\ndomain = ROOT/tenant\n"))
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(configs[""].Username).Should(Equal("capc"))
+		Ω(configs[""].Password).Should(Equal("s3cret"))
+		Ω(configs[""].Domain).Should(Equal("ROOT/tenant"))
+	})
+})
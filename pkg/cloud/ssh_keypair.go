@@ -0,0 +1,172 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloud
+
+import (
+	"crypto/md5" //nolint:gosec // Only used for fingerprints, see SSHPublicKeyFingerprint.
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/apache/cloudstack-go/v2/cloudstack"
+	"github.com/pkg/errors"
+)
+
+// ErrSSHKeyPairNotCreatedByCAPC is returned for a keypair that already exists under the requested name but wasn't
+// created by CAPC. Such keypairs are never taken over, as CAPC deletes the keypairs it manages.
+var ErrSSHKeyPairNotCreatedByCAPC = errors.New("SSH keypair was not created by CAPC")
+
+// SSHKeyPair is a CloudStack SSH keypair. PrivateKey is only ever populated right after CloudStack generated the
+// keypair, as CloudStack does not return it again afterwards. Fingerprint is the one CAPC recorded when it created the
+// keypair, if any, and is updated to the one CloudStack reports.
+type SSHKeyPair struct {
+	Name        string
+	ID          string
+	Fingerprint string
+	PublicKey   string
+	PrivateKey  string
+}
+
+type SSHKeyPairIface interface {
+	FetchSSHKeyPair(keyPair *SSHKeyPair) error
+	GetOrRegisterSSHKeyPair(keyPair *SSHKeyPair) error
+	GetOrCreateSSHKeyPair(keyPair *SSHKeyPair) error
+	DeleteSSHKeyPair(keyPair *SSHKeyPair) error
+}
+
+// FetchSSHKeyPair looks up a keypair by name in the client user's account or project. It returns an error wrapping
+// ErrNotFound if there's no such keypair.
+func (c *client) FetchSSHKeyPair(keyPair *SSHKeyPair) error {
+	if keyPair.Name == "" {
+		return errors.New("could not fetch SSH keypair without a name")
+	}
+	resp, count, err := c.cs.SSH.GetSSHKeyPairByName(keyPair.Name, cloudstack.WithProject(c.user.Project.ID))
+	if (err != nil && strings.Contains(strings.ToLower(err.Error()), "no match found")) || (err == nil && count == 0) {
+		return errors.Wrapf(ErrNotFound, "SSH keypair %s", keyPair.Name)
+	} else if err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+
+		return err
+	} else if count != 1 {
+		return errors.Errorf("expected 1 SSH keypair with name %s, but got %d", keyPair.Name, count)
+	}
+	keyPair.ID = resp.Id
+	keyPair.Fingerprint = resp.Fingerprint
+
+	return nil
+}
+
+// fetchOwnedSSHKeyPair fetches the named keypair like FetchSSHKeyPair, and returns an error wrapping
+// ErrSSHKeyPairNotCreatedByCAPC unless its fingerprint is the one recorded in keyPair.
+func (c *client) fetchOwnedSSHKeyPair(keyPair *SSHKeyPair) error {
+	recorded := keyPair.Fingerprint
+	if err := c.FetchSSHKeyPair(keyPair); err != nil {
+		return err
+	}
+	if recorded == "" || !strings.EqualFold(recorded, keyPair.Fingerprint) {
+		return errors.Wrapf(ErrSSHKeyPairNotCreatedByCAPC, "SSH keypair %s", keyPair.Name)
+	}
+
+	return nil
+}
+
+// GetOrRegisterSSHKeyPair fetches the named keypair, registering keyPair.PublicKey under that name if it's not found.
+// An existing keypair is only accepted if CAPC created it and it still holds keyPair.PublicKey.
+func (c *client) GetOrRegisterSSHKeyPair(keyPair *SSHKeyPair) error {
+	if keyPair.PublicKey == "" {
+		return errors.Errorf("could not register SSH keypair %s without a public key", keyPair.Name)
+	}
+	fingerprint, err := SSHPublicKeyFingerprint(keyPair.PublicKey)
+	if err != nil {
+		return errors.Wrapf(err, "registering SSH keypair %s", keyPair.Name)
+	}
+	if err := c.fetchOwnedSSHKeyPair(keyPair); err == nil {
+		if !strings.EqualFold(keyPair.Fingerprint, fingerprint) {
+			return errors.Errorf("SSH keypair %s was registered with a different public key", keyPair.Name)
+		}
+
+		return nil
+	} else if !errors.Is(err, ErrNotFound) {
+		return err
+	}
+
+	p := c.cs.SSH.NewRegisterSSHKeyPairParams(keyPair.Name, keyPair.PublicKey)
+	setIfNotEmpty(c.user.Project.ID, p.SetProjectid)
+	resp, err := c.cs.SSH.RegisterSSHKeyPair(p)
+	if err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+
+		return errors.Wrapf(err, "registering SSH keypair %s", keyPair.Name)
+	}
+	keyPair.ID = resp.Id
+	keyPair.Fingerprint = resp.Fingerprint
+
+	return nil
+}
+
+// GetOrCreateSSHKeyPair fetches the named keypair, asking CloudStack to generate it if it's not found. The generated
+// private key is set on keyPair and must be persisted by the caller. An existing keypair is only accepted if CAPC
+// created it.
+func (c *client) GetOrCreateSSHKeyPair(keyPair *SSHKeyPair) error {
+	if err := c.fetchOwnedSSHKeyPair(keyPair); err == nil || !errors.Is(err, ErrNotFound) {
+		return err
+	}
+
+	p := c.cs.SSH.NewCreateSSHKeyPairParams(keyPair.Name)
+	setIfNotEmpty(c.user.Project.ID, p.SetProjectid)
+	resp, err := c.cs.SSH.CreateSSHKeyPair(p)
+	if err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+
+		return errors.Wrapf(err, "creating SSH keypair %s", keyPair.Name)
+	}
+	keyPair.ID = resp.Id
+	keyPair.Fingerprint = resp.Fingerprint
+	keyPair.PrivateKey = resp.Privatekey
+
+	return nil
+}
+
+// DeleteSSHKeyPair deletes the named keypair from the client user's account or project.
+func (c *client) DeleteSSHKeyPair(keyPair *SSHKeyPair) error {
+	p := c.cs.SSH.NewDeleteSSHKeyPairParams(keyPair.Name)
+	setIfNotEmpty(c.user.Project.ID, p.SetProjectid)
+	_, err := c.cs.SSH.DeleteSSHKeyPair(p)
+	c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+
+	return err
+}
+
+// SSHPublicKeyFingerprint returns the MD5 fingerprint of an OpenSSH public key in the colon separated form CloudStack
+// reports for keypairs.
+func SSHPublicKeyFingerprint(publicKey string) (string, error) {
+	fields := strings.Fields(publicKey)
+	if len(fields) < 2 {
+		return "", errors.New("public key is not in the OpenSSH authorized_keys format")
+	}
+	blob, err := base64.StdEncoding.DecodeString(fields[1])
+	if err != nil {
+		return "", errors.Wrap(err, "decoding public key")
+	}
+	sum := md5.Sum(blob) //nolint:gosec // CloudStack identifies keypairs by the MD5 fingerprint of their public key.
+	hexBytes := make([]string, 0, len(sum))
+	for _, b := range sum {
+		hexBytes = append(hexBytes, fmt.Sprintf("%02x", b))
+	}
+
+	return strings.Join(hexBytes, ":"), nil
+}
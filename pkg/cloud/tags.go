@@ -41,10 +41,15 @@ type ResourceType string
 const (
 	ClusterTagNamePrefix                      = "CAPC_cluster_"
 	CreatedByCAPCTagName                      = "created_by_CAPC"
+	APIServerPortTagName                      = "CAPC_api_server_port"
 	ResourceTypeNetwork          ResourceType = "Network"
 	ResourceTypeIPAddress        ResourceType = "PublicIpAddress"
 	ResourceTypeLoadBalancerRule ResourceType = "LoadBalancer"
 	ResourceTypeFirewallRule     ResourceType = "FirewallRule"
+	ResourceTypeVPC              ResourceType = "Vpc"
+	ResourceTypeNetworkACLList   ResourceType = "NetworkACLList"
+	ResourceTypeProject          ResourceType = "Project"
+	ResourceTypeAccount          ResourceType = "Account"
 )
 
 // ignoreAlreadyPresentErrors returns nil if the error is an already present tag error.
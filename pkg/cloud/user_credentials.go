@@ -17,9 +17,14 @@ limitations under the License.
 package cloud
 
 import (
+	"crypto/rand"
+	"encoding/base64"
 	"strings"
 
+	"github.com/apache/cloudstack-go/v2/cloudstack"
 	"github.com/pkg/errors"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
 )
 
 const (
@@ -27,12 +32,27 @@ const (
 	domainDelimiter = "/"
 )
 
+// CloudStack resource types as used by updateResourceLimit.
+const (
+	resourceTypeInstance       = 0
+	resourceTypePublicIP       = 1
+	resourceTypeVolume         = 2
+	resourceTypeNetwork        = 6
+	resourceTypeCPU            = 8
+	resourceTypeMemory         = 9
+	resourceTypePrimaryStorage = 10
+)
+
 type UserCredIFace interface {
 	ResolveDomain(domain *Domain) error
 	ResolveAccount(account *Account) error
 	ResolveUser(user *User) error
 	ResolveUserKeys(user *User) error
 	GetUserWithKeys(user *User) (bool, error)
+	GetOrCreateProject(project *Project, limits *infrav1.CloudStackResourceLimits) error
+	DeleteProject(project *Project) error
+	GetOrCreateAccount(account *Account, roleID string, limits *infrav1.CloudStackResourceLimits) error
+	DeleteAccount(account *Account) error
 }
 
 // Domain contains specifications that identify a domain.
@@ -43,6 +63,11 @@ type Domain struct {
 	CPUAvailable    string
 	MemoryAvailable string
 	VMAvailable     string
+	// PrimaryStorageAvailable is in GiB.
+	PrimaryStorageAvailable string
+	VolumeAvailable         string
+	IPAvailable             string
+	NetworkAvailable        string
 }
 
 // Account contains specifications that identify an account.
@@ -53,6 +78,11 @@ type Account struct {
 	CPUAvailable    string
 	MemoryAvailable string
 	VMAvailable     string
+	// PrimaryStorageAvailable is in GiB.
+	PrimaryStorageAvailable string
+	VolumeAvailable         string
+	IPAvailable             string
+	NetworkAvailable        string
 }
 
 // Project contains specifications that identify a project.
@@ -62,6 +92,11 @@ type Project struct {
 	CPUAvailable    string
 	MemoryAvailable string
 	VMAvailable     string
+	// PrimaryStorageAvailable is in GiB.
+	PrimaryStorageAvailable string
+	VolumeAvailable         string
+	IPAvailable             string
+	NetworkAvailable        string
 }
 
 // User contains information uniquely identifying and scoping a user.
@@ -125,6 +160,10 @@ func (c *client) ResolveDomain(domain *Domain) error {
 		domain.CPUAvailable = resp.Domains[0].Cpuavailable
 		domain.MemoryAvailable = resp.Domains[0].Memoryavailable
 		domain.VMAvailable = resp.Domains[0].Vmavailable
+		domain.PrimaryStorageAvailable = resp.Domains[0].Primarystorageavailable
+		domain.VolumeAvailable = resp.Domains[0].Volumeavailable
+		domain.IPAvailable = resp.Domains[0].Ipavailable
+		domain.NetworkAvailable = resp.Domains[0].Networkavailable
 
 		return nil
 	}
@@ -146,6 +185,10 @@ func (c *client) ResolveDomain(domain *Domain) error {
 		domain.CPUAvailable = possibleDomain.Cpuavailable
 		domain.MemoryAvailable = possibleDomain.Memoryavailable
 		domain.VMAvailable = possibleDomain.Vmavailable
+		domain.PrimaryStorageAvailable = possibleDomain.Primarystorageavailable
+		domain.VolumeAvailable = possibleDomain.Volumeavailable
+		domain.IPAvailable = possibleDomain.Ipavailable
+		domain.NetworkAvailable = possibleDomain.Networkavailable
 
 		return nil
 	}
@@ -171,7 +214,7 @@ func (c *client) ResolveAccount(account *Account) error {
 
 		return retErr
 	} else if resp.Count == 0 {
-		return errors.Errorf("could not find account %s", account.Name)
+		return errors.Wrapf(ErrNotFound, "could not find account %s", account.Name)
 	} else if resp.Count != 1 {
 		return errors.Errorf("expected 1 Account with account name %s in domain ID %s, but got %d",
 			account.Name, account.Domain.ID, resp.Count)
@@ -181,6 +224,10 @@ func (c *client) ResolveAccount(account *Account) error {
 	account.CPUAvailable = resp.Accounts[0].Cpuavailable
 	account.MemoryAvailable = resp.Accounts[0].Memoryavailable
 	account.VMAvailable = resp.Accounts[0].Vmavailable
+	account.PrimaryStorageAvailable = resp.Accounts[0].Primarystorageavailable
+	account.VolumeAvailable = resp.Accounts[0].Volumeavailable
+	account.IPAvailable = resp.Accounts[0].Ipavailable
+	account.NetworkAvailable = resp.Accounts[0].Networkavailable
 
 	return nil
 }
@@ -212,6 +259,10 @@ func (c *client) ResolveProject(user *User) error {
 	c.user.Project.CPUAvailable = resp.Projects[0].Cpuavailable
 	c.user.Project.MemoryAvailable = resp.Projects[0].Memoryavailable
 	c.user.Project.VMAvailable = resp.Projects[0].Vmavailable
+	c.user.Project.PrimaryStorageAvailable = resp.Projects[0].Primarystorageavailable
+	c.user.Project.VolumeAvailable = resp.Projects[0].Volumeavailable
+	c.user.Project.IPAvailable = resp.Projects[0].Ipavailable
+	c.user.Project.NetworkAvailable = resp.Projects[0].Networkavailable
 
 	return nil
 }
@@ -290,11 +341,191 @@ func (c *client) GetUserWithKeys(user *User) (bool, error) {
 	// Return first user with keys.
 	for _, possibleUser := range resp.Users {
 		user.ID = possibleUser.Id
-		if err := c.ResolveUserKeys(user); err == nil {
+		// Users without keys resolve to empty ones.
+		if err := c.ResolveUserKeys(user); err == nil && user.APIKey != "" {
 			return true, nil
 		}
 	}
 	user.ID = ""
+	user.APIKey, user.SecretKey = "", ""
 
 	return false, nil
 }
+
+// GetOrCreateProject fetches a project by name, creating it under the client user's account if it's not found.
+// Limits are only applied to a newly created project, which is tagged as created by CAPC so that only it is ever
+// deleted.
+func (c *client) GetOrCreateProject(project *Project, limits *infrav1.CloudStackResourceLimits) error {
+	resp, count, err := c.cs.Project.GetProjectByName(project.Name, cloudstack.WithDomain(c.user.Account.Domain.ID))
+	if err == nil && count == 1 {
+		project.ID = resp.Id
+
+		return nil
+	} else if err != nil && !strings.Contains(strings.ToLower(err.Error()), "no match found") {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+
+		return errors.Wrapf(err, "getting project %s", project.Name)
+	}
+
+	p := c.csAsync.Project.NewCreateProjectParams(project.Name, project.Name)
+	p.SetName(project.Name)
+	setIfNotEmpty(c.user.Account.Domain.ID, p.SetDomainid)
+	setIfNotEmpty(c.user.Account.Name, p.SetAccount)
+	created, err := c.csAsync.Project.CreateProject(p)
+	if err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+
+		return errors.Wrapf(err, "creating project %s", project.Name)
+	}
+	project.ID = created.Id
+	if err := c.AddCreatedByCAPCTag(ResourceTypeProject, project.ID); err != nil {
+		return errors.Wrapf(err, "tagging project %s", project.Name)
+	}
+
+	return c.updateResourceLimits(limits, func(p *cloudstack.UpdateResourceLimitParams) {
+		p.SetProjectid(project.ID)
+	})
+}
+
+// DeleteProject deletes a project CAPC created along with any resources left in it. Projects without the created by
+// CAPC tag are left alone.
+func (c *client) DeleteProject(project *Project) error {
+	if project.ID == "" {
+		id, count, err := c.cs.Project.GetProjectID(project.Name, cloudstack.WithDomain(c.user.Account.Domain.ID))
+		if (err != nil && strings.Contains(strings.ToLower(err.Error()), "no match found")) || (err == nil && count == 0) {
+			// Already gone.
+			return nil
+		} else if err != nil {
+			c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+
+			return errors.Wrapf(err, "getting project %s", project.Name)
+		}
+		project.ID = id
+	}
+	if managedByCAPC, err := c.IsCapcManaged(ResourceTypeProject, project.ID); err != nil || !managedByCAPC {
+		return err
+	}
+
+	p := c.csAsync.Project.NewDeleteProjectParams(project.ID)
+	p.SetCleanup(true)
+	_, err := c.csAsync.Project.DeleteProject(p)
+	c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+
+	return errors.Wrapf(err, "deleting project %s", project.Name)
+}
+
+// GetOrCreateAccount fetches an account by name, creating it with a single user holding API keys if it's not found.
+// If no domain is specified, the client user's domain is used. Limits are only applied to a newly created account,
+// which is tagged as created by CAPC so that only it is ever deleted.
+func (c *client) GetOrCreateAccount(account *Account, roleID string, limits *infrav1.CloudStackResourceLimits) error {
+	if account.Domain.Path == "" && account.Domain.ID == "" {
+		account.Domain.ID = c.user.Account.Domain.ID
+	}
+	if err := c.ResolveDomain(&account.Domain); err != nil {
+		return errors.Wrapf(err, "resolving domain for account %s", account.Name)
+	}
+	if err := c.ResolveAccount(account); err == nil || !errors.Is(err, ErrNotFound) {
+		return err
+	}
+
+	password, err := generatePassword()
+	if err != nil {
+		return err
+	}
+	p := c.cs.Account.NewCreateAccountParams(account.Name+"@capc.invalid", "CAPC", account.Name, password, account.Name)
+	p.SetAccount(account.Name)
+	p.SetDomainid(account.Domain.ID)
+	if roleID != "" {
+		p.SetRoleid(roleID)
+	} else {
+		p.SetAccounttype(0)
+	}
+	resp, err := c.cs.Account.CreateAccount(p)
+	if err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+
+		return errors.Wrapf(err, "creating account %s", account.Name)
+	}
+	account.ID = resp.Id
+	if err := c.AddCreatedByCAPCTag(ResourceTypeAccount, account.ID); err != nil {
+		return errors.Wrapf(err, "tagging account %s", account.Name)
+	}
+	if len(resp.User) == 0 {
+		return errors.Errorf("created account %s has no user", account.Name)
+	}
+	if _, err := c.cs.User.RegisterUserKeys(c.cs.User.NewRegisterUserKeysParams(resp.User[0].Id)); err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+
+		return errors.Wrapf(err, "registering API keys for account %s", account.Name)
+	}
+
+	return c.updateResourceLimits(limits, func(p *cloudstack.UpdateResourceLimitParams) {
+		p.SetAccount(account.Name)
+		p.SetDomainid(account.Domain.ID)
+	})
+}
+
+// DeleteAccount deletes an account CAPC created along with all of its resources. Accounts without the created by CAPC
+// tag are left alone.
+func (c *client) DeleteAccount(account *Account) error {
+	if account.ID == "" {
+		if account.Domain.Path == "" && account.Domain.ID == "" {
+			account.Domain.ID = c.user.Account.Domain.ID
+		}
+		if err := c.ResolveAccount(account); errors.Is(err, ErrNotFound) {
+			// Already gone.
+			return nil
+		} else if err != nil {
+			return errors.Wrapf(err, "resolving account %s", account.Name)
+		}
+	}
+	if managedByCAPC, err := c.IsCapcManaged(ResourceTypeAccount, account.ID); err != nil || !managedByCAPC {
+		return err
+	}
+
+	_, err := c.csAsync.Account.DeleteAccount(c.csAsync.Account.NewDeleteAccountParams(account.ID))
+	c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+
+	return errors.Wrapf(err, "deleting account %s", account.Name)
+}
+
+// updateResourceLimits sets each specified limit. The scope function targets the project or account.
+func (c *client) updateResourceLimits(limits *infrav1.CloudStackResourceLimits, scope func(*cloudstack.UpdateResourceLimitParams)) error {
+	if limits == nil {
+		return nil
+	}
+	limitsByType := map[int]*int64{
+		resourceTypeInstance:       limits.Instances,
+		resourceTypeCPU:            limits.CPU,
+		resourceTypeMemory:         limits.MemoryMiB,
+		resourceTypePrimaryStorage: limits.PrimaryStorageGiB,
+		resourceTypeVolume:         limits.Volumes,
+		resourceTypePublicIP:       limits.PublicIPs,
+		resourceTypeNetwork:        limits.Networks,
+	}
+	for resourceType, limit := range limitsByType {
+		if limit == nil {
+			continue
+		}
+		p := c.cs.Limit.NewUpdateResourceLimitParams(resourceType)
+		p.SetMax(*limit)
+		scope(p)
+		if _, err := c.cs.Limit.UpdateResourceLimit(p); err != nil {
+			c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+
+			return errors.Wrapf(err, "updating resource limit of type %d", resourceType)
+		}
+	}
+
+	return nil
+}
+
+// generatePassword returns a random password for users CAPC creates. CAPC only ever authenticates with API keys.
+func generatePassword() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", errors.Wrap(err, "generating password")
+	}
+
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
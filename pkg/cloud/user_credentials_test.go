@@ -25,6 +25,7 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	infrav1 "sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
 	"sigs.k8s.io/cluster-api-provider-cloudstack/pkg/cloud"
 	dummies "sigs.k8s.io/cluster-api-provider-cloudstack/test/dummies/v1beta3"
 	"sigs.k8s.io/cluster-api-provider-cloudstack/test/helpers"
@@ -431,6 +432,194 @@ var _ = Describe("User Credentials", func() {
 		})
 	})
 
+	Context("Managed projects", func() {
+		var (
+			ps *csapi.MockProjectServiceIface
+			rs *csapi.MockResourcetagsServiceIface
+		)
+
+		BeforeEach(func() {
+			ps = mockClient.Project.(*csapi.MockProjectServiceIface)
+			rs = mockClient.Resourcetags.(*csapi.MockResourcetagsServiceIface)
+		})
+
+		It("uses an existing project", func() {
+			ps.EXPECT().GetProjectByName("capc-project", gomock.Any()).Return(&csapi.Project{Id: "project-id"}, 1, nil)
+
+			project := &cloud.Project{Name: "capc-project"}
+			Ω(client.GetOrCreateProject(project, nil)).Should(Succeed())
+			Ω(project.ID).Should(Equal("project-id"))
+		})
+
+		It("doesn't create a project when the lookup fails", func() {
+			ps.EXPECT().GetProjectByName("capc-project", gomock.Any()).Return(nil, -1, fakeError)
+
+			Ω(client.GetOrCreateProject(&cloud.Project{Name: "capc-project"}, nil)).
+				Should(MatchError(ContainSubstring("getting project capc-project")))
+		})
+
+		It("creates a missing project, tags it and applies its limits", func() {
+			ls := mockClient.Limit.(*csapi.MockLimitServiceIface)
+			cpp := &csapi.CreateProjectParams{}
+			ulp := &csapi.UpdateResourceLimitParams{}
+			instances := int64(10)
+			ps.EXPECT().GetProjectByName("capc-project", gomock.Any()).
+				Return(nil, 0, errors.New("No match found for capc-project"))
+			ps.EXPECT().NewCreateProjectParams("capc-project", "capc-project").Return(cpp)
+			ps.EXPECT().CreateProject(cpp).Return(&csapi.CreateProjectResponse{Id: "project-id"}, nil)
+			rs.EXPECT().NewCreateTagsParams([]string{"project-id"}, string(cloud.ResourceTypeProject),
+				map[string]string{cloud.CreatedByCAPCTagName: "1"}).Return(&csapi.CreateTagsParams{})
+			rs.EXPECT().CreateTags(gomock.Any()).Return(&csapi.CreateTagsResponse{}, nil)
+			ls.EXPECT().NewUpdateResourceLimitParams(0).Return(ulp)
+			ls.EXPECT().UpdateResourceLimit(ulp).Return(&csapi.UpdateResourceLimitResponse{}, nil)
+
+			project := &cloud.Project{Name: "capc-project"}
+			Ω(client.GetOrCreateProject(project, &infrav1.CloudStackResourceLimits{Instances: &instances})).Should(Succeed())
+			Ω(project.ID).Should(Equal("project-id"))
+		})
+
+		It("surfaces project creation errors", func() {
+			cpp := &csapi.CreateProjectParams{}
+			ps.EXPECT().GetProjectByName("capc-project", gomock.Any()).Return(nil, 0, nil)
+			ps.EXPECT().NewCreateProjectParams("capc-project", "capc-project").Return(cpp)
+			ps.EXPECT().CreateProject(cpp).Return(nil, fakeError)
+
+			Ω(client.GetOrCreateProject(&cloud.Project{Name: "capc-project"}, nil)).
+				Should(MatchError(ContainSubstring(errorMessage)))
+		})
+
+		It("deletes a project CAPC created with cleanup", func() {
+			dpp := &csapi.DeleteProjectParams{}
+			ps.EXPECT().GetProjectID("capc-project", gomock.Any()).Return("project-id", 1, nil)
+			rs.EXPECT().NewListTagsParams().Return(&csapi.ListTagsParams{})
+			rs.EXPECT().ListTags(gomock.Any()).Return(&csapi.ListTagsResponse{
+				Count: 1, Tags: []*csapi.Tag{{Key: cloud.CreatedByCAPCTagName, Value: "1"}},
+			}, nil)
+			ps.EXPECT().NewDeleteProjectParams("project-id").Return(dpp)
+			ps.EXPECT().DeleteProject(dpp).Return(&csapi.DeleteProjectResponse{}, nil)
+
+			Ω(client.DeleteProject(&cloud.Project{Name: "capc-project"})).Should(Succeed())
+			cleanup, _ := dpp.GetCleanup()
+			Ω(cleanup).Should(BeTrue())
+		})
+
+		It("leaves a project CAPC didn't create alone", func() {
+			ps.EXPECT().GetProjectID("capc-project", gomock.Any()).Return("project-id", 1, nil)
+			rs.EXPECT().NewListTagsParams().Return(&csapi.ListTagsParams{})
+			rs.EXPECT().ListTags(gomock.Any()).Return(&csapi.ListTagsResponse{}, nil)
+
+			Ω(client.DeleteProject(&cloud.Project{Name: "capc-project"})).Should(Succeed())
+		})
+
+		It("doesn't fail deleting a project that is already gone", func() {
+			ps.EXPECT().GetProjectID("capc-project", gomock.Any()).Return("", 0, errors.New("No match found for capc-project"))
+
+			Ω(client.DeleteProject(&cloud.Project{Name: "capc-project"})).Should(Succeed())
+		})
+
+		It("doesn't take a failed lookup for a project that is gone", func() {
+			ps.EXPECT().GetProjectID("capc-project", gomock.Any()).Return("", -1, fakeError)
+
+			Ω(client.DeleteProject(&cloud.Project{Name: "capc-project"})).Should(MatchError(ContainSubstring(errorMessage)))
+		})
+	})
+
+	Context("Managed accounts", func() {
+		var rs *csapi.MockResourcetagsServiceIface
+
+		BeforeEach(func() {
+			rs = mockClient.Resourcetags.(*csapi.MockResourcetagsServiceIface)
+			ds.EXPECT().GetDomainByID(dummies.Domain.ID).AnyTimes().Return(&csapi.Domain{
+				Id: dummies.Domain.ID, Name: dummies.Domain.Name, Path: dummies.Domain.Path,
+			}, 1, nil)
+			ds.EXPECT().NewListDomainsParams().AnyTimes().Return(&csapi.ListDomainsParams{})
+			ds.EXPECT().ListDomains(gomock.Any()).AnyTimes().Return(&csapi.ListDomainsResponse{
+				Count: 1, Domains: []*csapi.Domain{{Id: dummies.Domain.ID, Name: dummies.Domain.Name, Path: dummies.Domain.Path}},
+			}, nil)
+			as.EXPECT().NewListAccountsParams().AnyTimes().Return(&csapi.ListAccountsParams{})
+		})
+
+		It("uses an existing account", func() {
+			as.EXPECT().ListAccounts(gomock.Any()).Return(&csapi.ListAccountsResponse{
+				Count: 1, Accounts: []*csapi.Account{{Id: "account-id", Name: "capc-account"}},
+			}, nil)
+
+			account := &cloud.Account{Name: "capc-account", Domain: cloud.Domain{ID: dummies.Domain.ID}}
+			Ω(client.GetOrCreateAccount(account, "", nil)).Should(Succeed())
+			Ω(account.ID).Should(Equal("account-id"))
+		})
+
+		It("doesn't create an account when the lookup fails", func() {
+			as.EXPECT().ListAccounts(gomock.Any()).Return(nil, fakeError)
+
+			account := &cloud.Account{Name: "capc-account", Domain: cloud.Domain{ID: dummies.Domain.ID}}
+			Ω(client.GetOrCreateAccount(account, "", nil)).Should(MatchError(errorMessage))
+		})
+
+		It("creates a missing account, tags it and registers API keys for its user", func() {
+			acp := &csapi.CreateAccountParams{}
+			rukp := &csapi.RegisterUserKeysParams{}
+			as.EXPECT().ListAccounts(gomock.Any()).Return(&csapi.ListAccountsResponse{Count: 0}, nil)
+			as.EXPECT().NewCreateAccountParams("capc-account@capc.invalid", "CAPC", "capc-account", gomock.Any(), "capc-account").
+				Return(acp)
+			as.EXPECT().CreateAccount(acp).Return(&csapi.CreateAccountResponse{
+				Id: "account-id", User: []csapi.CreateAccountResponseUser{{Id: "user-id"}},
+			}, nil)
+			rs.EXPECT().NewCreateTagsParams([]string{"account-id"}, string(cloud.ResourceTypeAccount),
+				map[string]string{cloud.CreatedByCAPCTagName: "1"}).Return(&csapi.CreateTagsParams{})
+			rs.EXPECT().CreateTags(gomock.Any()).Return(&csapi.CreateTagsResponse{}, nil)
+			us.EXPECT().NewRegisterUserKeysParams("user-id").Return(rukp)
+			us.EXPECT().RegisterUserKeys(rukp).Return(&csapi.RegisterUserKeysResponse{}, nil)
+
+			account := &cloud.Account{Name: "capc-account", Domain: cloud.Domain{ID: dummies.Domain.ID}}
+			Ω(client.GetOrCreateAccount(account, "role-id", nil)).Should(Succeed())
+			Ω(account.ID).Should(Equal("account-id"))
+			roleID, _ := acp.GetRoleid()
+			Ω(roleID).Should(Equal("role-id"))
+		})
+
+		It("deletes an account CAPC created", func() {
+			as.EXPECT().ListAccounts(gomock.Any()).Return(&csapi.ListAccountsResponse{
+				Count: 1, Accounts: []*csapi.Account{{Id: "account-id", Name: "capc-account"}},
+			}, nil)
+			rs.EXPECT().NewListTagsParams().Return(&csapi.ListTagsParams{})
+			rs.EXPECT().ListTags(gomock.Any()).Return(&csapi.ListTagsResponse{
+				Count: 1, Tags: []*csapi.Tag{{Key: cloud.CreatedByCAPCTagName, Value: "1"}},
+			}, nil)
+			dap := &csapi.DeleteAccountParams{}
+			as.EXPECT().NewDeleteAccountParams("account-id").Return(dap)
+			as.EXPECT().DeleteAccount(dap).Return(&csapi.DeleteAccountResponse{}, nil)
+
+			Ω(client.DeleteAccount(&cloud.Account{Name: "capc-account", Domain: cloud.Domain{ID: dummies.Domain.ID}})).
+				Should(Succeed())
+		})
+
+		It("leaves an account CAPC didn't create alone", func() {
+			as.EXPECT().ListAccounts(gomock.Any()).Return(&csapi.ListAccountsResponse{
+				Count: 1, Accounts: []*csapi.Account{{Id: "account-id", Name: "capc-account"}},
+			}, nil)
+			rs.EXPECT().NewListTagsParams().Return(&csapi.ListTagsParams{})
+			rs.EXPECT().ListTags(gomock.Any()).Return(&csapi.ListTagsResponse{}, nil)
+
+			Ω(client.DeleteAccount(&cloud.Account{Name: "capc-account", Domain: cloud.Domain{ID: dummies.Domain.ID}})).
+				Should(Succeed())
+		})
+
+		It("doesn't fail deleting an account that is already gone", func() {
+			as.EXPECT().ListAccounts(gomock.Any()).Return(&csapi.ListAccountsResponse{Count: 0}, nil)
+
+			Ω(client.DeleteAccount(&cloud.Account{Name: "capc-account", Domain: cloud.Domain{ID: dummies.Domain.ID}})).
+				Should(Succeed())
+		})
+
+		It("doesn't take a failed lookup for an account that is gone", func() {
+			as.EXPECT().ListAccounts(gomock.Any()).Return(nil, fakeError)
+
+			Ω(client.DeleteAccount(&cloud.Account{Name: "capc-account", Domain: cloud.Domain{ID: dummies.Domain.ID}})).
+				Should(MatchError(ContainSubstring(errorMessage)))
+		})
+	})
+
 	Context("UserCred Integ Tests", Label("integ"), func() {
 		var domain cloud.Domain
 		var account cloud.Account
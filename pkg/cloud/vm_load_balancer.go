@@ -0,0 +1,332 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloud
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
+)
+
+// maxAPIServerVMLoadBalancerReplicas is the most load balancer VMs a cluster can have, which is also how many are looked
+// for on deletion.
+const maxAPIServerVMLoadBalancerReplicas = 2
+
+type VMLoadBalancerIface interface {
+	ReconcileAPIServerVMLoadBalancer(fd *infrav1.CloudStackFailureDomain, csCluster *infrav1.CloudStackCluster, backends []string) error
+	DeleteAPIServerVMLoadBalancer(csCluster *infrav1.CloudStackCluster) error
+}
+
+// apiServerVMLoadBalancerMachine describes a load balancer VM of a cluster as a CloudStackMachine, so that it's
+// deployed, looked up and destroyed the same way as the cluster's machines.
+func apiServerVMLoadBalancerMachine(csCluster *infrav1.CloudStackCluster, idx int) *infrav1.CloudStackMachine {
+	machine := &infrav1.CloudStackMachine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-apiserver-lb-%d", csCluster.Name, idx),
+			Namespace: csCluster.Namespace,
+		},
+		// The sync script on the VMs reads the haproxy config straight from the user data.
+		Spec: infrav1.CloudStackMachineSpec{UncompressedUserData: ptr.To(true)},
+	}
+	if vmLB := csCluster.Spec.APIServerVMLoadBalancer; vmLB != nil {
+		machine.Spec.Offering = vmLB.Offering
+		machine.Spec.Template = vmLB.Template
+		machine.Spec.SSHKey = vmLB.SSHKey
+	}
+	if status := csCluster.Status.APIServerVMLoadBalancer; status != nil && idx < len(status.InstanceIDs) &&
+		status.InstanceIDs[idx] != "" {
+		machine.Spec.InstanceID = ptr.To(status.InstanceIDs[idx])
+	}
+
+	return machine
+}
+
+// ReconcileAPIServerVMLoadBalancer deploys the cluster's HAProxy load balancer VMs in the shared network of the failure
+// domain, and points them at the backends. VMs that are already running pick up changed backends from their user data.
+func (c *client) ReconcileAPIServerVMLoadBalancer(
+	fd *infrav1.CloudStackFailureDomain,
+	csCluster *infrav1.CloudStackCluster,
+	backends []string,
+) error {
+	if csCluster.Status.APIServerVMLoadBalancer == nil {
+		csCluster.Status.APIServerVMLoadBalancer = &infrav1.APIServerVMLoadBalancerStatus{}
+	}
+	status := csCluster.Status.APIServerVMLoadBalancer
+	status.FailureDomainName = fd.Spec.Name
+	backends = slices.Clone(backends)
+	slices.Sort(backends)
+	backendsChanged := !slices.Equal(status.Backends, backends)
+
+	replicas := csCluster.Spec.APIServerVMLoadBalancer.ReplicasOrDefault()
+	status.InstanceIDs = resizeStrings(status.InstanceIDs, replicas)
+	status.IPAddresses = resizeStrings(status.IPAddresses, replicas)
+	ready := true
+	for idx := 0; idx < replicas; idx++ {
+		userData, err := apiServerVMLoadBalancerUserData(csCluster, idx, backends)
+		if err != nil {
+			return err
+		}
+		machine := apiServerVMLoadBalancerMachine(csCluster, idx)
+		deployed := machine.Spec.InstanceID != nil
+		err = c.GetOrCreateVMInstance(machine, &clusterv1.Machine{ObjectMeta: machine.ObjectMeta}, fd, nil, userData)
+		// An incomplete deployment still yields an instance, which is recorded so that it gets cleaned up.
+		if machine.Spec.InstanceID != nil {
+			status.InstanceIDs[idx] = *machine.Spec.InstanceID
+		}
+		if err != nil {
+			return errors.Wrapf(err, "deploying load balancer VM %s", machine.Name)
+		}
+		if len(machine.Status.Addresses) > 0 {
+			status.IPAddresses[idx] = machine.Status.Addresses[0].Address
+		}
+		if deployed && backendsChanged {
+			if err := c.updateUserData(status.InstanceIDs[idx], userData); err != nil {
+				return err
+			}
+		}
+		ready = ready && machine.Status.InstanceState == "Running"
+	}
+	status.Backends = backends
+	status.Ready = ready
+
+	return nil
+}
+
+// updateUserData replaces the user data of a VM. A running VM sees the new user data on the data server of its network
+// straight away.
+func (c *client) updateUserData(instanceID string, userData string) error {
+	p := c.cs.VirtualMachine.NewUpdateVirtualMachineParams(instanceID)
+	p.SetUserdata(base64.StdEncoding.EncodeToString([]byte(userData)))
+	if _, err := c.cs.VirtualMachine.UpdateVirtualMachine(p); err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+
+		return errors.Wrapf(err, "updating user data of VM with ID %s", instanceID)
+	}
+
+	return nil
+}
+
+// DeleteAPIServerVMLoadBalancer destroys the cluster's load balancer VMs. The status is cleared once they're all gone.
+func (c *client) DeleteAPIServerVMLoadBalancer(csCluster *infrav1.CloudStackCluster) error {
+	if csCluster.Status.APIServerVMLoadBalancer == nil {
+		return nil
+	}
+	for idx := 0; idx < maxAPIServerVMLoadBalancerReplicas; idx++ {
+		machine := apiServerVMLoadBalancerMachine(csCluster, idx)
+		if machine.Spec.InstanceID == nil {
+			// The VM may have been deployed without its ID being recorded.
+			if err := c.ResolveVMInstanceDetails(machine); err != nil {
+				if !strings.Contains(strings.ToLower(err.Error()), "no match") {
+					return err
+				}
+
+				continue
+			}
+		}
+		if err := c.DestroyVMInstance(machine); err != nil {
+			return errors.Wrapf(err, "destroying load balancer VM %s", machine.Name)
+		}
+	}
+	csCluster.Status.APIServerVMLoadBalancer = nil
+
+	return nil
+}
+
+// resizeStrings returns the slice with exactly n elements, padding it with empty strings.
+func resizeStrings(s []string, n int) []string {
+	if len(s) >= n {
+		return s[:n]
+	}
+
+	return append(s, make([]string, n-len(s))...)
+}
+
+var apiServerVMLoadBalancerUserDataTemplate = template.Must(template.New("userdata").Parse(`#cloud-config
+write_files:
+- path: /etc/haproxy/haproxy.cfg
+  encoding: b64
+  content: {{ .HAProxyConfig }}
+{{- if .KeepalivedConfig }}
+- path: /etc/keepalived/keepalived.conf
+  encoding: b64
+  content: {{ .KeepalivedConfig }}
+{{- end }}
+- path: /usr/local/bin/haproxy-config-sync
+  permissions: "0755"
+  content: |
+    #!/bin/sh
+    # Applies the haproxy config of the current user data, which CAPC updates as control plane machines come and go.
+    set -e
+    curl -sf http://data-server./latest/user-data |
+      awk '/path: \/etc\/haproxy\/haproxy.cfg/ { found = 1 } found && /content:/ { print $2; exit }' |
+      base64 -d > /etc/haproxy/haproxy.cfg.new
+    if ! cmp -s /etc/haproxy/haproxy.cfg.new /etc/haproxy/haproxy.cfg && haproxy -c -q -f /etc/haproxy/haproxy.cfg.new; then
+      mv /etc/haproxy/haproxy.cfg.new /etc/haproxy/haproxy.cfg
+      systemctl reload haproxy
+    fi
+- path: /etc/systemd/system/haproxy-config-sync.service
+  content: |
+    [Unit]
+    Description=Apply the haproxy config of the current user data
+    [Service]
+    Type=oneshot
+    ExecStart=/usr/local/bin/haproxy-config-sync
+- path: /etc/systemd/system/haproxy-config-sync.timer
+  content: |
+    [Unit]
+    Description=Periodically apply the haproxy config of the current user data
+    [Timer]
+    OnBootSec=30s
+    OnUnitActiveSec=30s
+    [Install]
+    WantedBy=timers.target
+runcmd:
+{{- if .KeepalivedConfig }}
+- sed -i "s/__INTERFACE__/$(ip route show default | awk '{ print $5; exit }')/" /etc/keepalived/keepalived.conf
+- systemctl enable --now keepalived
+{{- end }}
+- systemctl enable haproxy
+- systemctl restart haproxy
+- systemctl daemon-reload
+- systemctl enable --now haproxy-config-sync.timer
+`))
+
+var haproxyConfigTemplate = template.Must(template.New("haproxy").Parse(`global
+  log /dev/log local0
+  maxconn 4096
+
+defaults
+  mode tcp
+  log global
+  option tcplog
+  timeout connect 10s
+  timeout client 1h
+  timeout server 1h
+
+frontend kube-apiserver
+  bind :{{ .Port }}
+  default_backend kube-apiserver
+
+backend kube-apiserver
+  option httpchk GET /readyz
+  http-check expect status 200
+  balance roundrobin
+{{- range $idx, $backend := .Backends }}
+  server control-plane-{{ $idx }} {{ $backend }}:{{ $.BackendPort }} check check-ssl verify none
+{{- end }}
+`))
+
+var keepalivedConfigTemplate = template.Must(template.New("keepalived").Parse(`vrrp_script haproxy {
+  script "pidof haproxy"
+  interval 2
+}
+
+vrrp_instance kube-apiserver {
+  state {{ .State }}
+  interface __INTERFACE__
+  virtual_router_id {{ .VirtualRouterID }}
+  priority {{ .Priority }}
+  authentication {
+    auth_type PASS
+    auth_pass {{ .AuthPass }}
+  }
+  virtual_ipaddress {
+    {{ .VirtualIP }}
+  }
+  track_script {
+    haproxy
+  }
+}
+`))
+
+// apiServerVMLoadBalancerUserData renders the cloud-config of a load balancer VM. HAProxy forwards the control plane
+// endpoint port to the API servers of the backends, and keepalived holds the virtual IP on one of the VMs.
+func apiServerVMLoadBalancerUserData(csCluster *infrav1.CloudStackCluster, idx int, backends []string) (string, error) {
+	vmLB := csCluster.Spec.APIServerVMLoadBalancer
+	port := int(csCluster.Spec.ControlPlaneEndpoint.Port)
+	if port == 0 {
+		port = K8sDefaultAPIPort
+	}
+	haproxyConfig := &bytes.Buffer{}
+	if err := haproxyConfigTemplate.Execute(haproxyConfig, map[string]interface{}{
+		"Port":        port,
+		"BackendPort": K8sDefaultAPIPort,
+		"Backends":    backends,
+	}); err != nil {
+		return "", errors.Wrap(err, "rendering haproxy config")
+	}
+
+	keepalivedConfig := &bytes.Buffer{}
+	if vmLB.VirtualIP != "" {
+		// The virtual router ID has to be unique among the clusters sharing the network.
+		routerID := fnv.New32a()
+		_, _ = routerID.Write([]byte(csCluster.UID))
+		state := "MASTER"
+		if idx > 0 {
+			state = "BACKUP"
+		}
+		if err := keepalivedConfigTemplate.Execute(keepalivedConfig, map[string]interface{}{
+			"State":           state,
+			"VirtualRouterID": routerID.Sum32()%255 + 1,
+			"Priority":        150 - idx*50,
+			"AuthPass":        keepalivedAuthPass(csCluster),
+			"VirtualIP":       vmLB.VirtualIP,
+		}); err != nil {
+			return "", errors.Wrap(err, "rendering keepalived config")
+		}
+	}
+
+	userData := &bytes.Buffer{}
+	if err := apiServerVMLoadBalancerUserDataTemplate.Execute(userData, map[string]interface{}{
+		"HAProxyConfig":    base64.StdEncoding.EncodeToString(haproxyConfig.Bytes()),
+		"KeepalivedConfig": encodeIfNotEmpty(keepalivedConfig.Bytes()),
+	}); err != nil {
+		return "", errors.Wrap(err, "rendering load balancer user data")
+	}
+
+	return userData.String(), nil
+}
+
+// keepalivedAuthPass derives the VRRP password of a cluster's load balancer VMs from the cluster UID. keepalived only
+// uses the first eight characters.
+func keepalivedAuthPass(csCluster *infrav1.CloudStackCluster) string {
+	pass := strings.ReplaceAll(string(csCluster.UID), "-", "")
+	if len(pass) > 8 {
+		pass = pass[:8]
+	}
+
+	return pass
+}
+
+func encodeIfNotEmpty(content []byte) string {
+	if len(content) == 0 {
+		return ""
+	}
+
+	return base64.StdEncoding.EncodeToString(content)
+}
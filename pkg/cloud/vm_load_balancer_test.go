@@ -0,0 +1,142 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloud_test
+
+import (
+	"encoding/base64"
+	"errors"
+	"regexp"
+
+	csapi "github.com/apache/cloudstack-go/v2/cloudstack"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
+	"sigs.k8s.io/cluster-api-provider-cloudstack/pkg/cloud"
+	dummies "sigs.k8s.io/cluster-api-provider-cloudstack/test/dummies/v1beta3"
+)
+
+// fileFromUserData extracts a b64 encoded file that the load balancer VMs write from their user data.
+func fileFromUserData(encodedUserData, path string) string {
+	userData, err := base64.StdEncoding.DecodeString(encodedUserData)
+	Ω(err).ShouldNot(HaveOccurred())
+	match := regexp.MustCompile(`path: ` + regexp.QuoteMeta(path) + `\s+encoding: b64\s+content: (\S+)`).FindSubmatch(userData)
+	Ω(match).Should(HaveLen(2))
+	config, err := base64.StdEncoding.DecodeString(string(match[1]))
+	Ω(err).ShouldNot(HaveOccurred())
+
+	return string(config)
+}
+
+var _ = Describe("API server VM load balancer", func() {
+	var (
+		mockCtrl   *gomock.Controller
+		mockClient *csapi.CloudStackClient
+		vms        *csapi.MockVirtualMachineServiceIface
+		client     cloud.Client
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockClient = csapi.NewMockClient(mockCtrl)
+		vms = mockClient.VirtualMachine.(*csapi.MockVirtualMachineServiceIface)
+		client = cloud.NewClientFromCSAPIClient(mockClient, nil)
+		dummies.SetDummyVars()
+		dummies.CSCluster.Spec.APIServerLoadBalancer = nil
+		dummies.CSCluster.Spec.APIServerVMLoadBalancer = dummies.APIServerVMLoadBalancer
+		dummies.CSCluster.Status.APIServerVMLoadBalancer = &infrav1.APIServerVMLoadBalancerStatus{
+			InstanceIDs: []string{"lb-0", "lb-1"},
+			Backends:    []string{"10.0.0.20"},
+		}
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	Context("Reconciling the load balancer VMs", func() {
+		It("hands changed backends to the running VMs through their user data", func() {
+			for idx, id := range []string{"lb-0", "lb-1"} {
+				vms.EXPECT().GetVirtualMachinesMetricByID(id, gomock.Any()).Return(&csapi.VirtualMachinesMetric{
+					Id: id, Ipaddress: []string{"10.0.0.5", "10.0.0.6"}[idx], State: "Running",
+				}, 1, nil)
+				vms.EXPECT().NewUpdateVirtualMachineParams(id).Return(&csapi.UpdateVirtualMachineParams{})
+				vms.EXPECT().UpdateVirtualMachine(gomock.Any()).DoAndReturn(
+					func(p *csapi.UpdateVirtualMachineParams) (*csapi.UpdateVirtualMachineResponse, error) {
+						userData, _ := p.GetUserdata()
+						config := fileFromUserData(userData, "/etc/haproxy/haproxy.cfg")
+						Ω(config).Should(ContainSubstring("server control-plane-0 10.0.0.20:6443"))
+						Ω(config).Should(ContainSubstring("server control-plane-1 10.0.0.21:6443"))
+						// psmisc, which ships killall, isn't part of every load balancer template.
+						Ω(fileFromUserData(userData, "/etc/keepalived/keepalived.conf")).Should(ContainSubstring(`script "pidof haproxy"`))
+
+						return &csapi.UpdateVirtualMachineResponse{}, nil
+					})
+			}
+
+			Ω(client.ReconcileAPIServerVMLoadBalancer(dummies.CSFailureDomain1, dummies.CSCluster,
+				[]string{"10.0.0.21", "10.0.0.20"})).Should(Succeed())
+			Ω(*dummies.CSCluster.Status.APIServerVMLoadBalancer).Should(Equal(infrav1.APIServerVMLoadBalancerStatus{
+				FailureDomainName: dummies.CSFailureDomain1.Spec.Name,
+				InstanceIDs:       []string{"lb-0", "lb-1"},
+				IPAddresses:       []string{"10.0.0.5", "10.0.0.6"},
+				Backends:          []string{"10.0.0.20", "10.0.0.21"},
+				Ready:             true,
+			}))
+		})
+
+		It("leaves the user data alone when the backends haven't changed", func() {
+			vms.EXPECT().GetVirtualMachinesMetricByID("lb-0", gomock.Any()).Return(&csapi.VirtualMachinesMetric{
+				Id: "lb-0", Ipaddress: "10.0.0.5", State: "Running",
+			}, 1, nil)
+			vms.EXPECT().GetVirtualMachinesMetricByID("lb-1", gomock.Any()).Return(&csapi.VirtualMachinesMetric{
+				Id: "lb-1", Ipaddress: "10.0.0.6", State: "Starting",
+			}, 1, nil)
+
+			Ω(client.ReconcileAPIServerVMLoadBalancer(dummies.CSFailureDomain1, dummies.CSCluster,
+				[]string{"10.0.0.20"})).Should(Succeed())
+			Ω(dummies.CSCluster.Status.APIServerVMLoadBalancer.Ready).Should(BeFalse())
+		})
+
+		It("returns an error when the user data can't be updated", func() {
+			dummies.CSCluster.Spec.APIServerVMLoadBalancer.Replicas = 1
+			vms.EXPECT().GetVirtualMachinesMetricByID("lb-0", gomock.Any()).Return(&csapi.VirtualMachinesMetric{
+				Id: "lb-0", Ipaddress: "10.0.0.5", State: "Running",
+			}, 1, nil)
+			vms.EXPECT().NewUpdateVirtualMachineParams("lb-0").Return(&csapi.UpdateVirtualMachineParams{})
+			vms.EXPECT().UpdateVirtualMachine(gomock.Any()).Return(nil, errors.New("api down"))
+
+			Ω(client.ReconcileAPIServerVMLoadBalancer(dummies.CSFailureDomain1, dummies.CSCluster, nil)).
+				Should(MatchError(ContainSubstring("updating user data of VM with ID lb-0")))
+			Ω(dummies.CSCluster.Status.APIServerVMLoadBalancer.Backends).Should(Equal([]string{"10.0.0.20"}))
+		})
+	})
+
+	Context("Deleting the load balancer VMs", func() {
+		It("clears the status when the load balancer VMs were never deployed", func() {
+			dummies.CSCluster.Status.APIServerVMLoadBalancer = &infrav1.APIServerVMLoadBalancerStatus{}
+			for _, name := range []string{"-apiserver-lb-0", "-apiserver-lb-1"} {
+				vms.EXPECT().GetVirtualMachinesMetricByName(dummies.CSCluster.Name+name, gomock.Any()).
+					Return(nil, -1, errors.New("No match found for "+dummies.CSCluster.Name+name))
+			}
+
+			Ω(client.DeleteAPIServerVMLoadBalancer(dummies.CSCluster)).Should(Succeed())
+			Ω(dummies.CSCluster.Status.APIServerVMLoadBalancer).Should(BeNil())
+		})
+	})
+})
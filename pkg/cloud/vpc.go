@@ -0,0 +1,589 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloud
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/apache/cloudstack-go/v2/cloudstack"
+	"github.com/pkg/errors"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
+	capcstrings "sigs.k8s.io/cluster-api-provider-cloudstack/pkg/utils/strings"
+)
+
+type VPCIface interface {
+	GetOrCreateVPC(fd *infrav1.CloudStackFailureDomain, vpc *infrav1.CloudStackVPC) error
+	GetOrCreateVPCTier(fd *infrav1.CloudStackFailureDomain, vpc *infrav1.CloudStackVPC, tier infrav1.VPCTierSpec, status *infrav1.VPCTierStatus) error
+	AssociateVPCPublicIPAddress(fd *infrav1.CloudStackFailureDomain, vpc *infrav1.CloudStackVPC, desiredIP string) (*cloudstack.PublicIpAddress, error)
+	ReconcileVPCLoadBalancerRules(vpc *infrav1.CloudStackVPC, csCluster *infrav1.CloudStackCluster) error
+	AssignVMToVPCLoadBalancerRules(vpc *infrav1.CloudStackVPC, instanceID string) error
+	ReleaseVPCPublicIPAddress(vpc *infrav1.CloudStackVPC, csCluster *infrav1.CloudStackCluster) error
+	DisposeVPCResources(vpc *infrav1.CloudStackVPC, csCluster *infrav1.CloudStackCluster) error
+}
+
+// GetOrCreateVPC resolves the VPC by ID, or else by name, and creates it if there's no VPC with the name.
+func (c *client) GetOrCreateVPC(fd *infrav1.CloudStackFailureDomain, vpc *infrav1.CloudStackVPC) error {
+	if vpc.Spec.ID != "" {
+		vpcDetails, count, err := c.cs.VPC.GetVPCByID(vpc.Spec.ID, cloudstack.WithProject(c.user.Project.ID))
+		if err != nil {
+			c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+
+			return errors.Wrapf(err, "getting VPC with ID %s", vpc.Spec.ID)
+		} else if count != 1 {
+			return errors.Errorf("expected 1 VPC with ID %s, but got %d", vpc.Spec.ID, count)
+		}
+		vpc.Spec.Name = vpcDetails.Name
+		vpc.Spec.CIDR = vpcDetails.Cidr
+
+		return nil
+	}
+
+	vpcDetails, count, err := c.cs.VPC.GetVPCByName(vpc.Spec.Name, cloudstack.WithProject(c.user.Project.ID))
+	if err == nil {
+		vpc.Spec.ID = vpcDetails.Id
+		vpc.Spec.CIDR = vpcDetails.Cidr
+
+		return nil
+	} else if count > 1 || !strings.Contains(strings.ToLower(err.Error()), "no match found") {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+
+		return errors.Wrapf(err, "getting VPC with name %s", vpc.Spec.Name)
+	}
+
+	return c.createVPC(fd, vpc)
+}
+
+// createVPC creates a VPC with the offering and CIDR of the spec.
+func (c *client) createVPC(fd *infrav1.CloudStackFailureDomain, vpc *infrav1.CloudStackVPC) error {
+	// The VPC takes up a public IP for its source NAT.
+	if err := c.checkResourceLimit(LimitResourcePublicIP, c.ipAvailability(), 1); err != nil {
+		return err
+	}
+	offeringID, count, err := c.cs.VPC.GetVPCOfferingID(vpc.Spec.Offering)
+	if err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+
+		return errors.Wrapf(err, "getting VPC offering %s", vpc.Spec.Offering)
+	} else if count != 1 {
+		return errors.Errorf("expected 1 VPC offering with name %s, but got %d", vpc.Spec.Offering, count)
+	}
+
+	p := c.cs.VPC.NewCreateVPCParams(vpc.Spec.CIDR, vpc.Spec.Name, vpc.Spec.Name, offeringID, fd.Spec.Zone.ID)
+	setIfNotEmpty(c.user.Project.ID, p.SetProjectid)
+	resp, err := c.cs.VPC.CreateVPC(p)
+	if err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+
+		return errors.Wrapf(err, "creating VPC with name %s", vpc.Spec.Name)
+	}
+	vpc.Spec.ID = resp.Id
+	vpc.Spec.CIDR = resp.Cidr
+
+	return c.AddCreatedByCAPCTag(ResourceTypeVPC, vpc.Spec.ID)
+}
+
+// GetOrCreateVPCTier makes sure the tier exists in the VPC, and that its network ACL list holds the rules of the spec.
+// The tier and ACL list IDs are recorded in status.
+func (c *client) GetOrCreateVPCTier(
+	fd *infrav1.CloudStackFailureDomain,
+	vpc *infrav1.CloudStackVPC,
+	tier infrav1.VPCTierSpec,
+	status *infrav1.VPCTierStatus,
+) error {
+	aclID, err := c.getOrCreateNetworkACLList(vpc, tier.Name+"-acl")
+	if err != nil {
+		return err
+	}
+	status.ACLID = aclID
+	if err := c.reconcileNetworkACLRules(aclID, tier.ACL); err != nil {
+		return errors.Wrapf(err, "reconciling network ACL of tier %s", tier.Name)
+	}
+
+	netDetails, count, err := c.cs.Network.GetNetworkByName(tier.Name,
+		cloudstack.WithProject(c.user.Project.ID), cloudstack.WithVPCID(vpc.Spec.ID))
+	if err != nil && (count > 1 || !strings.Contains(strings.ToLower(err.Error()), "no match found")) {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+
+		return errors.Wrapf(err, "getting tier with name %s", tier.Name)
+	} else if err != nil {
+		status.ID, err = c.createVPCTier(fd, vpc, tier, aclID)
+
+		return err
+	}
+	status.ID = netDetails.Id
+
+	// Adopted tiers are moved onto the managed ACL list.
+	if netDetails.Aclid != aclID {
+		p := c.cs.NetworkACL.NewReplaceNetworkACLListParams(aclID)
+		p.SetNetworkid(netDetails.Id)
+		if _, err := c.cs.NetworkACL.ReplaceNetworkACLList(p); err != nil {
+			c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+
+			return errors.Wrapf(err, "replacing network ACL list of tier %s", tier.Name)
+		}
+	}
+
+	return nil
+}
+
+// ControlPlaneTierACL returns the network ACL rules of the control plane tier when its spec lists none. Traffic from
+// within the VPC is allowed, while traffic from outside only reaches the API server ports, and only from the allowed
+// CIDRs of the API server load balancer.
+func ControlPlaneTierACL(vpc *infrav1.CloudStackVPC, csCluster *infrav1.CloudStackCluster) []infrav1.NetworkACLRule {
+	port := int(csCluster.Spec.ControlPlaneEndpoint.Port)
+	if port == 0 {
+		port = K8sDefaultAPIPort
+	}
+	ports := []int{port}
+	if csCluster.Spec.APIServerLoadBalancer != nil {
+		ports = append(ports, csCluster.Spec.APIServerLoadBalancer.AdditionalPorts...)
+	}
+	publicIP := ""
+	if vpc.Status.APIServerLoadBalancer != nil {
+		publicIP = vpc.Status.APIServerLoadBalancer.IPAddress
+	}
+
+	rules := []infrav1.NetworkACLRule{{Protocol: "all", CIDRs: []string{vpc.Spec.CIDR}, TrafficType: infrav1.NetworkACLTrafficTypeIngress}}
+	// Without any valid allowed CIDR, the API server ports stay closed to the outside rather than falling back to
+	// the 0.0.0.0/0 default of the rules.
+	if cidrs := getCanonicalAllowedCIDRs(publicIP, csCluster); len(cidrs) > 0 {
+		for _, port := range ports {
+			rules = append(rules, infrav1.NetworkACLRule{
+				Protocol: NetworkProtocolTCP, CIDRs: cidrs, StartPort: port, TrafficType: infrav1.NetworkACLTrafficTypeIngress,
+			})
+		}
+	}
+
+	return append(rules, infrav1.NetworkACLRule{Protocol: "all", TrafficType: infrav1.NetworkACLTrafficTypeEgress})
+}
+
+// createVPCTier creates a tier network in the VPC and returns its ID.
+func (c *client) createVPCTier(fd *infrav1.CloudStackFailureDomain, vpc *infrav1.CloudStackVPC, tier infrav1.VPCTierSpec, aclID string) (string, error) {
+	if err := c.checkResourceLimit(LimitResourceNetwork, c.networkAvailability(), 1); err != nil {
+		return "", err
+	}
+	offeringID, count, err := c.cs.NetworkOffering.GetNetworkOfferingID(VPCTierNetOffering)
+	if err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+
+		return "", errors.Wrapf(err, "getting network offering %s", VPCTierNetOffering)
+	} else if count != 1 {
+		return "", errors.Errorf("expected 1 network offering with name %s, but got %d", VPCTierNetOffering, count)
+	}
+	m, err := parseCIDR(tier.CIDR)
+	if err != nil {
+		return "", errors.Wrap(err, "parsing CIDR")
+	}
+
+	p := c.cs.Network.NewCreateNetworkParams(tier.Name, offeringID, fd.Spec.Zone.ID)
+	p.SetDisplaytext(tier.Name)
+	p.SetVpcid(vpc.Spec.ID)
+	p.SetAclid(aclID)
+	p.SetGateway(m["gateway"])
+	p.SetNetmask(m["netmask"])
+	setIfNotEmpty(c.user.Project.ID, p.SetProjectid)
+	resp, err := c.cs.Network.CreateNetwork(p)
+	if err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+
+		return "", errors.Wrapf(err, "creating tier with name %s", tier.Name)
+	}
+
+	return resp.Id, c.AddCreatedByCAPCTag(ResourceTypeNetwork, resp.Id)
+}
+
+// getOrCreateNetworkACLList returns the ID of the VPC's network ACL list with the given name, creating it if needed.
+func (c *client) getOrCreateNetworkACLList(vpc *infrav1.CloudStackVPC, name string) (string, error) {
+	aclID, count, err := c.cs.NetworkACL.GetNetworkACLListID(name, cloudstack.WithVPCID(vpc.Spec.ID))
+	if err == nil {
+		return aclID, nil
+	} else if count > 1 || !strings.Contains(strings.ToLower(err.Error()), "no match found") {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+
+		return "", errors.Wrapf(err, "getting network ACL list with name %s", name)
+	}
+
+	p := c.cs.NetworkACL.NewCreateNetworkACLListParams(name, vpc.Spec.ID)
+	p.SetDescription(name)
+	resp, err := c.cs.NetworkACL.CreateNetworkACLList(p)
+	if err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+
+		return "", errors.Wrapf(err, "creating network ACL list with name %s", name)
+	}
+
+	return resp.Id, c.AddCreatedByCAPCTag(ResourceTypeNetworkACLList, resp.Id)
+}
+
+// reconcileNetworkACLRules makes the rules of a network ACL list match the given ones, numbered in order. Without
+// rules, all traffic is allowed, as the worker tier does by default.
+func (c *client) reconcileNetworkACLRules(aclID string, rules []infrav1.NetworkACLRule) error {
+	if len(rules) == 0 {
+		rules = []infrav1.NetworkACLRule{
+			{Protocol: "all", TrafficType: infrav1.NetworkACLTrafficTypeIngress},
+			{Protocol: "all", TrafficType: infrav1.NetworkACLTrafficTypeEgress},
+		}
+	}
+
+	p := c.cs.NetworkACL.NewListNetworkACLsParams()
+	p.SetAclid(aclID)
+	setIfNotEmpty(c.user.Project.ID, p.SetProjectid)
+	resp, err := c.cs.NetworkACL.ListNetworkACLs(p)
+	if err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+
+		return errors.Wrapf(err, "listing rules of network ACL list %s", aclID)
+	}
+	desired := map[string]bool{}
+	for idx, rule := range rules {
+		desired[networkACLRuleKey(idx+1, rule)] = true
+	}
+	// Rules that were changed or dropped from the spec are removed, as are duplicates.
+	existing := map[string]bool{}
+	for _, rule := range resp.NetworkACLs {
+		key := existingNetworkACLRuleKey(rule)
+		if desired[key] && !existing[key] {
+			existing[key] = true
+
+			continue
+		}
+		if err := c.deleteNetworkACLRule(rule.Id); err != nil {
+			return err
+		}
+	}
+	for idx, rule := range rules {
+		if !existing[networkACLRuleKey(idx+1, rule)] {
+			if err := c.createNetworkACLRule(aclID, idx+1, rule); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// createNetworkACLRule adds a rule to a network ACL list.
+func (c *client) createNetworkACLRule(aclID string, number int, rule infrav1.NetworkACLRule) error {
+	p := c.cs.NetworkACL.NewCreateNetworkACLParams(rule.Protocol)
+	p.SetAclid(aclID)
+	p.SetNumber(number)
+	p.SetCidrlist(networkACLRuleCIDRs(rule))
+	p.SetTraffictype(networkACLRuleTrafficType(rule))
+	p.SetAction(networkACLRuleAction(rule))
+	if start, end := networkACLRulePorts(rule); start != "" {
+		startPort, _ := strconv.Atoi(start)
+		endPort, _ := strconv.Atoi(end)
+		p.SetStartport(startPort)
+		p.SetEndport(endPort)
+	}
+	if rule.Protocol == NetworkProtocolICMP {
+		p.SetIcmptype(-1)
+		p.SetIcmpcode(-1)
+	}
+	if _, err := c.cs.NetworkACL.CreateNetworkACL(p); err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+
+		return errors.Wrapf(err, "creating rule %d of network ACL list %s", number, aclID)
+	}
+
+	return nil
+}
+
+// deleteNetworkACLRule removes a rule from a network ACL list.
+func (c *client) deleteNetworkACLRule(id string) error {
+	if _, err := c.cs.NetworkACL.DeleteNetworkACL(c.cs.NetworkACL.NewDeleteNetworkACLParams(id)); err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+
+		return errors.Wrapf(err, "deleting network ACL rule with ID %s", id)
+	}
+
+	return nil
+}
+
+// networkACLRuleKey identifies a desired rule by everything that makes it up, so that changed rules are replaced.
+func networkACLRuleKey(number int, rule infrav1.NetworkACLRule) string {
+	start, end := networkACLRulePorts(rule)
+
+	return fmt.Sprintf("%d/%s/%s/%s-%s/%s/%s", number, strings.ToLower(rule.Protocol), strings.Join(networkACLRuleCIDRs(rule), ","),
+		start, end, strings.ToLower(networkACLRuleTrafficType(rule)), strings.ToLower(networkACLRuleAction(rule)))
+}
+
+// existingNetworkACLRuleKey identifies a rule of a network ACL list like networkACLRuleKey does.
+func existingNetworkACLRuleKey(rule *cloudstack.NetworkACL) string {
+	return fmt.Sprintf("%d/%s/%s/%s-%s/%s/%s", rule.Number, strings.ToLower(rule.Protocol), strings.ReplaceAll(rule.Cidrlist, " ", ""),
+		rule.Startport, rule.Endport, strings.ToLower(rule.Traffictype), strings.ToLower(rule.Action))
+}
+
+func networkACLRuleCIDRs(rule infrav1.NetworkACLRule) []string {
+	if len(rule.CIDRs) == 0 {
+		return []string{"0.0.0.0/0"}
+	}
+
+	return rule.CIDRs
+}
+
+// networkACLRulePorts returns the port range of a rule as CloudStack reports it, empty if the rule has none.
+func networkACLRulePorts(rule infrav1.NetworkACLRule) (string, string) {
+	if rule.StartPort == 0 || (rule.Protocol != NetworkProtocolTCP && rule.Protocol != NetworkProtocolUDP) {
+		return "", ""
+	}
+	end := rule.EndPort
+	if end == 0 {
+		end = rule.StartPort
+	}
+
+	return strconv.Itoa(rule.StartPort), strconv.Itoa(end)
+}
+
+func networkACLRuleTrafficType(rule infrav1.NetworkACLRule) string {
+	if rule.TrafficType == "" {
+		return infrav1.NetworkACLTrafficTypeIngress
+	}
+
+	return rule.TrafficType
+}
+
+func networkACLRuleAction(rule infrav1.NetworkACLRule) string {
+	if rule.Action == "" {
+		return infrav1.NetworkACLActionAllow
+	}
+
+	return rule.Action
+}
+
+// AssociateVPCPublicIPAddress gets a public IP and associates it to the VPC. The VPC's source NAT address can't carry
+// load balancer rules, so this is always a separate address.
+func (c *client) AssociateVPCPublicIPAddress(
+	fd *infrav1.CloudStackFailureDomain,
+	vpc *infrav1.CloudStackVPC,
+	desiredIP string,
+) (*cloudstack.PublicIpAddress, error) {
+	publicAddress, err := c.GetPublicIP(fd, desiredIP)
+	if err != nil {
+		return nil, errors.Wrap(err, "fetching a public IP address")
+	}
+	if publicAddress.Vpcid == vpc.Spec.ID {
+		return publicAddress, nil
+	}
+
+	if err := c.checkResourceLimit(LimitResourcePublicIP, c.ipAvailability(), 1); err != nil {
+		return nil, err
+	}
+	p := c.cs.Address.NewAssociateIpAddressParams()
+	p.SetIpaddress(publicAddress.Ipaddress)
+	p.SetVpcid(vpc.Spec.ID)
+	setIfNotEmpty(c.user.Project.ID, p.SetProjectid)
+	if _, err := c.cs.Address.AssociateIpAddress(p); err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+
+		return nil, errors.Wrapf(err,
+			"associating public IP address with ID %s to VPC with ID %s",
+			publicAddress.Id, vpc.Spec.ID)
+	} else if err := c.AddCreatedByCAPCTag(ResourceTypeIPAddress, publicAddress.Id); err != nil {
+		return nil, errors.Wrapf(err,
+			"adding tag to public IP address with ID %s", publicAddress.Id)
+	}
+
+	return publicAddress, nil
+}
+
+// ReconcileVPCLoadBalancerRules manages the load balancer rules forwarding the API server ports of the VPC public IP
+// to the control plane tier. Access to them is governed by the control plane tier's network ACL.
+func (c *client) ReconcileVPCLoadBalancerRules(vpc *infrav1.CloudStackVPC, csCluster *infrav1.CloudStackCluster) error {
+	if vpc.Status.APIServerLoadBalancer == nil || vpc.Status.APIServerLoadBalancer.IPAddressID == "" {
+		return nil
+	}
+
+	p := c.cs.LoadBalancer.NewListLoadBalancerRulesParams()
+	p.SetPublicipid(vpc.Status.APIServerLoadBalancer.IPAddressID)
+	setIfNotEmpty(c.user.Project.ID, p.SetProjectid)
+	resp, err := c.cs.LoadBalancer.ListLoadBalancerRules(p)
+	if err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+
+		return errors.Wrap(err, "listing load balancer rules")
+	}
+	portsAndIDs := mapExistingLoadBalancerRules(resp.LoadBalancerRules)
+
+	if !csCluster.Spec.APIServerLoadBalancer.IsEnabled() {
+		if err := c.cleanupAllLoadBalancerRules(portsAndIDs); err != nil {
+			return err
+		}
+		vpc.Status.LoadBalancerRuleIDs = []string{}
+
+		return nil
+	}
+
+	ports := gatherPorts(csCluster)
+	lbRuleIDs := make([]string, 0, len(ports))
+	for _, port := range ports {
+		ruleID, found := portsAndIDs[strconv.Itoa(port)]
+		if !found {
+			if ruleID, err = c.createVPCLoadBalancerRule(vpc, port, csCluster.Spec.APIServerLoadBalancer.AlgorithmOrDefault()); err != nil {
+				return errors.Wrap(err, "creating load balancer rule")
+			}
+		}
+		lbRuleIDs = append(lbRuleIDs, ruleID)
+	}
+	if err := c.cleanupObsoleteLoadBalancerRules(portsAndIDs, ports); err != nil {
+		return err
+	}
+	if err := c.reconcileAPIServerLoadBalancerRuleSettings(resp.LoadBalancerRules, lbRuleIDs, csCluster.Spec.APIServerLoadBalancer, vpc.Status.APIServerLoadBalancer); err != nil {
+		return errors.Wrap(err, "reconciling load balancer rule settings")
+	}
+	vpc.Status.LoadBalancerRuleIDs = capcstrings.Canonicalize(lbRuleIDs)
+
+	return nil
+}
+
+// createVPCLoadBalancerRule forwards a port of the VPC public IP to the same port of the control plane tier machines.
+func (c *client) createVPCLoadBalancerRule(vpc *infrav1.CloudStackVPC, port int, algorithm string) (string, error) {
+	p := c.cs.LoadBalancer.NewCreateLoadBalancerRuleParams(algorithm, fmt.Sprintf("K8s_API_%d", port), port, port)
+	p.SetPublicport(port)
+	p.SetNetworkid(vpc.Status.ControlPlaneTier.ID)
+	p.SetPublicipid(vpc.Status.APIServerLoadBalancer.IPAddressID)
+	p.SetProtocol(NetworkProtocolTCP)
+	resp, err := c.cs.LoadBalancer.CreateLoadBalancerRule(p)
+	if err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+
+		return "", err
+	}
+	if err := c.AddCreatedByCAPCTag(ResourceTypeLoadBalancerRule, resp.Id); err != nil {
+		return "", errors.Wrap(err, "adding created by CAPC tag")
+	}
+
+	return resp.Id, nil
+}
+
+// AssignVMToVPCLoadBalancerRules assigns a VM to the load balancer rules of the VPC, if not already assigned.
+func (c *client) AssignVMToVPCLoadBalancerRules(vpc *infrav1.CloudStackVPC, instanceID string) error {
+	for _, lbRuleID := range vpc.Status.LoadBalancerRuleIDs {
+		lbRuleInstances, err := c.cs.LoadBalancer.ListLoadBalancerRuleInstances(
+			c.cs.LoadBalancer.NewListLoadBalancerRuleInstancesParams(lbRuleID))
+		if err != nil {
+			c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+
+			return err
+		}
+		found := false
+		for _, instance := range lbRuleInstances.LoadBalancerRuleInstances {
+			if instance.Id == instanceID {
+				found = true
+
+				break
+			}
+		}
+		if found {
+			continue
+		}
+
+		p := c.cs.LoadBalancer.NewAssignToLoadBalancerRuleParams(lbRuleID)
+		p.SetVirtualmachineids([]string{instanceID})
+		if _, err = c.cs.LoadBalancer.AssignToLoadBalancerRule(p); err != nil {
+			c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ReleaseVPCPublicIPAddress removes the cluster's tag from the API server load balancer IP of the VPC, and releases
+// the IP unless another cluster still uses it.
+func (c *client) ReleaseVPCPublicIPAddress(vpc *infrav1.CloudStackVPC, csCluster *infrav1.CloudStackCluster) error {
+	lb := vpc.Status.APIServerLoadBalancer
+	if lb == nil || lb.IPAddressID == "" {
+		return nil
+	}
+	if err := c.DeleteClusterTag(ResourceTypeIPAddress, lb.IPAddressID, csCluster); err != nil {
+		return err
+	}
+	_, err := c.DisassociatePublicIPAddressIfNotInUse(lb.IPAddressID)
+
+	return err
+}
+
+// DisposeVPCResources releases the load balancer IP and removes the tiers, their ACL lists and the VPC, as far as CAPC
+// created them and no other cluster still uses them.
+func (c *client) DisposeVPCResources(vpc *infrav1.CloudStackVPC, csCluster *infrav1.CloudStackCluster) error {
+	if err := c.ReleaseVPCPublicIPAddress(vpc, csCluster); err != nil {
+		return err
+	}
+
+	tiersLeft := false
+	for _, tier := range []infrav1.VPCTierStatus{vpc.Status.ControlPlaneTier, vpc.Status.WorkerTier} {
+		if tier.ID == "" {
+			continue
+		}
+		deleted, err := c.disposeVPCTier(tier, csCluster)
+		if err != nil {
+			return err
+		}
+		tiersLeft = tiersLeft || !deleted
+	}
+	if tiersLeft || vpc.Spec.ID == "" {
+		return nil
+	}
+
+	if err := c.DeleteClusterTag(ResourceTypeVPC, vpc.Spec.ID, csCluster); err != nil {
+		return err
+	}
+	if allowDisposal, err := c.DoClusterTagsAllowDisposal(ResourceTypeVPC, vpc.Spec.ID); err != nil || !allowDisposal {
+		return err
+	}
+	if _, err := c.cs.VPC.DeleteVPC(c.cs.VPC.NewDeleteVPCParams(vpc.Spec.ID)); err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+
+		return errors.Wrapf(err, "deleting VPC with ID %s", vpc.Spec.ID)
+	}
+
+	return nil
+}
+
+// disposeVPCTier removes the cluster's tag from a tier and deletes the tier and its ACL list once unused. It returns
+// whether the tier is gone.
+func (c *client) disposeVPCTier(tier infrav1.VPCTierStatus, csCluster *infrav1.CloudStackCluster) (bool, error) {
+	net := infrav1.Network{ID: tier.ID}
+	if err := c.RemoveClusterTagFromNetwork(csCluster, net); err != nil {
+		return false, err
+	}
+	if allowDisposal, err := c.DoClusterTagsAllowDisposal(ResourceTypeNetwork, tier.ID); err != nil || !allowDisposal {
+		return false, err
+	}
+	if err := c.DeleteNetwork(net); err != nil {
+		return false, err
+	}
+
+	if tier.ACLID == "" {
+		return true, nil
+	}
+	if isCAPCManaged, err := c.IsCapcManaged(ResourceTypeNetworkACLList, tier.ACLID); err != nil || !isCAPCManaged {
+		return true, err
+	}
+	if _, err := c.cs.NetworkACL.DeleteNetworkACLList(c.cs.NetworkACL.NewDeleteNetworkACLListParams(tier.ACLID)); err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+
+		return true, errors.Wrapf(err, "deleting network ACL list with ID %s", tier.ACLID)
+	}
+
+	return true, nil
+}
@@ -0,0 +1,215 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloud_test
+
+import (
+	csapi "github.com/apache/cloudstack-go/v2/cloudstack"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"k8s.io/utils/ptr"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
+	"sigs.k8s.io/cluster-api-provider-cloudstack/pkg/cloud"
+	dummies "sigs.k8s.io/cluster-api-provider-cloudstack/test/dummies/v1beta3"
+)
+
+var _ = Describe("VPC", func() {
+	var (
+		mockCtrl   *gomock.Controller
+		mockClient *csapi.CloudStackClient
+		vs         *csapi.MockVPCServiceIface
+		acls       *csapi.MockNetworkACLServiceIface
+		ns         *csapi.MockNetworkServiceIface
+		lbs        *csapi.MockLoadBalancerServiceIface
+		rs         *csapi.MockResourcetagsServiceIface
+		client     cloud.Client
+		vpc        *infrav1.CloudStackVPC
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockClient = csapi.NewMockClient(mockCtrl)
+		vs = mockClient.VPC.(*csapi.MockVPCServiceIface)
+		acls = mockClient.NetworkACL.(*csapi.MockNetworkACLServiceIface)
+		ns = mockClient.Network.(*csapi.MockNetworkServiceIface)
+		lbs = mockClient.LoadBalancer.(*csapi.MockLoadBalancerServiceIface)
+		rs = mockClient.Resourcetags.(*csapi.MockResourcetagsServiceIface)
+		client = cloud.NewClientFromCSAPIClient(mockClient, nil)
+		dummies.SetDummyVars()
+		vpc = &infrav1.CloudStackVPC{Spec: infrav1.CloudStackVPCSpec{
+			Name: "vpc1",
+			VPCSpec: infrav1.VPCSpec{
+				CIDR:     infrav1.DefaultVPCCIDR,
+				Offering: infrav1.DefaultVPCOffering,
+				ControlPlaneTier: infrav1.VPCTierSpec{
+					Name: "vpc1-control-plane",
+					CIDR: infrav1.DefaultControlPlaneTierCIDR,
+					ACL:  []infrav1.NetworkACLRule{{Protocol: "tcp", StartPort: 6443}},
+				},
+			},
+		}}
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	Context("Getting or creating the VPC", func() {
+		It("adopts an existing VPC with the name", func() {
+			vs.EXPECT().GetVPCByName("vpc1", gomock.Any()).Return(&csapi.VPC{Id: "vpc-id", Cidr: "10.1.0.0/16"}, 1, nil)
+
+			Ω(client.GetOrCreateVPC(dummies.CSFailureDomain1, vpc)).Should(Succeed())
+			Ω(vpc.Spec.ID).Should(Equal("vpc-id"))
+			Ω(vpc.Spec.CIDR).Should(Equal("10.1.0.0/16"))
+		})
+
+		It("creates the VPC when there's none with the name", func() {
+			vs.EXPECT().GetVPCByName("vpc1", gomock.Any()).Return(nil, 0, errors.New("No match found for vpc1"))
+			vs.EXPECT().GetVPCOfferingID(infrav1.DefaultVPCOffering).Return("offering-id", 1, nil)
+			vs.EXPECT().NewCreateVPCParams(infrav1.DefaultVPCCIDR, "vpc1", "vpc1", "offering-id", dummies.CSFailureDomain1.Spec.Zone.ID).
+				Return(&csapi.CreateVPCParams{})
+			vs.EXPECT().CreateVPC(gomock.Any()).Return(&csapi.CreateVPCResponse{Id: "vpc-id", Cidr: infrav1.DefaultVPCCIDR}, nil)
+			rs.EXPECT().NewCreateTagsParams([]string{"vpc-id"}, string(cloud.ResourceTypeVPC), gomock.Any()).
+				Return(&csapi.CreateTagsParams{})
+			rs.EXPECT().CreateTags(gomock.Any()).Return(&csapi.CreateTagsResponse{}, nil)
+
+			Ω(client.GetOrCreateVPC(dummies.CSFailureDomain1, vpc)).Should(Succeed())
+			Ω(vpc.Spec.ID).Should(Equal("vpc-id"))
+		})
+
+		It("fails on errors other than a missing VPC", func() {
+			vs.EXPECT().GetVPCByName("vpc1", gomock.Any()).Return(nil, -1, errors.New("API failure"))
+
+			Ω(client.GetOrCreateVPC(dummies.CSFailureDomain1, vpc)).ShouldNot(Succeed())
+		})
+	})
+
+	Context("Getting or creating a VPC tier", func() {
+		BeforeEach(func() {
+			vpc.Spec.ID = "vpc-id"
+		})
+
+		It("replaces changed network ACL rules and keeps matching ones", func() {
+			tier := vpc.Spec.ControlPlaneTier
+			tier.ACL = append(tier.ACL, infrav1.NetworkACLRule{Protocol: "all", TrafficType: infrav1.NetworkACLTrafficTypeEgress})
+			acls.EXPECT().GetNetworkACLListID("vpc1-control-plane-acl", gomock.Any()).Return("acl-id", 1, nil)
+			acls.EXPECT().NewListNetworkACLsParams().Return(&csapi.ListNetworkACLsParams{})
+			acls.EXPECT().ListNetworkACLs(gomock.Any()).Return(&csapi.ListNetworkACLsResponse{NetworkACLs: []*csapi.NetworkACL{
+				{Id: "kept", Number: 1, Protocol: "tcp", Cidrlist: "0.0.0.0/0", Startport: "6443", Endport: "6443", Traffictype: "Ingress", Action: "Allow"},
+				{Id: "stale", Number: 2, Protocol: "all", Cidrlist: "0.0.0.0/0", Traffictype: "Ingress", Action: "Allow"},
+			}}, nil)
+			acls.EXPECT().NewDeleteNetworkACLParams("stale").Return(&csapi.DeleteNetworkACLParams{})
+			acls.EXPECT().DeleteNetworkACL(gomock.Any()).Return(&csapi.DeleteNetworkACLResponse{}, nil)
+			acls.EXPECT().NewCreateNetworkACLParams("all").Return(&csapi.CreateNetworkACLParams{})
+			acls.EXPECT().CreateNetworkACL(gomock.Any()).DoAndReturn(func(p *csapi.CreateNetworkACLParams) (*csapi.CreateNetworkACLResponse, error) {
+				number, _ := p.GetNumber()
+				trafficType, _ := p.GetTraffictype()
+				Ω(number).Should(Equal(2))
+				Ω(trafficType).Should(Equal(infrav1.NetworkACLTrafficTypeEgress))
+
+				return &csapi.CreateNetworkACLResponse{}, nil
+			})
+			ns.EXPECT().GetNetworkByName("vpc1-control-plane", gomock.Any(), gomock.Any()).Return(&csapi.Network{Id: "tier-id", Aclid: "acl-id"}, 1, nil)
+
+			status := &infrav1.VPCTierStatus{}
+			Ω(client.GetOrCreateVPCTier(dummies.CSFailureDomain1, vpc, tier, status)).Should(Succeed())
+			Ω(status.ID).Should(Equal("tier-id"))
+			Ω(status.ACLID).Should(Equal("acl-id"))
+		})
+
+		It("creates a missing tier on the tier's network ACL list", func() {
+			tier := vpc.Spec.ControlPlaneTier
+			acls.EXPECT().GetNetworkACLListID("vpc1-control-plane-acl", gomock.Any()).Return("acl-id", 1, nil)
+			acls.EXPECT().NewListNetworkACLsParams().Return(&csapi.ListNetworkACLsParams{})
+			acls.EXPECT().ListNetworkACLs(gomock.Any()).Return(&csapi.ListNetworkACLsResponse{NetworkACLs: []*csapi.NetworkACL{
+				{Id: "kept", Number: 1, Protocol: "tcp", Cidrlist: "0.0.0.0/0", Startport: "6443", Endport: "6443", Traffictype: "Ingress", Action: "Allow"},
+			}}, nil)
+			ns.EXPECT().GetNetworkByName("vpc1-control-plane", gomock.Any(), gomock.Any()).Return(nil, 0, errors.New("No match found for vpc1-control-plane"))
+			mockClient.NetworkOffering.(*csapi.MockNetworkOfferingServiceIface).EXPECT().
+				GetNetworkOfferingID(cloud.VPCTierNetOffering).Return("offering-id", 1, nil)
+			ns.EXPECT().NewCreateNetworkParams("vpc1-control-plane", "offering-id", dummies.CSFailureDomain1.Spec.Zone.ID).
+				Return(&csapi.CreateNetworkParams{})
+			ns.EXPECT().CreateNetwork(gomock.Any()).DoAndReturn(func(p *csapi.CreateNetworkParams) (*csapi.CreateNetworkResponse, error) {
+				vpcID, _ := p.GetVpcid()
+				aclID, _ := p.GetAclid()
+				gateway, _ := p.GetGateway()
+				Ω(vpcID).Should(Equal("vpc-id"))
+				Ω(aclID).Should(Equal("acl-id"))
+				Ω(gateway).Should(Equal("10.0.1.1"))
+
+				return &csapi.CreateNetworkResponse{Id: "tier-id"}, nil
+			})
+			rs.EXPECT().NewCreateTagsParams([]string{"tier-id"}, string(cloud.ResourceTypeNetwork), gomock.Any()).
+				Return(&csapi.CreateTagsParams{})
+			rs.EXPECT().CreateTags(gomock.Any()).Return(&csapi.CreateTagsResponse{}, nil)
+
+			status := &infrav1.VPCTierStatus{}
+			Ω(client.GetOrCreateVPCTier(dummies.CSFailureDomain1, vpc, tier, status)).Should(Succeed())
+			Ω(status.ID).Should(Equal("tier-id"))
+		})
+	})
+
+	Context("Defaulting the control plane tier ACL", func() {
+		BeforeEach(func() {
+			dummies.CSCluster.Spec.ControlPlaneEndpoint.Port = 6443
+			dummies.CSCluster.Spec.APIServerLoadBalancer = &infrav1.APIServerLoadBalancer{
+				Enabled: ptr.To(true), AdditionalPorts: []int{8443},
+			}
+		})
+
+		It("opens the API server ports to all without allowed CIDRs", func() {
+			Ω(cloud.ControlPlaneTierACL(vpc, dummies.CSCluster)).Should(Equal([]infrav1.NetworkACLRule{
+				{Protocol: "all", CIDRs: []string{infrav1.DefaultVPCCIDR}, TrafficType: infrav1.NetworkACLTrafficTypeIngress},
+				{Protocol: "tcp", CIDRs: []string{"0.0.0.0/0"}, StartPort: 6443, TrafficType: infrav1.NetworkACLTrafficTypeIngress},
+				{Protocol: "tcp", CIDRs: []string{"0.0.0.0/0"}, StartPort: 8443, TrafficType: infrav1.NetworkACLTrafficTypeIngress},
+				{Protocol: "all", TrafficType: infrav1.NetworkACLTrafficTypeEgress},
+			}))
+		})
+
+		It("only opens the API server ports to the allowed CIDRs and the VPC's own public IP", func() {
+			dummies.CSCluster.Spec.APIServerLoadBalancer.AdditionalPorts = nil
+			dummies.CSCluster.Spec.APIServerLoadBalancer.AllowedCIDRs = []string{"192.168.0.0/24", "10.10.10.10"}
+			vpc.Status.APIServerLoadBalancer = &infrav1.LoadBalancer{IPAddress: "203.0.113.10"}
+
+			Ω(cloud.ControlPlaneTierACL(vpc, dummies.CSCluster)).Should(ContainElement(infrav1.NetworkACLRule{
+				Protocol:    "tcp",
+				CIDRs:       []string{"10.10.10.10/32", "192.168.0.0/24", "203.0.113.10/32"},
+				StartPort:   6443,
+				TrafficType: infrav1.NetworkACLTrafficTypeIngress,
+			}))
+			Ω(cloud.ControlPlaneTierACL(vpc, dummies.CSCluster)).ShouldNot(ContainElement(HaveField("CIDRs", ContainElement("0.0.0.0/0"))))
+		})
+	})
+
+	Context("Assigning VMs to the VPC load balancer", func() {
+		It("only assigns the VM to rules it isn't assigned to yet", func() {
+			vpc.Status.LoadBalancerRuleIDs = []string{"rule1", "rule2"}
+			lbs.EXPECT().NewListLoadBalancerRuleInstancesParams("rule1").Return(&csapi.ListLoadBalancerRuleInstancesParams{})
+			lbs.EXPECT().NewListLoadBalancerRuleInstancesParams("rule2").Return(&csapi.ListLoadBalancerRuleInstancesParams{})
+			lbs.EXPECT().ListLoadBalancerRuleInstances(gomock.Any()).Return(&csapi.ListLoadBalancerRuleInstancesResponse{
+				LoadBalancerRuleInstances: []*csapi.VirtualMachine{{Id: "vm-id"}},
+			}, nil)
+			lbs.EXPECT().ListLoadBalancerRuleInstances(gomock.Any()).Return(&csapi.ListLoadBalancerRuleInstancesResponse{}, nil)
+			lbs.EXPECT().NewAssignToLoadBalancerRuleParams("rule2").Return(&csapi.AssignToLoadBalancerRuleParams{})
+			lbs.EXPECT().AssignToLoadBalancerRule(gomock.Any()).Return(&csapi.AssignToLoadBalancerRuleResponse{}, nil)
+
+			Ω(client.AssignVMToVPCLoadBalancerRules(vpc, "vm-id")).Should(Succeed())
+		})
+	})
+})
@@ -27,6 +27,22 @@ import (
 type ZoneIFace interface {
 	ResolveZone(zSpec *infrav1.CloudStackZoneSpec) error
 	ResolveNetworkForZone(zSpec *infrav1.CloudStackZoneSpec) error
+	GetZoneAllocationState(zoneID string) (string, error)
+	GetNetworkState(networkID string) (string, error)
+}
+
+// ZoneAllocationStateEnabled is the allocation state of a zone that VMs may be deployed in.
+const ZoneAllocationStateEnabled = "Enabled"
+
+// Network states in which a network can't serve new or existing VMs.
+const (
+	NetworkStateShutdown = "Shutdown"
+	NetworkStateDestroy  = "Destroy"
+)
+
+// IsNetworkDegraded returns whether a network in the state can't be used.
+func IsNetworkDegraded(state string) bool {
+	return state == NetworkStateShutdown || state == NetworkStateDestroy
 }
 
 func (c *client) ResolveZone(zSpec *infrav1.CloudStackZoneSpec) (retErr error) {
@@ -87,3 +103,31 @@ func (c *client) ResolveNetworkForZone(zSpec *infrav1.CloudStackZoneSpec) (retEr
 
 	return nil
 }
+
+// GetZoneAllocationState returns whether the zone is Enabled or Disabled for allocation.
+func (c *client) GetZoneAllocationState(zoneID string) (string, error) {
+	zone, count, err := c.cs.Zone.GetZoneByID(zoneID)
+	if err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+
+		return "", errors.Wrapf(err, "could not get Zone by ID %s", zoneID)
+	} else if count != 1 {
+		return "", errors.Errorf("expected 1 Zone with UUID %s, but got %d", zoneID, count)
+	}
+
+	return zone.Allocationstate, nil
+}
+
+// GetNetworkState returns the state of the network, e.g. Implemented.
+func (c *client) GetNetworkState(networkID string) (string, error) {
+	network, count, err := c.cs.Network.GetNetworkByID(networkID, cloudstack.WithProject(c.user.Project.ID))
+	if err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+
+		return "", errors.Wrapf(err, "could not get Network by ID %s", networkID)
+	} else if count != 1 {
+		return "", errors.Errorf("expected 1 Network with UUID %s, but got %d", networkID, count)
+	}
+
+	return network.State, nil
+}
@@ -113,4 +113,34 @@ var _ = Describe("Zone", func() {
 			Ω(client.ResolveNetworkForZone(&dummies.CSFailureDomain2.Spec.Zone).Error()).Should(ContainSubstring("could not get Network by ID " + dummies.Zone2.Network.ID))
 		})
 	})
+
+	Context("Availability", func() {
+		It("returns the allocation state of the zone", func() {
+			zs.EXPECT().GetZoneByID(dummies.Zone1.ID).Return(&csapi.Zone{Allocationstate: "Disabled"}, 1, nil)
+
+			Ω(client.GetZoneAllocationState(dummies.Zone1.ID)).Should(Equal("Disabled"))
+		})
+
+		It("returns the error of a zone lookup", func() {
+			zs.EXPECT().GetZoneByID(dummies.Zone1.ID).Return(nil, -1, fakeError)
+
+			_, err := client.GetZoneAllocationState(dummies.Zone1.ID)
+			Ω(err).Should(MatchError(ContainSubstring("could not get Zone by ID")))
+		})
+
+		It("returns the state of the network", func() {
+			ns.EXPECT().GetNetworkByID(dummies.Zone2.Network.ID, gomock.Any()).Return(&csapi.Network{State: cloud.NetworkStateShutdown}, 1, nil)
+
+			state, err := client.GetNetworkState(dummies.Zone2.Network.ID)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(cloud.IsNetworkDegraded(state)).Should(BeTrue())
+		})
+
+		It("fails if the network isn't found", func() {
+			ns.EXPECT().GetNetworkByID(dummies.Zone2.Network.ID, gomock.Any()).Return(nil, 0, nil)
+
+			_, err := client.GetNetworkState(dummies.Zone2.Network.ID)
+			Ω(err).Should(MatchError(ContainSubstring("expected 1 Network with UUID")))
+		})
+	})
 })
@@ -70,3 +70,61 @@ func (m *ACSCustomMetrics) EvaluateErrorAndIncrementAcsReconciliationErrorCounte
 		}
 	}
 }
+
+// ClientCacheMetrics encapsulates the metrics of the CloudStack client cache.
+type ClientCacheMetrics struct {
+	hits      prometheus.Counter
+	misses    prometheus.Counter
+	evictions *prometheus.CounterVec
+}
+
+// NewClientCacheMetrics constructs a ClientCacheMetrics, registering its collectors if they aren't already.
+func NewClientCacheMetrics() ClientCacheMetrics {
+	return ClientCacheMetrics{
+		hits: registerOrGetExisting(prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "acs_client_cache_hits_total",
+			Help: "Count of CloudStack client lookups served from the client cache",
+		})).(prometheus.Counter),
+		misses: registerOrGetExisting(prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "acs_client_cache_misses_total",
+			Help: "Count of CloudStack client lookups that had to build a new client",
+		})).(prometheus.Counter),
+		evictions: registerOrGetExisting(prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "acs_client_cache_evictions_total",
+				Help: "Count of CloudStack clients evicted from the client cache, bucketed by reason",
+			},
+			[]string{"reason"},
+		)).(*prometheus.CounterVec),
+	}
+}
+
+// IncrementHits counts a client cache hit.
+func (m *ClientCacheMetrics) IncrementHits() {
+	m.hits.Inc()
+}
+
+// IncrementMisses counts a client cache miss.
+func (m *ClientCacheMetrics) IncrementMisses() {
+	m.misses.Inc()
+}
+
+// IncrementEvictions counts a client evicted from the cache for the passed reason.
+func (m *ClientCacheMetrics) IncrementEvictions(reason string) {
+	m.evictions.WithLabelValues(reason).Inc()
+}
+
+// registerOrGetExisting registers the collector with the controller-runtime registry, returning the collector that
+// was registered before it if there is one.
+func registerOrGetExisting(collector prometheus.Collector) prometheus.Collector {
+	if err := crtlmetrics.Registry.Register(collector); err != nil {
+		are := prometheus.AlreadyRegisteredError{}
+		if errors.As(err, &are) {
+			return are.ExistingCollector
+		}
+		// Something else went wrong!
+		panic(err)
+	}
+
+	return collector
+}
@@ -27,7 +27,11 @@ func GetYamlVal(variable string) string {
 var ( // Declare exported dummy vars.
 	AffinityGroup           *cloud.AffinityGroup
 	CSAffinityGroup         *infrav1.CloudStackAffinityGroup
+	SSHKeyPair              *cloud.SSHKeyPair
+	CSSSHKeyPair            *infrav1.CloudStackSSHKeyPair
 	CSCluster               *infrav1.CloudStackCluster
+	Bastion                 *infrav1.Bastion
+	APIServerVMLoadBalancer *infrav1.APIServerVMLoadBalancer
 	CAPIMachine             *clusterv1.Machine
 	CSMachine1              *infrav1.CloudStackMachine
 	CAPICluster             *clusterv1.Cluster
@@ -342,6 +346,31 @@ func SetDummyCAPCClusterVars() {
 			Type:              AffinityGroup.Type,
 			ID:                AffinityGroup.ID}}
 
+	SSHKeyPair = &cloud.SSHKeyPair{Name: "fake-keypair"}
+	CSSSHKeyPair = &infrav1.CloudStackSSHKeyPair{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: CSApiVersion,
+			Kind:       "CloudStackSSHKeyPair",
+		},
+		ObjectMeta: metav1.ObjectMeta{Name: SSHKeyPair.Name, Namespace: "default", Labels: ClusterLabel},
+		Spec: infrav1.CloudStackSSHKeyPairSpec{
+			FailureDomainName: CSFailureDomain1.Spec.Name,
+			Name:              SSHKeyPair.Name}}
+
+	Bastion = &infrav1.Bastion{
+		FailureDomainName: CSFailureDomain1.Spec.Name,
+		Offering:          infrav1.CloudStackResourceIdentifier{Name: "BastionOffering"},
+		Template:          infrav1.CloudStackResourceIdentifier{Name: "BastionTemplate"},
+		SSHKey:            SSHKeyPair.Name,
+		AllowedCIDRs:      []string{"198.51.100.0/24"},
+	}
+	APIServerVMLoadBalancer = &infrav1.APIServerVMLoadBalancer{
+		FailureDomainName: CSFailureDomain1.Spec.Name,
+		Offering:          infrav1.CloudStackResourceIdentifier{Name: "LoadBalancerOffering"},
+		Template:          infrav1.CloudStackResourceIdentifier{Name: "HAProxyTemplate"},
+		VirtualIP:         "10.0.0.10",
+	}
+
 	CSCluster = &infrav1.CloudStackCluster{
 		TypeMeta: metav1.TypeMeta{
 			APIVersion: CSApiVersion,